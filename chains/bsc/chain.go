@@ -143,23 +143,23 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 		method    = chain.GetMethod(log.Topics[0])
 		bigNumber = big.NewInt(int64(log.BlockNumber))
 	)
-	txsHash, err := mapprotocol.GetTxsByBn(client, bigNumber)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-	}
 	var receipts []*types.Receipt
-	key := strconv.FormatUint(selfId, 10) + "_" + bigNumber.String()
-	if v, ok := proof.CacheReceipt.Get(key); ok {
-		receipts = v.([]*types.Receipt)
-	} else {
-		if proofType == constant.ProofTypeOfNewOracle {
-			receipts, err = tx.GetReceiptsByTxsHash(client, txsHash)
+	if proofType == constant.ProofTypeOfNewOracle {
+		var err error
+		receipts, err = proof.CacheReceipt.Receipts(selfId, log.BlockHash, func() ([]*types.Receipt, error) {
+			txsHash, err := mapprotocol.GetTxsByBn(client, bigNumber)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+			}
+			receipts, err := tx.GetBlockReceipts(client, bigNumber, txsHash)
 			if err != nil {
 				return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
 			}
+			return receipts, nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		proof.CacheReceipt.Add(key, receipts)
 	}
 
 	headers := make([]*ethclient.BscHeader, mapprotocol.HeaderCountOfBsc)
@@ -177,7 +177,7 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 		params = append(params, bsc.ConvertHeader(h))
 	}
 
-	ret, err := bsc.AssembleProof(params, log, receipts, method, msg.ChainId(selfId),
+	ret, err := bsc.AssembleProof(client, params, log, receipts, method, msg.ChainId(selfId),
 		proofType, sign)
 	if err != nil {
 		return nil, fmt.Errorf("unable to Parse Log: %w", err)
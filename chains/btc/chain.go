@@ -0,0 +1,116 @@
+// Package btc is the bitcoin chain type. Bitcoin has no contract logs, the
+// BTC log service indexes the bridge's transactions into logs, and btc
+// relays those to MAP the way sol relays its filtered logs.
+package btc
+
+import (
+	"fmt"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+type Chain struct {
+	cfg    *core.ChainConfig
+	conn   core.Connection
+	writer *Writer
+	stop   chan<- int
+	listen core.Listener
+}
+
+func New() *Chain {
+	return &Chain{}
+}
+
+func (c *Chain) New(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, role mapprotocol.Role) (core.Chain, error) {
+	return createChain(chainCfg, logger, sysErr, role)
+}
+
+func createChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, role mapprotocol.Role) (core.Chain, error) {
+	config, err := parseCfg(chainCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var handler Handler
+	switch role {
+	case mapprotocol.RoleOfMessenger:
+		handler = messenger
+	case mapprotocol.RoleOfOracle:
+		handler = oracle
+	default:
+		return nil, fmt.Errorf("chain %s: btc has no %s", config.Name, role)
+	}
+	if chainCfg.StartLatest {
+		logger.Warn("Btc starts from the blockstore or startLogId, the log service has no latest id")
+	}
+
+	var (
+		conn = NewConnection()
+		stop = make(chan int)
+	)
+	bs, err := chain.SetupBlockStore(&config.Config, role)
+	if err != nil {
+		return nil, err
+	}
+	if !config.FreshStart {
+		// the blockstore of a btc chain keeps the log id, not a block
+		id, err := bs.TryLoadLatestBlock()
+		if err != nil {
+			return nil, err
+		}
+		if id.Int64() > config.LogId {
+			config.LogId = id.Int64()
+		}
+	}
+	cs := chain.NewCommonSync(conn, &config.Config, logger, stop, sysErr, bs)
+	cs.RegisterState(config.Name, string(role))
+
+	return &Chain{
+		conn:   conn,
+		stop:   stop,
+		listen: newSync(cs, handler, NewHttpLogClient(config.BtcHost), config),
+		cfg:    chainCfg,
+		writer: newWriter(logger),
+	}, nil
+}
+
+func (c *Chain) SetRouter(r core.Router) {
+	r.Listen(c.cfg.Id, c.writer)
+	c.listen.SetRouter(r)
+}
+
+func (c *Chain) Start() error {
+	err := c.listen.Sync()
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Successfully started Chain")
+	return nil
+}
+
+func (c *Chain) Id() msg.ChainId {
+	return c.cfg.Id
+}
+
+func (c *Chain) Name() string {
+	return c.cfg.Name
+}
+
+// Stop signals to any running routines to exit
+func (c *Chain) Stop() {
+	close(c.stop)
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// Conn return Connection interface for relayer register
+func (c *Chain) Conn() core.Connection {
+	return c.conn
+}
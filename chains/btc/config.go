@@ -0,0 +1,52 @@
+package btc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+)
+
+// StartLogIdOpt is the id of the log service the relay starts after.
+const StartLogIdOpt = "startLogId"
+
+type Config struct {
+	chain.Config
+	Mcs    string   // address of the btc bridge the logs are of
+	Topics []string // topics of the relayed logs
+	LogId  int64    // id of the last log relayed, the cursor of the log service
+}
+
+func parseCfg(chainCfg *core.ChainConfig) (*Config, error) {
+	cfg, err := chain.ParseConfig(chainCfg)
+	if err != nil {
+		return nil, err
+	}
+	ret := Config{
+		Config: *cfg,
+	}
+
+	if ele, ok := chainCfg.Opts[chain.McsOpt]; ok && ele != "" {
+		ret.Mcs = ele
+	}
+	if v, ok := chainCfg.Opts[chain.Event]; ok && v != "" {
+		ret.Topics = append(ret.Topics, strings.Split(v, "|")...)
+	}
+	if v, ok := chainCfg.Opts[StartLogIdOpt]; ok && v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("chain %s: unable to parse %s", chainCfg.Name, StartLogIdOpt)
+		}
+		ret.LogId = id
+	}
+
+	if ret.BtcHost == "" {
+		return nil, fmt.Errorf("chain %s: btc needs the btc_url of the log service", chainCfg.Name)
+	}
+	if ret.Mcs == "" || len(ret.Topics) == 0 {
+		return nil, fmt.Errorf("chain %s: btc needs the %s and %s options", chainCfg.Name, chain.McsOpt, chain.Event)
+	}
+	return &ret, nil
+}
@@ -0,0 +1,64 @@
+package btc
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+)
+
+// Connection stands in for the rpc connection btc doesn't have, everything
+// comes from the log service.
+type Connection struct {
+	stop chan int
+}
+
+func NewConnection() *Connection {
+	return &Connection{stop: make(chan int)}
+}
+
+func (c *Connection) Connect() error {
+	return nil
+}
+
+func (c *Connection) Keypair() *keystore.Key {
+	return nil
+}
+
+func (c *Connection) Client() *ethclient.Client {
+	return nil
+}
+
+func (c *Connection) Opts() *bind.TransactOpts {
+	return nil
+}
+
+func (c *Connection) CallOpts() *bind.CallOpts {
+	return nil
+}
+
+func (c *Connection) UnlockOpts() {
+}
+
+func (c *Connection) LockAndUpdateOpts(needNewNonce bool) error {
+	return nil
+}
+
+func (c *Connection) LatestBlock() (*big.Int, error) {
+	return nil, errors.New("btc has no rpc connection")
+}
+
+func (c *Connection) EnsureHasBytecode(addr ethcommon.Address) error {
+	return nil
+}
+
+func (c *Connection) WaitForBlock(targetBlock *big.Int, delay *big.Int) error {
+	return nil
+}
+
+func (c *Connection) Close() {
+	close(c.stop)
+}
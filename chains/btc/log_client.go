@@ -0,0 +1,54 @@
+package btc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/stream"
+	"github.com/pkg/errors"
+)
+
+type LogListRequest struct {
+	StartID int64 // logs after this id
+	ChainID int64
+	Topic   string // topics, multiple with , interval
+	Limit   int
+}
+
+// LogClient is where the btc bridge logs come from, the BTC log service
+// indexes them off the bitcoin chain.
+type LogClient interface {
+	ListLogs(req LogListRequest) (*stream.BtcLogListResp, error)
+}
+
+type HttpLogClient struct {
+	Host string
+}
+
+func NewHttpLogClient(host string) *HttpLogClient {
+	return &HttpLogClient{Host: host}
+}
+
+func (c *HttpLogClient) ListLogs(req LogListRequest) (*stream.BtcLogListResp, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 1
+	}
+	data, err := chain.Request(fmt.Sprintf("%s/%s?id=%d&chain_id=%d&topic=%s&limit=%d", c.Host, constant.FilterBtcLogUrl,
+		req.StartID, req.ChainID, url.QueryEscape(req.Topic), limit))
+	if err != nil {
+		return nil, err
+	}
+	listData, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal resp.Data failed")
+	}
+	back := stream.BtcLogListResp{}
+	if err = json.Unmarshal(listData, &back); err != nil {
+		return nil, err
+	}
+	return &back, nil
+}
@@ -0,0 +1,219 @@
+package btc
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// Handler relays the log after the cursor and returns its id, 0 when there
+// was none.
+type Handler func(*sync) (int64, error)
+
+var (
+	getSigner   = chain.GetSigner
+	mulSignInfo = chain.MulSignInfo
+)
+
+type sync struct {
+	*chain.CommonSync
+	handler Handler
+	client  LogClient
+	cfg     *Config
+}
+
+func newSync(cs *chain.CommonSync, handler Handler, client LogClient, cfg *Config) *sync {
+	return &sync{CommonSync: cs, handler: handler, client: client, cfg: cfg}
+}
+
+func (m *sync) Sync() error {
+	m.Log.Info("Starting listener...")
+	if !m.Cfg.SyncToMap {
+		time.Sleep(time.Hour * 2400)
+		return nil
+	}
+	go func() {
+		err := m.sync()
+		if err != nil {
+			m.Log.Error("Polling btc logs failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+func (m *sync) sync() error {
+	for {
+		select {
+		case <-m.Stop:
+			return errors.New("polling terminated")
+		default:
+			id, err := m.handler(m)
+			if err != nil {
+				if errors.Is(err, chain.NotVerifyAble) {
+					m.Log.Info("Btc log not verifiable yet, will retry", "err", err)
+					time.Sleep(constant.BlockRetryInterval)
+					continue
+				}
+				m.Log.Error("Btc handler failed", "err", err)
+				util.Alarm(context.Background(), fmt.Sprintf("btc handler failed, chain=%s, err is %s", m.Cfg.Name, err.Error()))
+				time.Sleep(constant.BlockRetryInterval)
+				continue
+			}
+			if id == 0 {
+				time.Sleep(constant.MessengerInterval)
+				continue
+			}
+
+			m.cfg.LogId = id
+			_ = m.WaitUntilMsgHandled(1)
+			err = m.BlockStore.StoreBlock(big.NewInt(id))
+			if err != nil {
+				m.Log.Error("Failed to write latest block to blockstore", "id", id, "err", err)
+			}
+		}
+	}
+}
+
+// Log is a bridge log the log service indexed. Data is the hex of the
+// MessageOutEvent, abi encoded the way solEventEncode takes it.
+type Log struct {
+	Id          int64
+	BlockNumber int64
+	Topic       string
+	Data        string
+	TxHash      string
+}
+
+// filter returns the log after the cursor, nil when there is none yet.
+func filter(m *sync) (*Log, error) {
+	back, err := m.client.ListLogs(LogListRequest{
+		StartID: m.cfg.LogId,
+		ChainID: int64(m.Cfg.Id),
+		Topic:   strings.Join(m.cfg.Topics, ","),
+		Limit:   1,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list btc logs failed")
+	}
+	if len(back.Items) == 0 {
+		return nil, nil
+	}
+	ele := back.Items[0]
+	return &Log{
+		Id:          ele.Id,
+		BlockNumber: ele.BlockNumber,
+		Topic:       ele.Topic,
+		Data:        ele.LogData,
+		TxHash:      ele.TxHash,
+	}, nil
+}
+
+func messenger(m *sync) (int64, error) {
+	log, err := filter(m)
+	if err != nil {
+		return 0, err
+	}
+	if log == nil || log.Id == 0 {
+		return 0, nil
+	}
+	receiptHash, receiptPack, orderId, err := m.genReceipt(log)
+	if err != nil {
+		return 0, errors.Wrapf(err, "gen receipt of log %d failed", log.Id)
+	}
+	m.Log.Info("Btc2Map messenger generate", "id", log.Id, "receiptHash", receiptHash)
+	bn := proof.GenLogBlockNumber(big.NewInt(log.BlockNumber), 1, uint(log.Id))
+	proposalInfo, err := getSigner(bn, receiptHash, uint64(m.Cfg.Id), uint64(m.Cfg.MapChainID))
+	if err != nil {
+		return 0, err
+	}
+
+	pd := proof.SignLogData{
+		ProofType:   1,
+		BlockNum:    bn,
+		ReceiptRoot: receiptHash,
+		Signatures:  proposalInfo.Signatures,
+		Proof:       receiptPack,
+	}
+	input, err := mapprotocol.GetAbi.Methods[mapprotocol.MethodOfGetBytes].Inputs.Pack(pd)
+	if err != nil {
+		return 0, errors.Wrap(err, "pack getBytes failed")
+	}
+	finalInput, err := mapprotocol.PackInput(mapprotocol.Mcs, mapprotocol.MethodOfMessageIn,
+		big.NewInt(0).SetUint64(uint64(m.Cfg.Id)), big.NewInt(0), orderId, input)
+	if err != nil {
+		return 0, errors.Wrap(err, "pack messageIn failed")
+	}
+
+	message := msg.NewSwapWithProof(m.Cfg.Id, m.Cfg.MapChainID, []interface{}{finalInput,
+		orderId, bn, log.TxHash}, m.MsgCh)
+	err = m.Router.Send(message)
+	if err != nil {
+		m.Log.Error("subscription error: failed to route message", "err", err)
+		return 0, nil
+	}
+	return log.Id, nil
+}
+
+func oracle(m *sync) (int64, error) {
+	log, err := filter(m)
+	if err != nil {
+		return 0, err
+	}
+	if log == nil || log.Id == 0 {
+		return 0, nil
+	}
+	receiptHash, _, _, err := m.genReceipt(log)
+	if err != nil {
+		return 0, errors.Wrapf(err, "gen receipt of log %d failed", log.Id)
+	}
+	m.Log.Info("Btc2Map oracle generate", "id", log.Id, "receiptHash", receiptHash)
+	bn := proof.GenLogBlockNumber(big.NewInt(log.BlockNumber), 1, uint(log.Id))
+
+	ret, err := mulSignInfo(0, uint64(m.Cfg.MapChainID))
+	if err != nil {
+		return 0, errors.Wrap(err, "mul sign failed")
+	}
+	input, err := mapprotocol.PackAbi.Methods[mapprotocol.MethodOfSolidityPack].Inputs.Pack(receiptHash,
+		ret.Version, bn, big.NewInt(int64(m.Cfg.Id)))
+	if err != nil {
+		return 0, errors.Wrap(err, "oracle pack input failed")
+	}
+
+	message := msg.NewProposal(m.Cfg.Id, m.Cfg.MapChainID, []interface{}{input, &receiptHash, bn}, m.MsgCh)
+	err = m.Router.Send(message)
+	if err != nil {
+		m.Log.Error("subscription error: failed to route message", "err", err)
+		return 0, nil
+	}
+	return log.Id, nil
+}
+
+// genReceipt packs log the way the btc light client on MAP hashes it: the
+// bridge address, the topic and the encoded event.
+func (m *sync) genReceipt(log *Log) (common.Hash, []byte, common.Hash, error) {
+	data := common.FromHex(log.Data)
+	args, err := mapprotocol.SolAbi.Methods[mapprotocol.MethodOfSolEventEncode].Inputs.Unpack(data)
+	if err != nil {
+		return common.Hash{}, nil, common.Hash{}, errors.Wrap(err, "unpack log data failed")
+	}
+	eo := *abi.ConvertType(args[0], new(mapprotocol.MessageOutEvent)).(*mapprotocol.MessageOutEvent)
+	receiptPack, err := mapprotocol.SolAbi.Methods[mapprotocol.MethodOfSolPackReceipt].Inputs.Pack([]byte(m.cfg.Mcs),
+		[]byte(log.Topic), data)
+	if err != nil {
+		return common.Hash{}, nil, common.Hash{}, errors.Wrap(err, "pack receipt failed")
+	}
+	return common.BytesToHash(crypto.Keccak256(receiptPack)), receiptPack, eo.OrderId, nil
+}
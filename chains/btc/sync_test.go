@@ -0,0 +1,307 @@
+package btc
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
+	"github.com/mapprotocol/compass/pkg/blockstore"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+const (
+	testChainId = 1360095883558913
+	testMcs     = "bc1q8q7fuufwpqdnpts6yq5jxmuu6m4rlxhs5qj6gr"
+	testTopic   = "MessageOutEvent"
+)
+
+type testLog struct {
+	Id          int64  `json:"id"`
+	ChainId     string `json:"chain_id"`
+	Topic       string `json:"topic"`
+	LogData     string `json:"log_data"`
+	TxHash      string `json:"tx_hash"`
+	TxTimestamp int    `json:"tx_timestamp"`
+	BlockNumber int64  `json:"block_number"`
+}
+
+// logServer is a local BTC log service, it serves the logs after the asked
+// id the way the service pages them.
+type logServer struct {
+	mu      gosync.Mutex
+	logs    []testLog
+	queries []map[string]string
+}
+
+func newLogServer(t *testing.T, logs ...testLog) (string, *logServer) {
+	t.Helper()
+	s := &logServer{logs: logs}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/logs" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		s.mu.Lock()
+		s.queries = append(s.queries, map[string]string{"id": q.Get("id"), "chain_id": q.Get("chain_id"),
+			"topic": q.Get("topic"), "limit": q.Get("limit")})
+		start, _ := strconv.ParseInt(q.Get("id"), 10, 64)
+		limit, _ := strconv.Atoi(q.Get("limit"))
+		items := make([]testLog, 0)
+		for _, l := range s.logs {
+			if l.Id > start && len(items) < limit {
+				items = append(items, l)
+			}
+		}
+		s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 200,
+			"data": map[string]interface{}{"total": len(items), "items": items},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, s
+}
+
+func (s *logServer) asked() []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]string(nil), s.queries...)
+}
+
+// newLog returns the log of a MessageOutEvent with orderId.
+func newLog(t *testing.T, id, blockNumber int64, orderId common.Hash) testLog {
+	t.Helper()
+	eo := mapprotocol.MessageOutEvent{
+		Relay:       true,
+		MessageType: 3,
+		FromChain:   big.NewInt(testChainId),
+		ToChain:     big.NewInt(56),
+		OrderId:     orderId,
+		Mos:         []byte(testMcs),
+		Token:       common.Hex2Bytes("425443"),
+		Initiator:   []byte("bc1qinitiator"),
+		From:        []byte("bc1qinitiator"),
+		To:          common.Hex2Bytes("2e784874ddb32cd7975d68565b509412a5b519f4"),
+		Amount:      big.NewInt(100000),
+		GasLimit:    big.NewInt(0),
+		SwapData:    []byte{},
+	}
+	data, err := mapprotocol.SolAbi.Methods[mapprotocol.MethodOfSolEventEncode].Inputs.Pack(&eo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testLog{
+		Id:          id,
+		ChainId:     strconv.Itoa(testChainId),
+		Topic:       testTopic,
+		LogData:     common.Bytes2Hex(data),
+		TxHash:      "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16",
+		BlockNumber: blockNumber,
+	}
+}
+
+// ackRouter keeps what is sent and handles it right away.
+type ackRouter struct {
+	mu   gosync.Mutex
+	sent []msg.Message
+}
+
+func (r *ackRouter) Send(m msg.Message) error {
+	r.mu.Lock()
+	r.sent = append(r.sent, m)
+	r.mu.Unlock()
+	go func() { m.DoneCh <- struct{}{} }()
+	return nil
+}
+
+func (r *ackRouter) Listen(msg.ChainId, core.Writer) {}
+
+func (r *ackRouter) messages() []msg.Message {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]msg.Message(nil), r.sent...)
+}
+
+func newTestSync(t *testing.T, url string, handler Handler, bs *blockstore.Blockstore) (*sync, *ackRouter, chan int) {
+	t.Helper()
+	cfg := &Config{
+		Config: chain.Config{Name: "btc", Id: testChainId, MapChainID: 22776, SyncToMap: true},
+		Mcs:    testMcs,
+		Topics: []string{testTopic},
+	}
+	stop := make(chan int)
+	cs := chain.NewCommonSync(NewConnection(), &cfg.Config, log15.New(), stop, nil, bs)
+	router := &ackRouter{}
+	cs.SetRouter(router)
+	return newSync(cs, handler, NewHttpLogClient(url), cfg), router, stop
+}
+
+func stubSigner(t *testing.T) *[]common.Hash {
+	t.Helper()
+	signed := make([]common.Hash, 0)
+	old := getSigner
+	getSigner = func(_ *big.Int, receiptHash common.Hash, _, _ uint64) (*chain.ProposalInfoResp, error) {
+		signed = append(signed, receiptHash)
+		return &chain.ProposalInfoResp{Signatures: [][]byte{make([]byte, 65)}, CanVerify: true}, nil
+	}
+	t.Cleanup(func() { getSigner = old })
+	return &signed
+}
+
+func TestListLogs(t *testing.T) {
+	url, srv := newLogServer(t, newLog(t, 3, 800000, common.Hash{1}), newLog(t, 5, 800001, common.Hash{2}))
+	back, err := NewHttpLogClient(url).ListLogs(LogListRequest{StartID: 3, ChainID: testChainId, Topic: "a|b,c", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Items) != 1 || back.Items[0].Id != 5 || back.Items[0].BlockNumber != 800001 {
+		t.Fatalf("listed %+v", back)
+	}
+	q := srv.asked()[0]
+	if q["id"] != "3" || q["chain_id"] != strconv.Itoa(testChainId) || q["topic"] != "a|b,c" || q["limit"] != "10" {
+		t.Fatalf("asked %v", q)
+	}
+}
+
+func TestMessenger(t *testing.T) {
+	orderId := common.HexToHash("0x5ee3a5e4ac4c8f3b0aa5b4d6bd6b6f1e5c2a1d0e9f8a7b6c5d4e3f2a1b0c9d8e")
+	l := newLog(t, 7, 840000, orderId)
+	url, _ := newLogServer(t, l)
+	signed := stubSigner(t)
+	m, router, _ := newTestSync(t, url, messenger, nil)
+
+	id, err := messenger(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 7 || len(router.sent) != 1 {
+		t.Fatalf("relayed %d with %d messages", id, len(router.sent))
+	}
+	sent := router.sent[0]
+	bn := proof.GenLogBlockNumber(big.NewInt(840000), 1, 7)
+	if sent.Type != msg.SwapWithProof || sent.Destination != 22776 || sent.Payload[1] != orderId ||
+		sent.Payload[2].(*big.Int).Cmp(bn) != 0 || sent.Payload[3] != l.TxHash {
+		t.Fatalf("sent %s to %d: %v", sent.Type, sent.Destination, sent.Payload[1:])
+	}
+
+	// the receipt signed is the hash of the bridge, topic and event
+	receiptPack, _ := mapprotocol.SolAbi.Methods[mapprotocol.MethodOfSolPackReceipt].Inputs.Pack([]byte(testMcs),
+		[]byte(testTopic), common.Hex2Bytes(l.LogData))
+	receiptHash := common.BytesToHash(crypto.Keccak256(receiptPack))
+	if len(*signed) != 1 || (*signed)[0] != receiptHash {
+		t.Fatalf("signed %v, want %s", *signed, receiptHash)
+	}
+	args, err := mapprotocol.Mcs.Methods[mapprotocol.MethodOfMessageIn].Inputs.Unpack(sent.Payload[0].([]byte)[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[0].(*big.Int).Int64() != testChainId || common.Hash(args[2].([32]byte)) != orderId {
+		t.Fatalf("messageIn(%v, %v, %x)", args[0], args[1], args[2])
+	}
+
+	// nothing after the cursor
+	m.cfg.LogId = 7
+	if id, err = messenger(m); err != nil || id != 0 {
+		t.Fatalf("got %d, %v", id, err)
+	}
+}
+
+func TestMessengerBadLog(t *testing.T) {
+	url, _ := newLogServer(t, testLog{Id: 4, Topic: testTopic, LogData: "0x1234", BlockNumber: 840000})
+	stubSigner(t)
+	m, router, _ := newTestSync(t, url, messenger, nil)
+	if _, err := messenger(m); err == nil || len(router.sent) != 0 {
+		t.Fatalf("relayed a log that isn't an event: %v", err)
+	}
+}
+
+func TestOracle(t *testing.T) {
+	l := newLog(t, 9, 840002, common.Hash{9})
+	url, _ := newLogServer(t, l)
+	old := mulSignInfo
+	mulSignInfo = func(int, uint64) (*chain.MulSignInfoResp, error) {
+		return &chain.MulSignInfoResp{Version: [32]byte{1}}, nil
+	}
+	t.Cleanup(func() { mulSignInfo = old })
+	m, router, _ := newTestSync(t, url, oracle, nil)
+
+	id, err := oracle(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 9 || len(router.sent) != 1 || router.sent[0].Type != msg.Proposal {
+		t.Fatalf("relayed %d with %v", id, router.sent)
+	}
+	receiptPack, _ := mapprotocol.SolAbi.Methods[mapprotocol.MethodOfSolPackReceipt].Inputs.Pack([]byte(testMcs),
+		[]byte(testTopic), common.Hex2Bytes(l.LogData))
+	if *router.sent[0].Payload[1].(*common.Hash) != common.BytesToHash(crypto.Keccak256(receiptPack)) {
+		t.Fatalf("proposed %s", router.sent[0].Payload[1])
+	}
+}
+
+func TestSyncStoresCursor(t *testing.T) {
+	url, srv := newLogServer(t, newLog(t, 7, 840000, common.Hash{7}), newLog(t, 9, 840002, common.Hash{9}))
+	stubSigner(t)
+	dir := t.TempDir()
+	bs, err := blockstore.NewBlockstore(dir, testChainId, "0x01", mapprotocol.RoleOfMessenger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, router, stop := newTestSync(t, url, messenger, bs)
+
+	done := make(chan error)
+	go func() { done <- m.sync() }()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(router.messages()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	<-done
+
+	if n := len(router.messages()); n != 2 {
+		t.Fatalf("%d messages, want 2", n)
+	}
+	// the second log was asked for after the first
+	if q := srv.asked(); q[0]["id"] != "0" || q[1]["id"] != "7" {
+		t.Fatalf("asked %v", q)
+	}
+	bs, _ = blockstore.NewBlockstore(dir, testChainId, "0x01", mapprotocol.RoleOfMessenger)
+	latest, err := bs.TryLoadLatestBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Int64() != 9 {
+		t.Fatalf("cursor %d, want 9", latest)
+	}
+}
+
+func TestParseCfgLogId(t *testing.T) {
+	cc := &core.ChainConfig{Name: "btc", Id: testChainId, BtcHost: "http://127.0.0.1", Opts: map[string]string{
+		chain.McsOpt: testMcs, chain.Event: testTopic, chain.StartBlockOpt: "840000", StartLogIdOpt: "12",
+	}}
+	cfg, err := parseCfg(cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LogId != 12 {
+		t.Fatalf("log id %d, want 12", cfg.LogId)
+	}
+	cc.Opts[StartLogIdOpt] = "x"
+	if _, err = parseCfg(cc); err == nil {
+		t.Fatal("parsed a bad log id")
+	}
+}
@@ -0,0 +1,20 @@
+package btc
+
+import (
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+// Writer takes no messages, btc is only a source chain here.
+type Writer struct {
+	log log15.Logger
+}
+
+func newWriter(log log15.Logger) *Writer {
+	return &Writer{log: log}
+}
+
+func (w *Writer) ResolveMessage(m msg.Message) bool {
+	w.log.Error("Btc takes no messages", "type", m.Type, "src", m.Source, "dst", m.Destination)
+	return false
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/mapprotocol/compass/internal/constant"
 	ieth "github.com/mapprotocol/compass/internal/eth2"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
 	"github.com/mapprotocol/compass/internal/tx"
 	"github.com/mapprotocol/compass/pkg/abi"
 	"github.com/mapprotocol/compass/pkg/contract"
@@ -62,6 +63,9 @@ func (c *Chain) New(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan
 	if err != nil {
 		return nil, err
 	}
+	if err = chain.ApplyGasStrategy(conn, cfg); err != nil {
+		return nil, err
+	}
 
 	if chainCfg.StartLatest || chainCfg.LatestBlock {
 		if err := chain.StartLatestBlock(cfg, conn, logger); err != nil {
@@ -84,7 +88,13 @@ func (c *Chain) New(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan
 		logger.Info("map2eth2 Current situation", "height", height, "lightNode", cfg.LightNode)
 		mapprotocol.SyncOtherMap[cfg.Id] = height
 		mapprotocol.Map2OtherHeight[cfg.Id] = fn
-		listen = NewMaintainer(cs, conn.Eth2Client())
+		var committees *ieth.Client
+		if cfg.Eth2CommitteeUrl != "" {
+			if committees, err = ieth.DialHttp(cfg.Eth2CommitteeUrl); err != nil {
+				return nil, errors.Wrap(err, "eth2 dial committee beacon failed")
+			}
+		}
+		listen = NewMaintainer(cs, conn.Eth2Client(), committees)
 	case mapprotocol.RoleOfMessenger:
 		oracleAbi, _ := abi.New(mapprotocol.OracleAbiJson)
 		call := contract.New(conn, cfg.McsContract, oracleAbi)
@@ -190,16 +200,22 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 		return nil, err
 	}
 	// when syncToMap we need to assemble a tx proof
-	txsHash, err := mapprotocol.GetTxsByBn(client, blockNumber)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-	}
-	receipts, err := tx.GetReceiptsByTxsHash(client, txsHash)
+	receipts, err := proof.CacheReceipt.Receipts(selfId, log.BlockHash, func() ([]*types.Receipt, error) {
+		txsHash, err := mapprotocol.GetTxsByBn(client, blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		}
+		receipts, err := tx.GetBlockReceipts(client, blockNumber, txsHash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		}
+		return receipts, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		return nil, err
 	}
 
-	ret, err := ieth.AssembleProof(*ieth.ConvertHeader(header), log, receipts, method, msg.ChainId(selfId), proofType, sign)
+	ret, err := ieth.AssembleProof(client, *ieth.ConvertHeader(header), log, receipts, method, msg.ChainId(selfId), proofType, sign)
 	if err != nil {
 		return nil, fmt.Errorf("unable to Parse Log: %w", err)
 	}
@@ -24,12 +24,14 @@ type Maintainer struct {
 	*chain.CommonSync
 	syncedHeight *big.Int
 	eth2Client   *eth2.Client
+	verifier     *eth2.Verifier
 }
 
-func NewMaintainer(cs *chain.CommonSync, eth2Client *eth2.Client) *Maintainer {
+func NewMaintainer(cs *chain.CommonSync, eth2Client, committees *eth2.Client) *Maintainer {
 	return &Maintainer{
 		CommonSync:   cs,
 		eth2Client:   eth2Client,
+		verifier:     eth2.NewVerifier(eth2Client, committees),
 		syncedHeight: new(big.Int),
 	}
 }
@@ -62,6 +64,7 @@ func (m *Maintainer) sync() error {
 		m.Log.Error("Get synced Height failed", "err", err)
 		return err
 	}
+	m.seedCommittees()
 
 	if m.syncedHeight.Cmp(currentBlock) != 0 {
 		currentBlock.Add(m.syncedHeight, new(big.Int).SetInt64(1))
@@ -202,6 +205,12 @@ func (m *Maintainer) sendRegularLightClientUpdate(lastFinalizedSlotOnContract, l
 	if err != nil {
 		return err
 	}
+	// a bad update from the beacon node would only revert on MAP
+	err = m.verifier.Verify(context.Background(), lightUpdateData)
+	if err != nil {
+		m.Log.Error("Light client update failed verification", "beacon", m.eth2Client.Endpoint(), "err", err)
+		return err
+	}
 	lightClientInput, err := mapprotocol.Eth2.Methods[mapprotocol.MethodOfGetUpdatesBytes].Inputs.Pack(lightUpdateData)
 	if err != nil {
 		m.Log.Error("Failed to abi pack", "err", err)
@@ -220,9 +229,29 @@ func (m *Maintainer) sendRegularLightClientUpdate(lastFinalizedSlotOnContract, l
 	if err != nil {
 		return err
 	}
+	m.seedCommittees()
 	return nil
 }
 
+// seedCommittees hands the verifier the sync committees of the light client
+// on MAP, so updates are checked without asking another beacon node. When MAP
+// can't be read the committees are still taken from the beacon nodes.
+func (m *Maintainer) seedCommittees() {
+	period, committees, err := mapprotocol.GetEth2SyncCommittees(m.Cfg.Id)
+	if err != nil {
+		m.Log.Warn("Get sync committees of the light client failed", "err", err)
+		return
+	}
+	for i, c := range committees {
+		if len(c.Pubkeys) == 0 {
+			continue
+		}
+		if err = m.verifier.SetCommittee(period+uint64(i), (*eth2.ContractSyncCommittee)(&c)); err != nil {
+			m.Log.Warn("Sync committee of the light client is invalid", "period", period+uint64(i), "err", err)
+		}
+	}
+}
+
 func (m *Maintainer) getFinalityLightClientUpdate(lastFinalizedSlotOnContract *big.Int) (*eth2.LightClientUpdate, error) {
 	resp, err := m.eth2Client.FinallyUpdate(context.Background())
 	if err != nil {
@@ -0,0 +1,159 @@
+package eth2
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/eth2"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+var halfModulus, _ = new(big.Int).SetString("d0088f51cbff34d258dd3db21a5d66bb23ba5c279c2895fb39869507b587b120f55ffff58a9ffffdcff7fffffffd555", 16)
+
+// testCommittee is the sync committee of the secret keys offset+1 to offset+512.
+func testCommittee(offset int64) mapprotocol.SyncCommittee {
+	g1 := bls12381.NewG1()
+	compress := func(p *bls12381.PointG1) []byte {
+		raw := g1.ToBytes(p)
+		out := append([]byte{}, raw[:48]...)
+		out[0] |= 0x80
+		if new(big.Int).SetBytes(raw[48:]).Cmp(halfModulus) > 0 {
+			out[0] |= 0x20
+		}
+		return out
+	}
+	var ret mapprotocol.SyncCommittee
+	sum := g1.Zero()
+	for i := int64(0); i < 512; i++ {
+		pk := g1.New()
+		g1.MulScalar(pk, g1.One(), big.NewInt(offset+i+1))
+		g1.Add(sum, sum, pk)
+		ret.Pubkeys = append(ret.Pubkeys, compress(pk)...)
+	}
+	ret.AggregatePubkey = compress(sum)
+	return ret
+}
+
+// testUpdate is a finality update of period 3 signed by testCommittee(0).
+func testUpdate() *eth2.LightClientUpdate {
+	bits := make([]byte, 64)
+	for i := range bits {
+		bits[i] = 0xff
+	}
+	bits[3] = 0x7f
+	u := &eth2.LightClientUpdate{
+		AttestedHeader: eth2.BeaconBlockHeader{Slot: 3*8192 + 128, ProposerIndex: 9,
+			StateRoot: common.HexToHash("0xbc4e45bc0e8cf53c501af3318c4bb5d5f3a9f69f46a9b783118ad4e7a0a51a5c")},
+		SignatureSlot: 3*8192 + 129,
+		SyncAggregate: eth2.ContractSyncAggregate{
+			SyncCommitteeBits: bits,
+			SyncCommitteeSignature: common.FromHex("0x8b1d9db5db83367dcc431a2f07d302db0f6a20d348b9f05389ff5c8f8fbd4ec2" +
+				"d26d2a1f406cefdc595c013a054b6df9145b13f1539d24827a63ad5b8e505392f13c55406c23c34316eac48fda6add45" +
+				"293fafdae4fcfe894e15181796224671"),
+		},
+		FinalizedHeader: eth2.BeaconBlockHeader{Slot: 3*8192 + 64, ProposerIndex: 7, StateRoot: common.Hash{1}},
+	}
+	for _, b := range []string{
+		"0xaaa9402664f1a41f40ebbc52c9993eb66aeb366602958fdfaa283b71e64db123",
+		"0x01948b1bd7cac0632428490d44292e943945ab195c7bb956f8d0f5af250a8cfd",
+		"0x861883af4747e6627198f8c6d331e17ca1fb294341f1275b1a02610271540b9e",
+		"0xce6fa64e90e766eb0b9d8691da39cc5f7e26bcf1d8a047070f442812b678094a",
+		"0x77d1ebedfef2af7280d89d0d7ccd57345f3fb4785ee9f221a550cb082422c070",
+		"0x1f1ae777b429cdcacfa11bbc190ce7bf77ed50c49e587813696ed7d61eef6d77",
+	} {
+		u.FinalityBranch = append(u.FinalityBranch, common.HexToHash(b))
+	}
+	return u
+}
+
+// newBeacon serves the genesis and the fork schedule, and counts the light
+// client updates asked of it, which it doesn't have.
+func newBeacon(t *testing.T) (*eth2.Client, *int32) {
+	t.Helper()
+	var updates int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch r.URL.Path {
+		case "/eth/v1/beacon/genesis":
+			body = map[string]interface{}{"data": map[string]string{
+				"genesis_validators_root": "0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95",
+			}}
+		case "/eth/v1/config/fork_schedule":
+			body = map[string]interface{}{"data": []map[string]string{
+				{"previous_version": "0x00000000", "current_version": "0x00000000", "epoch": "0"},
+				{"previous_version": "0x03000000", "current_version": "0x04000000", "epoch": "768"},
+			}}
+		case "/eth/v1/beacon/light_client/updates":
+			atomic.AddInt32(&updates, 1)
+			http.NotFound(w, r)
+			return
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := eth2.DialHttp(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &updates
+}
+
+func TestMaintainerSingleBeacon(t *testing.T) {
+	current, next := testCommittee(0), testCommittee(1000)
+	read := 0
+	saved := mapprotocol.GetEth2SyncCommittees
+	defer func() { mapprotocol.GetEth2SyncCommittees = saved }()
+	mapprotocol.GetEth2SyncCommittees = func(chainId msg.ChainId) (uint64, []mapprotocol.SyncCommittee, error) {
+		read++
+		if chainId != 5 {
+			t.Fatalf("read the light client of chain %d", chainId)
+		}
+		return 3, []mapprotocol.SyncCommittee{current, next}, nil
+	}
+
+	client, updates := newBeacon(t)
+	m := NewMaintainer(&chain.CommonSync{Cfg: chain.Config{Id: 5}, Log: log15.New()}, client, nil)
+	m.seedCommittees()
+	if err := m.verifier.Verify(context.Background(), testUpdate()); err != nil {
+		t.Fatal(err)
+	}
+	if read != 1 || atomic.LoadInt32(updates) != 0 {
+		t.Fatalf("read MAP %d times, asked the beacon %d updates", read, *updates)
+	}
+
+	// the next committee of MAP is no good for the update of the period before
+	mapprotocol.GetEth2SyncCommittees = func(msg.ChainId) (uint64, []mapprotocol.SyncCommittee, error) {
+		return 2, []mapprotocol.SyncCommittee{next, current}, nil
+	}
+	m = NewMaintainer(&chain.CommonSync{Cfg: chain.Config{Id: 5}, Log: log15.New()}, client, nil)
+	m.seedCommittees()
+	if err := m.verifier.Verify(context.Background(), testUpdate()); err != nil {
+		t.Fatal(err)
+	}
+
+	// without MAP there is no other beacon node to take the committee from
+	mapprotocol.GetEth2SyncCommittees = func(msg.ChainId) (uint64, []mapprotocol.SyncCommittee, error) {
+		return 0, nil, errors.New("map is down")
+	}
+	m = NewMaintainer(&chain.CommonSync{Cfg: chain.Config{Id: 5}, Log: log15.New()}, client, nil)
+	m.seedCommittees()
+	err := m.verifier.Verify(context.Background(), testUpdate())
+	if err == nil || errors.Is(err, eth2.ErrInvalidUpdate) || !strings.Contains(err.Error(), "no beacon endpoint") {
+		t.Fatalf("got %v", err)
+	}
+}
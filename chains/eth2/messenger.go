@@ -11,6 +11,7 @@ import (
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/eth2"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
 	"github.com/mapprotocol/compass/internal/tx"
 	"github.com/mapprotocol/compass/pkg/msg"
 	"github.com/mapprotocol/compass/pkg/util"
@@ -133,44 +134,41 @@ func (m *Messenger) HandleFilterBlock(latestBlock uint64) (int, uint64, error) {
 	return m.filterMosHandler(latestBlock)
 }
 
+// filterMosHandler relays the next page of mos logs of the filter service,
+// in id order, up to the first one not confirmed yet.
 func (m *Messenger) filterMosHandler(latestBlock uint64) (int, uint64, error) {
-	count := 0
-	progressBlock := uint64(0)
-	topic := chain.BuildFilterTopic(m.Cfg.Events)
-	back, err := m.ListMosLogs(constant.ProjectOfMsger, topic, 1)
+	page, err := m.NextFilterPage(chain.BuildFilterTopic(m.Cfg.Events), constant.ProjectOfMsger)
 	if err != nil {
-		return 0, progressBlock, err
+		return 0, 0, err
 	}
-	if len(back.List) == 0 {
+	if len(page.Logs) == 0 {
 		time.Sleep(constant.QueryRetryInterval)
 		return 0, latestBlock, nil
 	}
 
-	for _, ele := range back.List {
-		progressBlock = ele.BlockNumber
+	count := 0
+	for _, ele := range page.Logs {
 		idx := m.Match(ele.ContractAddress)
 		if idx == -1 {
-			m.Log.Info("Filter Log Address Not Match", "id", ele.Id, "address", ele.ContractAddress)
-			m.Cfg.StartBlock = big.NewInt(ele.Id)
+			m.SkipFilter(ele)
 			continue
 		}
-		if latestBlock-ele.BlockNumber < m.BlockConfirmations.Uint64() {
+		if ele.BlockNumber+m.BlockConfirmations.Uint64() > latestBlock {
 			m.Log.Debug("Block not ready, will retry", "currentBlock", ele.BlockNumber, "latest", latestBlock)
 			time.Sleep(constant.BalanceRetryInterval)
-			continue
+			break
 		}
 
-		log := chain.MosRespToEthLog(ele)
-
-		send, err := log2Msg(m, log, idx)
+		send, err := log2Msg(m, chain.MosRespToEthLog(ele), idx)
 		if err != nil {
-			return 0, progressBlock, err
+			_ = m.WaitUntilMsgHandled(count)
+			return 0, m.FilterCursor().Block, err
 		}
 		count += send
-		m.Cfg.StartBlock = big.NewInt(ele.Id)
+		m.ProcessedFilter(ele)
 	}
 
-	return count, progressBlock, nil
+	return count, m.FilterCursor().Block, nil
 }
 
 func log2Msg(m *Messenger, log *types.Log, idx int) (int, error) {
@@ -199,17 +197,23 @@ func log2Msg(m *Messenger, log *types.Log, idx int) (int, error) {
 	if err != nil {
 		return 0, err
 	}
-	txsHash, err := mapprotocol.GetTxsByBn(m.Conn.Client(), blockNumber)
-	if err != nil {
-		return 0, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-	}
-	receipts, err := tx.GetReceiptsByTxsHash(m.Conn.Client(), txsHash)
+	receipts, err := proof.CacheReceipt.Receipts(uint64(m.Cfg.Id), log.BlockHash, func() ([]*types.Receipt, error) {
+		txsHash, err := mapprotocol.GetTxsByBn(m.Conn.Client(), blockNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		}
+		receipts, err := tx.GetBlockReceipts(m.Conn.Client(), blockNumber, txsHash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		}
+		return receipts, nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		return 0, err
 	}
 
 	m.Log.Info("Event found", "txHash", log.TxHash, "orderId", orderId, "method", method, "proofType", prepared.ProofType)
-	payload, err := eth2.AssembleProof(*eth2.ConvertHeader(header), log, receipts, method, m.Cfg.Id, prepared.ProofType, prepared.Sign)
+	payload, err := eth2.AssembleProof(m.Conn.Client(), *eth2.ConvertHeader(header), log, receipts, method, m.Cfg.Id, prepared.ProofType, prepared.Sign)
 	if err != nil {
 		return 0, fmt.Errorf("unable to Parse Log: %w", err)
 	}
@@ -19,6 +19,7 @@ import (
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/mapo"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
 	"github.com/mapprotocol/compass/internal/tx"
 	"github.com/mapprotocol/compass/pkg/abi"
 	"github.com/mapprotocol/compass/pkg/contract"
@@ -143,7 +144,7 @@ func (c *Chain) headerToMap(m *chain.Maintainer, latestBlock *big.Int) error {
 	if err != nil {
 		return err
 	}
-	enc, err := c.rlpEthereumHeaders(m.Cfg.Id, m.Cfg.MapChainID, []types.Header{*header})
+	enc, err := EncodeHeaders(m.Cfg.Id, m.Cfg.MapChainID, []types.Header{*header})
 	if err != nil {
 		m.Log.Error("failed to rlp ethereum headers", "err", err)
 		return err
@@ -195,7 +196,9 @@ func (c *Chain) assembleProof(m *chain.Messenger, log *types.Log, proofType int6
 	return &message, nil
 }
 
-func (c *Chain) rlpEthereumHeaders(source, destination msg.ChainId, headers []types.Header) ([]byte, error) {
+// EncodeHeaders packs headers of source the way the light client manager on
+// destination takes them in updateBlockHeader.
+func EncodeHeaders(source, destination msg.ChainId, headers []types.Header) ([]byte, error) {
 	h, err := rlp.EncodeToBytes(&headers)
 	if err != nil {
 		return nil, fmt.Errorf("rpl encode ethereum headers error: %v", err)
@@ -255,13 +258,19 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 		method    = chain.GetMethod(log.Topics[0])
 		bigNumber = big.NewInt(int64(log.BlockNumber))
 	)
-	txsHash, err := mapprotocol.GetTxsByBn(client, bigNumber)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-	}
-	receipts, err := tx.GetReceiptsByTxsHash(client, txsHash)
+	receipts, err := proof.CacheReceipt.Receipts(selfId, log.BlockHash, func() ([]*types.Receipt, error) {
+		txsHash, err := mapprotocol.GetTxsByBn(client, bigNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		}
+		receipts, err := tx.GetBlockReceipts(client, bigNumber, txsHash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		}
+		return receipts, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		return nil, err
 	}
 	header, err := client.MAPHeaderByNumber(context.Background(), bigNumber)
 	if err != nil {
@@ -279,7 +288,7 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 			receipts = append(receipts, lr)
 		}
 
-		_, ret, err = mapo.AssembleMapProof(client, log, receipts, header, constant.MapChainId, method, "", proofType, sign)
+		_, ret, err = mapo.AssembleMapProof(client, log, receipts, header, constant.MapChainId, method, proofType, sign)
 		if err != nil {
 			return nil, fmt.Errorf("unable to Parse Log: %w", err)
 		}
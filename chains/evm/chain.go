@@ -0,0 +1,231 @@
+// Package evm is the chain type of EVM chains that need no code of their own.
+// How their headers are read, how their receipts are encoded, which proof
+// they relay and how their headers reach MAP are all chain options, so a new
+// chain only needs a config entry.
+package evm
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	maptypes "github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/compass/chains/ethereum"
+	connection "github.com/mapprotocol/compass/connections/ethereum"
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapo"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
+	"github.com/mapprotocol/compass/internal/tx"
+	"github.com/mapprotocol/compass/pkg/abi"
+	"github.com/mapprotocol/compass/pkg/contract"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/pkg/errors"
+)
+
+type Chain struct {
+}
+
+func New() *Chain {
+	return &Chain{}
+}
+
+func (c *Chain) New(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error,
+	role mapprotocol.Role) (core.Chain, error) {
+	if strconv.FormatUint(uint64(chainCfg.Id), 10) == mapprotocol.MapId {
+		return nil, fmt.Errorf("chain %s: the map chain runs as %s, not %s", chainCfg.Name, constant.Ethereum, constant.Evm)
+	}
+	o, err := Configure(chainCfg.Id, chainCfg.Opts)
+	if err != nil {
+		return nil, fmt.Errorf("chain %s: %w", chainCfg.Name, err)
+	}
+	logger.Info("Evm chain options", "headerFormat", o.HeaderFormat, "proofType", o.ProofType, "headerSync", o.HeaderSync)
+
+	sync2Map := noHeaderToMap
+	if o.HeaderSync == HeaderSyncRlp {
+		sync2Map = c.headerToMap
+	}
+	return chain.New(chainCfg, logger, sysErr, role, connection.NewConnection,
+		chain.OptOfSync2Map(sync2Map),
+		chain.OptOfInitHeight(mapprotocol.HeaderOneCount),
+		chain.OptOfAssembleProof(c.assembleProof),
+		chain.OptOfOracleHandler(chain.DefaultOracleHandler))
+}
+
+// noHeaderToMap is the header sync of oracle chains, whose light client
+// takes no headers. The maintainer only keeps its position.
+func noHeaderToMap(*chain.Maintainer, *big.Int) error {
+	return nil
+}
+
+func (c *Chain) headerToMap(m *chain.Maintainer, latestBlock *big.Int) error {
+	syncedHeight, err := mapprotocol.Get2MapHeight(m.Cfg.Id)
+	if err != nil {
+		m.Log.Error("Get synced Height failed", "err", err)
+		return err
+	}
+	if latestBlock.Cmp(syncedHeight) <= 0 {
+		m.Log.Info("currentBlock less than synchronized headerHeight", "synced height", syncedHeight,
+			"current height", latestBlock)
+		return nil
+	}
+	m.Log.Info("Sync Header to Map Chain", "current", latestBlock)
+	data, err := updateBlockHeader(m.Conn.Client(), m.Cfg.Id, m.Cfg.MapChainID, latestBlock)
+	if err != nil {
+		return err
+	}
+	id := big.NewInt(0).SetUint64(uint64(m.Cfg.Id))
+	msgpayload := []interface{}{id, data}
+	message := msg.NewSyncToMap(m.Cfg.Id, m.Cfg.MapChainID, msgpayload, m.MsgCh)
+
+	err = m.Router.Send(message)
+	if err != nil {
+		m.Log.Error("subscription error: failed to route message", "err", err)
+		return err
+	}
+	return m.WaitUntilMsgHandled(1)
+}
+
+// updateBlockHeader packs the light client manager call that syncs the
+// header at height of chain id.
+func updateBlockHeader(client *ethclient.Client, id, mapId msg.ChainId, height *big.Int) ([]byte, error) {
+	header, err := client.HeaderByNumber(context.Background(), height)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := ethereum.EncodeHeaders(id, mapId, []types.Header{*header})
+	if err != nil {
+		return nil, err
+	}
+	return mapprotocol.PackInput(mapprotocol.LightManger, mapprotocol.MethodUpdateBlockHeader,
+		big.NewInt(0).SetUint64(uint64(id)), enc)
+}
+
+func (c *Chain) assembleProof(m *chain.Messenger, log *types.Log, proofType int64, toChainID uint64, sign [][]byte) (*msg.Message, error) {
+	var (
+		message msg.Message
+		orderId = log.Topics[1]
+	)
+	payload, err := c.Proof(m.Conn.Client(), log, m.Cfg.Endpoint, proofType, uint64(m.Cfg.Id), toChainID, sign)
+	if err != nil {
+		return nil, fmt.Errorf("build Proof failed, err: %w", err)
+	}
+	if m.Cfg.SyncToMap {
+		msgPayload := []interface{}{payload, orderId, log.BlockNumber, log.TxHash}
+		message = msg.NewSwapWithProof(m.Cfg.Id, m.Cfg.MapChainID, msgPayload, m.MsgCh)
+	}
+	return &message, nil
+}
+
+func (c *Chain) Connect(id, endpoint, mcs, lightNode, oracleNode string) (*ethclient.Client, error) {
+	conn := connection.NewConnection(endpoint, true, nil, nil, big.NewInt(chain.DefaultGasLimit),
+		big.NewInt(chain.DefaultGasPrice), chain.DefaultGasMultiplier)
+	err := conn.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	fn := sync.OnceFunc(func() {
+		idInt, _ := strconv.ParseUint(id, 10, 64)
+		oracleAbi, _ := abi.New(mapprotocol.OracleAbiJson)
+		call := contract.New(conn, []common.Address{common.HexToAddress(mcs)}, oracleAbi)
+		mapprotocol.ContractMapping[msg.ChainId(idInt)] = call
+
+		oAbi, _ := abi.New(mapprotocol.SignerJson)
+		oracleCall := contract.New(conn, []common.Address{common.HexToAddress(oracleNode)}, oAbi)
+		mapprotocol.SingMapping[msg.ChainId(idInt)] = oracleCall
+
+		fn := mapprotocol.Map2EthHeight(constant.ZeroAddress.Hex(), common.HexToAddress(lightNode), conn.Client())
+		mapprotocol.Map2OtherHeight[msg.ChainId(idInt)] = fn
+	})
+	fn()
+
+	return conn.Client(), nil
+}
+
+func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string, proofType int64, selfId,
+	toChainID uint64, sign [][]byte) ([]byte, error) {
+	var (
+		o         = OptionsOf(msg.ChainId(selfId))
+		method    = chain.GetMethod(log.Topics[0])
+		bigNumber = big.NewInt(int64(log.BlockNumber))
+	)
+	if o.ProofType != 0 && o.ProofType != proofType {
+		return nil, fmt.Errorf("chain %d is configured with %s %d, but proof type %d was asked for", selfId, ProofTypeOpt, o.ProofType, proofType)
+	}
+	if len(sign) == 0 && (proofType == constant.ProofTypeOfNewOracle || proofType == constant.ProofTypeOfLogOracle) {
+		ret, err := chain.Signer(client, selfId, toChainID, log, proofType)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get signatures: %w", err)
+		}
+		sign = ret.Signatures
+	}
+
+	receipts, err := proof.CacheReceipt.Receipts(selfId, log.BlockHash, func() ([]*types.Receipt, error) {
+		txsHash, err := mapprotocol.GetTxsByBn(client, bigNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		}
+		receipts, err := tx.GetBlockReceipts(client, bigNumber, txsHash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		}
+		return receipts, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	header, err := headerByNumber(client, o.HeaderFormat, bigNumber)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query header Logs: %w", err)
+	}
+
+	ret, err := mapo.AssembleEthProof(client, log, receipts, header, method, msg.ChainId(selfId), proofType, sign)
+	if err != nil {
+		return nil, fmt.Errorf("unable to Parse Log: %w", err)
+	}
+	return ret, nil
+}
+
+// headerByNumber reads the header at number in format. Proofs only need its
+// number and receipts root.
+func headerByNumber(client *ethclient.Client, format string, number *big.Int) (*maptypes.Header, error) {
+	switch format {
+	case HeaderFormatMap:
+		return client.MAPHeaderByNumber(context.Background(), number)
+	case HeaderFormatEthereum:
+		header, err := client.HeaderByNumber(context.Background(), number)
+		if err != nil {
+			return nil, err
+		}
+		return &maptypes.Header{Number: header.Number, ReceiptHash: header.ReceiptHash}, nil
+	case HeaderFormatRaw:
+		root, err := client.ReceiptsRootByNumber(context.Background(), number)
+		if err != nil {
+			return nil, err
+		}
+		return &maptypes.Header{Number: number, ReceiptHash: root}, nil
+	default:
+		return nil, fmt.Errorf("unknown header format %q", format)
+	}
+}
+
+func (c *Chain) Maintainer(client *ethclient.Client, selfId, toChainId uint64, srcEndpoint string) ([]byte, error) {
+	if OptionsOf(msg.ChainId(selfId)).HeaderSync != HeaderSyncRlp {
+		return nil, fmt.Errorf("chain %d syncs no headers, set %s to %s", selfId, HeaderSyncOpt, HeaderSyncRlp)
+	}
+	syncedHeight, err := mapprotocol.Get2MapHeight(msg.ChainId(selfId))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get synced height")
+	}
+	return updateBlockHeader(client, msg.ChainId(selfId), msg.ChainId(toChainId),
+		new(big.Int).Add(syncedHeight, big.NewInt(1)))
+}
@@ -0,0 +1,111 @@
+package evm
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+// Chain specific options of the evm chain type. The receipt encoding is the
+// receiptEncoding option every chain type takes.
+const (
+	HeaderFormatOpt = "headerFormat"
+	ProofTypeOpt    = "proofType"
+	HeaderSyncOpt   = "headerSync"
+)
+
+// Header formats, how the headers of a chain are read.
+const (
+	HeaderFormatMap      = "map"      // MAP's header, lenient about the fields ethereum forks add
+	HeaderFormatEthereum = "ethereum" // go-ethereum's header, the one light clients take
+	HeaderFormatRaw      = "raw"      // only the receipts root, for headers neither decodes
+)
+
+// Header sync strategies.
+const (
+	HeaderSyncNone = "none" // oracle chains, the light client needs no headers
+	HeaderSyncRlp  = "rlp"  // every header, rlp encoded, through the light client manager
+)
+
+var proofTypes = map[string]int64{
+	"oracle":    constant.ProofTypeOfOracle,
+	"newOracle": constant.ProofTypeOfNewOracle,
+	"logOracle": constant.ProofTypeOfLogOracle,
+}
+
+// Options are what an evm chain is configured with on top of the common
+// chain config.
+type Options struct {
+	HeaderFormat string
+	ProofType    int64 // 0 uses the proof type the light client reports
+	HeaderSync   string
+}
+
+// ParseOptions reads the evm options out of a chain's opts.
+func ParseOptions(opts map[string]string) (*Options, error) {
+	ret := &Options{HeaderFormat: HeaderFormatMap, HeaderSync: HeaderSyncNone}
+	if v, ok := opts[HeaderSyncOpt]; ok && v != "" {
+		if v != HeaderSyncNone && v != HeaderSyncRlp {
+			return nil, fmt.Errorf("unable to parse %s, want %s or %s", HeaderSyncOpt, HeaderSyncNone, HeaderSyncRlp)
+		}
+		ret.HeaderSync = v
+	}
+	if ret.HeaderSync == HeaderSyncRlp {
+		ret.HeaderFormat = HeaderFormatEthereum
+	}
+	if v, ok := opts[HeaderFormatOpt]; ok && v != "" {
+		switch v {
+		case HeaderFormatMap, HeaderFormatEthereum, HeaderFormatRaw:
+		default:
+			return nil, fmt.Errorf("unable to parse %s, want %s, %s or %s", HeaderFormatOpt,
+				HeaderFormatMap, HeaderFormatEthereum, HeaderFormatRaw)
+		}
+		if ret.HeaderSync == HeaderSyncRlp && v != HeaderFormatEthereum {
+			return nil, fmt.Errorf("%s %s needs %s %s", HeaderSyncOpt, HeaderSyncRlp, HeaderFormatOpt, HeaderFormatEthereum)
+		}
+		ret.HeaderFormat = v
+	}
+	if v, ok := opts[ProofTypeOpt]; ok && v != "" && v != "auto" {
+		pt, ok := proofTypes[v]
+		if !ok {
+			return nil, fmt.Errorf("unable to parse %s, want auto, oracle, newOracle or logOracle", ProofTypeOpt)
+		}
+		ret.ProofType = pt
+	}
+	return ret, nil
+}
+
+var registry = struct {
+	mu      sync.RWMutex
+	options map[msg.ChainId]*Options
+}{
+	options: make(map[msg.ChainId]*Options),
+}
+
+// Configure parses opts and keeps them for chain id, so proofs assembled
+// without a running chain, by the proof command or the expose service, use
+// them too.
+func Configure(id msg.ChainId, opts map[string]string) (*Options, error) {
+	o, err := ParseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.options[id] = o
+	return o, nil
+}
+
+// OptionsOf returns the options of chain id, the defaults when it was never
+// configured.
+func OptionsOf(id msg.ChainId) *Options {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	if o, ok := registry.options[id]; ok {
+		return o
+	}
+	o, _ := ParseOptions(nil)
+	return o
+}
@@ -0,0 +1,82 @@
+package evm
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+func TestParseOptions(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts map[string]string
+		want Options
+		err  bool
+	}{
+		{name: "defaults", want: Options{HeaderFormat: HeaderFormatMap, HeaderSync: HeaderSyncNone}},
+		{
+			name: "raw header with a forced proof type",
+			opts: map[string]string{HeaderFormatOpt: "raw", ProofTypeOpt: "newOracle"},
+			want: Options{HeaderFormat: HeaderFormatRaw, HeaderSync: HeaderSyncNone, ProofType: constant.ProofTypeOfNewOracle},
+		},
+		{
+			name: "rlp sync reads ethereum headers",
+			opts: map[string]string{HeaderSyncOpt: "rlp"},
+			want: Options{HeaderFormat: HeaderFormatEthereum, HeaderSync: HeaderSyncRlp},
+		},
+		{name: "auto proof type", opts: map[string]string{ProofTypeOpt: "auto"}, want: Options{HeaderFormat: HeaderFormatMap, HeaderSync: HeaderSyncNone}},
+		{name: "rlp sync needs ethereum headers", opts: map[string]string{HeaderSyncOpt: "rlp", HeaderFormatOpt: "map"}, err: true},
+		{name: "unknown header format", opts: map[string]string{HeaderFormatOpt: "bsc"}, err: true},
+		{name: "unknown header sync", opts: map[string]string{HeaderSyncOpt: "epoch"}, err: true},
+		{name: "unsupported proof type", opts: map[string]string{ProofTypeOpt: "zk"}, err: true},
+	} {
+		got, err := ParseOptions(tc.opts)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: parsed %+v, want an error", tc.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if *got != tc.want {
+			t.Errorf("%s: got %+v, want %+v", tc.name, *got, tc.want)
+		}
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	id := msg.ChainId(196)
+	if got := OptionsOf(id); got.HeaderFormat != HeaderFormatMap {
+		t.Fatalf("unconfigured chain got %+v", got)
+	}
+	if _, err := Configure(id, map[string]string{HeaderFormatOpt: "raw"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := OptionsOf(id); got.HeaderFormat != HeaderFormatRaw {
+		t.Fatalf("configured chain got %+v", got)
+	}
+	if _, err := Configure(id, map[string]string{HeaderFormatOpt: "nope"}); err == nil {
+		t.Fatal("configured with a bad header format")
+	}
+	if got := OptionsOf(id); got.HeaderFormat != HeaderFormatRaw {
+		t.Fatalf("a bad config replaced the options: %+v", got)
+	}
+}
+
+func TestProofTypeMismatch(t *testing.T) {
+	id := msg.ChainId(9001)
+	if _, err := Configure(id, map[string]string{ProofTypeOpt: "logOracle"}); err != nil {
+		t.Fatal(err)
+	}
+	log := &types.Log{Topics: []common.Hash{{}}, BlockNumber: 1}
+	if _, err := new(Chain).Proof(nil, log, "", constant.ProofTypeOfOrigin, uint64(id), 22776, nil); err == nil {
+		t.Fatal("a proof type other than the configured one was accepted")
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"github.com/ChainSafe/log15"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/mapprotocol/compass/chains/bsc"
+	"github.com/mapprotocol/compass/chains/btc"
 	"github.com/mapprotocol/compass/chains/eth2"
 	"github.com/mapprotocol/compass/chains/ethereum"
+	"github.com/mapprotocol/compass/chains/evm"
 	"github.com/mapprotocol/compass/chains/matic"
+	"github.com/mapprotocol/compass/chains/near"
 	"github.com/mapprotocol/compass/chains/sol"
 	"github.com/mapprotocol/compass/chains/tron"
 	"github.com/mapprotocol/compass/core"
@@ -18,9 +21,12 @@ import (
 var (
 	chainMap = map[string]Chainer{
 		constant.Bsc:      bsc.New(),
+		constant.Btc:      btc.New(),
 		constant.Matic:    matic.New(),
 		constant.Eth2:     eth2.New(),
 		constant.Ethereum: ethereum.New(),
+		constant.Evm:      evm.New(),
+		constant.Near:     near.New(),
 		constant.Solana:   sol.New(),
 		constant.Tron:     tron.New(),
 	}
@@ -29,6 +35,7 @@ var (
 		constant.Matic:    matic.New(),
 		constant.Eth2:     eth2.New(),
 		constant.Ethereum: ethereum.New(),
+		constant.Evm:      evm.New(),
 		constant.Tron:     tron.New(),
 	}
 )
@@ -145,26 +145,26 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 		method    = chain.GetMethod(log.Topics[0])
 		bigNumber = big.NewInt(int64(log.BlockNumber))
 	)
-	txsHash, err := tx.GetTxsHashByBlockNumber(client, bigNumber)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-	}
-	var receipts []*types.Receipt
-	key := strconv.FormatUint(selfId, 10) + "_" + bigNumber.String()
-	if v, ok := proof.CacheReceipt.Get(key); ok {
-		receipts = v.([]*types.Receipt)
-	} else {
+	receipts, err := proof.CacheReceipt.Receipts(selfId, log.BlockHash, func() ([]*types.Receipt, error) {
+		txsHash, err := tx.GetTxsHashByBlockNumber(client, bigNumber)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		}
 		tmp, err := tx.GetMaticReceiptsByTxsHash(client, txsHash)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
 		}
+		receipts := make([]*types.Receipt, 0, len(tmp))
 		for _, t := range tmp {
 			if t == nil {
 				continue
 			}
 			receipts = append(receipts, t)
 		}
-		proof.CacheReceipt.Add(key, receipts)
+		return receipts, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	headers := make([]*types.Header, mapprotocol.ConfirmsOfMatic.Int64())
@@ -182,7 +182,7 @@ func (c *Chain) Proof(client *ethclient.Client, log *types.Log, endpoint string,
 		mHeaders = append(mHeaders, matic.ConvertHeader(h))
 	}
 
-	payload, err := matic.AssembleProof(mHeaders, log, msg.ChainId(selfId), receipts, method, proofType, sign)
+	payload, err := matic.AssembleProof(client, mHeaders, log, msg.ChainId(selfId), receipts, method, proofType, sign)
 	if err != nil {
 		return nil, fmt.Errorf("unable to Parse Log: %w", err)
 	}
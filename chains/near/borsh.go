@@ -0,0 +1,195 @@
+package near
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	nearclient "github.com/mapprotocol/near-api-go/pkg/client"
+	"github.com/mapprotocol/near-api-go/pkg/types"
+	"github.com/mapprotocol/near-api-go/pkg/types/hash"
+	"github.com/mapprotocol/near-api-go/pkg/types/signature"
+	"github.com/mr-tron/base58"
+)
+
+// The light client on MAP decodes near's borsh layouts, the same the
+// rainbow bridge uses. borshWriter writes the few types they are made of.
+type borshWriter struct {
+	bytes.Buffer
+}
+
+func (w *borshWriter) u8(v uint8) {
+	w.WriteByte(v)
+}
+
+func (w *borshWriter) u32(v uint32) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *borshWriter) u64(v uint64) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (w *borshWriter) u128(v types.Balance) {
+	w.u64(v.Lo)
+	w.u64(v.Hi)
+}
+
+func (w *borshWriter) hash(h hash.CryptoHash) {
+	w.Write(h[:])
+}
+
+func (w *borshWriter) bytes(b []byte) {
+	w.u32(uint32(len(b)))
+	w.Write(b)
+}
+
+func (w *borshWriter) string(s string) {
+	w.bytes([]byte(s))
+}
+
+func (w *borshWriter) innerLite(l *nearclient.BlockHeaderInnerLiteView) {
+	w.u64(l.Height)
+	w.hash(l.EpochID)
+	w.hash(l.NextEpochId)
+	w.hash(l.PrevStateRoot)
+	w.hash(l.OutcomeRoot)
+	w.u64(l.Timestamp)
+	w.hash(l.NextBpHash)
+	w.hash(l.BlockMerkleRoot)
+}
+
+func (w *borshWriter) merklePath(path nearclient.MerklePath) error {
+	w.u32(uint32(len(path)))
+	for _, item := range path {
+		w.hash(item.Hash)
+		switch item.Direction {
+		case "Left":
+			w.u8(0)
+		case "Right":
+			w.u8(1)
+		default:
+			return fmt.Errorf("unknown merkle path direction %q", item.Direction)
+		}
+	}
+	return nil
+}
+
+func (w *borshWriter) signature(sig *signature.Base58Signature) error {
+	if sig == nil {
+		w.u8(0)
+		return nil
+	}
+	if sig.Type != signature.SignatureTypeED25519 {
+		return fmt.Errorf("unsupported signature type %q", sig.Type)
+	}
+	raw, err := base58.Decode(sig.Value)
+	if err != nil {
+		return err
+	}
+	w.u8(1)
+	w.u8(signature.RawSignatureTypeED25519)
+	w.Write(raw)
+	return nil
+}
+
+// borshLightClientBlock encodes the block the maintainer syncs and the head
+// a receipt proof is checked against.
+func borshLightClientBlock(b *nearclient.LightClientBlockView) ([]byte, error) {
+	var w borshWriter
+	w.hash(b.PrevBlockHash)
+	w.hash(b.NextBlockInnerHash)
+	w.innerLite(&b.InnerLite)
+	w.hash(b.InnerRestHash)
+	if len(b.NextBps) == 0 {
+		w.u8(0)
+	} else {
+		w.u8(1)
+		w.u32(uint32(len(b.NextBps)))
+		for _, bp := range b.NextBps {
+			if bp.ValidatorStakeStructVersion != "" && bp.ValidatorStakeStructVersion != "V1" {
+				return nil, fmt.Errorf("unsupported validator stake version %q", bp.ValidatorStakeStructVersion)
+			}
+			w.u8(0)
+			w.string(bp.AccountID)
+			pk := bp.PublicKey.ToPublicKey()
+			w.Write(pk[:])
+			w.u128(bp.Stake)
+		}
+	}
+	w.u32(uint32(len(b.ApprovalsAfterNext)))
+	for _, sig := range b.ApprovalsAfterNext {
+		if err := w.signature(sig); err != nil {
+			return nil, err
+		}
+	}
+	return w.Bytes(), nil
+}
+
+// blockHash is the hash near gives the block of a light client block.
+func blockHash(prev, innerRest hash.CryptoHash, lite *nearclient.BlockHeaderInnerLiteView) hash.CryptoHash {
+	var w borshWriter
+	w.innerLite(lite)
+	inner := sha256.Sum256(w.Bytes())
+	inner = sha256.Sum256(append(inner[:], innerRest[:]...))
+	return sha256.Sum256(append(inner[:], prev[:]...))
+}
+
+func (w *borshWriter) outcome(o *nearclient.ExecutionOutcomeView) error {
+	w.u32(uint32(len(o.Logs)))
+	for _, l := range o.Logs {
+		w.string(l)
+	}
+	w.u32(uint32(len(o.ReceiptIDs)))
+	for _, id := range o.ReceiptIDs {
+		w.hash(id)
+	}
+	w.u64(o.GasBurnt)
+	w.u128(o.TokensBurnt)
+	w.string(o.ExecutorID)
+	switch {
+	case len(o.Status.Failure) != 0 && string(o.Status.Failure) != "null":
+		return errors.New("outcome failed, there is nothing to prove")
+	case o.Status.SuccessReceiptID != "":
+		id, err := hash.NewCryptoHashFromBase58(o.Status.SuccessReceiptID)
+		if err != nil {
+			return err
+		}
+		w.u8(3)
+		w.hash(id)
+	default:
+		value, err := base64.StdEncoding.DecodeString(o.Status.SuccessValue)
+		if err != nil {
+			return err
+		}
+		w.u8(2)
+		w.bytes(value)
+	}
+	return nil
+}
+
+// borshOutcomeProof encodes the light client proof of an execution outcome.
+func borshOutcomeProof(p *nearclient.RpcLightClientExecutionProofResponse) ([]byte, error) {
+	var w borshWriter
+	if err := w.merklePath(p.OutcomeProof.Proof); err != nil {
+		return nil, err
+	}
+	w.hash(p.OutcomeProof.BlockHash)
+	w.hash(p.OutcomeProof.ID)
+	if err := w.outcome(&p.OutcomeProof.Outcome); err != nil {
+		return nil, err
+	}
+	if err := w.merklePath(p.OutcomeRootProof); err != nil {
+		return nil, err
+	}
+	w.hash(p.BlockHeaderLite.PrevBlockHash)
+	w.hash(p.BlockHeaderLite.InnerRestHash)
+	w.innerLite(&p.BlockHeaderLite.InnerLite)
+	if err := w.merklePath(p.BlockProof); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
@@ -0,0 +1,126 @@
+// Package near is the near chain type. Its maintainer syncs near light client
+// blocks to MAP, its messenger proves the mos logs a near indexer pushes to
+// redis, and its writer syncs MAP headers to the light client on near.
+package near
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/log"
+	connection "github.com/mapprotocol/compass/connections/near"
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/keystore"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/redis"
+	"github.com/mapprotocol/near-api-go/pkg/types/key"
+)
+
+type Chain struct {
+	cfg    *core.ChainConfig
+	conn   core.Connection
+	writer *Writer
+	stop   chan<- int
+	listen core.Listener
+}
+
+func New() *Chain {
+	return &Chain{}
+}
+
+func (c *Chain) New(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, role mapprotocol.Role) (core.Chain, error) {
+	return createChain(chainCfg, logger, sysErr, role)
+}
+
+func createChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, role mapprotocol.Role) (core.Chain, error) {
+	config, err := parseCfg(chainCfg, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var kp *key.KeyPair
+	if role == mapprotocol.RoleOfMaintainer {
+		pair, err := keystore.NearKeyPairFrom(config.Network, config.NearKeystorePath, config.From)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load near key of %s: %w", config.From, err)
+		}
+		kp = &pair
+	}
+
+	conn := connection.NewConnection(config.Endpoint, config.Http, kp, logger, config.GasLimit, config.MaxGasPrice,
+		big.NewFloat(config.GasMultiplier))
+	err = conn.Connect()
+	if err != nil {
+		return nil, err
+	}
+	if config.LightNode != "" {
+		mapprotocol.Map2OtherHeight[config.Id] = mapprotocol.Map2NearHeight(config.LightNode, conn.NearClient())
+	}
+
+	var (
+		stop    = make(chan int)
+		handler Handler
+	)
+	switch role {
+	case mapprotocol.RoleOfMaintainer:
+		handler = maintainer
+	case mapprotocol.RoleOfMessenger:
+		redis.Init(config.RedisUrl)
+		handler = messenger
+	default:
+		return nil, fmt.Errorf("chain %s: near has no %s", config.Name, role)
+	}
+	bs, err := chain.SetupBlockStore(&config.Config, role)
+	if err != nil {
+		return nil, err
+	}
+	cs := chain.NewCommonSync(conn, &config.Config, logger, stop, sysErr, bs)
+	cs.RegisterState(config.Name, string(role))
+
+	return &Chain{
+		conn:   conn,
+		stop:   stop,
+		listen: newSync(cs, handler, conn, config),
+		cfg:    chainCfg,
+		writer: newWriter(conn, config, logger, stop),
+	}, nil
+}
+
+func (c *Chain) SetRouter(r core.Router) {
+	r.Listen(c.cfg.Id, c.writer)
+	c.listen.SetRouter(r)
+}
+
+func (c *Chain) Start() error {
+	err := c.listen.Sync()
+	if err != nil {
+		return err
+	}
+
+	log.Debug("Successfully started Chain")
+	return nil
+}
+
+func (c *Chain) Id() msg.ChainId {
+	return c.cfg.Id
+}
+
+func (c *Chain) Name() string {
+	return c.cfg.Name
+}
+
+// Stop signals to any running routines to exit
+func (c *Chain) Stop() {
+	close(c.stop)
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// Conn return Connection interface for relayer register
+func (c *Chain) Conn() core.Connection {
+	return c.conn
+}
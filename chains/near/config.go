@@ -0,0 +1,57 @@
+package near
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+)
+
+type Config struct {
+	chain.Config
+	Network          string
+	NearKeystorePath string
+	RedisUrl         string
+	LightNode        string   // account of the map light client on near
+	McsContract      []string // accounts of the near mos
+	Topics           []string // hex topics of the relayed logs
+}
+
+func parseCfg(chainCfg *core.ChainConfig, role mapprotocol.Role) (*Config, error) {
+	cfg, err := chain.ParseConfig(chainCfg)
+	if err != nil {
+		return nil, err
+	}
+	ret := Config{
+		Config:           *cfg,
+		Network:          chainCfg.Network,
+		NearKeystorePath: chainCfg.NearKeystorePath,
+	}
+
+	if ele, ok := chainCfg.Opts[chain.LightNode]; ok && ele != "" {
+		ret.LightNode = ele
+	}
+	if ele, ok := chainCfg.Opts[chain.McsOpt]; ok && ele != "" {
+		ret.McsContract = append(ret.McsContract, strings.Split(ele, ",")...)
+	}
+	if ele, ok := chainCfg.Opts[chain.Event]; ok && ele != "" {
+		for _, topic := range strings.Split(ele, "|") {
+			ret.Topics = append(ret.Topics, strings.TrimPrefix(topic, "0x"))
+		}
+	}
+	if ele, ok := chainCfg.Opts[chain.RedisOpt]; ok && ele != "" {
+		ret.RedisUrl = ele
+	}
+
+	if role == mapprotocol.RoleOfMessenger {
+		if ret.RedisUrl == "" {
+			return nil, fmt.Errorf("chain %s: near messenger needs the %s option", chainCfg.Name, chain.RedisOpt)
+		}
+		if len(ret.McsContract) == 0 || len(ret.Topics) == 0 {
+			return nil, fmt.Errorf("chain %s: near messenger needs the %s and %s options", chainCfg.Name, chain.McsOpt, chain.Event)
+		}
+	}
+	return &ret, nil
+}
@@ -0,0 +1,289 @@
+package near
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	goredis "github.com/go-redis/redis/v8"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/redis"
+	nearclient "github.com/mapprotocol/near-api-go/pkg/client"
+)
+
+var (
+	record      = flag.String("record", "", "near rpc endpoint TestRecord records a block from")
+	recordRedis = flag.String("redis", "", "redis of the near indexer TestRecord takes the block from")
+	recordMos   = flag.String("mos", "", "near mos account TestRecord proves the logs of")
+)
+
+const recordingFile = "testdata/recorded.json"
+
+// recording is a near block the mos logged in, as TestRecord saves it: the
+// streamer message the indexer left in redis and the rpc responses proving
+// its logs, keyed by method, and by receipt id for the light client proofs.
+// Synced is the height the light client on MAP is taken to be at; HeadHash is
+// the hash the block rpc gives for the light client block after it.
+type recording struct {
+	Mos      string                     `json:"mos"`
+	Synced   uint64                     `json:"synced"`
+	HeadHash string                     `json:"headHash"`
+	Streamer json.RawMessage            `json:"streamerMessage"`
+	Rpc      map[string]json.RawMessage `json:"rpc"`
+}
+
+func proofKey(receiptId interface{}) string {
+	return fmt.Sprintf("EXPERIMENTAL_light_client_proof/%v", receiptId)
+}
+
+func loadRecording(t *testing.T) *recording {
+	t.Helper()
+	data, err := os.ReadFile(recordingFile)
+	if err != nil {
+		t.Fatalf("no recorded near block, record one with -record <rpc> -redis <url> -mos <account>: %v", err)
+	}
+	var rec recording
+	if err = json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	return &rec
+}
+
+// result decodes the result of the recorded response of key.
+func (rec *recording) result(t *testing.T, key string, result interface{}) {
+	t.Helper()
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(rec.Rpc[key], &resp); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// merkleRoot folds path onto leaf the way nearcore's compute_root_from_path does.
+func merkleRoot(leaf [32]byte, path nearclient.MerklePath) [32]byte {
+	for _, item := range path {
+		if item.Direction == "Left" {
+			leaf = sha256.Sum256(append(item.Hash[:], leaf[:]...))
+		} else {
+			leaf = sha256.Sum256(append(leaf[:], item.Hash[:]...))
+		}
+	}
+	return leaf
+}
+
+// The hash of the encoded head must be the one near gives for its block.
+func TestRecordedHead(t *testing.T) {
+	rec := loadRecording(t)
+	var head nearclient.LightClientBlockView
+	rec.result(t, "next_light_client_block", &head)
+	if _, err := borshLightClientBlock(&head); err != nil {
+		t.Fatal(err)
+	}
+	if got := blockHash(head.PrevBlockHash, head.InnerRestHash, &head.InnerLite); got.String() != rec.HeadHash {
+		t.Fatalf("head hash %s, near has %s", got, rec.HeadHash)
+	}
+}
+
+// The outcome block of every proof must hash to the block it names and lead
+// to the block merkle root of the head.
+func TestRecordedProof(t *testing.T) {
+	rec := loadRecording(t)
+	var head nearclient.LightClientBlockView
+	rec.result(t, "next_light_client_block", &head)
+	proofs := 0
+	for key := range rec.Rpc {
+		if !strings.HasPrefix(key, proofKey("")) {
+			continue
+		}
+		proofs++
+		var proof nearclient.RpcLightClientExecutionProofResponse
+		rec.result(t, key, &proof)
+		if _, err := borshOutcomeProof(&proof); err != nil {
+			t.Fatal(err)
+		}
+		lite := proof.BlockHeaderLite
+		hash := blockHash(lite.PrevBlockHash, lite.InnerRestHash, &lite.InnerLite)
+		if hash != proof.OutcomeProof.BlockHash {
+			t.Fatalf("%s: block hash %s, proof names %s", key, hash, proof.OutcomeProof.BlockHash)
+		}
+		if root := merkleRoot(hash, proof.BlockProof); root != head.InnerLite.BlockMerkleRoot {
+			t.Fatalf("%s: block proof leads to %x, head has %s", key, root, head.InnerLite.BlockMerkleRoot)
+		}
+	}
+	if proofs == 0 {
+		t.Fatal("no light client proof recorded")
+	}
+}
+
+func TestRecordedMessenger(t *testing.T) {
+	rec := loadRecording(t)
+	rds := useRedis(t, string(rec.Streamer))
+	url, asked := serveNearRpc(t, func(method string, params map[string]interface{}) ([]byte, error) {
+		key := method
+		if method == "EXPERIMENTAL_light_client_proof" {
+			key = proofKey(params["receipt_id"])
+		}
+		data, ok := rec.Rpc[key]
+		if !ok {
+			return nil, fmt.Errorf("%s not recorded", key)
+		}
+		return data, nil
+	})
+	m, router := newTestSyncAt(t, url, messenger, int64(rec.Synced))
+	m.cfg.McsContract = []string{rec.Mos}
+
+	height, err := messenger(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height == 0 || rds.len() != 0 || len(router.sent) == 0 || len(router.sent) != len(*asked) {
+		t.Fatalf("relayed %d with %d messages for %d proofs, %d left", height, len(router.sent), len(*asked), rds.len())
+	}
+	for i, sent := range router.sent {
+		if (*asked)[i]["light_client_head"] != rec.HeadHash {
+			t.Fatalf("proof asked against %v, want %s", (*asked)[i]["light_client_head"], rec.HeadHash)
+		}
+		var proof nearclient.RpcLightClientExecutionProofResponse
+		rec.result(t, proofKey(sent.Payload[3]), &proof)
+		args, err := mapprotocol.Mcs.Methods[mapprotocol.MethodOfMessageIn].Inputs.Unpack(sent.Payload[0].([]byte)[4:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if common.Hash(args[2].([32]byte)) != sent.Payload[1].(common.Hash) {
+			t.Fatalf("messageIn order %x, message %s", args[2], sent.Payload[1])
+		}
+		log := proof.OutcomeProof.Outcome.Logs[args[1].(*big.Int).Int64()]
+		var body struct {
+			OrderId string `json:"order_id"`
+		}
+		if err = json.Unmarshal([]byte(strings.TrimPrefix(log, mapprotocol.NearOfDepositIn)), &body); err != nil ||
+			common.HexToHash(body.OrderId) != sent.Payload[1].(common.Hash) {
+			t.Fatalf("order %s is not the one of proven log %q", sent.Payload[1], log)
+		}
+	}
+}
+
+// TestRecord saves the oldest block of the near indexer's redis list and the
+// rpc responses that prove its mos logs to testdata/recorded.json:
+//
+//	go test ./chains/near -run TestRecord$ -record https://<rpc> -redis redis://<host> -mos <account>
+func TestRecord(t *testing.T) {
+	if *record == "" {
+		t.Skip("no -record")
+	}
+	ctx := context.Background()
+	opt, err := goredis.ParseURL(*recordRedis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdb := goredis.NewClient(opt)
+	defer rdb.Close()
+	streamer, err := rdb.LIndex(ctx, redis.ListKey, 0).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &sync{
+		CommonSync: &chain.CommonSync{Log: log15.New()},
+		cfg:        &Config{McsContract: []string{*recordMos}, Topics: []string{mapprotocol.NearOfDepositIn}},
+	}
+	var sm mapprotocol.StreamerMessage
+	if err = json.Unmarshal([]byte(streamer), &sm); err != nil {
+		t.Fatal(err)
+	}
+	events := s.match(&sm)
+	if len(events) == 0 {
+		t.Fatalf("block %d has no log of %s", sm.Block.Header.Height, *recordMos)
+	}
+
+	rec := &recording{
+		Mos:      *recordMos,
+		Synced:   sm.Block.Header.Height,
+		Streamer: json.RawMessage(streamer),
+		Rpc:      make(map[string]json.RawMessage),
+	}
+	rec.Rpc["status"] = nearCall(t, "status", []interface{}{})
+	rec.Rpc["block"] = nearCall(t, "block", map[string]interface{}{"block_id": rec.Synced})
+	var synced struct {
+		Header struct {
+			Hash string `json:"hash"`
+		} `json:"header"`
+	}
+	rec.result(t, "block", &synced)
+	rec.Rpc["next_light_client_block"] = nearCall(t, "next_light_client_block", []string{synced.Header.Hash})
+	var head nearclient.LightClientBlockView
+	rec.result(t, "next_light_client_block", &head)
+	if head.InnerLite.Height <= rec.Synced {
+		t.Fatalf("no light client block after %d yet", rec.Synced)
+	}
+	var headBlock struct {
+		Result struct {
+			Header struct {
+				Hash string `json:"hash"`
+			} `json:"header"`
+		} `json:"result"`
+	}
+	if err = json.Unmarshal(nearCall(t, "block", map[string]interface{}{"block_id": head.InnerLite.Height}), &headBlock); err != nil {
+		t.Fatal(err)
+	}
+	rec.HeadHash = headBlock.Result.Header.Hash
+	for _, ev := range events {
+		id := ev.outcome.ID.String()
+		rec.Rpc[proofKey(id)] = nearCall(t, "EXPERIMENTAL_light_client_proof", map[string]interface{}{
+			"type":              nearclient.TypeReceipt,
+			"receipt_id":        id,
+			"receiver_id":       ev.outcome.Outcome.ExecutorID,
+			"light_client_head": rec.HeadHash,
+		})
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(recordingFile, append(data, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("recorded block %d with %d logs", rec.Synced, len(events))
+}
+
+// nearCall returns the whole response of method at the -record endpoint.
+func nearCall(t *testing.T, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": "compass", "method": method, "params": params})
+	resp, err := http.Post(*record, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var check struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err = json.Unmarshal(data, &check); err != nil {
+		t.Fatal(err)
+	}
+	if len(check.Error) != 0 {
+		t.Fatalf("%s: %s", method, check.Error)
+	}
+	return data
+}
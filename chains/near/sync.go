@@ -0,0 +1,268 @@
+package near
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	goredis "github.com/go-redis/redis/v8"
+	connection "github.com/mapprotocol/compass/connections/near"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/redis"
+	"github.com/mapprotocol/compass/pkg/util"
+	nearclient "github.com/mapprotocol/near-api-go/pkg/client"
+	"github.com/mapprotocol/near-api-go/pkg/client/block"
+	"github.com/pkg/errors"
+)
+
+// Handler handles what there is to relay and returns the near height it got
+// to, 0 when there was nothing.
+type Handler func(*sync) (int64, error)
+
+type sync struct {
+	*chain.CommonSync
+	handler Handler
+	conn    *connection.Connection
+	cfg     *Config
+}
+
+func newSync(cs *chain.CommonSync, handler Handler, conn *connection.Connection, cfg *Config) *sync {
+	return &sync{CommonSync: cs, handler: handler, conn: conn, cfg: cfg}
+}
+
+func (m *sync) Sync() error {
+	m.Log.Info("Starting listener...")
+	if !m.Cfg.SyncToMap {
+		time.Sleep(time.Hour * 2400)
+		return nil
+	}
+	go func() {
+		err := m.sync()
+		if err != nil {
+			m.Log.Error("Polling near failed", "err", err)
+		}
+	}()
+	return nil
+}
+
+func (m *sync) sync() error {
+	for {
+		select {
+		case <-m.Stop:
+			return errors.New("polling terminated")
+		default:
+			height, err := m.handler(m)
+			if err != nil {
+				if errors.Is(err, chain.NotVerifyAble) {
+					m.Log.Info("Near block not provable yet, will retry", "err", err)
+					time.Sleep(constant.BlockRetryInterval)
+					continue
+				}
+				m.Log.Error("Near handler failed", "err", err)
+				util.Alarm(context.Background(), fmt.Sprintf("near handler failed, chain=%s, err is %s", m.Cfg.Name, err.Error()))
+				time.Sleep(constant.BlockRetryInterval)
+				continue
+			}
+			if height == 0 {
+				time.Sleep(constant.MessengerInterval)
+				continue
+			}
+			m.Cfg.StartBlock = big.NewInt(height)
+			err = m.BlockStore.StoreBlock(m.Cfg.StartBlock)
+			if err != nil {
+				m.Log.Error("Failed to write latest block to blockstore", "block", height, "err", err)
+			}
+		}
+	}
+}
+
+// nextLightClientBlock returns the light client block that follows the block
+// at height, which has to be one the light client on MAP knows.
+func (m *sync) nextLightClientBlock(height *big.Int) (*nearclient.LightClientBlockView, error) {
+	ctx := context.Background()
+	b, err := m.conn.NearClient().BlockDetails(ctx, block.BlockID(height.Uint64()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to get near block %d", height)
+	}
+	head, err := m.conn.NearClient().NextLightClientBlock(ctx, b.Header.Hash)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to get next light client block")
+	}
+	return &head, nil
+}
+
+// maintainer syncs the next light client block to MAP, once the one there
+// is final on near.
+func maintainer(m *sync) (int64, error) {
+	synced, err := mapprotocol.Get2MapHeight(m.Cfg.Id)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to get synced height")
+	}
+	head, err := m.nextLightClientBlock(synced)
+	if err != nil {
+		return 0, err
+	}
+	if head.InnerLite.Height <= synced.Uint64() {
+		m.Log.Debug("No light client block after the synced one", "synced", synced)
+		return 0, nil
+	}
+	data, err := borshLightClientBlock(head)
+	if err != nil {
+		return 0, errors.Wrap(err, "unable to encode light client block")
+	}
+	id := big.NewInt(0).SetUint64(uint64(m.Cfg.Id))
+	input, err := mapprotocol.PackInput(mapprotocol.LightManger, mapprotocol.MethodUpdateBlockHeader, id, data)
+	if err != nil {
+		return 0, errors.Wrap(err, "block2Map failed to pack abi data")
+	}
+	m.Log.Info("Sync Header to Map Chain", "synced", synced, "current", head.InnerLite.Height)
+	message := msg.NewSyncToMap(m.Cfg.Id, m.Cfg.MapChainID, []interface{}{id, input}, m.MsgCh)
+	err = m.Router.Send(message)
+	if err != nil {
+		m.Log.Error("subscription error: failed to route message", "err", err)
+		return 0, nil
+	}
+	_ = m.WaitUntilMsgHandled(1)
+	return int64(head.InnerLite.Height), nil
+}
+
+// event is a matched mos log of a near block.
+type event struct {
+	outcome *mapprotocol.ExecutionOutcomeWithIdView
+	logIdx  int
+	orderId common.Hash
+}
+
+// messenger proves the mos logs of the oldest block in the indexer's redis
+// list. The block leaves the list once every log of it is relayed.
+func messenger(m *sync) (int64, error) {
+	ctx := context.Background()
+	data, err := redis.GetClient().LIndex(ctx, redis.ListKey, 0).Result()
+	if err != nil {
+		if errors.Is(err, goredis.Nil) {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "unable to read near logs from redis")
+	}
+
+	var sm mapprotocol.StreamerMessage
+	if err = json.Unmarshal([]byte(data), &sm); err != nil {
+		m.Log.Warn("Dropping malformed near log", "err", err)
+		return 0, redis.GetClient().LPop(ctx, redis.ListKey).Err()
+	}
+	height := sm.Block.Header.Height
+	events := m.match(&sm)
+
+	messages := make([]msg.Message, 0, len(events))
+	for _, ev := range events {
+		message, err := m.prove(height, ev)
+		if err != nil {
+			return 0, err
+		}
+		messages = append(messages, message)
+	}
+	for _, message := range messages {
+		m.Log.Info("Relay near log", "block", height, "receipt", message.Payload[3], "orderId", message.Payload[1])
+		err = m.Router.Send(message)
+		if err != nil {
+			m.Log.Error("subscription error: failed to route message", "err", err)
+		}
+	}
+	_ = m.WaitUntilMsgHandled(len(messages))
+
+	if err = redis.GetClient().LPop(ctx, redis.ListKey).Err(); err != nil {
+		return 0, errors.Wrap(err, "unable to pop relayed near log")
+	}
+	return int64(height), nil
+}
+
+// match returns the logs of the block the mos emitted with a relayed topic.
+// A near log is the hex topic followed by the json of the event.
+func (m *sync) match(sm *mapprotocol.StreamerMessage) []event {
+	ret := make([]event, 0)
+	for _, shard := range sm.Shards {
+		for i := range shard.ReceiptExecutionOutcomes {
+			outcome := &shard.ReceiptExecutionOutcomes[i].ExecutionOutcome
+			if !isMcs(outcome.Outcome.ExecutorID, m.cfg.McsContract) {
+				continue
+			}
+			for idx, l := range outcome.Outcome.Logs {
+				for _, topic := range m.cfg.Topics {
+					if !strings.HasPrefix(l, topic) {
+						continue
+					}
+					var body struct {
+						OrderId string `json:"order_id"`
+					}
+					if err := json.Unmarshal([]byte(l[len(topic):]), &body); err != nil {
+						m.Log.Warn("Skip near log with a bad body", "receipt", outcome.ID, "err", err)
+						continue
+					}
+					ret = append(ret, event{outcome: outcome, logIdx: idx, orderId: common.HexToHash(body.OrderId)})
+				}
+			}
+		}
+	}
+	return ret
+}
+
+func isMcs(account string, mcs []string) bool {
+	for _, ele := range mcs {
+		if ele == account {
+			return true
+		}
+	}
+	return false
+}
+
+// prove builds the messageIn of ev. The head is the light client block after
+// the one synced to MAP, the light client there checks its approvals against
+// the block producers it keeps.
+func (m *sync) prove(height uint64, ev event) (msg.Message, error) {
+	synced, err := mapprotocol.Get2MapHeight(m.Cfg.Id)
+	if err != nil {
+		return msg.Message{}, errors.Wrap(err, "unable to get synced height")
+	}
+	head, err := m.nextLightClientBlock(synced)
+	if err != nil {
+		return msg.Message{}, err
+	}
+	if head.InnerLite.Height <= height {
+		return msg.Message{}, fmt.Errorf("block %d, head %d: %w", height, head.InnerLite.Height, chain.NotVerifyAble)
+	}
+
+	proof, err := m.conn.NearClient().LightClientProof(context.Background(), nearclient.Receipt{
+		ReceiptID:       ev.outcome.ID,
+		ReceiverID:      ev.outcome.Outcome.ExecutorID,
+		LightClientHead: blockHash(head.PrevBlockHash, head.InnerRestHash, &head.InnerLite),
+	})
+	if err != nil {
+		return msg.Message{}, errors.Wrap(err, "unable to get light client proof")
+	}
+	headData, err := borshLightClientBlock(head)
+	if err != nil {
+		return msg.Message{}, errors.Wrap(err, "unable to encode light client block")
+	}
+	proofData, err := borshOutcomeProof(&proof)
+	if err != nil {
+		return msg.Message{}, errors.Wrap(err, "unable to encode outcome proof")
+	}
+	receiptProof, err := mapprotocol.Near.Methods[mapprotocol.MethodOfGetBytes].Inputs.Pack(headData, proofData)
+	if err != nil {
+		return msg.Message{}, errors.Wrap(err, "pack getBytes failed")
+	}
+	input, err := mapprotocol.PackInput(mapprotocol.Mcs, mapprotocol.MethodOfMessageIn,
+		big.NewInt(0).SetUint64(uint64(m.Cfg.Id)), big.NewInt(int64(ev.logIdx)), ev.orderId, receiptProof)
+	if err != nil {
+		return msg.Message{}, errors.Wrap(err, "pack messageIn failed")
+	}
+	return msg.NewSwapWithProof(m.Cfg.Id, m.Cfg.MapChainID, []interface{}{input, ev.orderId,
+		big.NewInt(0).SetUint64(height), ev.outcome.ID.String()}, m.MsgCh), nil
+}
@@ -0,0 +1,323 @@
+package near
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	connection "github.com/mapprotocol/compass/connections/near"
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/redis"
+	nearclient "github.com/mapprotocol/near-api-go/pkg/client"
+)
+
+// fakeRedis speaks enough RESP for the list the near indexer feeds.
+type fakeRedis struct {
+	mu   gosync.Mutex
+	list []string
+}
+
+var (
+	testRedis     = &fakeRedis{}
+	testRedisOnce gosync.Once
+)
+
+// useRedis points the redis client at the package's stand-in holding list.
+// The client is set up once a process, so every test shares the stand-in.
+func useRedis(t *testing.T, list ...string) *fakeRedis {
+	t.Helper()
+	testRedisOnce.Do(func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go testRedis.serve(l)
+		redis.Init("redis://" + l.Addr().String())
+	})
+	testRedis.mu.Lock()
+	defer testRedis.mu.Unlock()
+	testRedis.list = list
+	return testRedis
+}
+
+func (r *fakeRedis) serve(l net.Listener) {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go r.handle(c)
+	}
+}
+
+func (r *fakeRedis) handle(c net.Conn) {
+	defer c.Close()
+	rd := bufio.NewReader(c)
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		r.mu.Lock()
+		var reply string
+		switch strings.ToUpper(args[0]) {
+		case "LINDEX":
+			idx, _ := strconv.Atoi(args[2])
+			reply = "$-1\r\n"
+			if idx < len(r.list) {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(r.list[idx]), r.list[idx])
+			}
+		case "LPOP":
+			reply = "$-1\r\n"
+			if len(r.list) > 0 {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(r.list[0]), r.list[0])
+				r.list = r.list[1:]
+			}
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		r.mu.Unlock()
+		if _, err = io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (r *fakeRedis) len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.list)
+}
+
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		if _, err = rd.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := rd.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+	return args, nil
+}
+
+// newNearRpc serves the synthetic near rpc responses and keeps the params of
+// the light client proof requests.
+func newNearRpc(t *testing.T) (string, *[]map[string]interface{}) {
+	t.Helper()
+	files := map[string]string{
+		"status":                          "synthetic_status.json",
+		"block":                           "synthetic_block.json",
+		"next_light_client_block":         "synthetic_next_light_client_block.json",
+		"EXPERIMENTAL_light_client_proof": "synthetic_light_client_proof.json",
+	}
+	return serveNearRpc(t, func(method string, _ map[string]interface{}) ([]byte, error) {
+		return os.ReadFile("testdata/" + files[method])
+	})
+}
+
+// serveNearRpc answers near rpc requests with the responses respond looks up
+// and keeps the params of the light client proof requests.
+func serveNearRpc(t *testing.T, respond func(method string, params map[string]interface{}) ([]byte, error)) (string, *[]map[string]interface{}) {
+	t.Helper()
+	var (
+		mu     gosync.Mutex
+		proofs []map[string]interface{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		params := make(map[string]interface{})
+		_ = json.Unmarshal(req.Params, &params)
+		if req.Method == "EXPERIMENTAL_light_client_proof" {
+			mu.Lock()
+			proofs = append(proofs, params)
+			mu.Unlock()
+		}
+		data, err := respond(req.Method, params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, &proofs
+}
+
+// ackRouter keeps what is sent and handles it right away.
+type ackRouter struct {
+	sent []msg.Message
+}
+
+func (r *ackRouter) Send(m msg.Message) error {
+	r.sent = append(r.sent, m)
+	go func() { m.DoneCh <- struct{}{} }()
+	return nil
+}
+
+func (r *ackRouter) Listen(msg.ChainId, core.Writer) {}
+
+func newTestSync(t *testing.T, handler Handler, synced int64) (*sync, *ackRouter) {
+	t.Helper()
+	url, _ := newNearRpc(t)
+	return newTestSyncAt(t, url, handler, synced)
+}
+
+func newTestSyncAt(t *testing.T, url string, handler Handler, synced int64) (*sync, *ackRouter) {
+	t.Helper()
+	conn := connection.NewConnection(url, true, nil, log15.New(), nil, nil, nil)
+	if err := conn.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	get2Map := mapprotocol.Get2MapHeight
+	mapprotocol.Get2MapHeight = func(msg.ChainId) (*big.Int, error) { return big.NewInt(synced), nil }
+	t.Cleanup(func() { mapprotocol.Get2MapHeight = get2Map })
+
+	cfg := &Config{
+		Config:      chain.Config{Name: "near", Id: 1360100178526209, MapChainID: 22776, SyncToMap: true},
+		McsContract: []string{"mos.map007.testnet"},
+		Topics:      []string{mapprotocol.NearOfDepositIn},
+	}
+	cs := chain.NewCommonSync(conn, &cfg.Config, log15.New(), make(chan int), nil, nil)
+	router := &ackRouter{}
+	cs.SetRouter(router)
+	return newSync(cs, handler, conn, cfg), router
+}
+
+func TestMaintainer(t *testing.T) {
+	m, router := newTestSync(t, maintainer, 100)
+	height, err := maintainer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 105 || len(router.sent) != 1 {
+		t.Fatalf("synced to %d with %d messages", height, len(router.sent))
+	}
+	sent := router.sent[0]
+	if sent.Type != msg.SyncToMap || sent.Destination != 22776 {
+		t.Fatalf("sent %s to %d", sent.Type, sent.Destination)
+	}
+	args, err := mapprotocol.LightManger.Methods[mapprotocol.MethodUpdateBlockHeader].Inputs.Unpack(sent.Payload[1].([]byte)[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var head nearclient.LightClientBlockView
+	fixture(t, "synthetic_next_light_client_block.json", &head)
+	want, _ := borshLightClientBlock(&head)
+	if args[0].(*big.Int).Uint64() != 1360100178526209 || string(args[1].([]byte)) != string(want) {
+		t.Fatalf("updateBlockHeader(%v, %x)", args[0], args[1])
+	}
+
+	// nothing to sync once the light client is at the latest light client block
+	m, router = newTestSync(t, maintainer, 105)
+	if height, err = maintainer(m); err != nil || height != 0 || len(router.sent) != 0 {
+		t.Fatalf("got %d, %v and %d messages", height, err, len(router.sent))
+	}
+}
+
+func TestMessenger(t *testing.T) {
+	block, err := os.ReadFile("testdata/synthetic_streamer_message.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rds := useRedis(t, "{not json", string(block))
+
+	url, proofs := newNearRpc(t)
+	m, router := newTestSyncAt(t, url, messenger, 100)
+
+	// a malformed entry is dropped
+	if height, err := messenger(m); err != nil || height != 0 || rds.len() != 1 {
+		t.Fatalf("got %d, %v, %d left", height, err, rds.len())
+	}
+
+	height, err := messenger(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if height != 103 || rds.len() != 0 {
+		t.Fatalf("relayed %d, %d left", height, rds.len())
+	}
+	// only the mos log is relayed
+	if len(router.sent) != 1 {
+		t.Fatalf("%d messages, want 1", len(router.sent))
+	}
+	sent := router.sent[0]
+	orderId := common.HexToHash("0x7d7f3c7eb4c8e0b3b41e5e74a6f8f2ab5a0a8c9e8f6b3d2c1a0f9e8d7c6b5a49")
+	if sent.Type != msg.SwapWithProof || sent.Payload[1].(common.Hash) != orderId ||
+		sent.Payload[3] != "8V4wPcrdG9WWedNvPuTUZ6a3uHT8SpeddJGCNWSt1wj2" {
+		t.Fatalf("sent %s %v", sent.Type, sent.Payload[1:])
+	}
+	args, err := mapprotocol.Mcs.Methods[mapprotocol.MethodOfMessageIn].Inputs.Unpack(sent.Payload[0].([]byte)[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args[1].(*big.Int).Int64() != 1 || common.Hash(args[2].([32]byte)) != orderId {
+		t.Fatalf("messageIn(%v, %v, %x)", args[0], args[1], args[2])
+	}
+	bs, err := mapprotocol.Near.Methods[mapprotocol.MethodOfGetBytes].Inputs.Unpack(args[3].([]byte))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		head  nearclient.LightClientBlockView
+		proof nearclient.RpcLightClientExecutionProofResponse
+	)
+	fixture(t, "synthetic_next_light_client_block.json", &head)
+	fixture(t, "synthetic_light_client_proof.json", &proof)
+	wantHead, _ := borshLightClientBlock(&head)
+	wantProof, _ := borshOutcomeProof(&proof)
+	if string(bs[0].([]byte)) != string(wantHead) || string(bs[1].([]byte)) != string(wantProof) {
+		t.Fatal("receipt proof is not the head and proof of the fixtures")
+	}
+	// the proof is asked against the head the light client gets
+	if len(*proofs) != 1 || (*proofs)[0]["light_client_head"] != blockHash(head.PrevBlockHash, head.InnerRestHash, &head.InnerLite).String() ||
+		(*proofs)[0]["receiver_id"] != "mos.map007.testnet" {
+		t.Fatalf("proof asked with %v", *proofs)
+	}
+}
+
+func TestMessengerNotProvable(t *testing.T) {
+	var sm map[string]interface{}
+	block, _ := os.ReadFile("testdata/synthetic_streamer_message.json")
+	_ = json.Unmarshal(block, &sm)
+	// the head after the synced block is 105, it can't prove a later block
+	sm["block"].(map[string]interface{})["header"].(map[string]interface{})["height"] = 105
+	block, _ = json.Marshal(sm)
+	rds := useRedis(t, string(block))
+
+	m, router := newTestSync(t, messenger, 100)
+	if _, err := messenger(m); !errors.Is(err, chain.NotVerifyAble) {
+		t.Fatalf("got %v, want not verifiable", err)
+	}
+	if rds.len() != 1 || len(router.sent) != 0 {
+		t.Fatalf("%d left, %d sent", rds.len(), len(router.sent))
+	}
+}
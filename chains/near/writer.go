@@ -0,0 +1,90 @@
+package near
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	connection "github.com/mapprotocol/compass/connections/near"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/util"
+	nearclient "github.com/mapprotocol/near-api-go/pkg/client"
+	"github.com/mapprotocol/near-api-go/pkg/types"
+	"github.com/mapprotocol/near-api-go/pkg/types/action"
+)
+
+const (
+	methodOfUpdateBlockHeader = "update_block_header"
+	updateBlockHeaderGas      = types.Gas(300_000_000_000_000)
+)
+
+type Writer struct {
+	cfg  *Config
+	log  log15.Logger
+	conn *connection.Connection
+	stop <-chan int
+}
+
+func newWriter(conn *connection.Connection, cfg *Config, log log15.Logger, stop <-chan int) *Writer {
+	return &Writer{
+		cfg:  cfg,
+		conn: conn,
+		log:  log,
+		stop: stop,
+	}
+}
+
+func (w *Writer) ResolveMessage(m msg.Message) bool {
+	w.log.Info("Attempting to resolve message", "type", m.Type, "src", m.Source, "dst", m.Destination)
+	switch m.Type {
+	case msg.SyncFromMap:
+		return w.updateBlockHeader(m)
+	default:
+		w.log.Error("Unknown message type received", "type", m.Type)
+		return false
+	}
+}
+
+// updateBlockHeader hands the MAP header of m, json the light client on near
+// takes, to update_block_header.
+func (w *Writer) updateBlockHeader(m msg.Message) bool {
+	var errorCount int64
+	for {
+		select {
+		case <-w.stop:
+			return false
+		default:
+			kp := w.conn.NearKeypair()
+			if kp == nil {
+				w.log.Error("No near key to sync map headers with", "from", w.cfg.From)
+				return false
+			}
+			res, err := w.conn.NearClient().TransactionSendAwait(context.Background(), w.cfg.From, w.cfg.LightNode,
+				[]action.Action{action.NewFunctionCall(methodOfUpdateBlockHeader, m.Payload[0].([]byte),
+					updateBlockHeaderGas, types.Balance{})},
+				nearclient.WithLatestBlock(), nearclient.WithKeyPair(*kp))
+			if err == nil && len(res.Status.Failure) != 0 {
+				err = fmt.Errorf("update_block_header failed: %s", res.Status.Failure)
+			}
+			if err == nil {
+				w.log.Info("Sync Map Header to near tx execution", "tx", res.Transaction.Hash, "src", m.Source, "dst", m.Destination)
+				m.DoneCh <- struct{}{}
+				return true
+			}
+			if w.cfg.SkipError {
+				w.log.Warn("Execution failed, ignore this error, Continue to the next ", "err", err)
+				m.DoneCh <- struct{}{}
+				return true
+			}
+			w.log.Warn("Sync Map Header to near Execution failed, header may already been synced", "err", err)
+			errorCount++
+			if errorCount >= 10 {
+				util.Alarm(context.Background(), fmt.Sprintf("map2near updateHeader failed, err is %s", err.Error()))
+				errorCount = 0
+			}
+			time.Sleep(constant.TxRetryInterval)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mapprotocol/compass/core"
 	"github.com/mapprotocol/compass/internal/chain"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/blockstore"
 	"github.com/mapprotocol/compass/pkg/keystore"
 	"github.com/mapprotocol/compass/pkg/msg"
 	"github.com/mapprotocol/compass/pkg/util"
@@ -69,9 +70,12 @@ func createChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<-
 
 	switch role {
 	case mapprotocol.RoleOfMessenger:
-		listen = newSync(cs, mosHandler, conn, config, conn.cli)
+		listen, err = newListener(newSync(cs, mosHandler, conn, config, conn.cli), relayMos, bs)
 	case mapprotocol.RoleOfOracle:
-		listen = newSync(cs, oracleHandler, conn, config, conn.cli)
+		listen, err = newListener(newSync(cs, oracleHandler, conn, config, conn.cli), relayOracle, bs)
+	}
+	if err != nil {
+		return nil, err
 	}
 	mapprotocol.MosMapping[config.Id] = config.McsContract[0]
 
@@ -84,6 +88,17 @@ func createChain(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<-
 	}, nil
 }
 
+// newListener reads the mos events the way the chain is configured to.
+func newListener(s *sync, relay Relayer, bs *blockstore.Blockstore) (core.Listener, error) {
+	switch s.cfg.Ingest {
+	case IngestSubscribe:
+		return newSubscriber(s, relay, bs)
+	case IngestScan:
+		return newScanner(s, relay, bs)
+	}
+	return s, nil
+}
+
 func (c *Chain) SetRouter(r core.Router) {
 	r.Listen(c.cfg.Id, c.writer)
 	c.listen.SetRouter(r)
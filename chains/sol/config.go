@@ -1,6 +1,8 @@
 package sol
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/mapprotocol/compass/core"
@@ -18,8 +20,30 @@ type Config struct {
 	WsolAda     string
 	ButterHost  string
 	PriceHost   string
+	Ingest      string // where mos events come from, one of the Ingest* modes
+	WsEndpoint  string // websocket endpoint of IngestSubscribe
+	PriorityFee PriorityFeeConfig
 }
 
+// PriorityFeeConfig is how the writer prices the compute units of its
+// transactions. A zero Cap leaves the transactions as Butter built them.
+type PriorityFeeConfig struct {
+	Cap        uint64  // most micro-lamports paid per compute unit
+	Percentile float64 // of the recent fees on the written accounts
+	UnitLimit  uint32  // compute unit limit, 0 to take it from a simulation
+}
+
+const (
+	// IngestFilter reads the mos events the filter service indexed.
+	IngestFilter = "filter"
+	// IngestSubscribe subscribes to the logs of the mos programs on the
+	// websocket of the rpc, the blockstore keeps the last relayed slot.
+	IngestSubscribe = "subscribe"
+	// IngestScan pages through the transactions of the mos programs with
+	// getSignaturesForAddress, the blockstore keeps a signature cursor.
+	IngestScan = "scan"
+)
+
 func parseCfg(chainCfg *core.ChainConfig) (*Config, error) {
 	cfg, err := chain.ParseConfig(chainCfg)
 	if err != nil {
@@ -51,5 +75,52 @@ func parseCfg(chainCfg *core.ChainConfig) (*Config, error) {
 		ret.SolEvent = append(ret.SolEvent, strings.Split(v, "|")...)
 	}
 
+	ret.Ingest = IngestFilter
+	if v, ok := chainCfg.Opts[chain.IngestOpt]; ok && v != "" {
+		ret.Ingest = v
+	}
+	switch ret.Ingest {
+	case IngestFilter, IngestScan:
+	case IngestSubscribe:
+		ret.WsEndpoint = wsEndpoint(ret.Endpoint)
+		if v, ok := chainCfg.Opts[chain.WsEndpointOpt]; ok && v != "" {
+			ret.WsEndpoint = v
+		}
+	default:
+		return nil, fmt.Errorf("chain %s: unknown %s %q", chainCfg.Name, chain.IngestOpt, ret.Ingest)
+	}
+
+	ret.PriorityFee.Percentile = defaultPriorityFeePercentile
+	if v, ok := chainCfg.Opts[chain.PriorityFeeCapOpt]; ok && v != "" {
+		ret.PriorityFee.Cap, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", chain.PriorityFeeCapOpt)
+		}
+	}
+	if v, ok := chainCfg.Opts[chain.PriorityFeePercentOpt]; ok && v != "" {
+		ret.PriorityFee.Percentile, err = strconv.ParseFloat(v, 64)
+		if err != nil || ret.PriorityFee.Percentile <= 0 || ret.PriorityFee.Percentile > 100 {
+			return nil, fmt.Errorf("unable to parse %s", chain.PriorityFeePercentOpt)
+		}
+	}
+	if v, ok := chainCfg.Opts[chain.ComputeUnitLimitOpt]; ok && v != "" {
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n > maxComputeUnitLimit {
+			return nil, fmt.Errorf("unable to parse %s", chain.ComputeUnitLimitOpt)
+		}
+		ret.PriorityFee.UnitLimit = uint32(n)
+	}
+
 	return &ret, nil
 }
+
+// wsEndpoint is the websocket endpoint next to the rpc endpoint.
+func wsEndpoint(endpoint string) string {
+	switch {
+	case strings.HasPrefix(endpoint, "https://"):
+		return "wss://" + strings.TrimPrefix(endpoint, "https://")
+	case strings.HasPrefix(endpoint, "http://"):
+		return "ws://" + strings.TrimPrefix(endpoint, "http://")
+	}
+	return endpoint
+}
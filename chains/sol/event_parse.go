@@ -1,11 +1,16 @@
 package sol
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
+	"io"
 	"math/big"
+	"strconv"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gagliardetto/solana-go"
+	"github.com/pkg/errors"
 )
 
 // CrossFinishEvent contains the parameters for a gateway deposit instruction
@@ -68,3 +73,157 @@ func parseCrossFinishEventData(data []byte) (*CrossFinishEvent, error) {
 		OrderRecord:  orderRecord,
 	}, nil
 }
+
+// CrossOutEvent is the event the mos program emits when an order leaves
+// solana. Its borsh layout after the discriminator is
+//
+//	relay bool, order_record OrderRecord, origin_receiver Vec<u8>,
+//	swap_data Vec<u8>, bridge_mint Pubkey, bridge_amount u64,
+//	after_balance u64, amount_out u64
+//
+// with the OrderRecord fields in their declaration order, u128 amounts and
+// Vec<u16> referer ids and fee ratios.
+type CrossOutEvent struct {
+	Relay          bool
+	OrderRecord    *OrderRecord
+	OriginReceiver []byte
+	SwapData       []byte
+	BridgeMint     solana.PublicKey
+	BridgeAmount   uint64
+	AfterBalance   uint64
+	AmountOut      uint64
+}
+
+func parseCrossOutEventData(data []byte) (*CrossOutEvent, error) {
+	if len(data) < 8 || !bytes.Equal(data[:8], anchorDiscriminator("event:CrossOutEvent")) {
+		return nil, errors.New("not a cross out event")
+	}
+	r := &borshReader{data: data[8:]}
+	ev := &CrossOutEvent{Relay: r.bool()}
+	order := &OrderRecord{OrderId: r.bytes(32)}
+	copy(order.Payer[:], r.bytes(32))
+	order.FromChainId = r.u64()
+	order.ToChainId = r.u64()
+	copy(order.ToToken[:], r.bytes(32))
+	copy(order.FromToken[:], r.bytes(32))
+	copy(order.From[:], r.bytes(32))
+	copy(order.Receiver[:], r.bytes(32))
+	order.TokenAmount = r.u128()
+	copy(order.SwapTokenOut[:], r.bytes(32))
+	order.SwapTokenOutBeforeBalance = r.u64()
+	order.SwapTokenOutMinAmountOut = r.u64()
+	order.MinAmountOut = r.u128()
+	order.RefererId = r.u16s()
+	order.FeeRatio = r.u16s()
+	ev.OrderRecord = order
+	ev.OriginReceiver = r.vec()
+	ev.SwapData = r.vec()
+	copy(ev.BridgeMint[:], r.bytes(32))
+	ev.BridgeAmount = r.u64()
+	ev.AfterBalance = r.u64()
+	ev.AmountOut = r.u64()
+	if r.err != nil {
+		return nil, errors.Wrap(r.err, "decode cross out event")
+	}
+	return ev, nil
+}
+
+// crossOutData is ev the way the filter service indexes it.
+func (ev *CrossOutEvent) crossOutData() *CrossOutData {
+	order := ev.OrderRecord
+	receiver := order.Receiver[:]
+	if isFirst12Zero(receiver) {
+		receiver = receiver[12:]
+	}
+	ret := &CrossOutData{
+		Relay:                     ev.Relay,
+		OrderId:                   hexutil.Encode(order.OrderId),
+		TokenAmount:               order.TokenAmount.String(),
+		From:                      order.From[:],
+		FromToken:                 order.FromToken[:],
+		ToToken:                   order.ToToken[:],
+		SwapTokenOut:              order.SwapTokenOut.String(),
+		SwapTokenOutMinAmountOut:  strconv.FormatUint(order.SwapTokenOutMinAmountOut, 10),
+		MinAmountOut:              order.MinAmountOut.Text(16),
+		SwapTokenOutBeforeBalance: strconv.FormatUint(order.SwapTokenOutBeforeBalance, 10),
+		AfterBalance:              strconv.FormatUint(ev.AfterBalance, 10),
+		Receiver:                  hexutil.Encode(receiver),
+		OriginReceiver:            ev.OriginReceiver,
+		ToChain:                   strconv.FormatUint(order.ToChainId, 16),
+		FromChainId:               strconv.FormatUint(order.FromChainId, 16),
+		AmountOut:                 strconv.FormatUint(ev.AmountOut, 16),
+		BridgeMint:                ev.BridgeMint.String(),
+		BridgeAmount:              strconv.FormatUint(ev.BridgeAmount, 10),
+	}
+	for _, id := range order.RefererId {
+		ret.RefererId = append(ret.RefererId, int(id))
+	}
+	for _, ratio := range order.FeeRatio {
+		ret.FeeRatio = append(ret.FeeRatio, int(ratio))
+	}
+	if len(ev.SwapData) > 0 {
+		ret.SwapData = hexutil.Encode(ev.SwapData)
+	}
+	return ret
+}
+
+// borshReader reads little endian borsh values, remembering the first read
+// past the end.
+type borshReader struct {
+	data []byte
+	err  error
+}
+
+func (r *borshReader) bytes(n int) []byte {
+	if r.err != nil || n > len(r.data) {
+		if r.err == nil {
+			r.err = io.ErrUnexpectedEOF
+		}
+		// fixed size reads still get their zero value
+		if n > 32 {
+			return nil
+		}
+		return make([]byte, n)
+	}
+	ret := make([]byte, n)
+	copy(ret, r.data[:n])
+	r.data = r.data[n:]
+	return ret
+}
+
+func (r *borshReader) bool() bool {
+	return r.bytes(1)[0] != 0
+}
+
+func (r *borshReader) u64() uint64 {
+	return binary.LittleEndian.Uint64(r.bytes(8))
+}
+
+func (r *borshReader) u128() *big.Int {
+	b := r.bytes(16)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return new(big.Int).SetBytes(b)
+}
+
+func (r *borshReader) length() int {
+	return int(binary.LittleEndian.Uint32(r.bytes(4)))
+}
+
+func (r *borshReader) vec() []byte {
+	return r.bytes(r.length())
+}
+
+func (r *borshReader) u16s() []int64 {
+	n := r.length()
+	if n > len(r.data)/2 {
+		r.bytes(2 * n)
+		return nil
+	}
+	ret := make([]int64, 0, n)
+	for i := 0; i < n; i++ {
+		ret = append(ret, int64(binary.LittleEndian.Uint16(r.bytes(2))))
+	}
+	return ret
+}
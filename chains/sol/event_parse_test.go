@@ -0,0 +1,140 @@
+package sol
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// borshCrossOutEvent encodes ev the way the mos program emits it.
+func borshCrossOutEvent(ev *CrossOutEvent) []byte {
+	var b []byte
+	u64 := func(v uint64) { b = binary.LittleEndian.AppendUint64(b, v) }
+	u128 := func(v *big.Int) {
+		le := make([]byte, 16)
+		v.FillBytes(le)
+		for i, j := 0, 15; i < j; i, j = i+1, j-1 {
+			le[i], le[j] = le[j], le[i]
+		}
+		b = append(b, le...)
+	}
+	vec := func(v []byte) {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(v)))
+		b = append(b, v...)
+	}
+	u16s := func(v []int64) {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(v)))
+		for _, e := range v {
+			b = binary.LittleEndian.AppendUint16(b, uint16(e))
+		}
+	}
+	order := ev.OrderRecord
+	b = append(b, anchorDiscriminator("event:CrossOutEvent")...)
+	if ev.Relay {
+		b = append(b, 1)
+	} else {
+		b = append(b, 0)
+	}
+	b = append(b, order.OrderId...)
+	b = append(b, order.Payer[:]...)
+	u64(order.FromChainId)
+	u64(order.ToChainId)
+	b = append(b, order.ToToken[:]...)
+	b = append(b, order.FromToken[:]...)
+	b = append(b, order.From[:]...)
+	b = append(b, order.Receiver[:]...)
+	u128(order.TokenAmount)
+	b = append(b, order.SwapTokenOut[:]...)
+	u64(order.SwapTokenOutBeforeBalance)
+	u64(order.SwapTokenOutMinAmountOut)
+	u128(order.MinAmountOut)
+	u16s(order.RefererId)
+	u16s(order.FeeRatio)
+	vec(ev.OriginReceiver)
+	vec(ev.SwapData)
+	b = append(b, ev.BridgeMint[:]...)
+	u64(ev.BridgeAmount)
+	u64(ev.AfterBalance)
+	u64(ev.AmountOut)
+	return b
+}
+
+func testCrossOutEvent(orderId byte) *CrossOutEvent {
+	order := &OrderRecord{
+		OrderId:                   make([]byte, 32),
+		Payer:                     solana.PublicKey{1},
+		FromChainId:               1360108768460801,
+		ToChainId:                 56,
+		TokenAmount:               big.NewInt(5_000_000),
+		SwapTokenOut:              solana.PublicKey{2},
+		SwapTokenOutBeforeBalance: 7,
+		SwapTokenOutMinAmountOut:  8,
+		MinAmountOut:              new(big.Int).Lsh(big.NewInt(1), 100),
+		RefererId:                 []int64{3},
+		FeeRatio:                  []int64{30},
+	}
+	order.OrderId[31] = orderId
+	order.ToToken[31] = 0x55
+	order.From[0] = 0x66
+	copy(order.Receiver[12:], []byte{0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd})
+	return &CrossOutEvent{
+		OrderRecord:    order,
+		OriginReceiver: append(make([]byte, 13), order.Receiver[12:]...),
+		SwapData:       []byte{},
+		BridgeMint:     solana.PublicKey{3},
+		BridgeAmount:   4_990_000,
+		AfterBalance:   9,
+		AmountOut:      4_990_000,
+	}
+}
+
+func TestParseCrossOutEventData(t *testing.T) {
+	want := testCrossOutEvent(1)
+	data := borshCrossOutEvent(want)
+	got, err := parseCrossOutEventData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for _, bad := range [][]byte{data[:len(data)-1], data[:60], append([]byte{0}, data[1:]...)} {
+		if _, err = parseCrossOutEventData(bad); err == nil {
+			t.Fatalf("decoded %x", bad)
+		}
+	}
+
+	out := got.crossOutData()
+	if out.OrderId != "0x0000000000000000000000000000000000000000000000000000000000000001" ||
+		out.ToChain != "38" || out.FromChainId != "4d50300000001" || out.TokenAmount != "5000000" ||
+		out.BridgeAmount != "4990000" || out.MinAmountOut != "10000000000000000000000000" ||
+		out.Receiver != "0xaabbccddeeff00112233445566778899aabbccdd" || out.BridgeMint != want.BridgeMint.String() ||
+		out.SwapData != "" || !reflect.DeepEqual(out.RefererId, []int{3}) || len(out.ToToken) != 32 {
+		t.Fatalf("cross out data %+v", out)
+	}
+}
+
+func TestSolLogEvents(t *testing.T) {
+	data := base64.StdEncoding.EncodeToString([]byte{1, 2, 3})
+	logs := []string{
+		"Program Mos111 invoke [1]",
+		"Program log: Instruction: CrossOut",
+		"Program Token111 invoke [2]",
+		"Program Token111 consumed 4645 of 180000 compute units",
+		"Program Token111 success",
+		"Program data: " + data,
+		"Program Other111 invoke [2]",
+		"Program data: " + data,
+		"Program Other111 failed: custom program error: 0x1",
+		"Program Mos111 success",
+		"Program data: not base64!",
+	}
+	got := solLogEvents(logs)
+	if len(got) != 2 || got[0].program != "Mos111" || got[1].program != "Other111" || string(got[0].data) != "\x01\x02\x03" {
+		t.Fatalf("got %+v", got)
+	}
+}
@@ -0,0 +1,224 @@
+package sol
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxComputeUnitLimit is the most compute units a transaction can ask for.
+	maxComputeUnitLimit = 1_400_000
+	// maxTransactionSize is the most bytes a transaction can take.
+	maxTransactionSize           = 1232
+	defaultPriorityFeePercentile = 75
+	// priorityFeeStep is the least a rebroadcast raises the priority fee by,
+	// in micro-lamports per compute unit.
+	priorityFeeStep = 10_000
+
+	setComputeUnitLimit = 2 // compute budget instruction setting the limit, u32
+	setComputeUnitPrice = 3 // compute budget instruction setting the price, u64
+)
+
+var errComputeBudgetNotAllowed = errors.New("compute budget can't be changed")
+
+// computeBudget is what the compute budget instructions of a transaction set.
+type computeBudget struct {
+	limit uint32 // compute units, 0 for the default of the runtime
+	price uint64 // micro-lamports per compute unit
+}
+
+// readComputeBudget returns the compute budget the instructions of msg set.
+func readComputeBudget(msg *solana.Message) computeBudget {
+	ret := computeBudget{}
+	for _, inst := range msg.Instructions {
+		if int(inst.ProgramIDIndex) >= len(msg.AccountKeys) || !msg.AccountKeys[inst.ProgramIDIndex].Equals(solana.ComputeBudget) {
+			continue
+		}
+		switch {
+		case len(inst.Data) == 5 && inst.Data[0] == setComputeUnitLimit:
+			ret.limit = binary.LittleEndian.Uint32(inst.Data[1:])
+		case len(inst.Data) == 9 && inst.Data[0] == setComputeUnitPrice:
+			ret.price = binary.LittleEndian.Uint64(inst.Data[1:])
+		}
+	}
+	return ret
+}
+
+// budgetedTx decodes raw with its compute budget instructions replaced by
+// ones setting budget. Butter transactions allow it when signer is the only
+// one to sign them and the instructions fit.
+func budgetedTx(raw []byte, budget computeBudget, signer solana.PublicKey) (*solana.Transaction, error) {
+	trx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	msg := &trx.Message
+	for i := 0; i < int(msg.Header.NumRequiredSignatures) && i < len(msg.AccountKeys); i++ {
+		if !msg.AccountKeys[i].Equals(signer) {
+			return nil, errors.Wrapf(errComputeBudgetNotAllowed, "signed by %s too", msg.AccountKeys[i])
+		}
+	}
+
+	program := -1
+	for i, key := range msg.AccountKeys {
+		if key.Equals(solana.ComputeBudget) {
+			program = i
+		}
+	}
+	if program == -1 {
+		// a readonly unsigned account goes last of the static accounts, the
+		// accounts of the lookup tables are indexed after them
+		program = len(msg.AccountKeys)
+		msg.AccountKeys = append(msg.AccountKeys, solana.ComputeBudget)
+		msg.Header.NumReadonlyUnsignedAccounts++
+		for i := range msg.Instructions {
+			inst := &msg.Instructions[i]
+			if int(inst.ProgramIDIndex) >= program {
+				inst.ProgramIDIndex++
+			}
+			for j, account := range inst.Accounts {
+				if int(account) >= program {
+					inst.Accounts[j]++
+				}
+			}
+		}
+	}
+
+	instructions := make([]solana.CompiledInstruction, 0, len(msg.Instructions)+2)
+	if budget.limit != 0 {
+		data := binary.LittleEndian.AppendUint32([]byte{setComputeUnitLimit}, budget.limit)
+		instructions = append(instructions, solana.CompiledInstruction{ProgramIDIndex: uint16(program), Accounts: []uint16{}, Data: data})
+	}
+	if budget.price != 0 {
+		data := binary.LittleEndian.AppendUint64([]byte{setComputeUnitPrice}, budget.price)
+		instructions = append(instructions, solana.CompiledInstruction{ProgramIDIndex: uint16(program), Accounts: []uint16{}, Data: data})
+	}
+	for _, inst := range msg.Instructions {
+		if int(inst.ProgramIDIndex) == program && len(inst.Data) > 0 &&
+			(inst.Data[0] == setComputeUnitLimit || inst.Data[0] == setComputeUnitPrice) {
+			continue
+		}
+		instructions = append(instructions, inst)
+	}
+	msg.Instructions = instructions
+
+	bin, err := trx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if len(bin) > maxTransactionSize {
+		return nil, errors.Wrapf(errComputeBudgetNotAllowed, "%d bytes with the compute budget", len(bin))
+	}
+	return trx, nil
+}
+
+// writableAccounts are the static accounts msg writes, the ones whose
+// recent fees price it.
+func writableAccounts(msg *solana.Message) solana.PublicKeySlice {
+	h := msg.Header
+	ret := make(solana.PublicKeySlice, 0)
+	for i, key := range msg.AccountKeys {
+		signer := i < int(h.NumRequiredSignatures)
+		if signer && i < int(h.NumRequiredSignatures-h.NumReadonlySignedAccounts) ||
+			!signer && i < len(msg.AccountKeys)-int(h.NumReadonlyUnsignedAccounts) {
+			ret = append(ret, key)
+		}
+	}
+	return ret
+}
+
+// feePercentile is the percentile p of fees.
+func feePercentile(fees []rpc.PriorizationFeeResult, p float64) uint64 {
+	if len(fees) == 0 {
+		return 0
+	}
+	sorted := make([]uint64, 0, len(fees))
+	for _, f := range fees {
+		sorted = append(sorted, f.PrioritizationFee)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// planComputeBudget returns the compute budget of the first broadcast of
+// raw, nil when the writer leaves the transaction as Butter built it. The
+// price is the configured percentile of the recent fees on the accounts the
+// transaction writes, within the cap, and never below the price Butter set.
+func (w *Writer) planComputeBudget(ctx context.Context, raw []byte, signer solana.PublicKey) *computeBudget {
+	cfg := w.cfg.PriorityFee
+	if cfg.Cap == 0 {
+		return nil
+	}
+	trx, err := solana.TransactionFromBytes(raw)
+	if err != nil {
+		return nil
+	}
+	budget := readComputeBudget(&trx.Message)
+	fees, err := w.conn.cli.GetRecentPrioritizationFees(ctx, writableAccounts(&trx.Message))
+	if err != nil {
+		w.log.Warn("Failed to get recent prioritization fees", "err", err)
+	} else if price := feePercentile(fees, cfg.Percentile); price > budget.price {
+		budget.price = min(price, max(cfg.Cap, budget.price))
+	}
+
+	switch {
+	case cfg.UnitLimit != 0:
+		budget.limit = cfg.UnitLimit
+	case budget.limit == 0:
+		budget.limit = w.simulatedUnits(ctx, raw, budget, signer)
+	}
+	if _, err = budgetedTx(raw, budget, signer); err != nil {
+		w.log.Info("Leaving the compute budget of the transaction as it is", "reason", err)
+		return nil
+	}
+	return &budget
+}
+
+// simulatedUnits returns the compute units raw takes with a fifth on top,
+// 0 when it can't be simulated and keeps the default limit.
+func (w *Writer) simulatedUnits(ctx context.Context, raw []byte, budget computeBudget, signer solana.PublicKey) uint32 {
+	budget.limit = maxComputeUnitLimit
+	trx, err := budgetedTx(raw, budget, signer)
+	if err != nil {
+		return 0
+	}
+	resp, err := w.conn.cli.SimulateTransactionWithOpts(ctx, trx, &rpc.SimulateTransactionOpts{
+		Commitment:             rpc.CommitmentConfirmed,
+		ReplaceRecentBlockhash: true,
+	})
+	if err != nil || resp.Value == nil || resp.Value.Err != nil || resp.Value.UnitsConsumed == nil {
+		w.log.Warn("Failed to simulate the compute units of the transaction", "err", simulationErr(resp, err))
+		return 0
+	}
+	return uint32(min(*resp.Value.UnitsConsumed*6/5, maxComputeUnitLimit))
+}
+
+func simulationErr(resp *rpc.SimulateTransactionResponse, err error) error {
+	switch {
+	case err != nil:
+		return err
+	case resp.Value == nil:
+		return errors.New("no simulation result")
+	case resp.Value.Err != nil:
+		return fmt.Errorf("%v, logs %v", resp.Value.Err, resp.Value.Logs)
+	}
+	return errors.New("no compute units consumed")
+}
+
+// escalate is the price of the rebroadcast of a transaction priced price,
+// within the cap.
+func (w *Writer) escalate(price uint64) uint64 {
+	next := max(price*3/2, price+priorityFeeStep)
+	return max(min(next, w.cfg.PriorityFee.Cap), price)
+}
@@ -0,0 +1,214 @@
+package sol
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+var (
+	testRouter = solana.NewWallet().PrivateKey
+	testTable  = solana.PublicKey{0xcc}
+)
+
+// butterTx is a transaction as Butter builds it for the router: one mos
+// instruction on an account of a lookup table, unsigned.
+func butterTx(t *testing.T, signers ...solana.PublicKey) []byte {
+	t.Helper()
+	keys := append(solana.PublicKeySlice{testRouter.PublicKey()}, signers...)
+	msg := solana.Message{
+		Header:      solana.MessageHeader{NumRequiredSignatures: uint8(len(keys)), NumReadonlyUnsignedAccounts: 1},
+		AccountKeys: append(keys, testPayer, testProgram),
+		Instructions: []solana.CompiledInstruction{
+			// the account after the static ones is the writable of the lookup
+			{ProgramIDIndex: uint16(len(keys) + 1), Accounts: []uint16{0, uint16(len(keys)), uint16(len(keys) + 2)}, Data: solana.Base58{7, 7}},
+		},
+	}
+	msg.AddAddressTableLookup(solana.MessageAddressTableLookup{AccountKey: testTable, WritableIndexes: []uint8{4}, ReadonlyIndexes: []uint8{}})
+	tx := solana.Transaction{Signatures: make([]solana.Signature, len(keys)), Message: msg}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestBudgetedTx(t *testing.T) {
+	raw := butterTx(t)
+	trx, err := budgetedTx(raw, computeBudget{limit: 60_000, price: 5_000}, testRouter.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := trx.Message
+	if len(msg.AccountKeys) != 4 || !msg.AccountKeys[3].Equals(solana.ComputeBudget) || msg.Header.NumReadonlyUnsignedAccounts != 2 {
+		t.Fatalf("accounts %v, header %+v", msg.AccountKeys, msg.Header)
+	}
+	if got := readComputeBudget(&msg); got != (computeBudget{limit: 60_000, price: 5_000}) || len(msg.Instructions) != 3 {
+		t.Fatalf("budget %+v of %d instructions", got, len(msg.Instructions))
+	}
+	// the lookup account moved after the compute budget program
+	if mos := msg.Instructions[2]; mos.ProgramIDIndex != 2 || mos.Accounts[2] != 4 || !msg.IsVersioned() {
+		t.Fatalf("mos instruction %+v", mos)
+	}
+	if w := writableAccounts(&msg); len(w) != 2 || !w[0].Equals(testRouter.PublicKey()) || !w[1].Equals(testPayer) {
+		t.Fatalf("writable %v", w)
+	}
+
+	// budgeted again the compute budget instructions are replaced
+	bin, _ := trx.MarshalBinary()
+	again, err := budgetedTx(bin, computeBudget{price: 9_000}, testRouter.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readComputeBudget(&again.Message); got != (computeBudget{price: 9_000}) || len(again.Message.Instructions) != 2 || len(again.Message.AccountKeys) != 4 {
+		t.Fatalf("budget %+v, %+v", got, again.Message)
+	}
+
+	if _, err = budgetedTx(butterTx(t, solana.PublicKey{0xdd}), computeBudget{price: 1}, testRouter.PublicKey()); !errors.Is(err, errComputeBudgetNotAllowed) {
+		t.Fatalf("co-signed: %v", err)
+	}
+	bloated, _ := solana.TransactionFromBytes(raw)
+	bloated.Message.Instructions[0].Data = make([]byte, maxTransactionSize-len(raw))
+	bin, _ = bloated.MarshalBinary()
+	if _, err = budgetedTx(bin, computeBudget{price: 1}, testRouter.PublicKey()); !errors.Is(err, errComputeBudgetNotAllowed) {
+		t.Fatalf("too big: %v", err)
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	w := &Writer{cfg: &Config{PriorityFee: PriorityFeeConfig{Cap: 50_000}}}
+	got := []uint64{0}
+	for i := 0; i < 5; i++ {
+		got = append(got, w.escalate(got[len(got)-1]))
+	}
+	if want := []uint64{0, 10_000, 20_000, 30_000, 45_000, 50_000}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if w.escalate(80_000) != 80_000 {
+		t.Fatal("escalated over the cap")
+	}
+	fees := []rpc.PriorizationFeeResult{{PrioritizationFee: 0}, {PrioritizationFee: 400}, {PrioritizationFee: 100}, {PrioritizationFee: 300}}
+	if feePercentile(fees, 75) != 300 || feePercentile(fees, 100) != 400 || feePercentile(fees, 1) != 0 {
+		t.Fatal("percentile")
+	}
+}
+
+// landRpc is a node confirming the landing-th transaction sent to it, none
+// when it is 0, at block height height.
+type landRpc struct {
+	landing int
+
+	mu     gosync.Mutex
+	sent   []*solana.Transaction
+	height uint64
+}
+
+func (n *landRpc) serve(t *testing.T) string {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		var result interface{}
+		switch req.Method {
+		case "getLatestBlockhash":
+			result = map[string]interface{}{"context": map[string]int{"slot": 1},
+				"value": map[string]interface{}{"blockhash": solana.Hash{0xbb}.String(), "lastValidBlockHeight": 100}}
+		case "getRecentPrioritizationFees":
+			result = []map[string]uint64{{"slot": 1, "prioritizationFee": 1_000}, {"slot": 2, "prioritizationFee": 8_000}, {"slot": 3, "prioritizationFee": 2_000}}
+		case "simulateTransaction":
+			result = map[string]interface{}{"context": map[string]int{"slot": 1}, "value": map[string]interface{}{"err": nil, "logs": []string{}, "unitsConsumed": 50_000}}
+		case "sendTransaction":
+			var data string
+			_ = json.Unmarshal(req.Params[0], &data)
+			raw, _ := base64.StdEncoding.DecodeString(data)
+			trx, err := solana.TransactionFromBytes(raw)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n.sent = append(n.sent, trx)
+			result = trx.Signatures[0].String()
+		case "getSignatureStatuses":
+			var sigs []string
+			_ = json.Unmarshal(req.Params[0], &sigs)
+			statuses := make([]interface{}, len(sigs))
+			for i, sig := range sigs {
+				if len(n.sent) >= n.landing && n.landing > 0 && sig == n.sent[n.landing-1].Signatures[0].String() {
+					statuses[i] = map[string]interface{}{"slot": 9, "confirmations": 1, "err": nil, "confirmationStatus": "confirmed"}
+				}
+			}
+			result = map[string]interface{}{"context": map[string]int{"slot": 10}, "value": statuses}
+		case "getBlockHeight":
+			result = n.height
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newTestWriter(url string) *Writer {
+	return &Writer{
+		cfg:                 &Config{Pri: testRouter.String(), PriorityFee: PriorityFeeConfig{Cap: 20_000, Percentile: 75}},
+		log:                 log15.New(),
+		conn:                &Connection{cli: rpc.New(url)},
+		stop:                make(chan int),
+		pollInterval:        5 * time.Millisecond,
+		rebroadcastInterval: 20 * time.Millisecond,
+	}
+}
+
+func TestSendTx(t *testing.T) {
+	node := &landRpc{landing: 3, height: 90}
+	w := newTestWriter(node.serve(t))
+	sig, err := w.sendTx(hex.EncodeToString(butterTx(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if len(node.sent) < 3 || *sig != node.sent[2].Signatures[0] {
+		t.Fatalf("landed %s of %d sent", sig, len(node.sent))
+	}
+	// 8000 at the 75th percentile, then escalated up to the cap
+	for i, price := range []uint64{8_000, 18_000, 20_000} {
+		trx := node.sent[i]
+		budget := readComputeBudget(&trx.Message)
+		if budget.price != price || budget.limit != 60_000 || trx.Message.RecentBlockhash != (solana.Hash{0xbb}) {
+			t.Fatalf("attempt %d budget %+v", i+1, budget)
+		}
+		if err = trx.VerifySignatures(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSendTxExpired(t *testing.T) {
+	node := &landRpc{height: 101}
+	w := newTestWriter(node.serve(t))
+	w.cfg.PriorityFee.Cap = 0
+	if _, err := w.sendTx(hex.EncodeToString(butterTx(t))); err == nil {
+		t.Fatal("expired blockhash not returned")
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if len(node.sent) != 1 || len(node.sent[0].Message.Instructions) != 1 {
+		t.Fatalf("sent %d, %+v", len(node.sent), node.sent[0].Message)
+	}
+}
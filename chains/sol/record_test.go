@@ -0,0 +1,166 @@
+package sol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	gosync "sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/compass/internal/contract"
+)
+
+var (
+	record        = flag.String("record", "", "solana rpc endpoint TestRecordScan records a scan at")
+	recordProgram = flag.String("program", "", "mos program TestRecordScan scans")
+	recordSlot    = flag.Int64("slot", 0, "slot TestRecordScan scans back to")
+)
+
+const (
+	scanRecordingFile = "testdata/recorded_scan.json"
+	recordPageSize    = 25
+)
+
+// scanRecording is a scan of a mos program back to Slot as TestRecordScan
+// saves it: the getSignaturesForAddress and getTransaction requests the
+// scanner made, paging PageSize signatures at a time, with the node's results.
+type scanRecording struct {
+	Program   string     `json:"program"`
+	Slot      int64      `json:"slot"`
+	PageSize  int        `json:"pageSize"`
+	Exchanges []exchange `json:"exchanges"`
+}
+
+// acceptSwapData stands in for the MAP contract validating swap data.
+type acceptSwapData struct{}
+
+func (acceptSwapData) Validate(*contract.SwapDataValidator) (bool, error) { return true, nil }
+
+// checkRelay checks every relayed log against its transaction with checkLog
+// and builds its receipt with genReceipt.
+func checkRelay(t *testing.T, relayed *[]*Log) Relayer {
+	contract.SetDefaultValidator(acceptSwapData{})
+	return func(m *sync, log *Log) (bool, error) {
+		if err := m.checkLog(log); err != nil {
+			return false, err
+		}
+		receipt, pack, err := m.genReceipt(log)
+		if err != nil {
+			return false, err
+		}
+		if *receipt != common.BytesToHash(crypto.Keccak256(pack)) {
+			t.Errorf("receipt %s is not the hash of its pack", receipt)
+		}
+		*relayed = append(*relayed, log)
+		go func() { m.MsgCh <- struct{}{} }()
+		return true, nil
+	}
+}
+
+func TestRecordedScan(t *testing.T) {
+	data, err := os.ReadFile(scanRecordingFile)
+	if err != nil {
+		t.Fatalf("no recorded scan, record one with -record <rpc> -program <mos> -slot <slot>: %v", err)
+	}
+	var rec scanRecording
+	if err = json.Unmarshal(data, &rec); err != nil {
+		t.Fatal(err)
+	}
+	// only the recorded requests are answered
+	node := newSolRpc(t)
+	node.answer(rec.Exchanges)
+	m, bs := newTestSync(t, node.url, "", rec.Slot)
+	m.cfg.McsContract = []string{rec.Program}
+	m.cfg.Ingest = IngestScan
+
+	relayed := make([]*Log, 0)
+	s, err := newScanner(m, checkRelay(t, &relayed), bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.pageSize = rec.PageSize
+	if err = s.scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(relayed) == 0 {
+		t.Fatal("the recorded scan relayed nothing")
+	}
+	for i, log := range relayed {
+		if log.Addr != rec.Program || log.BlockNumber < rec.Slot {
+			t.Fatalf("relayed %s of %s at %d", log.TxHash, log.Addr, log.BlockNumber)
+		}
+		if i > 0 && log.BlockNumber < relayed[i-1].BlockNumber {
+			t.Fatalf("relayed slot %d after %d", log.BlockNumber, relayed[i-1].BlockNumber)
+		}
+	}
+}
+
+// TestRecordScan scans a mos program at a node back to a slot, checking and
+// relaying what it finds like TestRecordedScan does, and saves the requests
+// it made to testdata/recorded_scan.json:
+//
+//	go test ./chains/sol -run TestRecordScan$ -record https://<rpc> -program <mos> -slot <slot>
+func TestRecordScan(t *testing.T) {
+	if *record == "" {
+		t.Skip("no -record")
+	}
+	var (
+		mu  gosync.Mutex
+		rec = scanRecording{Program: *recordProgram, Slot: *recordSlot, PageSize: recordPageSize}
+		got = make(map[string]bool)
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		resp, err := http.Post(*record, "application/json", bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		var req, ret exchange
+		_ = json.Unmarshal(body, &req)
+		if json.Unmarshal(data, &ret) == nil && len(ret.Result) != 0 {
+			mu.Lock()
+			if key := requestKey(req.Method, req.Params); !got[key] {
+				got[key] = true
+				rec.Exchanges = append(rec.Exchanges, exchange{Method: req.Method, Params: req.Params, Result: ret.Result})
+			}
+			mu.Unlock()
+		}
+		_, _ = w.Write(data)
+	}))
+	defer proxy.Close()
+
+	m, bs := newTestSync(t, proxy.URL, "", rec.Slot)
+	m.cfg.McsContract = []string{rec.Program}
+	m.cfg.Ingest = IngestScan
+	relayed := make([]*Log, 0)
+	s, err := newScanner(m, checkRelay(t, &relayed), bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.pageSize = rec.PageSize
+	if err = s.scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(relayed) == 0 {
+		t.Fatalf("no cross out event of %s since slot %d", rec.Program, rec.Slot)
+	}
+
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(scanRecordingFile, append(data, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("recorded %d requests, %d events", len(rec.Exchanges), len(relayed))
+}
@@ -0,0 +1,226 @@
+package sol
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/golang/groupcache/lru"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/pkg/blockstore"
+	"github.com/mapprotocol/compass/pkg/util"
+	"github.com/pkg/errors"
+)
+
+// signaturesPageSize is the most getSignaturesForAddress returns.
+const signaturesPageSize = 1000
+
+// signatureCursor is how far the history of a mos program was scanned:
+// Until is the last transaction handled, the oldest first, so a scan broken
+// off goes on after it.
+type signatureCursor struct {
+	Until string `json:"until,omitempty"`
+}
+
+// scanner relays the mos events it finds paging through the transactions of
+// the mos programs with getSignaturesForAddress, without the filter service.
+// The first scan goes back to startBlock, a slot, or starts at the newest
+// transaction when it is 0.
+type scanner struct {
+	*sync
+	relay    Relayer
+	store    blockstore.Cursorstorer
+	pageSize int
+	cursors  map[string]*signatureCursor // by program
+	seen     *lru.Cache                  // signatures of the relayed transactions
+}
+
+func newScanner(s *sync, relay Relayer, store blockstore.Cursorstorer) (*scanner, error) {
+	ret := &scanner{
+		sync:     s,
+		relay:    relay,
+		store:    store,
+		pageSize: signaturesPageSize,
+		cursors:  make(map[string]*signatureCursor),
+		seen:     lru.New(seenSignatures),
+	}
+	// the file is the subscriber's slot cursor after a switch from subscribe,
+	// only the keys of the programs are read
+	stored := make(map[string]json.RawMessage)
+	if !s.Cfg.FreshStart {
+		if _, err := store.TryLoadCursor(&stored); err != nil {
+			return nil, err
+		}
+	}
+	for _, program := range s.cfg.McsContract {
+		if _, err := solana.PublicKeyFromBase58(program); err != nil {
+			return nil, errors.Wrapf(err, "mos program %s", program)
+		}
+		ret.cursors[program] = &signatureCursor{}
+		if raw, ok := stored[program]; ok {
+			if err := json.Unmarshal(raw, ret.cursors[program]); err != nil {
+				return nil, errors.Wrapf(err, "cursor of %s", program)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func (s *scanner) Sync() error {
+	s.Log.Info("Starting listener...", "ingest", IngestScan)
+	if !s.Cfg.SyncToMap {
+		time.Sleep(time.Hour * 2400)
+		return nil
+	}
+	go func() {
+		for {
+			select {
+			case <-s.Stop:
+				return
+			default:
+			}
+			if err := s.scan(context.Background()); err != nil {
+				s.Log.Error("Sol scan failed", "err", err)
+				util.Alarm(context.Background(), fmt.Sprintf("sol scan failed, chain=%s, err is %s", s.Cfg.Name, err.Error()))
+				time.Sleep(constant.BlockRetryInterval)
+				continue
+			}
+			time.Sleep(constant.MessengerInterval)
+		}
+	}()
+	return nil
+}
+
+// scan scans each mos program up to its newest transaction.
+func (s *scanner) scan(ctx context.Context) error {
+	for _, program := range s.cfg.McsContract {
+		if err := s.scanProgram(ctx, solana.MustPublicKeyFromBase58(program), s.cursors[program]); err != nil {
+			return errors.Wrapf(err, "scan %s", program)
+		}
+	}
+	return nil
+}
+
+// scanProgram relays the transactions of program after c.Until, oldest
+// first, keeping c as it goes.
+func (s *scanner) scanProgram(ctx context.Context, program solana.PublicKey, c *signatureCursor) error {
+	if c.Until == "" && s.Cfg.StartBlock.Sign() == 0 {
+		// nothing relayed yet, start at the newest transaction
+		page, err := s.signatures(ctx, program, solana.Signature{}, solana.Signature{}, 1)
+		if err != nil || len(page) == 0 {
+			return err
+		}
+		c.Until = page[0].Signature.String()
+		return s.store.StoreCursor(s.cursors)
+	}
+
+	until, err := optionalSignature(c.Until)
+	if err != nil {
+		return err
+	}
+	sigs, err := s.signaturesAfter(ctx, program, until, s.Cfg.StartBlock.Uint64())
+	if err != nil {
+		return err
+	}
+	// the rpc lists the newest first
+	for i := len(sigs) - 1; i >= 0; i-- {
+		sig := sigs[i]
+		if _, ok := s.seen.Get(sig.Signature.String()); !ok && sig.Err == nil {
+			if _, _, err = s.relayTx(ctx, s.relay, sig.Signature); err != nil {
+				return err
+			}
+			s.seen.Add(sig.Signature.String(), struct{}{})
+		}
+		c.Until = sig.Signature.String()
+		if err = s.store.StoreCursor(s.cursors); err != nil {
+			return err
+		}
+		if slot := int64(sig.Slot); slot > s.Cfg.StartBlock.Int64() {
+			s.Cfg.StartBlock = big.NewInt(slot)
+			if err = s.BlockStore.StoreBlock(s.Cfg.StartBlock); err != nil {
+				s.Log.Error("Failed to write latest block to blockstore", "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+// signaturesAfter pages back through the finalized transactions of program,
+// newest first, down to until or, when it isn't set, to slot floor.
+func (s *scanner) signaturesAfter(ctx context.Context, program solana.PublicKey, until solana.Signature,
+	floor uint64) ([]*rpc.TransactionSignature, error) {
+	ret := make([]*rpc.TransactionSignature, 0)
+	before := solana.Signature{}
+	for {
+		page, err := s.signatures(ctx, program, before, until, s.pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, sig := range page {
+			if until.IsZero() && sig.Slot < floor {
+				return ret, nil
+			}
+			ret = append(ret, sig)
+		}
+		if len(page) < s.pageSize {
+			return ret, nil
+		}
+		before = page[len(page)-1].Signature
+	}
+}
+
+func optionalSignature(sig string) (solana.Signature, error) {
+	if sig == "" {
+		return solana.Signature{}, nil
+	}
+	ret, err := solana.SignatureFromBase58(sig)
+	if err != nil {
+		return ret, errors.Wrapf(err, "cursor signature %s", sig)
+	}
+	return ret, nil
+}
+
+// signatures returns a page of the finalized transactions of program,
+// newest first, older than before and newer than until when they are set.
+func (m *sync) signatures(ctx context.Context, program solana.PublicKey, before, until solana.Signature, limit int) ([]*rpc.TransactionSignature, error) {
+	opts := &rpc.GetSignaturesForAddressOpts{Limit: &limit, Before: before, Until: until, Commitment: rpc.CommitmentFinalized}
+	ret, err := m.solClient.GetSignaturesForAddressWithOpts(ctx, program, opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get signatures of %s", program)
+	}
+	return ret, nil
+}
+
+// relayTx relays the cross out events of the finalized transaction sig with
+// relay, and returns its slot and how many events it had.
+func (m *sync) relayTx(ctx context.Context, relay Relayer, sig solana.Signature) (uint64, int, error) {
+	txResult, err := m.solClient.GetTransaction(ctx, sig, &rpc.GetTransactionOpts{
+		Commitment:                     rpc.CommitmentFinalized,
+		MaxSupportedTransactionVersion: &rpc.MaxSupportedTransactionVersion0,
+		Encoding:                       solana.EncodingBase64,
+	})
+	if err != nil {
+		return 0, 0, errors.Wrapf(err, "get transaction %s", sig)
+	}
+	logs, err := m.crossOutLogs(sig.String(), txResult)
+	if err != nil {
+		return 0, 0, err
+	}
+	for i, log := range logs {
+		m.Log.Info("Sol find cross out event", "slot", log.BlockNumber, "txHash", log.TxHash, "index", log.EventIndex)
+		sent, err := relay(m, log)
+		if err == nil && !sent {
+			err = errors.New("not sent")
+		}
+		if err != nil {
+			_ = m.WaitUntilMsgHandled(i)
+			return 0, 0, errors.Wrapf(err, "relay event %d of %s", log.EventIndex, sig)
+		}
+	}
+	_ = m.WaitUntilMsgHandled(len(logs))
+	return txResult.Slot, len(logs), nil
+}
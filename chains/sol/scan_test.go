@@ -0,0 +1,115 @@
+package sol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+// scanSignature is a transaction of testdata/synthetic_scan.json, made up
+// rpc answers rather than recorded ones, where the mos program made them at
+// slot 99, 105, 110 (failed), 115, 120 and 125.
+func scanSignature(n byte) string {
+	return solana.Signature{n, 0x5c}.String()
+}
+
+func TestScanner(t *testing.T) {
+	// only the requests of the fixture are answered, getTransaction included
+	node := newSolRpc(t)
+	node.replay(t, "synthetic_scan.json")
+	m, bs := newTestSync(t, node.url, "", 100)
+	m.cfg.Ingest = IngestScan
+
+	relayed := make([]string, 0)
+	failOrder := byte(5)
+	relay := func(m *sync, log *Log) (bool, error) {
+		var data CrossOutData
+		if err := json.Unmarshal([]byte(log.Data), &data); err != nil {
+			return false, err
+		}
+		order := data.OrderId[len(data.OrderId)-1:]
+		if data.OrderId == fmt.Sprintf("0x%064x", failOrder) {
+			failOrder = 0
+			return false, errors.New("map unreachable")
+		}
+		relayed = append(relayed, fmt.Sprintf("%s@%d/%d", order, log.BlockNumber, log.index()))
+		go func() { m.MsgCh <- struct{}{} }()
+		return true, nil
+	}
+	s, err := newScanner(m, relay, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.pageSize = 2
+	// oldest first, nothing newer goes out past a failure
+	if err = s.scan(context.Background()); err == nil {
+		t.Fatal("relay failure not returned")
+	}
+	if fmt.Sprint(relayed) != "[2@105/0]" || s.cursors[testProgram.String()].Until != scanSignature(4) {
+		t.Fatalf("relayed %v up to %+v", relayed, s.cursors[testProgram.String()])
+	}
+
+	// a restart goes on after the last transaction handled
+	s, err = newScanner(m, relay, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.pageSize = 2
+	for i := 0; i < 3; i++ {
+		if err = s.scan(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if fmt.Sprint(relayed) != "[2@105/0 5@120/0 6@125/0 7@125/1]" {
+		t.Fatalf("relayed %v", relayed)
+	}
+	if c := s.cursors[testProgram.String()]; c.Until != scanSignature(6) {
+		t.Fatalf("cursor %+v", c)
+	}
+	stored, err := bs.TryLoadLatestBlock()
+	if err != nil || stored.Int64() != 125 {
+		t.Fatalf("stored %v, %v", stored, err)
+	}
+}
+
+func TestScannerStartsAtNewest(t *testing.T) {
+	node := newSolRpc(t)
+	node.replay(t, "synthetic_scan.json")
+	m, bs := newTestSync(t, node.url, "", 0)
+	relay := func(m *sync, log *Log) (bool, error) {
+		t.Fatalf("relayed %s", log.TxHash)
+		return false, nil
+	}
+	s, err := newScanner(m, relay, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.pageSize = 2
+	for i := 0; i < 2; i++ {
+		if err = s.scan(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded := make(map[string]*signatureCursor)
+	if ok, err := bs.TryLoadCursor(&loaded); !ok || err != nil || loaded[testProgram.String()].Until != scanSignature(6) {
+		t.Fatalf("stored %v %v, %v", loaded, ok, err)
+	}
+}
+
+func TestScannerAfterSubscribe(t *testing.T) {
+	m, bs := newTestSync(t, newSolRpc(t).url, "", 0)
+	if err := bs.StoreCursor(&slotCursor{Slot: 110, Signatures: []string{scanSignature(3)}}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := newScanner(m, nil, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := s.cursors[testProgram.String()]; c.Until != "" {
+		t.Fatalf("cursor %+v", c)
+	}
+}
@@ -0,0 +1,254 @@
+package sol
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gagliardetto/solana-go/rpc/ws"
+	"github.com/golang/groupcache/lru"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/pkg/blockstore"
+	"github.com/mapprotocol/compass/pkg/util"
+	"github.com/pkg/errors"
+)
+
+const (
+	// finalizeTimeout is how long a confirmed transaction is waited for to
+	// be finalized.
+	finalizeTimeout = 2 * time.Minute
+	seenSignatures  = 4096
+)
+
+// slotCursor is the last slot relayed with the transactions relayed at it,
+// which a backfill from the slot leaves out.
+type slotCursor struct {
+	Slot       uint64   `json:"slot"`
+	Signatures []string `json:"signatures,omitempty"`
+}
+
+// subscriber relays the mos events it is notified of by the logs
+// subscriptions of the rpc. When a subscription drops it backfills from the
+// last relayed slot before subscribing again.
+type subscriber struct {
+	*sync
+	relay Relayer
+	store blockstore.Cursorstorer
+	last  slotCursor
+	seen  *lru.Cache // signatures of the relayed transactions
+}
+
+func newSubscriber(s *sync, relay Relayer, store blockstore.Cursorstorer) (*subscriber, error) {
+	ret := &subscriber{sync: s, relay: relay, store: store, seen: lru.New(seenSignatures)}
+	if !s.Cfg.FreshStart {
+		if _, err := store.TryLoadCursor(&ret.last); err != nil {
+			return nil, err
+		}
+	}
+	for _, sig := range ret.last.Signatures {
+		ret.seen.Add(sig, struct{}{})
+	}
+	return ret, nil
+}
+
+func (s *subscriber) Sync() error {
+	s.Log.Info("Starting listener...", "ingest", IngestSubscribe, "ws", s.cfg.WsEndpoint)
+	if !s.Cfg.SyncToMap {
+		time.Sleep(time.Hour * 2400)
+		return nil
+	}
+	go func() {
+		for {
+			err := s.subscribe()
+			select {
+			case <-s.Stop:
+				return
+			default:
+			}
+			s.Log.Error("Sol subscription dropped", "err", err)
+			util.Alarm(context.Background(), fmt.Sprintf("sol subscription dropped, chain=%s, err is %s", s.Cfg.Name, err.Error()))
+			time.Sleep(constant.BlockRetryInterval)
+		}
+	}()
+	return nil
+}
+
+// subscribe subscribes to the logs of the mos programs, backfills what was
+// missed and relays what it is notified of until a subscription drops.
+func (s *subscriber) subscribe() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, err := ws.Connect(ctx, s.cfg.WsEndpoint)
+	if err != nil {
+		return errors.Wrap(err, "connect ws")
+	}
+	defer client.Close()
+
+	notified := make(chan *ws.LogResult, 256)
+	dropped := make(chan error, len(s.cfg.McsContract))
+	for _, program := range s.cfg.McsContract {
+		pk, err := solana.PublicKeyFromBase58(program)
+		if err != nil {
+			return errors.Wrapf(err, "mos program %s", program)
+		}
+		sub, err := client.LogsSubscribeMentions(pk, rpc.CommitmentConfirmed)
+		if err != nil {
+			return errors.Wrapf(err, "subscribe logs of %s", program)
+		}
+		go func() {
+			defer sub.Unsubscribe()
+			for {
+				got, err := sub.Recv(ctx)
+				if err != nil {
+					dropped <- err
+					return
+				}
+				select {
+				case notified <- got:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	// subscribed first, so nothing falls between the backfill and the
+	// notifications, what is in both is relayed once
+	if err = s.backfill(ctx); err != nil {
+		return errors.Wrap(err, "backfill")
+	}
+	for {
+		select {
+		case <-s.Stop:
+			return errors.New("polling terminated")
+		case err = <-dropped:
+			return err
+		case got := <-notified:
+			if got.Value.Err != nil {
+				continue
+			}
+			sig := got.Value.Signature
+			if _, ok := s.seen.Get(sig.String()); ok {
+				continue
+			}
+			if err = s.waitFinalized(client, sig); err != nil {
+				return err
+			}
+			if err = s.relayTx(ctx, sig); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// waitFinalized waits for the confirmed transaction sig to be finalized.
+func (s *subscriber) waitFinalized(client *ws.Client, sig solana.Signature) error {
+	sub, err := client.SignatureSubscribe(sig, rpc.CommitmentFinalized)
+	if err != nil {
+		return errors.Wrapf(err, "subscribe signature %s", sig)
+	}
+	defer sub.Unsubscribe()
+	if _, err = sub.RecvWithTimeout(finalizeTimeout); err != nil {
+		return errors.Wrapf(err, "wait for %s to be finalized", sig)
+	}
+	return nil
+}
+
+// backfill relays the transactions of the mos programs from the last
+// relayed slot on, oldest first, but the ones already relayed at it.
+func (s *subscriber) backfill(ctx context.Context) error {
+	from := s.Cfg.StartBlock.Uint64()
+	if from == 0 {
+		return nil
+	}
+	sigs := make([]*rpc.TransactionSignature, 0)
+	have := make(map[solana.Signature]bool)
+	for _, program := range s.cfg.McsContract {
+		got, err := s.signaturesSince(ctx, solana.MustPublicKeyFromBase58(program), from)
+		if err != nil {
+			return err
+		}
+		for _, sig := range got {
+			if !have[sig.Signature] {
+				have[sig.Signature] = true
+				sigs = append(sigs, sig)
+			}
+		}
+	}
+	// the rpc lists the newest first
+	for i, j := 0, len(sigs)-1; i < j; i, j = i+1, j-1 {
+		sigs[i], sigs[j] = sigs[j], sigs[i]
+	}
+	sort.SliceStable(sigs, func(i, j int) bool { return sigs[i].Slot < sigs[j].Slot })
+	s.Log.Info("Sol backfill", "from", from, "transactions", len(sigs))
+	for _, sig := range sigs {
+		if sig.Err != nil {
+			continue
+		}
+		if _, ok := s.seen.Get(sig.Signature.String()); ok {
+			continue
+		}
+		if err := s.relayTx(ctx, sig.Signature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signaturesSince pages back through the finalized transactions of
+// program down to slot from.
+func (s *subscriber) signaturesSince(ctx context.Context, program solana.PublicKey, from uint64) ([]*rpc.TransactionSignature, error) {
+	ret := make([]*rpc.TransactionSignature, 0)
+	before := solana.Signature{}
+	for {
+		page, err := s.signatures(ctx, program, before, solana.Signature{}, signaturesPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, sig := range page {
+			if sig.Slot < from {
+				return ret, nil
+			}
+			ret = append(ret, sig)
+		}
+		if len(page) < signaturesPageSize {
+			return ret, nil
+		}
+		before = page[len(page)-1].Signature
+	}
+}
+
+// relayTx relays the cross out events of the finalized transaction sig and
+// moves the blockstore to its slot.
+func (s *subscriber) relayTx(ctx context.Context, sig solana.Signature) error {
+	slot, relayed, err := s.sync.relayTx(ctx, s.relay, sig)
+	if err != nil {
+		return err
+	}
+	s.seen.Add(sig.String(), struct{}{})
+	if relayed == 0 {
+		return nil
+	}
+	// the cursor goes first, a block stored without it would relay sig again
+	if slot > s.last.Slot {
+		s.last = slotCursor{Slot: slot}
+	}
+	if slot == s.last.Slot {
+		s.last.Signatures = append(s.last.Signatures, sig.String())
+		if err = s.store.StoreCursor(&s.last); err != nil {
+			s.Log.Error("Failed to write the slot cursor", "slot", slot, "err", err)
+		}
+	}
+	if int64(slot) <= s.Cfg.StartBlock.Int64() {
+		return nil
+	}
+	s.Cfg.StartBlock = new(big.Int).SetUint64(slot)
+	if err = s.BlockStore.StoreBlock(s.Cfg.StartBlock); err != nil {
+		s.Log.Error("Failed to write latest block to blockstore", "err", err)
+	}
+	return nil
+}
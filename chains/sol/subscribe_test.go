@@ -0,0 +1,391 @@
+package sol
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	gosync "sync"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/gorilla/websocket"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/blockstore"
+	"github.com/mr-tron/base58"
+)
+
+var (
+	testProgram = solana.PublicKey{0xaa}
+	testPayer   = solana.PublicKey{0xbb}
+)
+
+// solRpc answers json rpc requests with the recorded result of the same
+// request, or from a table keyed by the method and its first parameter.
+type solRpc struct {
+	url string
+
+	mu       gosync.Mutex
+	recorded map[string]json.RawMessage
+	results  map[string]interface{}
+}
+
+// exchange is a recorded json rpc request and its result.
+type exchange struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+}
+
+// requestKey is the method and the params of a request, independent of how
+// they were formatted.
+func requestKey(method string, params json.RawMessage) string {
+	var v interface{}
+	_ = json.Unmarshal(params, &v)
+	canonical, _ := json.Marshal(v)
+	return method + string(canonical)
+}
+
+func newSolRpc(t *testing.T) *solRpc {
+	t.Helper()
+	s := &solRpc{recorded: make(map[string]json.RawMessage), results: make(map[string]interface{})}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var params []json.RawMessage
+		_ = json.Unmarshal(req.Params, &params)
+		key := req.Method
+		if len(params) > 0 {
+			var first string
+			_ = json.Unmarshal(params[0], &first)
+			key += ":" + first
+		}
+		s.mu.Lock()
+		var result interface{}
+		recorded, ok := s.recorded[requestKey(req.Method, req.Params)]
+		if ok {
+			result = recorded
+		} else {
+			result, ok = s.results[key]
+		}
+		s.mu.Unlock()
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+		if !ok {
+			resp = map[string]interface{}{"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32602, "message": "no fixture for " + key}}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	s.url = srv.URL
+	return s
+}
+
+// replay answers the requests listed in the testdata file name.
+func (s *solRpc) replay(t *testing.T, name string) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exchanges []exchange
+	if err = json.Unmarshal(data, &exchanges); err != nil {
+		t.Fatal(err)
+	}
+	s.answer(exchanges)
+}
+
+// answer answers the requests of exchanges with their results.
+func (s *solRpc) answer(exchanges []exchange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range exchanges {
+		s.recorded[requestKey(e.Method, e.Params)] = e.Result
+	}
+}
+
+func (s *solRpc) set(key string, result interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[key] = result
+}
+
+// txResult is a getTransaction result of a mos call emitting events into
+// the logs and cpiEvents by self cpi.
+func txResult(t *testing.T, sig solana.Signature, slot uint64, logs []string, cpiEvents ...[]byte) map[string]interface{} {
+	t.Helper()
+	tx := solana.Transaction{
+		Signatures: []solana.Signature{sig},
+		Message: solana.Message{
+			Header:      solana.MessageHeader{NumRequiredSignatures: 1, NumReadonlyUnsignedAccounts: 1},
+			AccountKeys: solana.PublicKeySlice{testPayer, testProgram},
+			Instructions: []solana.CompiledInstruction{
+				{ProgramIDIndex: 1, Accounts: []uint16{0}, Data: solana.Base58{1}},
+			},
+		},
+	}
+	bin, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner := make([]map[string]interface{}, 0)
+	for _, ev := range cpiEvents {
+		inner = append(inner, map[string]interface{}{"index": 0, "instructions": []map[string]interface{}{{
+			"programIdIndex": 1, "accounts": []int{0}, "data": base58.Encode(append(append([]byte{}, anchorEventCpiDiscriminator...), ev...)),
+		}}})
+	}
+	return map[string]interface{}{
+		"slot":        slot,
+		"transaction": []string{base64.StdEncoding.EncodeToString(bin), "base64"},
+		"meta": map[string]interface{}{
+			"err": nil, "fee": 5000, "preBalances": []int{}, "postBalances": []int{},
+			"logMessages": logs, "innerInstructions": inner,
+			"loadedAddresses": map[string]interface{}{"writable": []string{}, "readonly": []string{}},
+		},
+	}
+}
+
+// mosLogs are the logs of a mos call emitting events.
+func mosLogs(events ...[]byte) []string {
+	logs := []string{"Program " + testProgram.String() + " invoke [1]"}
+	for _, ev := range events {
+		logs = append(logs, "Program data: "+base64.StdEncoding.EncodeToString(ev))
+	}
+	return append(logs, "Program "+testProgram.String()+" success")
+}
+
+func signatureInfo(sig solana.Signature, slot uint64, failed bool) map[string]interface{} {
+	ret := map[string]interface{}{"signature": sig.String(), "slot": slot, "err": nil, "confirmationStatus": "finalized"}
+	if failed {
+		ret["err"] = map[string]interface{}{"InstructionError": []interface{}{0, "InvalidArgument"}}
+	}
+	return ret
+}
+
+// solWs is a websocket rpc taking logs and signature subscriptions. The
+// signatures are finalized right away.
+type solWs struct {
+	url        string
+	subscribed chan string // mentioned programs
+	notify     chan []byte
+	drop       chan struct{}
+}
+
+func newSolWs(t *testing.T) *solWs {
+	t.Helper()
+	s := &solWs{subscribed: make(chan string, 8), notify: make(chan []byte, 8), drop: make(chan struct{})}
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var mu gosync.Mutex
+		write := func(v interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			_ = conn.WriteJSON(v)
+		}
+		go func() {
+			for {
+				select {
+				case n := <-s.notify:
+					write(json.RawMessage(n))
+				case <-s.drop:
+					_ = conn.Close()
+					return
+				}
+			}
+		}()
+		subID := 0
+		for {
+			var req struct {
+				ID     uint64            `json:"id"`
+				Method string            `json:"method"`
+				Params []json.RawMessage `json:"params"`
+			}
+			if err = conn.ReadJSON(&req); err != nil {
+				return
+			}
+			subID++
+			switch req.Method {
+			case "logsSubscribe":
+				write(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": subID})
+				var filter struct {
+					Mentions []string `json:"mentions"`
+				}
+				_ = json.Unmarshal(req.Params[0], &filter)
+				s.subscribed <- strings.Join(filter.Mentions, ",")
+			case "signatureSubscribe":
+				write(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": subID})
+				write(map[string]interface{}{"jsonrpc": "2.0", "method": "signatureNotification", "params": map[string]interface{}{
+					"subscription": subID, "result": map[string]interface{}{"context": map[string]int{"slot": 1}, "value": map[string]interface{}{"err": nil}},
+				}})
+			default:
+				write(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": true})
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	s.url = "ws" + strings.TrimPrefix(srv.URL, "http")
+	return s
+}
+
+func (s *solWs) logs(sig solana.Signature, slot uint64) {
+	n, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "method": "logsNotification", "params": map[string]interface{}{
+		"subscription": 1, "result": map[string]interface{}{
+			"context": map[string]uint64{"slot": slot},
+			"value":   map[string]interface{}{"signature": sig.String(), "err": nil, "logs": []string{}},
+		},
+	}})
+	s.notify <- n
+}
+
+// newTestSync is a sync of the test mos program relaying from slot start.
+func newTestSync(t *testing.T, rpcUrl, wsUrl string, start int64) (*sync, *blockstore.Blockstore) {
+	t.Helper()
+	cfg := &Config{
+		Config:      chain.Config{Name: "sol", Id: 1360108768460801, MapChainID: 22776, SyncToMap: true, StartBlock: big.NewInt(start)},
+		McsContract: []string{testProgram.String()},
+		Ingest:      IngestSubscribe,
+		WsEndpoint:  wsUrl,
+	}
+	bs, err := blockstore.NewBlockstore(t.TempDir(), cfg.Id, "relayer", mapprotocol.RoleOfMessenger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := chain.NewCommonSync(nil, &cfg.Config, log15.New(), make(chan int), nil, bs)
+	return newSync(cs, nil, nil, cfg, rpc.New(rpcUrl)), bs
+}
+
+// recordRelay keeps the relayed logs and handles them right away.
+func recordRelay() (Relayer, chan *Log) {
+	relayed := make(chan *Log, 16)
+	return func(m *sync, log *Log) (bool, error) {
+		relayed <- log
+		go func() { m.MsgCh <- struct{}{} }()
+		return true, nil
+	}, relayed
+}
+
+func nextRelayed(t *testing.T, relayed chan *Log) *Log {
+	t.Helper()
+	select {
+	case log := <-relayed:
+		return log
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing relayed")
+	}
+	return nil
+}
+
+func TestSubscriber(t *testing.T) {
+	node, ws := newSolRpc(t), newSolWs(t)
+	old, failed, missed, notified := solana.Signature{1}, solana.Signature{2}, solana.Signature{3}, solana.Signature{4}
+	node.set("getSignaturesForAddress:"+testProgram.String(), []interface{}{
+		signatureInfo(failed, 106, true), signatureInfo(missed, 105, false), signatureInfo(old, 99, false),
+	})
+	node.set("getTransaction:"+missed.String(), txResult(t, missed, 105, mosLogs(borshCrossOutEvent(testCrossOutEvent(1)))))
+	// an event of another program and a cross finish are left out
+	node.set("getTransaction:"+notified.String(), txResult(t, notified, 110, append(mosLogs(), "Program data: "+
+		base64.StdEncoding.EncodeToString(borshCrossOutEvent(testCrossOutEvent(9)))),
+		append(anchorDiscriminator("event:CrossFinishEvent"), make([]byte, 40)...),
+		borshCrossOutEvent(testCrossOutEvent(2)), borshCrossOutEvent(testCrossOutEvent(3))))
+
+	m, bs := newTestSync(t, node.url, ws.url, 100)
+	relay, relayed := recordRelay()
+	s, err := newSubscriber(m, relay, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- s.subscribe() }()
+
+	if got := <-ws.subscribed; got != testProgram.String() {
+		t.Fatalf("subscribed to %s", got)
+	}
+	// backfilled from slot 100
+	log := nextRelayed(t, relayed)
+	var data CrossOutData
+	if err := json.Unmarshal([]byte(log.Data), &data); err != nil {
+		t.Fatal(err)
+	}
+	if log.TxHash != missed.String() || log.BlockNumber != 105 || log.Addr != testProgram.String() || log.index() != 0 ||
+		data.OrderId != fmt.Sprintf("0x%064x", 1) {
+		t.Fatalf("relayed %+v", log)
+	}
+
+	ws.logs(missed, 105)
+	ws.logs(notified, 110)
+	for i := uint(0); i < 2; i++ {
+		log = nextRelayed(t, relayed)
+		_ = json.Unmarshal([]byte(log.Data), &data)
+		if log.TxHash != notified.String() || log.index() != i || data.OrderId != fmt.Sprintf("0x%064x", i+2) {
+			t.Fatalf("relayed %+v", log)
+		}
+	}
+
+	close(ws.drop)
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("no error when dropped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("drop not noticed")
+	}
+	if len(relayed) != 0 {
+		t.Fatalf("relayed %s again", (<-relayed).TxHash)
+	}
+	stored, err := bs.TryLoadLatestBlock()
+	if err != nil || stored.Int64() != 110 {
+		t.Fatalf("stored %v, %v", stored, err)
+	}
+}
+
+func TestSubscriberBackfillSkipsRelayed(t *testing.T) {
+	node := newSolRpc(t)
+	before, relayedAt, pending := solana.Signature{1}, solana.Signature{2}, solana.Signature{3}
+	node.set("getSignaturesForAddress:"+testProgram.String(), []interface{}{
+		signatureInfo(pending, 110, false), signatureInfo(relayedAt, 110, false), signatureInfo(before, 105, false),
+	})
+	node.set("getTransaction:"+pending.String(), txResult(t, pending, 110, mosLogs(borshCrossOutEvent(testCrossOutEvent(2)))))
+
+	// stopped after relaying one of the two transactions of slot 110
+	m, bs := newTestSync(t, node.url, "", 110)
+	if err := bs.StoreCursor(&slotCursor{Slot: 110, Signatures: []string{relayedAt.String()}}); err != nil {
+		t.Fatal(err)
+	}
+	relay, relayed := recordRelay()
+	s, err := newSubscriber(m, relay, bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.backfill(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(relayed); n != 1 {
+		t.Fatalf("relayed %d transactions, want 1", n)
+	}
+	if log := <-relayed; log.TxHash != pending.String() {
+		t.Fatalf("relayed %s", log.TxHash)
+	}
+	var c slotCursor
+	if ok, err := bs.TryLoadCursor(&c); !ok || err != nil || c.Slot != 110 || len(c.Signatures) != 2 {
+		t.Fatalf("cursor %+v, %v", c, err)
+	}
+}
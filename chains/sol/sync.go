@@ -30,7 +30,9 @@ import (
 	"github.com/pkg/errors"
 )
 
-type Handler func(*sync) (int64, error)
+// Handler relays the next page of logs of the filter service and returns how
+// many it relayed.
+type Handler func(*sync) (int, error)
 
 type sync struct {
 	*chain.CommonSync
@@ -56,7 +58,7 @@ func (m *sync) Sync() error {
 		return errors.New("polling terminated")
 	default:
 		for {
-			id, err := m.handler(m)
+			relayed, err := m.handler(m)
 			if err != nil {
 				if errors.Is(err, chain.NotVerifyAble) {
 					time.Sleep(constant.BlockRetryInterval)
@@ -67,19 +69,18 @@ func (m *sync) Sync() error {
 				time.Sleep(constant.BlockRetryInterval)
 				continue
 			}
-			if id == 0 {
-				time.Sleep(constant.MessengerInterval)
-				continue
+			if relayed > 0 {
+				m.State.IncEventsMatched(relayed)
 			}
 
-			m.Cfg.StartBlock = big.NewInt(id)
-			_ = m.WaitUntilMsgHandled(1)
-			err = m.BlockStore.StoreBlock(m.Cfg.StartBlock)
+			err = m.StoreFilterCursor()
 			if err != nil {
-				m.Log.Error("Failed to write latest block to blockstore", "err", err)
+				m.Log.Error("Failed to write the filter cursor", "cursor", m.FilterCursor().ID, "err", err)
 			}
 
-			time.Sleep(constant.MessengerInterval)
+			if !m.FilterBacklog() {
+				time.Sleep(constant.MessengerInterval)
+			}
 		}
 	}
 }
@@ -91,6 +92,22 @@ type Log struct {
 	Topic       string `json:"topic"`
 	Data        string `json:"data"`
 	TxHash      string `json:"txHash"`
+	EventIndex  uint   `json:"-"` // index of the event in its transaction, for logs read from the chain
+}
+
+// filterLogIdBase is the id of the first solana log of the filter service.
+const filterLogIdBase = 4249250
+
+// index is what tells the logs of a slot apart in the proof block number:
+// the filter id for logs of the filter service, which have one, and the
+// event index in the transaction for logs read from the chain. The
+// messenger and the oracle of a chain have to read logs the same way for
+// their block numbers to agree.
+func (l *Log) index() uint {
+	if l.Id == 0 {
+		return l.EventIndex
+	}
+	return uint(l.Id - filterLogIdBase)
 }
 
 type CrossOutData struct {
@@ -135,38 +152,39 @@ type solAnchorEvent struct {
 	AmountOut *uint64
 }
 
-func filter(m *sync) (*Log, error) {
-	topic := chain.BuildRawFilterTopic(m.cfg.SolEvent)
-	back, err := m.ListMosLogs(constant.ProjectOfOther, topic, 1)
+// filter relays the next page of logs of the filter service with relay, in
+// id order, up to the first one it doesn't send. Each is handled before the
+// next is relayed.
+func filter(m *sync, relay Relayer) (int, error) {
+	page, err := m.NextFilterPage(chain.BuildRawFilterTopic(m.cfg.SolEvent), constant.ProjectOfOther)
 	if err != nil {
-		return nil, err
-	}
-	if len(back.List) == 0 {
-		return nil, nil
+		return 0, errors.Wrap(err, "filter failed")
 	}
 
-	var ret = Log{}
-	for _, ele := range back.List {
-		idx := match(ele.ContractAddress, m.cfg.McsContract)
-		if idx == -1 {
-			m.Log.Info("Filter Log Address Not Match", "id", ele.Id, "address", ele.ContractAddress, "txHash", ele.TxHash)
-			m.Cfg.StartBlock = big.NewInt(ele.Id)
+	relayed := 0
+	for _, ele := range page.Logs {
+		if match(ele.ContractAddress, m.cfg.McsContract) == -1 {
+			m.SkipFilter(ele)
 			continue
 		}
 
-		ret = Log{
+		m.Log.Info("Filter find Log", "id", ele.Id, "txHash", ele.TxHash)
+		sent, err := relay(m, &Log{
 			Id:          ele.Id,
 			BlockNumber: int64(ele.BlockNumber),
 			Addr:        ele.ContractAddress,
 			Topic:       ele.Topic,
 			Data:        ele.LogData,
 			TxHash:      ele.TxHash,
+		})
+		if err != nil || !sent {
+			return relayed, err
 		}
-
-		m.Log.Info("Filter find Log", "id", ele.Id, "txHash", ele.TxHash)
+		_ = m.WaitUntilMsgHandled(1)
+		m.ProcessedFilter(ele)
+		relayed++
 	}
-
-	return &ret, nil
+	return relayed, nil
 }
 
 func match(addr string, target []string) int {
@@ -178,24 +196,24 @@ func match(addr string, target []string) int {
 	return -1
 }
 
-func mosHandler(m *sync) (int64, error) {
-	// 通过 filter 过滤
-	log, err := filter(m)
-	if err != nil {
-		return 0, errors.Wrap(err, "filter failed")
-	}
-	if log == nil || log.Id == 0 {
-		return 0, nil
-	}
+// Relayer sends log to MAP, it returns false when log wasn't sent and has to
+// be tried again.
+type Relayer func(m *sync, log *Log) (bool, error)
+
+func mosHandler(m *sync) (int, error) {
+	return filter(m, relayMos)
+}
+
+func relayMos(m *sync, log *Log) (bool, error) {
 	receiptHash, receiptPack, err := m.genReceipt(log)
 	if err != nil {
-		return 0, errors.Wrap(err, "gen receipt failed")
+		return false, errors.Wrap(err, "gen receipt failed")
 	}
 	m.Log.Info("Sol2Evm msger generate", "receiptHash", receiptHash)
-	bn := proof.GenLogBlockNumber(big.NewInt(log.BlockNumber), 1, uint(log.Id-4249250))
+	bn := proof.GenLogBlockNumber(big.NewInt(log.BlockNumber), 1, log.index())
 	proposalInfo, err := chain.GetSigner(bn, *receiptHash, uint64(m.cfg.Id), uint64(m.cfg.MapChainID))
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 	var fixedHash [32]byte
 	for i, v := range receiptHash {
@@ -211,20 +229,20 @@ func mosHandler(m *sync) (int64, error) {
 
 	input, err := mapprotocol.GetAbi.Methods[mapprotocol.MethodOfGetBytes].Inputs.Pack(pd)
 	if err != nil {
-		return 0, errors.Wrap(err, "pack getBytes failed")
+		return false, errors.Wrap(err, "pack getBytes failed")
 	}
 
 	tmpData := CrossOutData{}
 	err = json.Unmarshal([]byte(log.Data), &tmpData)
 	if err != nil {
-		return 0, errors.Wrap(err, "unmarshal resp.Data failed")
+		return false, errors.Wrap(err, "unmarshal resp.Data failed")
 	}
 	orderId := common.HexToHash(tmpData.OrderId)
 	finalInput, err := mapprotocol.PackInput(mapprotocol.Mcs, mapprotocol.MethodOfMessageIn,
 		big.NewInt(0).SetUint64(uint64(m.Cfg.Id)),
 		big.NewInt(int64(0)), orderId, input)
 	if err != nil {
-		return 0, nil
+		return false, nil
 	}
 
 	message := msg.NewSwapWithProof(m.Cfg.Id, m.Cfg.MapChainID, []interface{}{finalInput,
@@ -232,37 +250,32 @@ func mosHandler(m *sync) (int64, error) {
 	err = m.Router.Send(message)
 	if err != nil {
 		m.Log.Error("subscription error: failed to route message", "err", err)
-		return 0, nil
+		return false, nil
 	}
 
-	return log.Id, nil
+	return true, nil
 }
 
-func oracleHandler(m *sync) (int64, error) {
-	// 通过 filter 过滤
-	log, err := filter(m)
-	if err != nil {
-		return 0, errors.Wrap(err, "filter failed")
-	}
-	if log == nil || log.Id == 0 {
-		return 0, nil
-	}
+func oracleHandler(m *sync) (int, error) {
+	return filter(m, relayOracle)
+}
 
-	err = m.checkLog(log)
+func relayOracle(m *sync, log *Log) (bool, error) {
+	err := m.checkLog(log)
 	if err != nil {
-		return 0, err
+		return false, err
 	}
 
 	receiptHash, _, err := m.genReceipt(log)
 	if err != nil {
-		return 0, errors.Wrap(err, "gen receipt failed")
+		return false, errors.Wrap(err, "gen receipt failed")
 	}
 	m.Log.Info("Sol2Evm oracle generate", "receiptHash", receiptHash)
-	bn := proof.GenLogBlockNumber(big.NewInt(log.BlockNumber), 1, uint(log.Id-4249250))
+	bn := proof.GenLogBlockNumber(big.NewInt(log.BlockNumber), 1, log.index())
 
 	ret, err := chain.MulSignInfo(0, uint64(m.Cfg.MapChainID))
 	if err != nil {
-		return 0, errors.Wrap(err, "mul sign failed")
+		return false, errors.Wrap(err, "mul sign failed")
 	}
 
 	version := make([]byte, 0)
@@ -273,17 +286,17 @@ func oracleHandler(m *sync) (int64, error) {
 	input, err := mapprotocol.PackAbi.Methods[mapprotocol.MethodOfSolidityPack].Inputs.Pack(receiptHash,
 		ret.Version, bn, big.NewInt(int64(m.Cfg.Id)))
 	if err != nil {
-		return 0, errors.Wrap(err, "oracle pack input failed")
+		return false, errors.Wrap(err, "oracle pack input failed")
 	}
 
 	message := msg.NewProposal(m.Cfg.Id, m.Cfg.MapChainID, []interface{}{input, receiptHash, bn}, m.MsgCh)
 	err = m.Router.Send(message)
 	if err != nil {
 		m.Log.Error("subscription error: failed to route message", "err", err)
-		return 0, nil
+		return false, nil
 	}
 
-	return log.Id, nil
+	return true, nil
 }
 
 func (m *sync) genReceipt(log *Log) (*common.Hash, []byte, error) {
@@ -456,18 +469,8 @@ func (m *sync) findSolAnchorEvent(txResult *rpc.GetTransactionResult, tx *solana
 }
 
 func findSolAnchorEventFromLogs(logMessages []string, targetOrderId []byte) *solAnchorEvent {
-	const eventPrefix = "Program data: "
-	for _, msg := range logMessages {
-		if !strings.HasPrefix(msg, eventPrefix) {
-			continue
-		}
-		base64Data := strings.TrimPrefix(msg, eventPrefix)
-		data, err := base64.StdEncoding.DecodeString(base64Data)
-		if err != nil {
-			fmt.Println("base64 decode failed", err)
-			continue
-		}
-		ev, err := parseCrossFinishEventData(data)
+	for _, le := range solLogEvents(logMessages) {
+		ev, err := parseCrossFinishEventData(le.data)
 		if err == nil && bytes.Equal(targetOrderId, ev.OrderRecord.OrderId) {
 			return &solAnchorEvent{
 				Name:      "CrossFinishEvent",
@@ -479,9 +482,65 @@ func findSolAnchorEventFromLogs(logMessages []string, targetOrderId []byte) *sol
 	return nil
 }
 
+// solEvent is an anchor event and the program that emitted it.
+type solEvent struct {
+	program string
+	data    []byte // event discriminator and body
+}
+
+// solLogEvents returns the events emitted into the logs, each with the
+// program whose invocation logged it.
+func solLogEvents(logMessages []string) []solEvent {
+	const (
+		eventPrefix   = "Program data: "
+		programPrefix = "Program "
+	)
+	ret := make([]solEvent, 0)
+	invoked := make([]string, 0)
+	for _, msg := range logMessages {
+		if strings.HasPrefix(msg, eventPrefix) {
+			base64Data := strings.TrimPrefix(msg, eventPrefix)
+			data, err := base64.StdEncoding.DecodeString(base64Data)
+			if err != nil {
+				fmt.Println("base64 decode failed", err)
+				continue
+			}
+			ev := solEvent{data: data}
+			if len(invoked) > 0 {
+				ev.program = invoked[len(invoked)-1]
+			}
+			ret = append(ret, ev)
+			continue
+		}
+		// Program <id> invoke [depth], then Program <id> success or failed
+		fields := strings.Fields(strings.TrimPrefix(msg, programPrefix))
+		if !strings.HasPrefix(msg, programPrefix) || len(fields) < 2 {
+			continue
+		}
+		switch {
+		case fields[1] == "invoke":
+			invoked = append(invoked, fields[0])
+		case (fields[1] == "success" || strings.HasPrefix(fields[1], "failed")) && len(invoked) > 0:
+			invoked = invoked[:len(invoked)-1]
+		}
+	}
+	return ret
+}
+
 func (m *sync) findSolAnchorEventFromInnerInstructions(innerInstructions []rpc.InnerInstruction, tx *solana.Transaction, loadedAddresses rpc.LoadedAddresses, logAddr string, targetOrderId []byte) *solAnchorEvent {
+	for _, cpi := range solEventCpiData(innerInstructions, tx, loadedAddresses, m.solEventProgramFilter(logAddr)) {
+		if ev := parseSolAnchorEventCpi(cpi.data, targetOrderId); ev != nil {
+			return ev
+		}
+	}
+	return nil
+}
+
+// solEventCpiData returns the events emitted by the self cpis of the
+// programs in programFilter, of any program when it is empty.
+func solEventCpiData(innerInstructions []rpc.InnerInstruction, tx *solana.Transaction, loadedAddresses rpc.LoadedAddresses, programFilter map[string]bool) []solEvent {
 	accountKeys := solanaLoadedAccountKeys(tx, loadedAddresses)
-	programFilter := m.solEventProgramFilter(logAddr)
+	ret := make([]solEvent, 0)
 	for _, inner := range innerInstructions {
 		for _, inst := range inner.Instructions {
 			if int(inst.ProgramIDIndex) >= len(accountKeys) {
@@ -498,13 +557,60 @@ func (m *sync) findSolAnchorEventFromInnerInstructions(innerInstructions []rpc.I
 			if !bytes.Equal(data[:len(anchorEventCpiDiscriminator)], anchorEventCpiDiscriminator) {
 				continue
 			}
-			ev := parseSolAnchorEventCpi(data[len(anchorEventCpiDiscriminator):], targetOrderId)
-			if ev != nil {
-				return ev
-			}
+			ret = append(ret, solEvent{program: programID, data: data[len(anchorEventCpiDiscriminator):]})
 		}
 	}
-	return nil
+	return ret
+}
+
+// crossOutLogs returns the cross out events the mos programs emitted in the
+// transaction sig, as the filter service would have indexed them.
+func (m *sync) crossOutLogs(sig string, txResult *rpc.GetTransactionResult) ([]*Log, error) {
+	if txResult == nil || txResult.Meta == nil {
+		return nil, fmt.Errorf("missing transaction meta, hash(%s)", sig)
+	}
+	if txResult.Meta.Err != nil {
+		return nil, nil
+	}
+	tx, err := txResult.Transaction.GetTransaction()
+	if err != nil {
+		return nil, err
+	}
+	programs := make(map[string]bool)
+	for _, program := range m.cfg.McsContract {
+		programs[program] = true
+	}
+	events := make([]solEvent, 0)
+	for _, ev := range solLogEvents(txResult.Meta.LogMessages) {
+		if programs[ev.program] {
+			events = append(events, ev)
+		}
+	}
+	events = append(events, solEventCpiData(txResult.Meta.InnerInstructions, tx, txResult.Meta.LoadedAddresses, programs)...)
+
+	ret := make([]*Log, 0)
+	for _, ev := range events {
+		if len(ev.data) < 8 || solAnchorEventNames[string(ev.data[:8])] != "CrossOutEvent" {
+			continue
+		}
+		crossOut, err := parseCrossOutEventData(ev.data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "hash(%s)", sig)
+		}
+		data, err := json.Marshal(crossOut.crossOutData())
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, &Log{
+			BlockNumber: int64(txResult.Slot),
+			Addr:        ev.program,
+			Topic:       "CrossOutEvent",
+			Data:        string(data),
+			TxHash:      sig,
+			EventIndex:  uint(len(ret)),
+		})
+	}
+	return ret, nil
 }
 
 func parseSolAnchorEventCpi(data []byte, targetOrderId []byte) *solAnchorEvent {
@@ -13,7 +13,10 @@ import (
 	"github.com/gagliardetto/solana-go"
 	"github.com/gagliardetto/solana-go/rpc"
 	"github.com/mapprotocol/compass/internal/butter"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/observability"
 	"github.com/mapprotocol/compass/internal/proof"
+	"github.com/mapprotocol/compass/internal/txerr"
 	"github.com/mapprotocol/compass/pkg/msg"
 	"github.com/mr-tron/base58"
 	"github.com/pkg/errors"
@@ -23,26 +26,65 @@ import (
 	"github.com/mapprotocol/compass/pkg/util"
 )
 
+const (
+	// landPollInterval is how often the writer asks whether a sent
+	// transaction landed.
+	landPollInterval = 2 * time.Second
+	// rebroadcastInterval is how long a sent transaction is given to land
+	// before it is broadcast again.
+	rebroadcastInterval = 10 * time.Second
+)
+
 type Writer struct {
-	cfg    *Config
-	log    log15.Logger
-	conn   *Connection
-	stop   <-chan int
-	sysErr chan<- error
+	cfg                 *Config
+	log                 log15.Logger
+	conn                *Connection
+	stop                <-chan int
+	sysErr              chan<- error
+	balance             *chain.BalanceWatcher
+	spend               *chain.SpendMeter
+	landing             *observability.LandingState
+	pollInterval        time.Duration
+	rebroadcastInterval time.Duration
 }
 
 func newWriter(conn *Connection, cfg *Config, log log15.Logger, stop <-chan int, sysErr chan<- error) *Writer {
-	return &Writer{
-		cfg:    cfg,
-		conn:   conn,
-		log:    log,
-		stop:   stop,
-		sysErr: sysErr,
+	w := &Writer{
+		cfg:                 cfg,
+		conn:                conn,
+		log:                 log,
+		stop:                stop,
+		sysErr:              sysErr,
+		landing:             observability.RegisterLanding(cfg.Name),
+		pollInterval:        landPollInterval,
+		rebroadcastInterval: rebroadcastInterval,
+	}
+	account, err := solana.PublicKeyFromBase58(cfg.From)
+	if err != nil {
+		log.Warn("Invalid from address, balance watcher disabled", "from", cfg.From, "err", err)
+		w.spend = chain.NewSpendMeter(&cfg.Config, chain.SolDecimals, nil, log)
+		return w
 	}
+	w.balance = chain.NewBalanceWatcher(&cfg.Config, cfg.From, chain.SolDecimals, func(ctx context.Context) (*big.Int, error) {
+		resp, err := conn.cli.GetBalance(ctx, account, rpc.CommitmentFinalized)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(resp.Value), nil
+	}, log)
+	w.balance.Start(stop)
+	w.spend = chain.NewSpendMeter(&cfg.Config, chain.SolDecimals, w.balance, log)
+	return w
 }
 
 func (w *Writer) ResolveMessage(m msg.Message) bool {
 	w.log.Info("Attempting to resolve message", "type", m.Type, "src", m.Source, "dst", m.Destination)
+	if !w.balance.WaitUntilFunded(w.stop) {
+		return false
+	}
+	if !w.spend.WaitForBudget(m.Type, w.stop) {
+		return false
+	}
 	switch m.Type {
 	case msg.SwapSolProof:
 		return w.exeMcs(m)
@@ -55,6 +97,7 @@ func (w *Writer) ResolveMessage(m msg.Message) bool {
 func (w *Writer) exeMcs(m msg.Message) bool {
 	var (
 		errorCount      int64
+		alarmed         bool
 		receiveOpenDone bool
 		log             = m.Payload[0].(*types.Log)
 		method          = m.Payload[2].(string)
@@ -85,7 +128,7 @@ func (w *Writer) exeMcs(m msg.Message) bool {
 			}
 
 			w.log.Info("Send transaction", "srcHash", log.TxHash, "method", method)
-			mcsTxs, openDone, err := w.sendSolCrossInTxs(resp, receiveOpenDone)
+			mcsTxs, openDone, err := w.sendSolCrossInTxs(m, resp, receiveOpenDone)
 			if openDone {
 				receiveOpenDone = true
 			}
@@ -98,26 +141,22 @@ func (w *Writer) exeMcs(m msg.Message) bool {
 				m.DoneCh <- struct{}{}
 				return true
 			} else {
-				for e := range constant.IgnoreError {
-					if strings.Index(err.Error(), e) != -1 {
-						w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "err", err)
-						m.DoneCh <- struct{}{}
-						return true
-					}
+				if ce := txerr.Classify(err); ce.Category.Skip() {
+					w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "category", ce.Category, "reason", ce.Reason, "err", err)
+					m.DoneCh <- struct{}{}
+					return true
 				}
 				w.log.Warn("Execution failed, will retry", "srcHash", log.TxHash, "err", err)
 			}
-			errorCount++
-			if errorCount >= 10 {
+			if txerr.Alarm(&errorCount, &alarmed, err) {
 				w.mosAlarm(log.TxHash, err)
-				errorCount = 0
 			}
 			time.Sleep(constant.TxRetryInterval)
 		}
 	}
 }
 
-func (w *Writer) sendSolCrossInTxs(resp *butter.SolCrossInResp, receiveOpenDone bool) ([]string, bool, error) {
+func (w *Writer) sendSolCrossInTxs(m msg.Message, resp *butter.SolCrossInResp, receiveOpenDone bool) ([]string, bool, error) {
 	if resp == nil || len(resp.Data) == 0 {
 		return nil, receiveOpenDone, errors.New("solCrossIn response data is empty")
 	}
@@ -131,7 +170,7 @@ func (w *Writer) sendSolCrossInTxs(resp *butter.SolCrossInResp, receiveOpenDone
 		if !ok {
 			return nil, receiveOpenDone, errors.New("receiveOpen was already done, but receiveExecute txParam is missing")
 		}
-		txHash, err := w.sendSolCrossInTx(execute, 0)
+		txHash, err := w.sendSolCrossInTx(m, execute, 0)
 		if err != nil {
 			return nil, receiveOpenDone, err
 		}
@@ -140,7 +179,7 @@ func (w *Writer) sendSolCrossInTxs(resp *butter.SolCrossInResp, receiveOpenDone
 
 	switch len(txParams) {
 	case 1:
-		txHash, err := w.sendSolCrossInTx(txParams[0], 0)
+		txHash, err := w.sendSolCrossInTx(m, txParams[0], 0)
 		if err != nil {
 			return nil, receiveOpenDone, err
 		}
@@ -156,14 +195,14 @@ func (w *Writer) sendSolCrossInTxs(resp *butter.SolCrossInResp, receiveOpenDone
 		}
 
 		txHashes := make([]string, 0, 2)
-		txHash, err := w.sendSolCrossInTx(open, 0)
+		txHash, err := w.sendSolCrossInTx(m, open, 0)
 		if err != nil {
 			return nil, receiveOpenDone, err
 		}
 		receiveOpenDone = true
 		txHashes = append(txHashes, txHash)
 
-		txHash, err = w.sendSolCrossInTx(execute, 1)
+		txHash, err = w.sendSolCrossInTx(m, execute, 1)
 		if err != nil {
 			return txHashes, receiveOpenDone, err
 		}
@@ -174,7 +213,7 @@ func (w *Writer) sendSolCrossInTxs(resp *butter.SolCrossInResp, receiveOpenDone
 		if !ok {
 			return nil, receiveOpenDone, errors.New("receiveOpenExecute txParam is missing")
 		}
-		txHash, err := w.sendSolCrossInTx(openExecute, 0)
+		txHash, err := w.sendSolCrossInTx(m, openExecute, 0)
 		if err != nil {
 			return nil, receiveOpenDone, err
 		}
@@ -209,7 +248,7 @@ func findSolCrossInTxParam(txParams []butter.SolCrossInTxParam, step string) (bu
 	return butter.SolCrossInTxParam{}, false
 }
 
-func (w *Writer) sendSolCrossInTx(txParam butter.SolCrossInTxParam, idx int) (string, error) {
+func (w *Writer) sendSolCrossInTx(m msg.Message, txParam butter.SolCrossInTxParam, idx int) (string, error) {
 	if txParam.Data == "" {
 		return "", fmt.Errorf("solCrossIn txParam[%d] data is empty", idx)
 	}
@@ -219,33 +258,54 @@ func (w *Writer) sendSolCrossInTx(txParam butter.SolCrossInTxParam, idx int) (st
 		return "", err
 	}
 	w.log.Info("Submitted solCrossIn transaction", "index", idx, "step", txParam.Step, "mcsTx", mcsTx)
-	if err = w.txStatus(*mcsTx); err != nil {
+	if err = w.txStatus(m, *mcsTx); err != nil {
 		return "", err
 	}
 	return mcsTx.String(), nil
 }
 
+// sendTx signs the Butter transaction with a fresh blockhash and broadcasts
+// it until it lands, see land.
 func (w *Writer) sendTx(data string) (*solana.Signature, error) {
-	bbs, err := hex.DecodeString(data)
+	raw, err := hex.DecodeString(data)
 	if err != nil {
 		return nil, err
 	}
-	trx, err := solana.TransactionFromBytes(bbs)
+	signPri, err := solana.PrivateKeyFromBase58(w.cfg.Pri)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := w.conn.cli.GetLatestBlockhash(context.Background(), rpc.CommitmentFinalized)
+	ctx := context.Background()
+	resp, err := w.conn.cli.GetLatestBlockhash(ctx, rpc.CommitmentFinalized)
 	if err != nil {
 		return nil, err
 	}
-	trx.Message.RecentBlockhash = resp.Value.Blockhash
-	w.log.Info("Sending transaction", "blockHash", resp.Value.Blockhash)
-	signPri, err := solana.PrivateKeyFromBase58(w.cfg.Pri)
+	w.log.Info("Sending transaction", "blockHash", resp.Value.Blockhash, "lastValidBlockHeight", resp.Value.LastValidBlockHeight)
+
+	budget := w.planComputeBudget(ctx, raw, signPri.PublicKey())
+	build := func(budget *computeBudget) (*solana.Transaction, error) {
+		var trx *solana.Transaction
+		if budget == nil {
+			trx, err = solana.TransactionFromBytes(raw)
+		} else {
+			trx, err = budgetedTx(raw, *budget, signPri.PublicKey())
+		}
+		if err != nil {
+			return nil, err
+		}
+		trx.Message.RecentBlockhash = resp.Value.Blockhash
+		return trx, signTx(trx, signPri)
+	}
+	sig, err := w.land(ctx, build, budget, resp.Value.LastValidBlockHeight)
 	if err != nil {
 		return nil, err
 	}
+	return &sig, nil
+}
+
+func signTx(trx *solana.Transaction, signPri solana.PrivateKey) error {
 	signed := false
-	_, err = trx.PartialSign(func(key solana.PublicKey) *solana.PrivateKey {
+	_, err := trx.PartialSign(func(key solana.PublicKey) *solana.PrivateKey {
 		if key == signPri.PublicKey() {
 			signed = true
 			return &signPri
@@ -253,24 +313,112 @@ func (w *Writer) sendTx(data string) (*solana.Signature, error) {
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if !signed {
-		return nil, fmt.Errorf("router signer %s not required by transaction", signPri.PublicKey())
-	}
-	if err = ensureTransactionFullySigned(trx); err != nil {
-		return nil, err
+		return fmt.Errorf("router signer %s not required by transaction", signPri.PublicKey())
 	}
+	return ensureTransactionFullySigned(trx)
+}
 
-	w.log.Info("Sending will transaction")
-	sig, err := w.conn.cli.SendTransactionWithOpts(context.TODO(), trx, rpc.TransactionOpts{
-		SkipPreflight: false,
-	})
+// attempt is one broadcast of a transaction.
+type attempt struct {
+	trx    *solana.Transaction
+	sentAt time.Time
+}
+
+// land broadcasts the transaction build makes for budget and polls until
+// an attempt is confirmed, returning an error only when every attempt
+// confirmed failed. Every rebroadcastInterval it broadcasts again, with the
+// priority fee escalated while under the cap, and gives up once the chain
+// passes lastValid, the last block height of the blockhash. Budget is nil
+// when the transaction can't be changed, it is then resent as it is.
+func (w *Writer) land(ctx context.Context, build func(*computeBudget) (*solana.Transaction, error), budget *computeBudget, lastValid uint64) (solana.Signature, error) {
+	trx, err := build(budget)
 	if err != nil {
-		return nil, err
+		return solana.Signature{}, err
+	}
+	if budget != nil {
+		w.log.Info("Sending will transaction", "unitLimit", budget.limit, "unitPrice", budget.price)
+	} else {
+		w.log.Info("Sending will transaction")
+	}
+	if _, err = w.conn.cli.SendTransactionWithOpts(ctx, trx, rpc.TransactionOpts{SkipPreflight: false}); err != nil {
+		return solana.Signature{}, err
+	}
+	attempts := []attempt{{trx: trx, sentAt: time.Now()}}
+	lastSent := attempts[0].sentAt
 
+	for {
+		select {
+		case <-w.stop:
+			return solana.Signature{}, errors.New("writer stopped")
+		case <-time.After(w.pollInterval):
+		}
+		sigs := make([]solana.Signature, 0, len(attempts))
+		for _, a := range attempts {
+			sigs = append(sigs, a.trx.Signatures[0])
+		}
+		statuses, err := w.conn.cli.GetSignatureStatuses(ctx, false, sigs...)
+		if err != nil {
+			w.log.Warn("Failed to GetSignatureStatuses", "err", err)
+			continue
+		}
+		// every attempt may land, the ones after the first failing, so a
+		// success is taken over any failure
+		landed := -1
+		for i, status := range statuses.Value {
+			if i >= len(attempts) || status == nil || (status.ConfirmationStatus != rpc.ConfirmationStatusConfirmed &&
+				status.ConfirmationStatus != rpc.ConfirmationStatusFinalized) {
+				continue
+			}
+			if landed == -1 || (status.Err == nil && statuses.Value[landed].Err != nil) {
+				landed = i
+			}
+		}
+		if landed != -1 {
+			status := statuses.Value[landed]
+			latency := time.Since(attempts[landed].sentAt)
+			w.landing.Landed(landed+1, latency.Seconds())
+			w.log.Info("Transaction landed", "hash", sigs[landed], "attempt", landed+1, "latency", latency, "slot", status.Slot)
+			if status.Err != nil {
+				return sigs[landed], fmt.Errorf("transaction %s failed: %v", sigs[landed], status.Err)
+			}
+			return sigs[landed], nil
+		}
+
+		height, err := w.conn.cli.GetBlockHeight(ctx, rpc.CommitmentConfirmed)
+		if err != nil {
+			w.log.Warn("Failed to GetBlockHeight", "err", err)
+			continue
+		}
+		if height > lastValid {
+			w.landing.Expired(len(attempts))
+			return solana.Signature{}, fmt.Errorf("blockhash expired at height %d after %d attempts", lastValid, len(attempts))
+		}
+		if time.Since(lastSent) < w.rebroadcastInterval {
+			continue
+		}
+		if budget != nil && w.escalate(budget.price) > budget.price {
+			next := *budget
+			next.price = w.escalate(budget.price)
+			trx, err = build(&next)
+			if err != nil {
+				return solana.Signature{}, err
+			}
+			budget = &next
+			attempts = append(attempts, attempt{trx: trx, sentAt: time.Now()})
+			w.log.Info("Rebroadcasting with a higher priority fee", "attempt", len(attempts), "price", budget.price, "hash", trx.Signatures[0])
+		} else {
+			w.log.Info("Rebroadcasting", "attempt", len(attempts), "hash", trx.Signatures[0])
+		}
+		lastSent = time.Now()
+		// no preflight, an earlier attempt landing meanwhile would fail it
+		_, err = w.conn.cli.SendTransactionWithOpts(ctx, trx, rpc.TransactionOpts{SkipPreflight: true})
+		if err != nil {
+			w.log.Warn("Failed to rebroadcast", "err", err)
+		}
 	}
-	return &sig, nil
 }
 
 func ensureTransactionFullySigned(trx *solana.Transaction) error {
@@ -290,12 +438,12 @@ func ensureTransactionFullySigned(trx *solana.Transaction) error {
 	return nil
 }
 
-func (w *Writer) txStatus(txHash solana.Signature) error {
+func (w *Writer) txStatus(m msg.Message, txHash solana.Signature) error {
 	var count int64
 	time.Sleep(time.Second * 2)
 	for {
 		version := uint64(0)
-		_, err := w.conn.cli.GetTransaction(context.Background(), txHash, &rpc.GetTransactionOpts{
+		tx, err := w.conn.cli.GetTransaction(context.Background(), txHash, &rpc.GetTransactionOpts{
 			Encoding:                       solana.EncodingBase58,
 			Commitment:                     rpc.CommitmentFinalized,
 			MaxSupportedTransactionVersion: &version,
@@ -330,6 +478,9 @@ func (w *Writer) txStatus(txHash solana.Signature) error {
 		}
 
 		w.log.Info("Tx receipt status is success", "hash", txHash)
+		if tx.Meta != nil {
+			w.spend.Charge(m, new(big.Int).SetUint64(tx.Meta.Fee))
+		}
 		return nil
 	}
 }
@@ -1,39 +1,77 @@
 package sol
 
 import (
-	"reflect"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/gagliardetto/solana-go"
+	"github.com/gagliardetto/solana-go/rpc"
+	"github.com/mapprotocol/compass/internal/observability"
 )
 
-func TestDecodeRelayData(t *testing.T) {
-	type args struct {
-		data string
+// newLandRpc answers sendTransaction and getBlockHeight, and has the first
+// attempt land failed and the second one land fine once both were sent.
+func newLandRpc(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		var result interface{}
+		switch req.Method {
+		case "sendTransaction":
+			result = solana.Signature{}.String()
+		case "getBlockHeight":
+			result = 1
+		case "getSignatureStatuses":
+			var sigs []string
+			_ = json.Unmarshal(req.Params[0], &sigs)
+			value := []interface{}{nil}
+			if len(sigs) == 2 {
+				value = []interface{}{
+					map[string]interface{}{"slot": 10, "confirmationStatus": "confirmed",
+						"err": map[string]interface{}{"InstructionError": []interface{}{0, "InvalidArgument"}}},
+					map[string]interface{}{"slot": 11, "confirmationStatus": "confirmed", "err": nil},
+				}
+			}
+			result = map[string]interface{}{"context": map[string]int{"slot": 11}, "value": value}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestLandPrefersSuccess(t *testing.T) {
+	w := &Writer{
+		cfg:                 &Config{PriorityFee: PriorityFeeConfig{Cap: 1000000}},
+		conn:                &Connection{cli: rpc.New(newLandRpc(t))},
+		log:                 log15.New(),
+		stop:                make(chan int),
+		landing:             observability.RegisterLanding("sol-land-test"),
+		pollInterval:        time.Millisecond,
+		rebroadcastInterval: time.Millisecond,
 	}
-	tests := []struct {
-		name    string
-		args    args
-		want    *MessageData
-		wantErr bool
-	}{
-		{
-			name: "relay",
-			args: args{
-				data: "0000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000005c1003030314220000000000000000000000000000000000000000000000986f0c7872706d6f730cdae5da23b64bfecd421d6487ffeabf6558828d7244764b4d4364596b67746e427759696568385634393936326a735948577369465500000000",
-			},
-		},
+	built := make([]solana.Signature, 0)
+	build := func(*computeBudget) (*solana.Transaction, error) {
+		sig := solana.Signature{byte(len(built) + 1)}
+		built = append(built, sig)
+		return &solana.Transaction{Signatures: []solana.Signature{sig}}, nil
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := DecodeRelayData(tt.args.data)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("DecodeRelayData() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			t.Log("got ------ ", string(got.To))
-			t.Log("got ------ ", got)
-			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("DecodeRelayData() got = %+v, want %+v", got, tt.want)
-			}
-		})
+
+	sig, err := w.land(context.Background(), build, &computeBudget{price: 1000}, 100)
+	if err != nil {
+		t.Fatalf("attempt 1 failed, attempt 2 landed, got %v", err)
+	}
+	if len(built) != 2 || sig != built[1] {
+		t.Fatalf("landed %s of %v", sig, built)
 	}
 }
@@ -7,10 +7,10 @@ import (
 	gosync "sync"
 
 	"github.com/mapprotocol/compass/internal/mapprotocol"
-	"github.com/mapprotocol/compass/pkg/keystore"
 	"github.com/mapprotocol/compass/pkg/msg"
 
 	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
@@ -48,7 +48,14 @@ func (c *Chain) createChain(chainCfg *core.ChainConfig, logger log15.Logger, sys
 		return nil, err
 	}
 
-	conn := NewConnection(config.Endpoint, logger)
+	// only the messenger sends transactions to tron
+	var kp *keystore.Key
+	if role == mapprotocol.RoleOfMessenger {
+		if kp, err = loadKey(config, logger); err != nil {
+			return nil, err
+		}
+	}
+	conn := NewConnection(config.Endpoint, kp, logger)
 	err = conn.Connect()
 	if err != nil {
 		return nil, err
@@ -60,11 +67,6 @@ func (c *Chain) createChain(chainCfg *core.ChainConfig, logger log15.Logger, sys
 		return nil, err
 	}
 
-	pswd := make([]byte, 0)
-	if role == mapprotocol.RoleOfMessenger {
-		pswd = keystore.GetPassword(fmt.Sprintf("Enter password for key %s:", chainCfg.From))
-	}
-
 	var (
 		stop   = make(chan int)
 		listen core.Listener
@@ -108,7 +110,7 @@ func (c *Chain) createChain(chainCfg *core.ChainConfig, logger log15.Logger, sys
 		stop:   stop,
 		listen: listen,
 		cfg:    chainCfg,
-		writer: newWriter(conn, config, logger, stop, sysErr, pswd),
+		writer: newWriter(conn, config, logger, stop, sysErr),
 	}, nil
 }
 
@@ -177,28 +179,24 @@ func (c *Chain) Connect(id, endpoint, mcs, lightNode, oracleNode string) (*ethcl
 func (c *Chain) Proof(client *ethclient.Client, l *types.Log, endpoint string, proofType int64, selfId,
 	toChainID uint64, sign [][]byte) ([]byte, error) {
 	orderId := l.Topics[1]
-	var (
-		bn       = big.NewInt(0).SetUint64(l.BlockNumber)
-		receipts []*types.Receipt
-		key      = strconv.FormatUint(selfId, 10) + "_" + strconv.FormatUint(l.BlockNumber, 10)
-	)
-
-	if v, ok := proof.CacheReceipt.Get(key); ok {
-		receipts = v.([]*types.Receipt)
-	} else {
+	bn := big.NewInt(0).SetUint64(l.BlockNumber)
+	receipts, err := proof.CacheReceipt.Receipts(selfId, l.BlockHash, func() ([]*types.Receipt, error) {
 		txsHash, err := getTxsByBN(client, bn)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
 		}
-		receipts, err = tx.GetReceiptsByTxsHash(client, txsHash)
+		receipts, err := tx.GetBlockReceipts(client, bn, txsHash)
 		if err != nil {
 			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
 		}
-		proof.CacheReceipt.Add(key, receipts)
+		return receipts, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	method := chain.GetMethod(l.Topics[0])
-	proofType, err := chain.PreSendTx(0, selfId, toChainID, bn, orderId.Bytes())
+	proofType, err = chain.PreSendTx(0, selfId, toChainID, bn, orderId.Bytes())
 	if err != nil {
 		return nil, err
 	}
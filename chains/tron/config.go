@@ -8,16 +8,50 @@ import (
 
 	"github.com/mapprotocol/compass/core"
 	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/constant"
 )
 
 type Config struct {
 	chain.Config
-	LightNode, RentNode, FeeKey, FeeType, EnergySupply string
-	EthFrom                                            common.Address
-	McsContract                                        []string
-	Rent                                               bool
+	LightNode, RentNode, FeeKey, EnergySupply string
+	EthFrom                                   common.Address // the account as the key's Ethereum address
+	McsContract                               []string
+	Energy                                    EnergyConfig
 }
 
+// EnergyConfig is where the writer gets energy from when the account has too
+// little for a transaction. Amounts are in sun.
+type EnergyConfig struct {
+	Providers      []string // tried cheapest quote first, in this order on a tie
+	Margin         float64  // asked for on top of the estimate, 0.1 is a tenth more
+	RentDeposit    int64    // sent with rentResource, the most the rent node keeps
+	RentAmount     int64    // amount of rentResource and returnResource
+	RentMinBalance int64    // balance the account needs to rent
+	RentRefund     int64    // refund of returnResource expected before any return
+	FeeeEnergy     int64    // least energy ordered from feee.io
+	FeeeDays       int64    // days the feee.io energy is rented for
+	StakeMax       int64    // most the account freezes for energy
+	StakeCost      float64  // what TRX locked for energy costs, 0.01 is a hundredth of it
+}
+
+const (
+	// EnergyRentNode rents energy with rentResource of the rentNode contract
+	// and returns it once the transaction is sent.
+	EnergyRentNode = "rentNode"
+	// EnergyFeee orders energy from feee.io with the feeKey.
+	EnergyFeee = "feee"
+	// EnergyStake freezes TRX of the account for energy, up to stakeMax.
+	EnergyStake = "stake"
+
+	defaultEnergyMargin   = 0.1
+	defaultRentDeposit    = 300_000_000
+	defaultRentAmount     = 122_205_000_000
+	defaultRentMinBalance = 330_000_000
+	defaultFeeeEnergy     = 1_000_000
+	defaultFeeeDays       = 1
+	defaultStakeCost      = 0.01
+)
+
 func parseCfg(chainCfg *core.ChainConfig) (*Config, error) {
 	cfg, err := chain.ParseConfig(chainCfg)
 	if err != nil {
@@ -47,19 +81,14 @@ func parseCfg(chainCfg *core.ChainConfig) (*Config, error) {
 	if ele, ok := chainCfg.Opts[chain.FeeKey]; ok && ele != "" {
 		ret.FeeKey = ele
 	}
-	if ele, ok := chainCfg.Opts[chain.FeeType]; ok && ele != "" {
-		ret.FeeType = ele
-	}
 	if ele, ok := chainCfg.Opts[chain.EnergySupply]; ok && ele != "" {
 		ret.EnergySupply = ele
 	}
-	if ele, ok := chainCfg.Opts[chain.Rent]; ok && ele != "" {
-		rent, err := strconv.ParseBool(ele)
-		if err != nil {
-			return nil, fmt.Errorf("invalid Rent option")
-		}
-		ret.Rent = rent
+	energy, err := parseEnergy(chainCfg.Opts, &ret)
+	if err != nil {
+		return nil, err
 	}
+	ret.Energy = *energy
 
 	if contract, ok := chainCfg.Opts[chain.TronMcsOpt]; ok && contract != "" {
 		ret.Config.McsContract = make([]common.Address, 0)
@@ -69,3 +98,75 @@ func parseCfg(chainCfg *core.ChainConfig) (*Config, error) {
 	}
 	return &ret, nil
 }
+
+func parseEnergy(opts map[string]string, cfg *Config) (*EnergyConfig, error) {
+	ret := EnergyConfig{
+		Margin:         defaultEnergyMargin,
+		RentDeposit:    defaultRentDeposit,
+		RentAmount:     defaultRentAmount,
+		RentMinBalance: defaultRentMinBalance,
+		FeeeEnergy:     defaultFeeeEnergy,
+		FeeeDays:       defaultFeeeDays,
+		StakeCost:      defaultStakeCost,
+	}
+	if ele, ok := opts[chain.EnergyProvidersOpt]; ok && ele != "" {
+		for _, name := range strings.Split(ele, ",") {
+			ret.Providers = append(ret.Providers, strings.TrimSpace(name))
+		}
+	} else if ele, ok := opts[chain.Rent]; ok && ele != "" {
+		// rent and feeType choose the one provider of older configs
+		rent, err := strconv.ParseBool(ele)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Rent option")
+		}
+		if rent && opts[chain.FeeType] == constant.FeeRentType {
+			ret.Providers = []string{EnergyFeee}
+		} else if rent {
+			ret.Providers = []string{EnergyRentNode}
+		}
+	}
+
+	var err error
+	if ele, ok := opts[chain.EnergyMarginOpt]; ok && ele != "" {
+		ret.Margin, err = strconv.ParseFloat(ele, 64)
+		if err != nil || ret.Margin < 0 {
+			return nil, fmt.Errorf("unable to parse %s", chain.EnergyMarginOpt)
+		}
+	}
+	if ele, ok := opts[chain.StakeCostOpt]; ok && ele != "" {
+		ret.StakeCost, err = strconv.ParseFloat(ele, 64)
+		if err != nil || ret.StakeCost < 0 {
+			return nil, fmt.Errorf("unable to parse %s", chain.StakeCostOpt)
+		}
+	}
+	for opt, v := range map[string]*int64{
+		chain.RentDepositOpt:    &ret.RentDeposit,
+		chain.RentAmountOpt:     &ret.RentAmount,
+		chain.RentMinBalanceOpt: &ret.RentMinBalance,
+		chain.RentRefundOpt:     &ret.RentRefund,
+		chain.FeeeEnergyOpt:     &ret.FeeeEnergy,
+		chain.FeeeDaysOpt:       &ret.FeeeDays,
+		chain.StakeMaxOpt:       &ret.StakeMax,
+	} {
+		if ele, ok := opts[opt]; ok && ele != "" {
+			*v, err = strconv.ParseInt(ele, 10, 64)
+			if err != nil || *v < 0 {
+				return nil, fmt.Errorf("unable to parse %s", opt)
+			}
+		}
+	}
+
+	for _, name := range ret.Providers {
+		switch {
+		case name == EnergyRentNode && cfg.RentNode == "":
+			return nil, fmt.Errorf("energy provider %s needs %s", name, chain.RentNode)
+		case name == EnergyFeee && cfg.FeeKey == "":
+			return nil, fmt.Errorf("energy provider %s needs %s", name, chain.FeeKey)
+		case name == EnergyStake && ret.StakeMax == 0:
+			return nil, fmt.Errorf("energy provider %s needs %s", name, chain.StakeMaxOpt)
+		case name != EnergyRentNode && name != EnergyFeee && name != EnergyStake:
+			return nil, fmt.Errorf("unable to parse %s, unknown provider %s", chain.EnergyProvidersOpt, name)
+		}
+	}
+	return &ret, nil
+}
@@ -18,14 +18,16 @@ import (
 type Connection struct {
 	endpoint                  string
 	cli                       *client.GrpcClient
+	kp                        *keystore.Key
 	log                       log15.Logger
 	stop                      chan int
 	reqTime, cacheBlockNumber int64
 }
 
-func NewConnection(endpoint string, log log15.Logger) *Connection {
+func NewConnection(endpoint string, kp *keystore.Key, log log15.Logger) *Connection {
 	return &Connection{
 		endpoint: endpoint,
+		kp:       kp,
 		log:      log,
 		stop:     make(chan int),
 	}
@@ -43,7 +45,7 @@ func (c *Connection) Connect() error {
 }
 
 func (c *Connection) Keypair() *keystore.Key {
-	return nil
+	return c.kp
 }
 
 func (c *Connection) Client() *ethclient.Client {
@@ -0,0 +1,371 @@
+package tron
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/lbtsm/gotron-sdk/pkg/proto/core"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/pkg/errors"
+)
+
+const sunPerTrx = 1_000_000
+
+// EnergyProvider gets energy for the relayer account when it has too little
+// for a transaction.
+type EnergyProvider interface {
+	Name() string
+	// Quote is what getting energy units costs, in sun, or why the provider
+	// can't supply them now.
+	Quote(energy int64) (int64, error)
+	// Acquire gets energy units for the transaction of m and returns what
+	// it paid, in sun.
+	Acquire(m msg.Message, energy int64) (int64, error)
+}
+
+// energyLender is an EnergyProvider lending the energy, given back once the
+// transaction it was acquired for is sent.
+type energyLender interface {
+	EnergyProvider
+	// Return gives back the energy of the last Acquire and returns what that
+	// changed its cost by, in sun, negative for a refund.
+	Return(m msg.Message) (int64, error)
+}
+
+// energyManager keeps the account supplied with energy from the cheapest of
+// its providers.
+type energyManager struct {
+	providers []EnergyProvider
+	margin    float64
+	free      func() (int64, error) // energy the account has left
+	state     *observability.EnergyState
+	log       log15.Logger
+
+	lent     energyLender // provider of energy not given back yet
+	lentCost int64        // what the lent energy cost, in sun
+	cost     int64        // paid for the energy of the relay in progress, in sun
+}
+
+func newEnergyManager(w *Writer) *energyManager {
+	cfg := w.cfg.Energy
+	ret := &energyManager{
+		margin: cfg.Margin,
+		free: func() (int64, error) {
+			acc, err := w.conn.cli.GetAccountResource(w.cfg.From)
+			if err != nil {
+				return 0, err
+			}
+			return acc.EnergyLimit - acc.EnergyUsed, nil
+		},
+		state: observability.RegisterEnergy(w.cfg.Name),
+		log:   w.log,
+	}
+	for _, name := range cfg.Providers {
+		switch name {
+		case EnergyRentNode:
+			ret.providers = append(ret.providers, &rentNode{w: w, cfg: &w.cfg.Energy, balance: w.accountBalance})
+		case EnergyFeee:
+			ret.providers = append(ret.providers, newFeee(w.cfg.FeeKey, w.cfg.From, cfg.FeeeEnergy, cfg.FeeeDays))
+		case EnergyStake:
+			ret.providers = append(ret.providers, &stake{w: w})
+		}
+	}
+	return ret
+}
+
+type energyQuote struct {
+	provider EnergyProvider
+	cost     int64
+}
+
+// ensure makes sure the account has the energy estimated for a transaction
+// of m, with the margin on top, buying what it lacks from the provider
+// quoting the least. A provider failing falls back to the next cheapest.
+func (e *energyManager) ensure(m msg.Message, estimate int64) error {
+	if len(e.providers) == 0 {
+		return nil
+	}
+	free, err := e.free()
+	if err != nil {
+		return errors.Wrap(err, "get account resource failed")
+	}
+	need := int64(float64(estimate) * (1 + e.margin))
+	if free >= need {
+		e.log.Info("Account has enough energy", "have", free, "need", need)
+		return nil
+	}
+	lack := need - free
+
+	quotes := make([]energyQuote, 0, len(e.providers))
+	reasons := make([]string, 0, len(e.providers))
+	for _, p := range e.providers {
+		cost, err := p.Quote(lack)
+		if err != nil {
+			e.log.Info("Energy provider can't quote", "provider", p.Name(), "energy", lack, "err", err)
+			reasons = append(reasons, fmt.Sprintf("%s: %v", p.Name(), err))
+			continue
+		}
+		quotes = append(quotes, energyQuote{provider: p, cost: cost})
+	}
+	sort.SliceStable(quotes, func(i, j int) bool { return quotes[i].cost < quotes[j].cost })
+
+	for _, q := range quotes {
+		name := q.provider.Name()
+		e.log.Info("Acquire energy", "provider", name, "energy", lack, "quote", q.cost)
+		cost, err := q.provider.Acquire(m, lack)
+		if err != nil {
+			e.log.Warn("Energy provider failed, falling back", "provider", name, "err", err)
+			e.state.Failed(name)
+			reasons = append(reasons, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		e.state.Acquired(name)
+		if lender, ok := q.provider.(energyLender); ok {
+			e.lent, e.lentCost = lender, cost
+		} else {
+			e.spent(name, cost)
+		}
+		e.log.Info("Acquired energy", "provider", name, "energy", lack, "cost", cost)
+		return nil
+	}
+	return fmt.Errorf("no energy provider supplied %d energy, %s", lack, strings.Join(reasons, "; "))
+}
+
+// release gives back the energy lent for the transaction of m once it was
+// sent, and records what that energy cost.
+func (e *energyManager) release(m msg.Message) {
+	if e.lent == nil {
+		return
+	}
+	name := e.lent.Name()
+	delta, err := e.lent.Return(m)
+	if err != nil {
+		e.log.Warn("Return energy failed", "provider", name, "err", err)
+	}
+	e.spent(name, max(e.lentCost+delta, 0))
+	e.lent, e.lentCost = nil, 0
+}
+
+func (e *energyManager) spent(provider string, cost int64) {
+	e.cost += cost
+	e.state.Spent(provider, float64(cost)/sunPerTrx)
+}
+
+// settle records what the energy of the relay of m cost over all its
+// attempts, once it is done with.
+func (e *energyManager) settle(m msg.Message) {
+	if len(e.providers) == 0 {
+		return
+	}
+	e.release(m)
+	e.log.Info("Relay energy cost", "src", m.Source, "dst", m.Destination, "trx", float64(e.cost)/sunPerTrx)
+	e.state.Relayed(float64(e.cost) / sunPerTrx)
+	e.cost = 0
+}
+
+// rentNode rents energy with rentResource of the rent node contract, for a
+// deposit of which it refunds what it didn't keep on returnResource.
+type rentNode struct {
+	w       *Writer
+	cfg     *EnergyConfig
+	balance func() (int64, error) // of the account, in sun
+
+	before   int64 // energy limit of the account before renting
+	refunded int64 // refunded by the returns so far, in sun
+	returns  int64
+}
+
+func (r *rentNode) Name() string {
+	return EnergyRentNode
+}
+
+// Quote is the deposit less the refund expected of returning the energy: the
+// average refund of the returns so far, or rentRefund before the first.
+func (r *rentNode) Quote(int64) (int64, error) {
+	balance, err := r.balance()
+	if err != nil {
+		return 0, err
+	}
+	if balance < r.cfg.RentMinBalance {
+		return 0, fmt.Errorf("account not have enough balance(%d sun), need %d", balance, r.cfg.RentMinBalance)
+	}
+	refund := r.cfg.RentRefund
+	if r.returns > 0 {
+		refund = r.refunded / r.returns
+	}
+	return max(r.cfg.RentDeposit-refund, 0), nil
+}
+
+// returned records the refund of a return, in sun.
+func (r *rentNode) returned(refund int64) {
+	r.refunded += max(refund, 0)
+	r.returns++
+}
+
+func (r *rentNode) Acquire(m msg.Message, _ int64) (int64, error) {
+	w, cfg := r.w, r.w.cfg.Energy
+	acc, err := w.conn.cli.GetAccountResource(w.cfg.From)
+	if err != nil {
+		return 0, err
+	}
+	before, err := w.accountBalance()
+	if err != nil {
+		return 0, err
+	}
+	input, err := mapprotocol.TronAbi.Pack("rentResource", w.cfg.EthFrom, big.NewInt(cfg.RentAmount), big.NewInt(1))
+	if err != nil {
+		return 0, errors.Wrap(err, "pack input failed")
+	}
+	tx, err := w.sendTx(m, w.cfg.RentNode, "rent", input, cfg.RentDeposit, false)
+	if err != nil {
+		return 0, errors.Wrap(err, "sendTx failed")
+	}
+	w.log.Info("Rent energy", "tx", tx)
+	if err = w.txStatus(m, "rent", tx); err != nil {
+		return 0, err
+	}
+	r.before = acc.EnergyLimit
+	after, err := w.accountBalance()
+	if err != nil {
+		return cfg.RentDeposit, nil
+	}
+	return before - after, nil
+}
+
+// Return gives the rented energy back, unless the rental already ended.
+func (r *rentNode) Return(m msg.Message) (int64, error) {
+	w, cfg := r.w, r.w.cfg.Energy
+	time.Sleep(constant.BlockRetryInterval)
+	acc, err := w.conn.cli.GetAccountResource(w.cfg.From)
+	if err != nil {
+		return 0, err
+	}
+	if acc.EnergyLimit <= r.before {
+		w.log.Info("Return energy, the rental already ended", "limit", acc.EnergyLimit)
+		r.returned(0)
+		return 0, nil
+	}
+	before, err := w.accountBalance()
+	if err != nil {
+		return 0, err
+	}
+	input, err := mapprotocol.TronAbi.Pack("returnResource", w.cfg.EthFrom, big.NewInt(cfg.RentAmount), big.NewInt(1))
+	if err != nil {
+		return 0, errors.Wrap(err, "pack input failed")
+	}
+	tx, err := w.sendTx(m, w.cfg.RentNode, "return", input, 0, false)
+	if err != nil {
+		return 0, errors.Wrap(err, "sendTx failed")
+	}
+	if err = w.txStatus(m, "return", tx); err != nil {
+		return 0, err
+	}
+	w.log.Info("Return energy success", "tx", tx)
+	after, err := w.accountBalance()
+	if err != nil {
+		return 0, nil
+	}
+	r.returned(after - before)
+	return before - after, nil
+}
+
+// stake freezes TRX of the account for energy. It pays no more than the fee
+// of the freeze, the frozen TRX stays the account's, up to the stakeMax.
+type stake struct {
+	w   *Writer
+	fee int64 // paid for the last freeze, in sun
+}
+
+func (s *stake) Name() string {
+	return EnergyStake
+}
+
+// Quote is the fee of the last freeze and the stakeCost of the TRX it locks.
+func (s *stake) Quote(energy int64) (int64, error) {
+	amount, err := s.amount(energy)
+	if err != nil {
+		return 0, err
+	}
+	return s.fee + int64(float64(amount)*s.w.cfg.Energy.StakeCost), nil
+}
+
+func (s *stake) Acquire(m msg.Message, energy int64) (int64, error) {
+	w := s.w
+	amount, err := s.amount(energy)
+	if err != nil {
+		return 0, err
+	}
+	before, err := w.accountBalance()
+	if err != nil {
+		return 0, err
+	}
+	tx, err := w.conn.cli.FreezeBalanceV2(w.cfg.From, core.ResourceCode_ENERGY, amount)
+	if err != nil {
+		return 0, errors.Wrap(err, "freeze balance failed")
+	}
+	hash, err := w.signAndSend(tx)
+	if err != nil {
+		return 0, err
+	}
+	w.log.Info("Stake for energy", "tx", hash, "sun", amount)
+	if err = w.txStatus(m, "freeze", hash); err != nil {
+		return 0, err
+	}
+	after, err := w.accountBalance()
+	if err != nil {
+		return 0, nil
+	}
+	s.fee = max(before-after-amount, 0)
+	return s.fee, nil
+}
+
+// amount is the sun to freeze for energy units, when the account has it
+// and it keeps the staked total within stakeMax.
+func (s *stake) amount(energy int64) (int64, error) {
+	w := s.w
+	acc, err := w.conn.cli.GetAccountResource(w.cfg.From)
+	if err != nil {
+		return 0, err
+	}
+	account, err := w.conn.cli.GetAccount(w.cfg.From)
+	if err != nil {
+		return 0, err
+	}
+	var staked int64
+	for _, f := range account.GetFrozenV2() {
+		if f.GetType() == core.ResourceCode_ENERGY {
+			staked += f.GetAmount()
+		}
+	}
+	amount, err := stakeFor(energy, acc.TotalEnergyLimit, acc.TotalEnergyWeight)
+	if err != nil {
+		return 0, err
+	}
+	if staked+amount > w.cfg.Energy.StakeMax {
+		return 0, fmt.Errorf("staking %d sun more than stakeMax(%d), %d staked", amount, w.cfg.Energy.StakeMax, staked)
+	}
+	if account.Balance < amount {
+		return 0, fmt.Errorf("account not have enough balance(%d sun) to stake %d", account.Balance, amount)
+	}
+	return amount, nil
+}
+
+// stakeFor is the sun to freeze for energy units, the network sharing its
+// totalLimit energy among the totalWeight TRX frozen for it. Freezes are
+// whole TRX.
+func stakeFor(energy, totalLimit, totalWeight int64) (int64, error) {
+	if totalLimit <= 0 || totalWeight <= 0 {
+		return 0, errors.New("network energy totals unknown")
+	}
+	trx := new(big.Int).Mul(big.NewInt(energy), big.NewInt(totalWeight))
+	trx.Add(trx, big.NewInt(totalLimit-1))
+	trx.Quo(trx, big.NewInt(totalLimit))
+	return max(trx.Int64(), 1) * sunPerTrx, nil
+}
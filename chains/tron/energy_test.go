@@ -0,0 +1,229 @@
+package tron
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+// fakeProvider quotes quote, or fails to with quoteErr, and fails to acquire
+// with acquireErr.
+type fakeProvider struct {
+	name                 string
+	quote, refund        int64
+	quoteErr, acquireErr error
+
+	acquired []int64
+	returned int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Quote(int64) (int64, error) { return p.quote, p.quoteErr }
+
+func (p *fakeProvider) Acquire(_ msg.Message, energy int64) (int64, error) {
+	if p.acquireErr != nil {
+		return 0, p.acquireErr
+	}
+	p.acquired = append(p.acquired, energy)
+	return p.quote, nil
+}
+
+type fakeLender struct {
+	*fakeProvider
+}
+
+func (l fakeLender) Return(msg.Message) (int64, error) {
+	l.returned++
+	return -l.refund, nil
+}
+
+func newTestEnergy(free int64, providers ...EnergyProvider) *energyManager {
+	return &energyManager{
+		providers: providers,
+		margin:    0.1,
+		free:      func() (int64, error) { return free, nil },
+		log:       log15.New(),
+	}
+}
+
+func TestEnsureEnergy(t *testing.T) {
+	down := &fakeProvider{name: "down", quoteErr: errors.New("no quote")}
+	cheap := &fakeProvider{name: "cheap", quote: 10, acquireErr: errors.New("sold out")}
+	tie := &fakeProvider{name: "tie", quote: 20}
+	next := &fakeProvider{name: "next", quote: 20}
+	dear := &fakeProvider{name: "dear", quote: 90}
+	e := newTestEnergy(30_000, dear, down, next, tie, cheap)
+
+	// 50000 with the margin is 55000, of which 25000 lacks
+	if err := e.ensure(msg.Message{}, 50_000); err != nil {
+		t.Fatal(err)
+	}
+	if len(next.acquired) != 1 || next.acquired[0] != 25_000 || len(tie.acquired) != 0 || len(dear.acquired) != 0 {
+		t.Fatalf("acquired next %v, tie %v, dear %v", next.acquired, tie.acquired, dear.acquired)
+	}
+	e.settle(msg.Message{})
+	if e.cost != 0 {
+		t.Fatalf("cost %d left after settling", e.cost)
+	}
+
+	if err := newTestEnergy(55_000, dear).ensure(msg.Message{}, 50_000); err != nil || len(dear.acquired) != 0 {
+		t.Fatalf("acquired %v with enough energy, %v", dear.acquired, err)
+	}
+	if err := newTestEnergy(0, down, cheap).ensure(msg.Message{}, 1); err == nil {
+		t.Fatal("no provider supplying not returned")
+	}
+}
+
+func TestLentEnergy(t *testing.T) {
+	rent := fakeLender{&fakeProvider{name: "rent", quote: 300, refund: 260}}
+	e := newTestEnergy(0, rent)
+	for i := 0; i < 2; i++ {
+		if err := e.ensure(msg.Message{}, 1); err != nil {
+			t.Fatal(err)
+		}
+		if e.cost != 40*int64(i) {
+			t.Fatalf("cost %d before returning", e.cost)
+		}
+		e.release(msg.Message{})
+	}
+	if rent.returned != 2 || e.cost != 80 {
+		t.Fatalf("returned %d, cost %d", rent.returned, e.cost)
+	}
+	e.release(msg.Message{})
+	if rent.returned != 2 {
+		t.Fatal("returned energy not lent")
+	}
+}
+
+func TestFeee(t *testing.T) {
+	orders := make([]map[string]interface{}, 0)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("key") != "k" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 401, "msg": "bad key"})
+			return
+		}
+		switch r.URL.Path {
+		case "/open/v2/order/price":
+			if r.URL.Query().Get("resource_value") != "65000" || r.URL.Query().Get("rent_duration") != "2" {
+				t.Errorf("price of %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`{"code":0,"data":{"resource_value":65000,"pay_amount":6.5,"price_in_sun":100}}`))
+		case "/open/v2/order/submit":
+			order := make(map[string]interface{})
+			_ = json.NewDecoder(r.Body).Decode(&order)
+			orders = append(orders, order)
+			_, _ = w.Write([]byte(`{"code":0,"data":{"order_no":"1","pay_amount":6.6}}`))
+		}
+	}))
+	defer srv.Close()
+
+	f := newFeee("k", "TAddr", 65_000, 2)
+	f.host = srv.URL
+	if quote, err := f.Quote(1_000); err != nil || quote != 6_500_000 {
+		t.Fatalf("quote %d, %v", quote, err)
+	}
+	if cost, err := f.Acquire(msg.Message{}, 70_000); err != nil || cost != 6_600_000 {
+		t.Fatalf("cost %d, %v", cost, err)
+	}
+	want := []map[string]interface{}{{"resource_type": 1.0, "receive_address": "TAddr", "resource_value": 70_000.0,
+		"rent_duration": 2.0, "rent_time_unit": "d"}}
+	if !reflect.DeepEqual(orders, want) {
+		t.Fatalf("orders %v", orders)
+	}
+	f.key = "wrong"
+	if _, err := f.Quote(1_000); err == nil {
+		t.Fatal("error code not returned")
+	}
+}
+
+// testRent quotes as the rent node does and records what it acquires.
+type testRent struct {
+	*rentNode
+	acquired []int64
+}
+
+func (r *testRent) Acquire(_ msg.Message, energy int64) (int64, error) {
+	r.acquired = append(r.acquired, energy)
+	return r.cfg.RentDeposit, nil
+}
+
+func (r *testRent) Return(msg.Message) (int64, error) { return 0, nil }
+
+func TestRentCheaperThanFeee(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0,"data":{"resource_value":65000,"pay_amount":6.5,"price_in_sun":100}}`))
+	}))
+	defer srv.Close()
+	f := newFeee("k", "TAddr", 65_000, 1)
+	f.host = srv.URL
+	rent := &testRent{rentNode: &rentNode{
+		cfg:     &EnergyConfig{RentDeposit: 300_000_000, RentMinBalance: 330_000_000, RentRefund: 280_000_000},
+		balance: func() (int64, error) { return 400_000_000, nil },
+	}}
+
+	// with rentRefund the rent node quotes 20 TRX, more than the 6.5 of feee
+	if quote, err := rent.Quote(65_000); err != nil || quote != 20_000_000 {
+		t.Fatalf("quote %d, %v", quote, err)
+	}
+	// the returns so far got back all but 5 TRX on average
+	rent.returned(296_000_000)
+	rent.returned(294_000_000)
+	if quote, err := rent.Quote(65_000); err != nil || quote != 5_000_000 {
+		t.Fatalf("quote %d, %v", quote, err)
+	}
+	if err := newTestEnergy(0, f, rent).ensure(msg.Message{}, 50_000); err != nil {
+		t.Fatal(err)
+	}
+	if len(rent.acquired) != 1 || rent.acquired[0] != 55_000 {
+		t.Fatalf("rent acquired %v", rent.acquired)
+	}
+}
+
+func TestStakeFor(t *testing.T) {
+	// 90 billion energy a day shared among 18 billion TRX is 5 a TRX
+	for energy, want := range map[int64]int64{5: 1, 6: 2, 65_000: 13_000, 1: 1} {
+		got, err := stakeFor(energy, 90_000_000_000, 18_000_000_000)
+		if err != nil || got != want*sunPerTrx {
+			t.Fatalf("stake for %d energy %d, %v", energy, got, err)
+		}
+	}
+	if _, err := stakeFor(1, 0, 0); err == nil {
+		t.Fatal("unknown totals not returned")
+	}
+}
+
+func TestParseEnergy(t *testing.T) {
+	cfg := &Config{RentNode: "TRent", FeeKey: "k"}
+	got, err := parseEnergy(map[string]string{chain.Rent: "true", chain.FeeType: "fee.io", chain.FeeeDaysOpt: "3"}, cfg)
+	if err != nil || !reflect.DeepEqual(got.Providers, []string{EnergyFeee}) || got.FeeeDays != 3 || got.RentDeposit != defaultRentDeposit {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+	got, err = parseEnergy(map[string]string{chain.EnergyProvidersOpt: "stake, feee", chain.StakeMaxOpt: "5000000000", chain.Rent: "false",
+		chain.StakeCostOpt: "0.02"}, cfg)
+	if err != nil || !reflect.DeepEqual(got.Providers, []string{EnergyStake, EnergyFeee}) || got.StakeMax != 5_000_000_000 ||
+		got.StakeCost != 0.02 {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+	if _, err = parseEnergy(map[string]string{chain.Rent: "true"}, &Config{FeeKey: "k"}); err == nil {
+		t.Fatal("rent node without rentNode parsed")
+	}
+	for _, opts := range []map[string]string{
+		{chain.EnergyProvidersOpt: "stake"},
+		{chain.EnergyProvidersOpt: "sunswap"},
+		{chain.EnergyMarginOpt: "-1"},
+		{chain.RentDepositOpt: "300trx"},
+		{chain.StakeCostOpt: "-0.1"},
+	} {
+		if _, err = parseEnergy(opts, cfg); err == nil {
+			t.Fatalf("%v parsed", opts)
+		}
+	}
+}
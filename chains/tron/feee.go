@@ -6,81 +6,98 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/mapprotocol/compass/pkg/msg"
 )
 
-func GetOrderPrice(key string, resourceValue, rentDuration int64) (*GetOrderPriceResp, error) {
-	url := fmt.Sprintf("https://feee.io/open/v2/order/price?resource_value=%d&rent_duration=%d&rent_time_unit=d", resourceValue, rentDuration)
-	fmt.Println("GetOrderPrice url is", url)
-	method := "GET"
+const feeeHost = "https://feee.io"
 
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, nil)
+// feee orders energy from feee.io, paid from the balance of the key there.
+type feee struct {
+	host, key, addr string
+	minEnergy, days int64
+	client          *http.Client
+}
 
+func newFeee(key, addr string, minEnergy, days int64) *feee {
+	return &feee{host: feeeHost, key: key, addr: addr, minEnergy: minEnergy, days: days, client: &http.Client{}}
+}
+
+func (f *feee) Name() string {
+	return EnergyFeee
+}
+
+// Quote is the price of an order of energy units, of minEnergy at least.
+func (f *feee) Quote(energy int64) (int64, error) {
+	res, err := f.GetOrderPrice(max(energy, f.minEnergy), f.days)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	req.Header.Add("key", key)
-	req.Header.Add("User-Agent", "Feee.io Client/1.0.0 (https://feee.io)")
+	return int64(res.Data.PayAmount * sunPerTrx), nil
+}
 
-	res, err := client.Do(req)
+func (f *feee) Acquire(_ msg.Message, energy int64) (int64, error) {
+	res, err := f.OrderSubmit(max(energy, f.minEnergy), f.days)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer res.Body.Close()
+	return int64(res.Data.PayAmount * sunPerTrx), nil
+}
 
-	body, err := io.ReadAll(res.Body)
+func (f *feee) GetOrderPrice(resourceValue, rentDuration int64) (*GetOrderPriceResp, error) {
+	url := fmt.Sprintf("%s/open/v2/order/price?resource_value=%d&rent_duration=%d&rent_time_unit=d", f.host, resourceValue, rentDuration)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("GetOrderPrice back", string(body))
 	ret := GetOrderPriceResp{}
-	err = json.Unmarshal(body, &ret)
-	if err != nil {
+	if err = f.do(req, &ret); err != nil {
 		return nil, err
 	}
+	if ret.Code != 0 {
+		return nil, fmt.Errorf("feee order price failed, code %d, %s", ret.Code, ret.Msg)
+	}
 	return &ret, nil
 }
 
-func OrderSubmit(key, addr string, resValue, rentDuration int64) (*OrderResp, error) {
-	url := "https://feee.io/open/v2/order/submit"
-	method := "POST"
-
+func (f *feee) OrderSubmit(resValue, rentDuration int64) (*OrderResp, error) {
 	data := map[string]interface{}{
 		"resource_type":   1,
-		"receive_address": addr,
+		"receive_address": f.addr,
 		"resource_value":  resValue,
 		"rent_duration":   rentDuration,
 		"rent_time_unit":  "d",
 	}
 	by, _ := json.Marshal(data)
-
-	client := &http.Client{}
-	req, err := http.NewRequest(method, url, bytes.NewReader(by))
-
+	req, err := http.NewRequest(http.MethodPost, f.host+"/open/v2/order/submit", bytes.NewReader(by))
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("key", key)
-	req.Header.Add("User-Agent", "Feee.io Client/1.0.0 (https://feee.io)")
 	req.Header.Add("Content-Type", "application/json")
+	ret := OrderResp{}
+	if err = f.do(req, &ret); err != nil {
+		return nil, err
+	}
+	if ret.Code != 0 {
+		return nil, fmt.Errorf("feee order submit failed, code %d, %s", ret.Code, ret.Msg)
+	}
+	return &ret, nil
+}
 
-	res, err := client.Do(req)
+func (f *feee) do(req *http.Request, ret interface{}) error {
+	req.Header.Add("key", f.key)
+	req.Header.Add("User-Agent", "Feee.io Client/1.0.0 (https://feee.io)")
+	res, err := f.client.Do(req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer res.Body.Close()
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
-	}
-	fmt.Println("OrderSubmit back", string(body))
-	ret := OrderResp{}
-	err = json.Unmarshal(body, &ret)
-	if err != nil {
-		return nil, err
+		return err
 	}
-	return &ret, nil
+	return json.Unmarshal(body, ret)
 }
 
 type GetOrderPriceResp struct {
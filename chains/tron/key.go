@@ -0,0 +1,74 @@
+package tron
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ChainSafe/log15"
+	ethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	tronaddress "github.com/lbtsm/gotron-sdk/pkg/address"
+	tronkeystore "github.com/lbtsm/gotron-sdk/pkg/keystore"
+	"github.com/lbtsm/gotron-sdk/pkg/store"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/pkg/keystore"
+)
+
+// KeyFileOf returns the key file of the Tron address from in the gotron
+// keystore (~/.tronctl), nil when it has none.
+func KeyFileOf(from string) ([]byte, error) {
+	addr, err := tronaddress.Base58ToAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("address not valid: %s", from)
+	}
+	ks := store.FromAddress(from)
+	if ks == nil {
+		return nil, nil
+	}
+	account, err := ks.Find(tronkeystore.Account{Address: addr})
+	if err != nil {
+		return nil, nil
+	}
+	return os.ReadFile(account.URL.Path)
+}
+
+// loadKey loads the key the chain signs with: the key of from in the gotron
+// keystore when it is there, the way tron chains signed before, else the
+// Ethereum keystore file of keystorePath.
+func loadKey(cfg *Config, log log15.Logger) (*ethkeystore.Key, error) {
+	if cfg.From != "" {
+		keyjson, err := KeyFileOf(cfg.From)
+		if err != nil {
+			return nil, err
+		}
+		if keyjson != nil {
+			log.Warn("Signing with the gotron keystore, compass tron convert moves the key to an Ethereum keystore file",
+				"from", cfg.From)
+			pass := keystore.GetPassword(fmt.Sprintf("Enter password for key %s:", cfg.From))
+			kp, err := keystore.KeypairFromTron(keyjson, string(pass))
+			if err != nil {
+				return nil, err
+			}
+			return kp, checkKey(cfg, kp, cfg.From)
+		}
+	}
+	kp, err := keystore.KeypairFromEth(cfg.KeystorePath)
+	if err != nil {
+		return nil, err
+	}
+	return kp, checkKey(cfg, kp, cfg.KeystorePath)
+}
+
+// checkKey checks kp, loaded from source, is the key of from and ethFrom
+// when they are set, and sets them to its addresses.
+func checkKey(cfg *Config, kp *ethkeystore.Key, source string) error {
+	from := keystore.TronAddress(kp)
+	if cfg.From != "" && cfg.From != from {
+		return fmt.Errorf("key %s signs for %s, not for from %s", source, from, cfg.From)
+	}
+	if cfg.EthFrom != (common.Address{}) && cfg.EthFrom != kp.Address {
+		return fmt.Errorf("key %s is %s, not %s %s", source, kp.Address, chain.EthFrom, cfg.EthFrom)
+	}
+	cfg.From, cfg.EthFrom = from, kp.Address
+	return nil
+}
@@ -0,0 +1,46 @@
+package tron
+
+import (
+	"testing"
+
+	ethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/compass/pkg/keystore"
+)
+
+func TestCheckKey(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp := &ethkeystore.Key{Address: crypto.PubkeyToAddress(key.PublicKey), PrivateKey: key}
+	from := keystore.TronAddress(kp)
+
+	for _, tc := range []struct {
+		name    string
+		from    string
+		ethFrom common.Address
+		err     bool
+	}{
+		{name: "nothing set"},
+		{name: "from and ethFrom of the key", from: from, ethFrom: kp.Address},
+		{name: "other from", from: "TJRabPrwbZy45sbavfcjinPJC18kjpRTv8", err: true},
+		{name: "other ethFrom, left from the gotron setup", ethFrom: common.Address{1}, err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{EthFrom: tc.ethFrom}
+			cfg.From = tc.from
+			err := checkKey(cfg, kp, "test")
+			if tc.err {
+				if err == nil {
+					t.Fatal("a key of another account was taken")
+				}
+				return
+			}
+			if err != nil || cfg.From != from || cfg.EthFrom != kp.Address {
+				t.Fatalf("from %s ethFrom %s: %v", cfg.From, cfg.EthFrom, err)
+			}
+		})
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/pkg/util"
 )
 
@@ -24,13 +25,26 @@ func assembleProof(log *types.Log, receipts []*types.Receipt, method string, fId
 	if err != nil {
 		return nil, err
 	}
-	prf, err := proof.Get(types.Receipts(receipts), log.TxIndex)
+	// tron has no deposit receipts, so the encoder needs no source
+	dls, err := ireceipt.Encode(nil, fId, ireceipt.Ethereum, receipts)
+	if err != nil {
+		return nil, err
+	}
+	// tron headers carry no receipts root, the oracle signs the derived one
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	tr = proof.DeriveTire(dls, tr)
+	var prf [][]byte
+	if proofType == constant.ProofTypeOfLogOracle {
+		prf, err = proof.Get(dls, log.TxIndex)
+	} else {
+		prf, err = proof.GetVerified(fId, tr.Hash(), dls, log.TxIndex)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var key, ret []byte
-	key = rlp.AppendUint64(key[:0], uint64(log.TxIndex))
+	var ret []byte
+	key := proof.Key(dls, log.TxIndex)
 	idx := 0
 	for i, ele := range receipts[log.TxIndex].Logs {
 		if ele.Index != log.Index {
@@ -64,8 +78,6 @@ func assembleProof(log *types.Log, receipts []*types.Receipt, method string, fId
 	case constant.ProofTypeOfNewOracle:
 		fallthrough
 	case constant.ProofTypeOfLogOracle:
-		tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
-		tr = proof.DeriveTire(types.Receipts(receipts), tr)
 		signerRet, err := getSigner(log, tr.Hash(), uint64(fId), uint64(toChainId))
 		if err != nil {
 			return nil, err
@@ -0,0 +1,27 @@
+package tron
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lbtsm/gotron-sdk/pkg/proto/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// SignTx signs tx with key the way Tron does, a recoverable secp256k1
+// signature of the sha256 of its raw data, so the key of an Ethereum
+// keystore signs for its Tron address.
+func SignTx(tx *core.Transaction, key *ecdsa.PrivateKey) error {
+	raw, err := proto.Marshal(tx.GetRawData())
+	if err != nil {
+		return err
+	}
+	hash := sha256.Sum256(raw)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return err
+	}
+	tx.Signature = append(tx.Signature, sig)
+	return nil
+}
@@ -0,0 +1,30 @@
+package tron
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lbtsm/gotron-sdk/pkg/proto/core"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSignTx(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := &core.Transaction{RawData: &core.TransactionRaw{RefBlockBytes: []byte{1, 2}, Expiration: 1700000000000, FeeLimit: 30_000_000}}
+	if err = SignTx(tx, key); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := proto.Marshal(tx.RawData)
+	hash := sha256.Sum256(raw)
+	pub, err := crypto.SigToPub(hash[:], tx.Signature[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tx.Signature) != 1 || crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("signed by %s", crypto.PubkeyToAddress(*pub))
+	}
+}
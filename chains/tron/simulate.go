@@ -0,0 +1,133 @@
+package tron
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lbtsm/gotron-sdk/pkg/proto/api"
+	"github.com/lbtsm/gotron-sdk/pkg/proto/core"
+	"github.com/mapprotocol/compass/internal/txerr"
+	"github.com/pkg/errors"
+)
+
+const (
+	// energyPriceTTL is how long the energy price read from the chain
+	// parameters is used before it is read again.
+	energyPriceTTL = 10 * time.Minute
+
+	energyFeeParam   = "getEnergyFee"
+	maxFeeLimitParam = "getMaxFeeLimit"
+)
+
+// simulation is what running a call against the current state says sending
+// it takes.
+type simulation struct {
+	energy   int64 // energy the call takes
+	price    int64 // sun an energy unit burns
+	feeLimit int64 // most sun the transaction may burn
+}
+
+// energyPrice caches the energy price of the chain parameters.
+type energyPrice struct {
+	price       int64 // sun an energy unit burns
+	maxFeeLimit int64 // highest fee limit the chain accepts, 0 when unknown
+	at          time.Time
+}
+
+// simulate runs the call of method, input to addr sending value sun, with
+// triggerconstantcontract, and with estimateenergy on nodes that support it,
+// and prices its fee limit at the current energy price. A call that reverts
+// returns a *txerr.Error with the reason decoded.
+func (w *Writer) simulate(addr, method string, input []byte, value int64) (*simulation, error) {
+	tx, err := w.conn.cli.TriggerConstantContractByEstimate(w.cfg.From, addr, input, value)
+	if err != nil {
+		return nil, errors.Wrap(err, "trigger constant contract failed")
+	}
+	if err = revertOf(tx); err != nil {
+		return nil, txerr.Classify(err)
+	}
+	energy := tx.EnergyUsed
+	estimate, err := w.conn.cli.EstimateEnergy(w.cfg.From, addr, input, value, "", 0)
+	if err != nil {
+		w.log.Debug("Estimate energy unavailable, using the energy of the trigger", "method", method, "err", err)
+	} else {
+		energy = max(energy, estimate.EnergyRequired)
+	}
+	w.energy.state.Estimated(method, energy)
+
+	price, err := w.energyPrice()
+	if err != nil {
+		return nil, err
+	}
+	feeLimit := int64(float64(energy)*(1+w.cfg.Energy.Margin)*w.cfg.GasMultiplier) * price.price
+	if price.maxFeeLimit > 0 {
+		feeLimit = min(feeLimit, price.maxFeeLimit)
+	}
+	return &simulation{energy: energy, price: price.price, feeLimit: feeLimit}, nil
+}
+
+// revertOf is the error of a simulated call that failed, nil when it
+// succeeded. A revert reads "execution reverted" with the reason and the
+// revert data, for txerr to classify.
+func revertOf(tx *api.TransactionExtention) error {
+	result := tx.GetResult()
+	failed := result.GetCode() == api.Return_CONTRACT_EXE_ERROR
+	if ret := tx.GetTransaction().GetRet(); len(ret) > 0 && ret[0].GetRet() == core.Transaction_Result_FAILED {
+		failed = true
+	}
+	if !failed {
+		if result != nil && result.GetCode() != api.Return_SUCCESS {
+			return fmt.Errorf("simulation failed, %s: %s", result.GetCode(), result.GetMessage())
+		}
+		return nil
+	}
+
+	var data []byte
+	if len(tx.GetConstantResult()) > 0 {
+		data = tx.GetConstantResult()[0]
+	}
+	reason, ok := txerr.Decode(data)
+	if !ok {
+		reason = string(result.GetMessage())
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("execution reverted: %s", reason)
+	}
+	return fmt.Errorf("execution reverted: %s (0x%x)", reason, data)
+}
+
+// energyPrice is the sun an energy unit burns and the highest fee limit, read
+// from the chain parameters at most every energyPriceTTL. A failed read falls
+// back to the last price read.
+func (w *Writer) energyPrice() (energyPrice, error) {
+	if w.price.price > 0 && time.Since(w.price.at) < energyPriceTTL {
+		return w.price, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	params, err := w.conn.cli.Client.GetChainParameters(ctx, &api.EmptyMessage{})
+	if err == nil {
+		var got energyPrice
+		for _, p := range params.GetChainParameter() {
+			switch p.GetKey() {
+			case energyFeeParam:
+				got.price = p.GetValue()
+			case maxFeeLimitParam:
+				got.maxFeeLimit = p.GetValue()
+			}
+		}
+		if got.price > 0 {
+			got.at = time.Now()
+			w.price = got
+			w.energy.state.Price(got.price)
+			return got, nil
+		}
+		err = fmt.Errorf("chain parameters without %s", energyFeeParam)
+	}
+	if w.price.price > 0 {
+		w.log.Warn("Read energy price failed, using the last one", "price", w.price.price, "err", err)
+		return w.price, nil
+	}
+	return energyPrice{}, errors.Wrap(err, "get energy price failed")
+}
@@ -0,0 +1,170 @@
+package tron
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/lbtsm/gotron-sdk/pkg/address"
+	"github.com/lbtsm/gotron-sdk/pkg/client"
+	"github.com/lbtsm/gotron-sdk/pkg/proto/api"
+	"github.com/lbtsm/gotron-sdk/pkg/proto/core"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/txerr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeWallet answers the calls a simulation makes with trigger, estimate,
+// failing estimateenergy like nodes that don't enable it when estimate is nil,
+// and the chain parameters params.
+type fakeWallet struct {
+	api.UnimplementedWalletServer
+
+	trigger  *api.TransactionExtention
+	estimate *api.EstimateEnergyMessage
+	params   []*core.ChainParameters_ChainParameter
+
+	paramReads int
+}
+
+func (f *fakeWallet) TriggerConstantContract(context.Context, *core.TriggerSmartContract) (*api.TransactionExtention, error) {
+	return f.trigger, nil
+}
+
+func (f *fakeWallet) EstimateEnergy(context.Context, *core.TriggerSmartContract) (*api.EstimateEnergyMessage, error) {
+	if f.estimate == nil {
+		return nil, status.Error(codes.Unimplemented, "this node does not support estimate energy")
+	}
+	return f.estimate, nil
+}
+
+func (f *fakeWallet) GetChainParameters(context.Context, *api.EmptyMessage) (*core.ChainParameters, error) {
+	f.paramReads++
+	return &core.ChainParameters{ChainParameter: f.params}, nil
+}
+
+func newSimulateWriter(t *testing.T, wallet *fakeWallet) *Writer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	api.RegisterWalletServer(srv, wallet)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	cli := client.NewGrpcClient(lis.Addr().String())
+	if err = cli.Start(grpc.WithInsecure()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(cli.Stop)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		Config: chain.Config{From: address.PubkeyToAddress(key.PublicKey).String(), GasMultiplier: 1},
+		Energy: EnergyConfig{Margin: 0.1},
+	}
+	return &Writer{cfg: cfg, conn: &Connection{cli: cli}, log: log15.New(), energy: newTestEnergy(0)}
+}
+
+func succeeded(energy int64) *api.TransactionExtention {
+	return &api.TransactionExtention{
+		Result:         &api.Return{Result: true},
+		EnergyUsed:     energy,
+		ConstantResult: [][]byte{make([]byte, 32)},
+		Transaction:    &core.Transaction{Ret: []*core.Transaction_Result{{Ret: core.Transaction_Result_SUCESS}}},
+	}
+}
+
+func reverted(data []byte, message string) *api.TransactionExtention {
+	return &api.TransactionExtention{
+		Result:         &api.Return{Code: api.Return_CONTRACT_EXE_ERROR, Message: []byte(message)},
+		ConstantResult: [][]byte{data},
+		Transaction: &core.Transaction{Ret: []*core.Transaction_Result{{Ret: core.Transaction_Result_FAILED,
+			ContractRet: core.Transaction_Result_REVERT}}},
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	wallet := &fakeWallet{
+		trigger:  succeeded(50_000),
+		estimate: &api.EstimateEnergyMessage{Result: &api.Return{Result: true}, EnergyRequired: 60_000},
+		params: []*core.ChainParameters_ChainParameter{
+			{Key: "getMaintenanceTimeInterval", Value: 21_600_000},
+			{Key: energyFeeParam, Value: 420},
+			{Key: maxFeeLimitParam, Value: 30_000_000},
+		},
+	}
+	w := newSimulateWriter(t, wallet)
+	contract := w.cfg.From
+
+	sim, err := w.simulate(contract, "transferIn", []byte{1}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the higher estimate of the two, with the 10% margin, at 420 sun
+	if sim.energy != 60_000 || sim.price != 420 || sim.feeLimit != 66_000*420 {
+		t.Fatalf("got %+v", sim)
+	}
+
+	wallet.estimate = nil
+	wallet.trigger = succeeded(80_000)
+	if sim, err = w.simulate(contract, "transferIn", []byte{1}, 0); err != nil {
+		t.Fatal(err)
+	}
+	// the trigger alone, the fee limit capped at the chain's highest
+	if sim.energy != 80_000 || sim.feeLimit != 30_000_000 {
+		t.Fatalf("got %+v", sim)
+	}
+	if wallet.paramReads != 1 {
+		t.Fatalf("chain parameters read %d times", wallet.paramReads)
+	}
+}
+
+func TestSimulateReverted(t *testing.T) {
+	wallet := &fakeWallet{params: []*core.ChainParameters_ChainParameter{{Key: energyFeeParam, Value: 100}}}
+	w := newSimulateWriter(t, wallet)
+	contract := w.cfg.From
+	revert, err := (abi.Arguments{{Type: mustType(t, "string")}}).Pack("MOS: invalid proof")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revert = append(crypto.Keccak256([]byte("Error(string)"))[:4], revert...)
+
+	for _, tc := range []struct {
+		trigger  *api.TransactionExtention
+		category txerr.Category
+		reason   string
+	}{
+		{reverted(revert, "REVERT opcode executed"), txerr.Reverted, "MOS: invalid proof"},
+		{reverted(nil, "REVERT opcode executed"), txerr.AlreadyProcessed, ""},
+	} {
+		wallet.trigger = tc.trigger
+		_, err = w.simulate(contract, "transferIn", []byte{1}, 0)
+		var ce *txerr.Error
+		if !errors.As(err, &ce) || ce.Category != tc.category || ce.Reason != tc.reason {
+			t.Fatalf("got %v", err)
+		}
+	}
+
+	wallet.trigger = &api.TransactionExtention{Result: &api.Return{Code: api.Return_OTHER_ERROR, Message: []byte("account not exists")}}
+	if _, err = w.simulate(contract, "transferIn", []byte{1}, 0); err == nil || txerr.Of(err) == txerr.Reverted {
+		t.Fatalf("got %v", err)
+	}
+}
+
+func mustType(t *testing.T, name string) abi.Type {
+	typ, err := abi.NewType(name, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return typ
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"strconv"
 	"time"
 
 	"github.com/mapprotocol/compass/internal/mapprotocol"
@@ -20,6 +19,7 @@ import (
 	"github.com/mapprotocol/compass/internal/chain"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/internal/tx"
 	"github.com/mapprotocol/compass/pkg/ethclient"
 	"github.com/mapprotocol/compass/pkg/util"
@@ -147,36 +147,33 @@ func (m *sync) HandleFilterBlock(latestBlock uint64) (int, uint64, error) {
 	return count, latestBlock, nil
 }
 
+// filterMos relays the next page of mos logs of the filter service, in id
+// order, up to the first one not confirmed yet.
 func filterMos(m *sync, latestBlock *big.Int) (int, error) {
-	count := 0
-	topic := chain.BuildFilterTopic(m.Cfg.Events)
-	back, err := m.ListMosLogs(constant.ProjectOfMsger, topic, 1)
+	page, err := m.NextFilterPage(chain.BuildFilterTopic(m.Cfg.Events), constant.ProjectOfMsger)
 	if err != nil {
 		return 0, err
 	}
-	if len(back.List) == 0 {
-		return 0, nil
-	}
 
-	for _, ele := range back.List {
+	count := 0
+	for _, ele := range page.Logs {
 		idx := m.Match(ele.ContractAddress)
 		if idx == -1 {
-			m.Log.Info("Filter Log Address Not Match", "id", ele.Id, "address", ele.ContractAddress)
-			m.Cfg.StartBlock = big.NewInt(ele.Id)
+			m.SkipFilter(ele)
 			continue
 		}
-		if latestBlock.Uint64()-ele.BlockNumber < m.BlockConfirmations.Uint64() {
+		if ele.BlockNumber+m.BlockConfirmations.Uint64() > latestBlock.Uint64() {
 			m.Log.Info("Block not ready, will retry", "currentBlock", ele.BlockNumber, "latest", latestBlock)
-			continue
+			break
 		}
 
-		log := chain.MosRespToEthLog(ele)
-		send, err := log2Msg(m, log, idx)
+		send, err := log2Msg(m, chain.MosRespToEthLog(ele), idx)
 		if err != nil {
+			_ = m.WaitUntilMsgHandled(count)
 			return 0, err
 		}
 		count += send
-		m.Cfg.StartBlock = big.NewInt(ele.Id)
+		m.ProcessedFilter(ele)
 	}
 
 	return count, nil
@@ -218,26 +215,14 @@ func log2Msg(m *sync, l *types.Log, idx int) (int, error) {
 	}
 
 	var (
-		message  msg.Message
-		orderId  = l.Topics[1]
-		receipts []*types.Receipt
-		current  = big.NewInt(0).SetUint64(l.BlockNumber)
-		key      = strconv.FormatUint(uint64(m.Cfg.Id), 10) + "_" + current.String()
+		message msg.Message
+		orderId = l.Topics[1]
+		current = big.NewInt(0).SetUint64(l.BlockNumber)
 	)
 
-	if v, ok := proof.CacheReceipt.Get(key); ok {
-		receipts = v.([]*types.Receipt)
-		m.Log.Info("use cache receipt", "latestBlock ", current, "txHash", l.TxHash)
-	} else {
-		txsHash, err := getTxsByBN(m.Conn.Client(), current)
-		if err != nil {
-			return 0, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-		}
-		receipts, err = tx.GetReceiptsByTxsHash(m.Conn.Client(), txsHash)
-		if err != nil {
-			return 0, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
-		}
-		proof.CacheReceipt.Add(key, receipts)
+	receipts, err := blockReceipts(m, current, l.BlockHash)
+	if err != nil {
+		return 0, err
 	}
 
 	method := m.GetMethod(l.Topics[0])
@@ -274,43 +259,31 @@ func existTopic(target common.Hash, dst []constant.EventSig) bool {
 	return false
 }
 
+// filterOracle proposes the receipts of the next page of logs of the oracle
+// project of the filter service, in id order.
 func filterOracle(m *sync, latestBlock *big.Int) (int, error) {
-	topic := chain.BuildFilterTopic(m.Cfg.Events)
-
-	tmp := int64(0)
-	defer func() {
-		if tmp == 0 {
-			return
-		}
-		if tmp > m.Cfg.StartBlock.Int64() {
-			m.Cfg.StartBlock = big.NewInt(tmp)
-		}
-	}()
-
-	back, err := m.ListMosLogs(constant.ProjectOfOracle, topic, 1)
+	page, err := m.NextFilterPage(chain.BuildFilterTopic(m.Cfg.Events), constant.ProjectOfOracle)
 	if err != nil {
 		return 0, err
 	}
-	if len(back.List) == 0 {
-		return 0, nil
-	}
 
-	for _, ele := range back.List {
+	count := 0
+	for _, ele := range page.Logs {
 		if m.Cfg.OracleNode.Hex() != ele.ContractAddress {
-			m.Log.Info("Filter Log Address Not Match", "id", ele.Id, "address", ele.ContractAddress)
-			tmp = ele.Id
+			m.SkipFilter(ele)
 			continue
 		}
 
-		log := chain.MosRespToEthLog(ele)
-		_, err = log2Oracle(m, log)
+		send, err := log2Oracle(m, chain.MosRespToEthLog(ele))
 		if err != nil {
+			_ = m.WaitUntilMsgHandled(count)
 			return 0, err
 		}
-		tmp = ele.Id
+		count += send
+		m.ProcessedFilter(ele)
 	}
 
-	return 1, nil
+	return count, nil
 }
 
 func oracle(m *sync, latestBlock *big.Int) (int, error) {
@@ -338,16 +311,16 @@ func oracle(m *sync, latestBlock *big.Int) (int, error) {
 
 func log2Oracle(m *sync, l *types.Log) (int, error) {
 	latestBlock := big.NewInt(0).SetUint64(l.BlockNumber)
-	txsHash, err := getTxsByBN(m.Conn.Client(), latestBlock)
+	receipts, err := blockReceipts(m, latestBlock, l.BlockHash)
 	if err != nil {
-		return 0, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		return 0, err
 	}
-	receipts, err := tx.GetReceiptsByTxsHash(m.Conn.Client(), txsHash)
+	dls, err := ireceipt.Encode(nil, m.Cfg.Id, ireceipt.Ethereum, receipts)
 	if err != nil {
-		return 0, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		return 0, err
 	}
 	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
-	tr = proof.DeriveTire(types.Receipts(receipts), tr)
+	tr = proof.DeriveTire(dls, tr)
 	m.Log.Info("Tron Oracle receipt", "blockNumber", latestBlock, "hash", tr.Hash())
 	receiptHash := tr.Hash()
 	ret, err := chain.MulSignInfo(0, uint64(m.Cfg.MapChainID))
@@ -399,3 +372,18 @@ func getSigner(log *types.Log, receiptHash common.Hash, selfId, toChainID uint64
 	}
 	return piRet, nil
 }
+
+// blockReceipts returns the receipts of block number/hash through the shared receipt cache.
+func blockReceipts(m *sync, number *big.Int, hash common.Hash) ([]*types.Receipt, error) {
+	return proof.CacheReceipt.Receipts(uint64(m.Cfg.Id), hash, func() ([]*types.Receipt, error) {
+		txsHash, err := getTxsByBN(m.Conn.Client(), number)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+		}
+		receipts, err := tx.GetBlockReceipts(m.Conn.Client(), number, txsHash)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		}
+		return receipts, nil
+	})
+}
@@ -2,59 +2,69 @@ package tron
 
 import (
 	"context"
-	"encoding/hex"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 
+	"github.com/mapprotocol/compass/internal/chain"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/internal/report"
+	"github.com/mapprotocol/compass/internal/txerr"
 	"github.com/mapprotocol/compass/pkg/msg"
 
 	"github.com/lbtsm/gotron-sdk/pkg/proto/api"
 
-	"github.com/lbtsm/gotron-sdk/pkg/store"
-
 	"github.com/pkg/errors"
 
 	"github.com/lbtsm/gotron-sdk/pkg/proto/core"
 
 	"github.com/ChainSafe/log15"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/lbtsm/gotron-sdk/pkg/client/transaction"
-	"github.com/lbtsm/gotron-sdk/pkg/keystore"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/pkg/util"
 )
 
-var multiple = big.NewInt(420)
-
 type Writer struct {
-	cfg    *Config
-	log    log15.Logger
-	conn   *Connection
-	stop   <-chan int
-	sysErr chan<- error
-	pass   []byte
-	ks     *keystore.KeyStore
-	acc    *keystore.Account
-	isRent bool
+	cfg     *Config
+	log     log15.Logger
+	conn    *Connection
+	stop    <-chan int
+	sysErr  chan<- error
+	balance *chain.BalanceWatcher
+	spend   *chain.SpendMeter
+	energy  *energyManager
+	price   energyPrice
 }
 
-func newWriter(conn *Connection, cfg *Config, log log15.Logger, stop <-chan int, sysErr chan<- error, pass []byte) *Writer {
-	return &Writer{
+func newWriter(conn *Connection, cfg *Config, log log15.Logger, stop <-chan int, sysErr chan<- error) *Writer {
+	w := &Writer{
 		cfg:    cfg,
 		conn:   conn,
 		log:    log,
 		stop:   stop,
 		sysErr: sysErr,
-		pass:   pass,
 	}
+	w.balance = chain.NewBalanceWatcher(&cfg.Config, cfg.From, chain.TronDecimals, func(ctx context.Context) (*big.Int, error) {
+		account, err := conn.cli.GetAccount(cfg.From)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(account.Balance), nil
+	}, log)
+	w.balance.Start(stop)
+	w.spend = chain.NewSpendMeter(&cfg.Config, chain.TronDecimals, w.balance, log)
+	w.energy = newEnergyManager(w)
+	return w
 }
 
 func (w *Writer) ResolveMessage(m msg.Message) bool {
 	w.log.Info("Attempting to resolve message", "type", m.Type, "src", m.Source, "dst", m.Destination)
+	if !w.balance.WaitUntilFunded(w.stop) {
+		return false
+	}
+	if !w.spend.WaitForBudget(m.Type, w.stop) {
+		return false
+	}
 	switch m.Type {
 	case msg.SwapWithMapProof:
 		return w.exeMcs(m)
@@ -65,7 +75,10 @@ func (w *Writer) ResolveMessage(m msg.Message) bool {
 }
 
 func (w *Writer) exeMcs(m msg.Message) bool {
-	var errorCount, checkIdCount int64
+	var (
+		errorCount, checkIdCount int64
+		alarmed                  bool
+	)
 	addr := w.cfg.McsContract[m.Idx]
 	orderId32 := m.Payload[1].(common.Hash)
 	var orderId []byte
@@ -86,6 +99,7 @@ func (w *Writer) exeMcs(m msg.Message) bool {
 		m.DoneCh <- struct{}{}
 		return true
 	}
+	defer w.energy.settle(m)
 
 	for {
 		select {
@@ -98,53 +112,21 @@ func (w *Writer) exeMcs(m msg.Message) bool {
 			}
 			method := m.Payload[4].(string)
 
-			contract, err := w.conn.cli.TriggerConstantContractByEstimate(w.cfg.From, addr, m.Payload[0].([]byte), 0)
-			if err != nil {
-				w.log.Error("Failed to TriggerConstantContract EstimateEnergy", "err", err)
-				time.Sleep(time.Second * 10)
-				continue
-			}
-			for _, v := range contract.ConstantResult {
-				w.log.Info("Contract result", "err", string(v), "v", v)
-				ele := strings.TrimSpace(string(v))
-				internalErr := "0x" + hex.EncodeToString(v)
-				if ele == "" {
-					continue
-				}
-				for e := range constant.IgnoreError {
-					if strings.Contains(internalErr, e) {
-						w.log.Info("Ignore This Error, Continue to the next", "inputHash", inputHash, "err", internalErr)
-						m.DoneCh <- struct{}{}
-						return true
-					}
-				}
-				err = errors.New(internalErr)
-			}
-			if err != nil {
-				w.mosAlarm(inputHash, fmt.Errorf("contract result failed, err is %v", err))
-				time.Sleep(time.Second * 10)
-				continue
-			}
-			w.log.Info("Trigger Contract result detail", "used", contract.EnergyUsed, "method", method)
-
-			err = w.rentEnergy(contract.EnergyUsed, method)
-			if err != nil {
+			w.log.Info("Send transaction", "addr", addr, "srcHash", inputHash, "method", method)
+			mcsTx, err := w.sendTx(m, addr, method, m.Payload[0].([]byte), 0, true)
+			if errors.Is(err, errEnergy) {
 				w.log.Info("Check energy failed", "srcHash", inputHash, "err", err)
 				w.mosAlarm(inputHash, errors.Wrap(err, "please admin handler"))
 				time.Sleep(time.Second * 10)
 				continue
 			}
-
-			w.log.Info("Send transaction", "addr", addr, "srcHash", inputHash, "method", method)
-			mcsTx, err := w.sendTx(addr, method, m.Payload[0].([]byte), 0, int64(w.cfg.GasMultiplier),
-				0, false)
 			if err == nil {
 				w.log.Info("Submitted cross tx execution", "src", m.Source, "dst", m.Destination, "srcHash", inputHash, "mcsTx", mcsTx)
-				err = w.txStatus(mcsTx)
+				err = w.txStatus(m, method, mcsTx)
 				if err != nil {
 					w.log.Warn("TxHash status is not successful, will retry", "err", err)
 				} else {
-					w.newReturn(method)
+					w.energy.release(m)
 					report.Add(&report.Data{
 						Hash:    mcsTx,
 						IsRelay: false,
@@ -158,90 +140,74 @@ func (w *Writer) exeMcs(m msg.Message) bool {
 				m.DoneCh <- struct{}{}
 				return true
 			} else {
-				for e := range constant.IgnoreError {
-					if strings.Contains(err.Error(), e) {
-						w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "err", err)
-						m.DoneCh <- struct{}{}
-						return true
-					}
+				if ce := txerr.Classify(err); ce.Category.Skip() {
+					w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "category", ce.Category, "reason", ce.Reason, "err", err)
+					m.DoneCh <- struct{}{}
+					return true
 				}
 				w.log.Warn("Execution failed, will retry", "srcHash", inputHash, "err", err)
 			}
-			w.newReturn(method)
-			errorCount++
-			if errorCount >= 10 {
+			w.energy.release(m)
+			if txerr.Alarm(&errorCount, &alarmed, err) {
 				w.mosAlarm(inputHash, err)
-				errorCount = 0
 			}
 			time.Sleep(constant.TxRetryInterval)
 		}
 	}
 }
 
-func (w *Writer) sendTx(addr, method string, input []byte, txAmount, mul, used int64, ignore bool) (string, error) {
-	// online estimateEnergy
-	contract, err := w.conn.cli.TriggerConstantContractByEstimate(w.cfg.From, addr, input, txAmount)
+// errEnergy wraps the failure to get the energy a transaction takes.
+var errEnergy = errors.New("energy not acquired")
+
+// sendTx simulates the call of method, input to addr sending value sun, and
+// sends it with the fee limit the simulation prices. With acquire it first
+// gets the account the energy the call takes, for the transaction of m.
+func (w *Writer) sendTx(m msg.Message, addr, method string, input []byte, value int64, acquire bool) (string, error) {
+	sim, err := w.simulate(addr, method, input, value)
 	if err != nil {
-		w.log.Error("Failed to TriggerConstantContract EstimateEnergy", "err", err)
+		w.log.Warn("Simulate transaction failed", "method", method, "err", err)
 		return "", err
 	}
-
-	for _, v := range contract.ConstantResult {
-		w.log.Info("contract result", "err", string(v), "v", v, "hex", common.Bytes2Hex(v))
-		ele := strings.TrimSpace(string(v))
-		if ele != "" && ele != "4,^" && ele != "0�" && !ignore {
-			return "", errors.New(ele)
+	w.log.Info("Simulated transaction", "method", method, "energy", sim.energy, "price", sim.price, "feeLimit", sim.feeLimit)
+	if acquire {
+		if err = w.energy.ensure(m, sim.energy); err != nil {
+			return "", fmt.Errorf("%w, %v", errEnergy, err)
 		}
 	}
 
-	if used == 0 {
-		used = contract.EnergyUsed
-	}
-
-	// // testnet
-	// estimate, err := w.conn.cli.EstimateEnergy(w.cfg.From, addr, input, 0, "", 0)
-	// if err != nil {
-	// 	w.log.Error("Failed to EstimateEnergy", "err", err)
-	// 	return "", err
-	// }
-	feeLimit := big.NewInt(0).Mul(big.NewInt(used), big.NewInt(420*mul))
-	w.log.Info("EstimateEnergy", "estimate", used, "multiple", multiple, "feeLimit", feeLimit, "mul", mul)
-
-	acco, err := w.conn.cli.GetAccountResource(w.cfg.From)
-	if err != nil {
-		return "", errors.Wrap(err, "get account failed")
-	}
-	final := float64(used) * 1.1
-	// 22000 > (40000 - 10000) = false, continue exec
-	if int64(final) >= (acco.EnergyLimit-acco.EnergyUsed) && method != "rent" && w.cfg.Rent {
-		w.log.Info("SendTx EstimateEnergy", "err", "txUsed(%d) energy more than acount have(%d)", int64(final), acco.EnergyLimit)
-		//if estimate.EnergyRequired >= account.EnergyLimit {
-		return "", fmt.Errorf("txUsed(%d) energy more than acount have(%d)", int64(final), acco.EnergyLimit)
-	}
-
-	tx, err := w.conn.cli.TriggerContract(w.cfg.From, addr, input, feeLimit.Int64(), txAmount, "", 0)
+	tx, err := w.conn.cli.TriggerContract(w.cfg.From, addr, input, sim.feeLimit, value, "", 0)
 	if err != nil {
 		w.log.Error("Failed to TriggerContract", "err", err)
 		return "", err
 	}
+	return w.signAndSend(tx)
+}
 
-	ks, acc, err := store.UnlockedKeystore(w.cfg.From, string(w.pass))
-	if err != nil {
-		w.log.Error("Failed to UnlockedKeystore", "err", err)
+// signAndSend signs tx with the key of the account and broadcasts it.
+func (w *Writer) signAndSend(tx *api.TransactionExtention) (string, error) {
+	kp := w.conn.Keypair()
+	if kp == nil {
+		return "", errors.New("no key loaded, only the messenger signs")
+	}
+	if err := SignTx(tx.Transaction, kp.PrivateKey); err != nil {
+		w.log.Error("Failed to sign transaction", "err", err)
 		return "", err
 	}
-	controller := transaction.NewController(w.conn.cli, ks, acc, tx.Transaction)
-	if err = controller.ExecuteTransaction(); err != nil {
-		w.log.Error("Failed to ExecuteTransaction", "err", err)
+	result, err := w.conn.cli.Broadcast(tx.Transaction)
+	if err != nil {
+		w.log.Error("Failed to broadcast transaction", "err", err)
 		return "", err
 	}
-	if controller.GetResultError() != nil {
-		return "", fmt.Errorf("contro resultError is %v", controller.GetResultError())
+	if result.Code != 0 {
+		return "", fmt.Errorf("bad transaction: %v", string(result.GetMessage()))
 	}
 	return common.Bytes2Hex(tx.GetTxid()), nil
 }
 
-func (w *Writer) txStatus(txHash string) error {
+// txStatus waits for txHash, sent for m, to be confirmed, charges its fee to
+// the spend meter and records the energy its call of method used, whether or
+// not it succeeded.
+func (w *Writer) txStatus(m msg.Message, method, txHash string) error {
 	var count int64
 	time.Sleep(time.Second * 2)
 	for {
@@ -255,6 +221,8 @@ func (w *Writer) txStatus(txHash string) error {
 			}
 			continue
 		}
+		w.spend.Charge(m, big.NewInt(id.Fee))
+		w.energy.state.Used(method, id.GetReceipt().GetEnergyUsageTotal())
 		if id.Receipt.Result == core.Transaction_Result_SUCCESS {
 			w.log.Info("Tx receipt status is success", "hash", txHash)
 			return nil
@@ -291,120 +259,11 @@ func (w *Writer) checkOrderId(toAddress string, input []byte) (bool, error) {
 	return exist, nil
 }
 
-var (
-	wei = big.NewFloat(1000000)
-)
-
-func (w *Writer) rentEnergy(used int64, method string) error {
-	if !w.cfg.Rent {
-		w.log.Info("dont need rent energy, cfg is false")
-		return nil
-	}
-	acc, err := w.conn.cli.GetAccountResource(w.cfg.From)
-	if err != nil {
-		return err
-	}
-	if w.cfg.FeeType == constant.FeeRentType {
-		return w.feeRentEnergy(used, acc)
-	}
-
-	account, err := w.conn.cli.GetAccount(w.cfg.From)
-	if err != nil {
-		return err
-	}
-	balance, _ := big.NewFloat(0).Quo(big.NewFloat(0).SetInt64(account.Balance), wei).Float64()
-	w.log.Info("Rent energy, account energy detail", "account", w.cfg.From, "all", acc.EnergyLimit, "used", acc.EnergyUsed,
-		"trx", balance)
-
-	mul := float64(used) * 1.1
-	if (acc.EnergyLimit - acc.EnergyUsed) > int64(mul) {
-		w.log.Info("Rent energy, account have enough energy", "account", w.cfg.From,
-			"have", acc.EnergyLimit-acc.EnergyUsed, "estimate", mul)
-		//return nil
-	}
-
-	if balance < 330 {
-		return errors.New("account not have enough balance(330 trx)")
-	}
-
-	input, err := mapprotocol.TronAbi.Pack("rentResource", w.cfg.EthFrom,
-		big.NewInt(122205000000), big.NewInt(1))
-	if err != nil {
-		return errors.Wrap(err, "pack input failed")
-	}
-	w.log.Info("Rent energy will rent")
-	tx, err := w.sendTx(w.cfg.RentNode, "rent", input, 300000000, 1, 70000, false)
-	if err != nil {
-		return errors.Wrap(err, "sendTx failed")
-	}
-	w.log.Info("Rent energy success", "tx", tx)
-	err = w.txStatus(tx)
-	if err != nil {
-		w.log.Warn("Rent TxHash Status is not successful, will retry", "err", err)
-		return err
-	}
-
-	w.isRent = true
-	return nil
-}
-
-func (w *Writer) feeRentEnergy(used int64, acc *api.AccountResourceMessage) error {
-	resValue := int64(1000000)
-	rentDuration := int64(1)
-	if acc.EnergyLimit > used {
-		w.log.Info("FeeRentEnergy dont need rent, because account have enough energy", "have", acc.EnergyLimit, "used", used)
-		return nil
-	}
+// accountBalance is the TRX balance of the account, in sun.
+func (w *Writer) accountBalance() (int64, error) {
 	account, err := w.conn.cli.GetAccount(w.cfg.From)
 	if err != nil {
-		return err
-	}
-	balance, _ := big.NewFloat(0).Quo(big.NewFloat(0).SetInt64(account.Balance), wei).Float64()
-	res, err := GetOrderPrice(w.cfg.FeeKey, resValue, rentDuration)
-	if err != nil {
-		return err
-	}
-	if res.Data.PayAmount > balance {
-		return fmt.Errorf("account not have enough balance(%0.4f trx)", res.Data.PayAmount)
-	}
-	ret, err := OrderSubmit(w.cfg.FeeKey, w.cfg.From, resValue, rentDuration)
-	if err != nil {
-		return err
-	}
-	w.log.Info("FeeRentEnergy rent success", "no", ret.Data.OrderNo)
-	return nil
-}
-
-func (w *Writer) newReturn(method string) {
-	if !w.isRent {
-		w.log.Info("Return energy, is not rent, dont return")
-		return
-	}
-	w.log.Info("Return energy will start")
-	time.Sleep(constant.BlockRetryInterval)
-	acc, err := w.conn.cli.GetAccountResource(w.cfg.From)
-	if err != nil {
-		w.log.Error("Return energy, GetAccountResource failed", "err", err)
-		return
-	}
-	if acc.EnergyLimit-20004 <= 0 {
-		w.log.Info("Return energy, user not rent energy", "gas", acc.EnergyLimit)
-		return
-	}
-	input, err := mapprotocol.TronAbi.Pack("returnResource", w.cfg.EthFrom, big.NewInt(122205000000), big.NewInt(1))
-	if err != nil {
-		w.log.Error("Return energy, Pack failed", "err", err)
-		return
-	}
-	tx, err := w.sendTx(w.cfg.RentNode, "return", input, 0, 1, 80000, true)
-	if err != nil {
-		w.log.Error("Return energy, sendTx failed", "err", err)
-		return
-	}
-	err = w.txStatus(tx)
-	if err != nil {
-		w.log.Warn("Return TxHash Status is not successful, will retry", "err", err)
+		return 0, err
 	}
-	w.log.Info("Return energy success", "tx", tx)
-	w.isRent = false
+	return account.Balance, nil
 }
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/mapprotocol/compass/config"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/eth2"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+	cpkeystore "github.com/mapprotocol/compass/pkg/keystore"
+	"github.com/urfave/cli/v2"
+)
+
+var eth2Command = cli.Command{
+	Name:  "eth2",
+	Usage: "manage the eth2 light client on MAP",
+	Subcommands: []*cli.Command{
+		&eth2BootstrapCommand,
+	},
+}
+
+var eth2BootstrapCommand = cli.Command{
+	Name:  "bootstrap",
+	Usage: "build the initialization of the eth2 light client from a trusted checkpoint",
+	Description: "Fetches the light client bootstrap of a finalized checkpoint, verifies its sync committees and prints the initialize calldata.\n" +
+		"\tcompass eth2 bootstrap --beacon <url> --checkpoint 0x.. --controller 0x.. --mpt-verify 0x.. [--format json]\n" +
+		"\tWith --map-endpoint and --light-node it also prints how far the light client is from the checkpoint,\n" +
+		"\tand --submit sends the initialization with the key of --keystorePath.",
+	Action: eth2Bootstrap,
+	Flags: []cli.Flag{
+		config.VerbosityFlag,
+		config.BeaconFlag,
+		config.CheckpointFlag,
+		config.FinalizedFlag,
+		config.Eth2ChainIdFlag,
+		config.ControllerFlag,
+		config.MptVerifyFlag,
+		config.MapEndpointFlag,
+		config.LightNodeFlag,
+		config.SubmitFlag,
+		config.KeyPathFlag,
+		config.FormatFlag,
+		config.OutFlag,
+	},
+}
+
+// bootstrapOutput is what --format json prints.
+type bootstrapOutput struct {
+	Checkpoint  string     `json:"checkpoint"`
+	Slot        uint64     `json:"slot"`
+	Period      uint64     `json:"period"`
+	Method      string     `json:"method,omitempty"`
+	Args        []proofArg `json:"args,omitempty"`
+	Calldata    string     `json:"calldata"`
+	DecodeError string     `json:"decodeError,omitempty"`
+}
+
+func eth2Bootstrap(ctx *cli.Context) error {
+	if err := startLogger(ctx); err != nil {
+		return err
+	}
+	format := ctx.String(config.FormatFlag.Name)
+	if format != "hex" && format != "json" && format != "calldata" {
+		return fmt.Errorf("unknown format %q, want hex, json or calldata", format)
+	}
+	checkpoint := ctx.String(config.CheckpointFlag.Name)
+	if ctx.Bool(config.FinalizedFlag.Name) == (checkpoint != "") {
+		return errors.New("set one of --checkpoint and --finalized")
+	}
+	if checkpoint == "" {
+		checkpoint = string(constant.FinalBlockIdOfEth2)
+	} else if len(common.FromHex(checkpoint)) != common.HashLength {
+		return fmt.Errorf("checkpoint %q is not a block root", checkpoint)
+	}
+	submit := ctx.Bool(config.SubmitFlag.Name)
+	mapEndpoint := ctx.String(config.MapEndpointFlag.Name)
+	lightNode := ctx.String(config.LightNodeFlag.Name)
+	if submit && (mapEndpoint == "" || lightNode == "") {
+		return errors.New("--submit needs --map-endpoint and --light-node")
+	}
+
+	beacon, err := eth2.DialHttp(ctx.String(config.BeaconFlag.Name))
+	if err != nil {
+		return err
+	}
+	bootstrap, err := eth2.FetchBootstrap(context.Background(), beacon, checkpoint)
+	if err != nil {
+		return err
+	}
+	data, err := mapprotocol.Eth2Init.Pack(mapprotocol.MethodOfInitialize,
+		new(big.Int).SetUint64(ctx.Uint64(config.Eth2ChainIdFlag.Name)),
+		common.HexToAddress(ctx.String(config.ControllerFlag.Name)),
+		common.HexToAddress(ctx.String(config.MptVerifyFlag.Name)),
+		bootstrap.Header, bootstrap.ExecutionNumber, bootstrap.ExecutionHash,
+		bootstrap.CurrentSyncCommittee, bootstrap.NextSyncCommittee)
+	if err != nil {
+		return fmt.Errorf("pack initialize: %w", err)
+	}
+
+	var client *ethclient.Client
+	if mapEndpoint != "" {
+		rpcClient, err := rpc.DialContext(context.Background(), mapEndpoint)
+		if err != nil {
+			return fmt.Errorf("connect %s: %w", mapEndpoint, err)
+		}
+		client = ethclient.NewClient(rpcClient, mapEndpoint, http.DefaultClient)
+		defer client.Close()
+	}
+	if client != nil && lightNode != "" {
+		printDistance(client, common.HexToAddress(lightNode), bootstrap)
+	}
+	if submit {
+		hash, err := sendInitialize(ctx.String(config.KeyPathFlag.Name), client, common.HexToAddress(lightNode), data)
+		if err != nil {
+			return err
+		}
+		fmt.Println(hash)
+		return nil
+	}
+
+	out := bootstrapOutput{
+		Checkpoint: bootstrap.Root.Hex(),
+		Slot:       bootstrap.Header.Slot,
+		Period:     bootstrap.Period(),
+		Calldata:   hexutil.Encode(data),
+	}
+	switch format {
+	case "json":
+		var decoded proofOutput
+		decodeCalldata(&decoded, data)
+		out.Method, out.Args, out.DecodeError = decoded.Method, decoded.Args, decoded.DecodeError
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case "calldata":
+		path := ctx.String(config.OutFlag.Name)
+		if path == "" {
+			path = fmt.Sprintf("eth2-bootstrap-%d.calldata", bootstrap.Header.Slot)
+		}
+		if err = os.WriteFile(path, []byte(out.Calldata+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	default:
+		fmt.Println(out.Calldata)
+	}
+	return nil
+}
+
+// printDistance prints how far the light client is from the checkpoint, on
+// stderr so the calldata on stdout stays usable. The maintainer moves the
+// light client one sync committee period at a time, so a checkpoint more
+// than a period ahead can't be reached by it.
+func printDistance(client *ethclient.Client, lightNode common.Address, bootstrap *eth2.Bootstrap) {
+	height, err := mapprotocol.Map2EthHeight(constant.ZeroAddress.Hex(), lightNode, client)()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "light client %s: %v\n", lightNode, err)
+		return
+	}
+	slotsPerPeriod := uint64(constant.SlotsPerEpoch * constant.EpochsPerPeriod)
+	onChain := height.Uint64()
+	checkpoint := bootstrap.Header.Slot
+	switch {
+	case onChain == 0:
+		fmt.Fprintf(os.Stderr, "light client %s is not initialized, checkpoint at slot %d (period %d)\n",
+			lightNode, checkpoint, bootstrap.Period())
+	case onChain < checkpoint:
+		fmt.Fprintf(os.Stderr, "light client %s at slot %d (period %d) is %d slots, %d periods behind the checkpoint at slot %d (period %d)\n",
+			lightNode, onChain, onChain/slotsPerPeriod, checkpoint-onChain, bootstrap.Period()-onChain/slotsPerPeriod, checkpoint, bootstrap.Period())
+	default:
+		fmt.Fprintf(os.Stderr, "light client %s at slot %d (period %d) is %d slots, %d periods ahead of the checkpoint at slot %d (period %d)\n",
+			lightNode, onChain, onChain/slotsPerPeriod, onChain-checkpoint, onChain/slotsPerPeriod-bootstrap.Period(), checkpoint, bootstrap.Period())
+	}
+}
+
+// sendInitialize signs data to lightNode with the key at keyPath and sends
+// it, priced the way the swap failed sender prices its transactions.
+func sendInitialize(keyPath string, client *ethclient.Client, lightNode common.Address, data []byte) (string, error) {
+	if keyPath == "" {
+		return "", errors.New("--submit needs --keystorePath")
+	}
+	kp, err := cpkeystore.KeypairFromEth(keyPath)
+	if err != nil {
+		return "", fmt.Errorf("load keystore: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("get chain id: %w", err)
+	}
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: kp.Address, To: &lightNode, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("estimate gas: %w", err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, kp.Address)
+	if err != nil {
+		return "", fmt.Errorf("pending nonce: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return "", fmt.Errorf("suggest gas price: %w", err)
+	}
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &lightNode,
+		Gas:      gasLimit * 15 / 10,
+		GasPrice: new(big.Int).Div(new(big.Int).Mul(gasPrice, big.NewInt(12)), big.NewInt(10)),
+		Data:     data,
+	}), types.NewEIP155Signer(chainID), kp.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("sign: %w", err)
+	}
+	if err = client.SendTransaction(ctx, tx); err != nil {
+		return "", fmt.Errorf("send: %w", err)
+	}
+	return tx.Hash().Hex(), nil
+}
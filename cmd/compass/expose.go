@@ -3,16 +3,23 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/gin-gonic/gin"
 	"github.com/mapprotocol/compass/chains"
+	"github.com/mapprotocol/compass/chains/evm"
 	"github.com/mapprotocol/compass/config"
 	"github.com/mapprotocol/compass/internal/butter"
+	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/expose"
 	"github.com/mapprotocol/compass/internal/expose/handler"
+	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/pkg/keystore"
+	"github.com/mapprotocol/compass/pkg/msg"
 	"github.com/mapprotocol/compass/pkg/util"
 	"github.com/urfave/cli/v2"
 )
@@ -57,8 +64,32 @@ func api(ctx *cli.Context) error {
 	}
 	butter.SetAPIKey(butterAPIKeyFromExposeConfig(ctx, cfg))
 	util.Init(cfg.Other.Env, cfg.Other.MonitorUrl)
+	proof.InitReceiptCache(proof.CacheConfig{
+		Size: cfg.Other.ReceiptCacheSize,
+		TTL:  time.Duration(cfg.Other.ReceiptCacheTTL) * time.Second,
+		Dir:  cfg.Other.ReceiptCacheDir,
+	})
+	proof.InitZkProver(proof.ZkConfig{
+		Url:       cfg.Other.ZkUrl,
+		CacheSize: cfg.Other.ZkCacheSize,
+		Wait:      time.Duration(cfg.Other.ZkWait) * time.Second,
+	})
 	// pre init
 	for _, ele := range cfg.Chains {
+		id, err := strconv.ParseUint(ele.Id, 10, 64)
+		if err != nil {
+			return fmt.Errorf("chain %s: invalid id %s", ele.Name, ele.Id)
+		}
+		if ele.ReceiptEncoding != "" {
+			if err = ireceipt.Bind(msg.ChainId(id), ele.ReceiptEncoding); err != nil {
+				return fmt.Errorf("chain %s: %w", ele.Name, err)
+			}
+		}
+		if ele.Type == constant.Evm {
+			if _, err = evm.Configure(msg.ChainId(id), ele.Opts); err != nil {
+				return fmt.Errorf("chain %s: %w", ele.Name, err)
+			}
+		}
 		creator, ok := chains.CreateProffer(ele.Type)
 		fmt.Println("------ creator ", creator, " ------- ", ele.Type, ele.Name, ok)
 		_, err = creator.Connect(ele.Id, ele.Endpoint, ele.Mcs, ele.LightNode, ele.OracleNode)
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/mapprotocol/compass/config"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/txerr"
+	cpkeystore "github.com/mapprotocol/compass/pkg/keystore"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/urfave/cli/v2"
+)
+
+var heldCommand = cli.Command{
+	Name:  "held",
+	Usage: "inspect and replay the deliveries parked in the holding area",
+	Description: "A delivery whose simulation reverted preflightHoldAfter times in a row is parked in the holding area of its chain.\n" +
+		"\tcompass held list --config ./config.json --blockstore ./block-eth-map\n" +
+		"\tcompass held replay --config ./config.json --blockstore ./block-eth-map --chain bsc --index 0",
+	Subcommands: []*cli.Command{
+		&heldListCommand,
+		&heldReplayCommand,
+	},
+}
+
+var heldListCommand = cli.Command{
+	Name:   "list",
+	Usage:  "print the held deliveries of every chain, or of --chain",
+	Action: heldList,
+	Flags: []cli.Flag{
+		config.ConfigFileFlag,
+		config.VerbosityFlag,
+		config.BlockstorePathFlag,
+		config.HeldChainFlag,
+	},
+}
+
+var heldReplayCommand = cli.Command{
+	Name:   "replay",
+	Usage:  "simulate a held delivery again and, if it passes now, send it and take it out of the holding area",
+	Action: heldReplay,
+	Flags: []cli.Flag{
+		config.ConfigFileFlag,
+		config.VerbosityFlag,
+		config.BlockstorePathFlag,
+		config.KeyPathFlag,
+		config.HeldChainFlag,
+		config.HeldIndexFlag,
+	},
+}
+
+// heldChain is a configured chain with its holding area.
+type heldChain struct {
+	raw     config.RawChainConfig
+	id      msg.ChainId
+	holding *chain.HoldingArea
+}
+
+// heldChains returns the holding areas of the configured chains, only the
+// one named name when it isn't empty.
+func heldChains(ctx *cli.Context, name string) ([]heldChain, error) {
+	cfg, err := config.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]heldChain, 0)
+	for _, ele := range append([]config.RawChainConfig{cfg.MapChain}, cfg.Chains...) {
+		if name != "" && ele.Name != name {
+			continue
+		}
+		id, err := strconv.ParseUint(ele.Id, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		holding, err := chain.NewHoldingArea(&chain.Config{
+			Name:           ele.Name,
+			Id:             msg.ChainId(id),
+			From:           ele.From,
+			BlockstorePath: ctx.String(config.BlockstorePathFlag.Name),
+			HoldingPath:    ele.Opts[chain.HoldingPathOpt],
+		})
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, heldChain{raw: ele, id: msg.ChainId(id), holding: holding})
+	}
+	if name != "" && len(ret) == 0 {
+		return nil, fmt.Errorf("no chain named %q in the config", name)
+	}
+	return ret, nil
+}
+
+func heldList(ctx *cli.Context) error {
+	if err := startLogger(ctx); err != nil {
+		return err
+	}
+	chains, err := heldChains(ctx, ctx.String(config.HeldChainFlag.Name))
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tINDEX\tHELD AT\tTYPE\tSRC HASH\tCATEGORY\tREASON")
+	for _, c := range chains {
+		held, err := c.holding.List()
+		if err != nil {
+			return err
+		}
+		for i, hm := range held {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", c.raw.Name, i, time.Unix(hm.Time, 0).Format(time.RFC3339),
+				hm.Type, hm.SrcHash, hm.Category, hm.Reason)
+		}
+	}
+	return w.Flush()
+}
+
+func heldReplay(ctx *cli.Context) error {
+	if err := startLogger(ctx); err != nil {
+		return err
+	}
+	name := ctx.String(config.HeldChainFlag.Name)
+	if name == "" {
+		return fmt.Errorf("--%s is required", config.HeldChainFlag.Name)
+	}
+	chains, err := heldChains(ctx, name)
+	if err != nil {
+		return err
+	}
+	c := chains[0]
+	held, err := c.holding.List()
+	if err != nil {
+		return err
+	}
+	idx := ctx.Int(config.HeldIndexFlag.Name)
+	if idx < 0 || idx >= len(held) {
+		return fmt.Errorf("%s has %d held deliveries, no index %d", name, len(held), idx)
+	}
+	hm := held[idx]
+
+	keyPath := c.raw.KeystorePath
+	if keyPath == "" {
+		keyPath = ctx.String(config.KeyPathFlag.Name)
+	}
+	if keyPath == "" {
+		return fmt.Errorf("%s has no keystorePath, pass --%s", name, config.KeyPathFlag.Name)
+	}
+	kp, err := cpkeystore.KeypairFromEth(keyPath)
+	if err != nil {
+		return fmt.Errorf("load keystore: %w", err)
+	}
+	client, err := ethclient.Dial(c.raw.Endpoint)
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", c.raw.Endpoint, err)
+	}
+	defer client.Close()
+
+	tx, err := sendHeld(client, kp, big.NewInt(int64(c.id)), hm)
+	if errors.Is(err, errHeldDelivered) {
+		if rerr := c.holding.Remove(hm); rerr != nil {
+			return rerr
+		}
+		fmt.Printf("%v, removed from %s\n", err, c.holding.Path())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Sent %s, waiting for it to be mined\n", tx.Hash().Hex())
+	waitCtx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	receipt, err := bind.WaitMined(waitCtx, client, tx)
+	if err != nil {
+		return fmt.Errorf("wait for %s: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("%s failed on chain, the delivery stays held", tx.Hash().Hex())
+	}
+	if err = c.holding.Remove(hm); err != nil {
+		return fmt.Errorf("%s succeeded but the holding area wasn't updated: %w", tx.Hash().Hex(), err)
+	}
+	fmt.Printf("Delivered in block %d, removed from %s\n", receipt.BlockNumber.Uint64(), c.holding.Path())
+	return nil
+}
+
+// errHeldDelivered is returned by sendHeld when the simulation says the held
+// delivery went through some other way.
+var errHeldDelivered = errors.New("already delivered")
+
+// sendHeld simulates the call of hm and, when it no longer reverts, signs and
+// sends it with the key of kp.
+func sendHeld(client *ethclient.Client, kp *keystore.Key, chainID *big.Int, hm chain.HeldMessage) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	from := kp.Address
+	to := common.HexToAddress(hm.To)
+	data := common.FromHex(hm.Input)
+	gasLimit, err := client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &to, Data: data})
+	if err != nil {
+		ce := txerr.Classify(err)
+		if ce.Category.Skip() {
+			return nil, fmt.Errorf("%w (%s %s)", errHeldDelivered, ce.Category, ce.Reason)
+		}
+		return nil, fmt.Errorf("still fails in simulation (%s %s): %w", ce.Category, ce.Reason, err)
+	}
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("pending nonce: %w", err)
+	}
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggest gas price: %w", err)
+	}
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Gas:      gasLimit * 15 / 10,
+		GasPrice: gasPrice,
+		Data:     data,
+	}), types.NewEIP155Signer(chainID), kp.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %w", err)
+	}
+	if err = client.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("send: %w", err)
+	}
+	return tx, nil
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/ChainSafe/log15"
 	"github.com/ethereum/go-ethereum/common"
@@ -18,7 +19,9 @@ import (
 	"github.com/mapprotocol/compass/internal/contract"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/internal/proof"
 	"github.com/mapprotocol/compass/internal/report"
+	"github.com/mapprotocol/compass/internal/spend"
 	"github.com/mapprotocol/compass/pkg/abi"
 	contract2 "github.com/mapprotocol/compass/pkg/contract"
 	"github.com/mapprotocol/compass/pkg/msg"
@@ -102,6 +105,10 @@ func init() {
 		&oracleCommand,
 		&exposeCommand,
 		&swapFailedCommand,
+		&proofCommand,
+		&heldCommand,
+		&eth2Command,
+		&tronCommand,
 		&versionCommand,
 	}
 
@@ -176,6 +183,7 @@ func run(ctx *cli.Context, role mapprotocol.Role) error {
 	butter.Init(cfg.Other.ButterAPIKey)
 	util.Init(cfg.Other.Env, cfg.Other.MonitorUrl)
 	report.Init(cfg.Other.ReportUrl)
+	spend.Init(util.Alarm)
 
 	// Stand up observability (metrics + /status + pprof + alarms) before
 	// any chain goroutines start so the first tick can already publish.
@@ -190,6 +198,17 @@ func run(ctx *cli.Context, role mapprotocol.Role) error {
 	observability.SetDefault(obs)
 	obs.StartHTTP()
 	obs.StartBlockLagAlarms(observability.DefaultBlockLagRule())
+	proof.InitReceiptCache(proof.CacheConfig{
+		Size: cfg.Other.ReceiptCacheSize,
+		TTL:  time.Duration(cfg.Other.ReceiptCacheTTL) * time.Second,
+		Dir:  cfg.Other.ReceiptCacheDir,
+	})
+	proof.InitZkProver(proof.ZkConfig{
+		Url:       cfg.Other.ZkUrl,
+		Ahead:     cfg.Other.ZkPrefetch,
+		CacheSize: cfg.Other.ZkCacheSize,
+		Wait:      time.Duration(cfg.Other.ZkWait) * time.Second,
+	})
 	log.Info("Observability HTTP serving", "addr", obsAddr,
 		"endpoints", "/metrics /status /healthz /debug/pprof/")
 	defer obs.Stop()
@@ -205,6 +224,7 @@ func run(ctx *cli.Context, role mapprotocol.Role) error {
 	log.Info("Filter API auth configured", "enabled", filterAPIKey != "")
 	butter.SetAPIKey(butterAPIKeyFromConfig(ctx, cfg))
 
+	conns := make(map[msg.ChainId]core.Connection, len(cfg.Chains)+1)
 	allChains := make([]config.RawChainConfig, 0, len(cfg.Chains)+1)
 	allChains = append(allChains, cfg.MapChain)
 	allChains = append(allChains, cfg.Chains...)
@@ -269,12 +289,22 @@ func run(ctx *cli.Context, role mapprotocol.Role) error {
 			mapprotocol.Init2GetEth22MapNumber(common.HexToAddress(chainConfig.Opts[chain2.LightNode]))
 			mapprotocol.InitOtherChain2MapHeight(common.HexToAddress(chainConfig.Opts[chain2.LightNode]))
 			mapprotocol.InitLightManager(common.HexToAddress(chainConfig.Opts[chain2.LightNode]))
+			mapprotocol.InitEth2SyncCommittees(common.HexToAddress(chainConfig.Opts[chain2.LightNode]))
 			mapprotocol.LightManagerNodeType(common.HexToAddress(chainConfig.Opts[chain2.LightNode]))
 		}
 
 		mapprotocol.OnlineChaId[chainConfig.Id] = chainConfig.Name
+		conns[chainConfig.Id] = newChain.Conn()
 		c.AddChain(newChain)
 	}
+
+	watchdog := chain2.NewLightClientWatchdog(time.Duration(cfg.Other.LightClientInterval)*time.Second,
+		time.Duration(cfg.Other.LightClientStall)*time.Second, log.Root().New("module", "lightclient"))
+	chain2.WatchLightClients(watchdog, msg.ChainId(mapcid), conns)
+	log.Info("Watching light clients", "count", watchdog.Len())
+	watchdogStop := make(chan int)
+	defer close(watchdogStop)
+	watchdog.Start(watchdogStop)
 	c.Start()
 
 	return nil
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/mapprotocol/compass/chains"
+	"github.com/mapprotocol/compass/chains/evm"
+	"github.com/mapprotocol/compass/config"
+	"github.com/mapprotocol/compass/internal/chain"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/urfave/cli/v2"
+)
+
+var proofCommand = cli.Command{
+	Name:  "proof",
+	Usage: "generate the proof of an event and print it",
+	Description: "Builds the proof compass would submit for one event log with the chain's registered Proffer, without a relayer config or keystore.\n" +
+		"\tcompass proof --type ethereum --endpoint <rpc> --tx 0x.. --log-index 3 --to 22776 [--proof-type 1] [--format json]\n" +
+		"\tWith --maintainer it prints the light client header update for --to instead.",
+	Action: proofAction,
+	Flags: []cli.Flag{
+		config.VerbosityFlag,
+		config.ChainTypeFlag,
+		config.EndpointFlag,
+		config.FromChainFlag,
+		config.TxHashFlag,
+		config.LogIndexFlag,
+		config.ToChainFlag,
+		config.ProofTypeFlag,
+		config.McsFlag,
+		config.ToMcsFlag,
+		config.ToEndpointFlag,
+		config.LightNodeFlag,
+		config.OracleNodeFlag,
+		config.SignaturesFlag,
+		config.MaintainerFlag,
+		config.FormatFlag,
+		config.OutFlag,
+		config.ReceiptEncodingFlag,
+		config.ZkUrlFlag,
+		config.ChainOptFlag,
+	},
+}
+
+// proofOutput is what --format json prints.
+type proofOutput struct {
+	Type        string     `json:"type"`
+	From        uint64     `json:"from"`
+	To          uint64     `json:"to"`
+	Tx          string     `json:"tx,omitempty"`
+	BlockNumber uint64     `json:"blockNumber,omitempty"`
+	LogIndex    uint       `json:"logIndex,omitempty"`
+	ProofType   int64      `json:"proofType,omitempty"`
+	Method      string     `json:"method,omitempty"`
+	Args        []proofArg `json:"args,omitempty"`
+	Calldata    string     `json:"calldata"`
+	DecodeError string     `json:"decodeError,omitempty"`
+}
+
+type proofArg struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+func proofAction(ctx *cli.Context) error {
+	if err := startLogger(ctx); err != nil {
+		return err
+	}
+	format := ctx.String(config.FormatFlag.Name)
+	if format != "hex" && format != "json" && format != "calldata" {
+		return fmt.Errorf("unknown format %q, want hex, json or calldata", format)
+	}
+	chainType := ctx.String(config.ChainTypeFlag.Name)
+	proffer, ok := chains.CreateProffer(chainType)
+	if !ok {
+		return fmt.Errorf("chain type %q has no proffer", chainType)
+	}
+
+	endpoint := ctx.String(config.EndpointFlag.Name)
+	from := ctx.Uint64(config.FromChainFlag.Name)
+	if from == 0 {
+		id, err := chainIdOf(endpoint)
+		if err != nil {
+			return err
+		}
+		from = id
+	}
+	to := ctx.Uint64(config.ToChainFlag.Name)
+	proof.InitZkProver(proof.ZkConfig{Url: ctx.String(config.ZkUrlFlag.Name)})
+	if family := ctx.String(config.ReceiptEncodingFlag.Name); family != "" {
+		if err := ireceipt.Bind(msg.ChainId(from), family); err != nil {
+			return err
+		}
+	}
+	opts := make(map[string]string)
+	for _, kv := range ctx.StringSlice(config.ChainOptFlag.Name) {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return fmt.Errorf("option %q is not key=value", kv)
+		}
+		opts[k] = v
+	}
+	var optProofType int64
+	if chainType == constant.Evm {
+		o, err := evm.Configure(msg.ChainId(from), opts)
+		if err != nil {
+			return err
+		}
+		optProofType = o.ProofType
+	}
+	client, err := proffer.Connect(strconv.FormatUint(from, 10), endpoint, ctx.String(config.McsFlag.Name),
+		ctx.String(config.LightNodeFlag.Name), ctx.String(config.OracleNodeFlag.Name))
+	if err != nil {
+		return fmt.Errorf("connect %s: %w", endpoint, err)
+	}
+
+	out := proofOutput{Type: chainType, From: from, To: to}
+	var data []byte
+	if ctx.Bool(config.MaintainerFlag.Name) {
+		data, err = proffer.Maintainer(client, from, to, endpoint)
+		if err != nil {
+			return fmt.Errorf("assemble maintainer: %w", err)
+		}
+	} else {
+		txHash := ctx.String(config.TxHashFlag.Name)
+		if txHash == "" {
+			return errors.New("--tx is required unless --maintainer is set")
+		}
+		receipt, err := client.TransactionReceipt(context.Background(), common.HexToHash(txHash))
+		if err != nil {
+			return fmt.Errorf("get receipt of %s: %w", txHash, err)
+		}
+		logIndex := ctx.Uint(config.LogIndexFlag.Name)
+		var target *types.Log
+		for _, l := range receipt.Logs {
+			if l.Index == logIndex {
+				target = l
+				break
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("tx %s has no log with index %d", txHash, logIndex)
+		}
+		if len(target.Topics) < 2 {
+			return fmt.Errorf("log %d of tx %s is not a cross-chain event", logIndex, txHash)
+		}
+
+		proofType := ctx.Int64(config.ProofTypeFlag.Name)
+		if proofType == 0 {
+			proofType = optProofType
+		}
+		if proofType == 0 {
+			proofType = int64(constant.ProofTypeOfOrigin)
+			if mos := ctx.String(config.ToMcsFlag.Name); mos != "" {
+				pt, err := orderNodeType(ctx.String(config.ToEndpointFlag.Name), common.HexToAddress(mos), from,
+					new(big.Int).SetUint64(target.BlockNumber), target.Topics[1])
+				if err != nil {
+					return fmt.Errorf("look up proof type: %w", err)
+				}
+				if pt != 0 {
+					proofType = pt
+				}
+			}
+		}
+		sign := make([][]byte, 0)
+		for _, s := range ctx.StringSlice(config.SignaturesFlag.Name) {
+			sign = append(sign, common.FromHex(s))
+		}
+
+		data, err = proffer.Proof(client, target, endpoint, proofType, from, to, sign)
+		if err != nil {
+			return fmt.Errorf("assemble proof: %w", err)
+		}
+		out.Tx = target.TxHash.Hex()
+		out.BlockNumber = target.BlockNumber
+		out.LogIndex = target.Index
+		out.ProofType = proofType
+	}
+	out.Calldata = hexutil.Encode(data)
+
+	switch format {
+	case "json":
+		decodeCalldata(&out, data)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case "calldata":
+		path := ctx.String(config.OutFlag.Name)
+		if path == "" {
+			name := out.Tx
+			if name == "" {
+				name = fmt.Sprintf("maintainer-%d-%d", from, to)
+			}
+			path = name + ".calldata"
+		}
+		if err = os.WriteFile(path, []byte(out.Calldata+"\n"), 0644); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	default:
+		fmt.Println(out.Calldata)
+	}
+	return nil
+}
+
+// chainIdOf asks endpoint for its chain id.
+func chainIdOf(endpoint string) (uint64, error) {
+	client, err := ethclient.Dial(endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("connect %s: %w", endpoint, err)
+	}
+	defer client.Close()
+	id, err := client.ChainID(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("get chain id of %s, set --from: %w", endpoint, err)
+	}
+	return id.Uint64(), nil
+}
+
+// orderNodeType asks the MOS mos of the destination chain at endpoint for the
+// proof type it verifies order orderId of chain from with.
+func orderNodeType(endpoint string, mos common.Address, from uint64, blockNumber *big.Int, orderId common.Hash) (int64, error) {
+	if endpoint == "" {
+		return 0, fmt.Errorf("--%s needs --%s", config.ToMcsFlag.Name, config.ToEndpointFlag.Name)
+	}
+	client, err := ethclient.Dial(endpoint)
+	if err != nil {
+		return 0, fmt.Errorf("connect %s: %w", endpoint, err)
+	}
+	defer client.Close()
+	input, err := mapprotocol.OracleAbi.Pack(mapprotocol.MethodOfOrderStatus, new(big.Int).SetUint64(from), blockNumber, orderId)
+	if err != nil {
+		return 0, err
+	}
+	out, err := client.CallContract(context.Background(), ethereum.CallMsg{To: &mos, Data: input}, nil)
+	if err != nil {
+		return 0, err
+	}
+	var ret chain.OrderStatusResp
+	if err = mapprotocol.OracleAbi.UnpackIntoInterface(&ret, mapprotocol.MethodOfOrderStatus, out); err != nil {
+		return 0, err
+	}
+	return ret.NodeType.Int64(), nil
+}
+
+// calldataAbis are the contracts proofs and header updates are sent to.
+var calldataAbis = []abi.ABI{
+	mapprotocol.Mcs, mapprotocol.LightManger, mapprotocol.Map2Other, mapprotocol.Bsc, mapprotocol.Matic,
+	mapprotocol.Eth2, mapprotocol.Eth2Init, mapprotocol.Conflux, mapprotocol.Klaytn, mapprotocol.Near, mapprotocol.TronAbi,
+	mapprotocol.OracleAbi, mapprotocol.Other,
+}
+
+// decodeCalldata fills the method and arguments of out from data.
+func decodeCalldata(out *proofOutput, data []byte) {
+	if len(data) < 4 {
+		out.DecodeError = "calldata shorter than a selector"
+		return
+	}
+	for _, a := range calldataAbis {
+		method, err := a.MethodById(data[:4])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.UnpackValues(data[4:])
+		if err != nil {
+			out.DecodeError = err.Error()
+			return
+		}
+		out.Method = method.Sig
+		for i, in := range method.Inputs {
+			out.Args = append(out.Args, proofArg{Name: in.Name, Type: in.Type.String(), Value: jsonValue(reflect.ValueOf(values[i]))})
+		}
+		return
+	}
+	out.DecodeError = fmt.Sprintf("unknown selector 0x%x", data[:4])
+}
+
+// jsonValue turns abi-decoded values into something json prints readably:
+// bytes and fixed byte arrays as hex, big integers as decimal strings.
+func jsonValue(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		if b, ok := v.Interface().(*big.Int); ok {
+			return b.String()
+		}
+		return jsonValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		ret := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			ret = append(ret, jsonValue(v.Index(i)))
+		}
+		return ret
+	case reflect.Struct:
+		ret := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			ret[lowerFirst(f.Name)] = jsonValue(v.Field(i))
+		}
+		return ret
+	default:
+		return v.Interface()
+	}
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
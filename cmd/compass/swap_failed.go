@@ -317,7 +317,7 @@ func attempt(httpc *http.Client, sender *senderRegistry, tx pendingTx, butterAPI
 
 	hash, err := sender.send(*chosen, logger)
 	if err != nil {
-		// Estimate / pre-exec matched constant.IgnoreError → bridge already
+		// Estimate / pre-exec classified as skippable by txerr → bridge already
 		// settled this order, no rescue needed. Mark done so we don't retry.
 		if errors.Is(err, errIgnorable) {
 			markDone(tx.ID)
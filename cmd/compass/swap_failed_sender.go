@@ -3,7 +3,6 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
-	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -17,15 +16,15 @@ import (
 	ethereum "github.com/ethereum/go-ethereum"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	tronaddress "github.com/lbtsm/gotron-sdk/pkg/address"
 	gtronclient "github.com/lbtsm/gotron-sdk/pkg/client"
+	"github.com/mapprotocol/compass/chains/tron"
 	"github.com/mapprotocol/compass/config"
 	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/txerr"
 	cpkeystore "github.com/mapprotocol/compass/pkg/keystore"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/proto"
 )
 
 // minGasPriceByChain enforces a floor for chains where validators reject very
@@ -41,23 +40,12 @@ var minGasPriceByChain = map[string]*big.Int{
 	// absurdly low, switch to a public broadcast endpoint instead of forcing.
 }
 
-// errIgnorable wraps an underlying error whose message matched one of
-// constant.IgnoreError's substrings (e.g. "order exist", "already verified").
-// Callers should treat this as success: rescue isn't needed because the
-// bridge has already settled the order on its own.
+// errIgnorable wraps an underlying error that txerr classifies as skippable
+// (e.g. "order exist", "already verified"). Callers should treat this as
+// success: rescue isn't needed because the bridge has already settled the
+// order on its own.
 var errIgnorable = errors.New("ignorable")
 
-// matchIgnore returns the first IgnoreError substring contained in msg, or ""
-// if none matches.
-func matchIgnore(msg string) string {
-	for pat := range constant.IgnoreError {
-		if pat != "" && strings.Contains(msg, pat) {
-			return pat
-		}
-	}
-	return ""
-}
-
 // senderRegistry caches per-chain ethclient connections and lazily resolves
 // the right sender (EVM vs Tron) for whatever chainId butter-api returns.
 // The same secp256k1 private key signs on every chain: tron addresses are
@@ -96,15 +84,10 @@ func newSenderRegistry(cfg *config.Config) (*senderRegistry, error) {
 		return nil, fmt.Errorf("load swap_failed_keystore: %w", err)
 	}
 
-	// Same secp256k1 key signs both EVM and Tron txs. Tron address is just
-	// the eth address with the "0x" replaced by "41" + base58check.
-	tronHex := "41" + strings.TrimPrefix(strings.ToLower(kp.Address.Hex()), "0x")
-	tronFrom := tronaddress.HexToAddress(tronHex).String()
-
 	r := &senderRegistry{
 		evmKey:       kp.PrivateKey,
 		evmFrom:      kp.Address,
-		tronFrom:     tronFrom,
+		tronFrom:     cpkeystore.TronAddress(kp),
 		evmEndpoints: make(map[string]string),
 		evmClients:   make(map[string]*ethclient.Client),
 	}
@@ -183,8 +166,10 @@ func (r *senderRegistry) sendEvm(tx txParam, logger log.Logger) (string, error)
 	})
 	if err != nil {
 		explained := explainEvmCallError(ctx, client, err, endpoint, chainID, r.evmFrom, to, value, data)
-		if pat := matchIgnore(explained); pat != "" {
-			return "", fmt.Errorf("%w (matched %q): %s", errIgnorable, pat, explained)
+		// Classify the explained error too: the replay may decode a revert
+		// reason the estimate didn't carry.
+		if ce := txerr.Classify(fmt.Errorf("%w: %s", err, explained)); ce.Category.Skip() {
+			return "", fmt.Errorf("%w (%s): %s", errIgnorable, ce.Category, explained)
 		}
 		return "", fmt.Errorf("estimate gas: %s", explained)
 	}
@@ -250,9 +235,8 @@ func (r *senderRegistry) evmClient(chainID, endpoint string) (*ethclient.Client,
 }
 
 // sendTron signs and broadcasts a tron tx using the same secp256k1 key that
-// signs EVM txs. We replicate gotron-sdk's keystore.SignTx inline (sha256 over
-// proto-marshaled RawData → crypto.Sign) so the keeper doesn't need a separate
-// tron keystore file.
+// signs EVM txs, with the signer of the tron writer, so the keeper doesn't
+// need a separate tron keystore file.
 func (r *senderRegistry) sendTron(tx txParam, logger log.Logger) (string, error) {
 	if r.tronEndpoint == "" {
 		return "", fmt.Errorf("no tron endpoint in cfg.chains")
@@ -288,8 +272,8 @@ func (r *senderRegistry) sendTron(tx txParam, logger log.Logger) (string, error)
 		if ele == "" || ele == "4,^" || ele == "0\xef" {
 			continue
 		}
-		if pat := matchIgnore(ele); pat != "" {
-			return "", fmt.Errorf("%w (matched %q): pre-exec revert: %s", errIgnorable, pat, ele)
+		if ce := txerr.Classify(errors.New("0x" + hex.EncodeToString(v))); ce.Category.Skip() {
+			return "", fmt.Errorf("%w (%s %s): pre-exec revert: %s", errIgnorable, ce.Category, ce.Reason, ele)
 		}
 		return "", fmt.Errorf("pre-exec revert: %s", ele)
 	}
@@ -307,17 +291,10 @@ func (r *senderRegistry) sendTron(tx txParam, logger log.Logger) (string, error)
 	}
 	logger.Info("tron send: tx built", "txid", hex.EncodeToString(builtTx.GetTxid()))
 
-	rawData, err := proto.Marshal(builtTx.Transaction.GetRawData())
-	if err != nil {
-		return "", fmt.Errorf("marshal raw: %w", err)
-	}
-	h := sha256.Sum256(rawData)
-	sig, err := ethcrypto.Sign(h[:], r.evmKey)
-	if err != nil {
+	if err = tron.SignTx(builtTx.Transaction, r.evmKey); err != nil {
 		return "", fmt.Errorf("sign: %w", err)
 	}
-	builtTx.Transaction.Signature = append(builtTx.Transaction.Signature, sig)
-	logger.Info("tron send: signed", "sigLen", len(sig))
+	logger.Info("tron send: signed")
 
 	result, err := cli.Broadcast(builtTx.Transaction)
 	if err != nil {
@@ -440,9 +417,9 @@ func explainEvmCallError(
 }
 
 // extractRevertReason pulls the JSON-RPC "data" field from a go-ethereum
-// EstimateGas / Call error. The data is usually ABI-encoded `Error(string)`
-// (selector 0x08c379a0), in which case we decode it; otherwise we return the
-// hex blob so the caller can inspect / replay.
+// EstimateGas / Call error and decodes it with txerr (Error(string), Panic or
+// a known custom error); otherwise we return the hex blob so the caller can
+// inspect / replay.
 func extractRevertReason(err error) (string, string, bool) {
 	type dataError interface {
 		ErrorData() interface{}
@@ -466,14 +443,8 @@ func extractRevertReason(err error) (string, string, bool) {
 	if decErr != nil {
 		return "", raw, true
 	}
-	// Error(string) selector + ABI-encoded string.
-	if len(b) >= 4+32+32 && b[0] == 0x08 && b[1] == 0xc3 && b[2] == 0x79 && b[3] == 0xa0 {
-		// offset is at b[4:36] (always 0x20), length at b[36:68], data at b[68:68+len]
-		strLen := new(big.Int).SetBytes(b[36:68]).Int64()
-		end := int64(68) + strLen
-		if end <= int64(len(b)) {
-			return string(b[68:end]), raw, true
-		}
+	if reason, ok := txerr.Decode(b); ok {
+		return reason, raw, true
 	}
 	return "", raw, true
 }
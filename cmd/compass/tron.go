@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	ethkeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/mapprotocol/compass/chains/tron"
+	"github.com/mapprotocol/compass/config"
+	cpkeystore "github.com/mapprotocol/compass/pkg/keystore"
+	"github.com/urfave/cli/v2"
+)
+
+var tronCommand = cli.Command{
+	Name:  "tron",
+	Usage: "manage the key of the tron chains",
+	Subcommands: []*cli.Command{
+		&tronConvertCommand,
+		&tronAddressCommand,
+	},
+}
+
+var tronConvertCommand = cli.Command{
+	Name:  "convert",
+	Usage: "convert a key of the gotron keystore into an Ethereum keystore file",
+	Description: "Tron chains sign with an Ethereum keystore file like the other chains, the Tron address is derived from its key.\n" +
+		"\tcompass tron convert --address T.. --out keys/tron.json",
+	Action: tronConvert,
+	Flags: []cli.Flag{
+		config.TronAddressFlag,
+		config.KeyOutFlag,
+	},
+}
+
+var tronAddressCommand = cli.Command{
+	Name:        "address",
+	Usage:       "print the Ethereum and Tron addresses of an Ethereum keystore file",
+	Description: "\tcompass tron address --keystorePath keys/tron.json",
+	Action:      tronAddress,
+	Flags: []cli.Flag{
+		config.KeyPathFlag,
+	},
+}
+
+func tronConvert(ctx *cli.Context) error {
+	from := ctx.String(config.TronAddressFlag.Name)
+	out := ctx.String(config.KeyOutFlag.Name)
+	if _, err := os.Stat(out); err == nil {
+		return fmt.Errorf("%s exists, not overwriting it", out)
+	}
+	keyjson, err := tron.KeyFileOf(from)
+	if err != nil {
+		return err
+	}
+	if keyjson == nil {
+		return fmt.Errorf("could not find %s in keystore", from)
+	}
+
+	pass := cpkeystore.GetPassword(fmt.Sprintf("Enter password for key %s:", from))
+	newPass := cpkeystore.GetPassword(fmt.Sprintf("Enter password for %s:", out))
+	if string(cpkeystore.GetPassword("Repeat it:")) != string(newPass) {
+		return errors.New("passwords do not match")
+	}
+	ethjson, kp, err := cpkeystore.EthKeyFromTron(keyjson, string(pass), string(newPass), ethkeystore.StandardScryptN, ethkeystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(out, ethjson, 0600); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\nEthereum address: %s\nTron address:     %s\n", out, kp.Address.Hex(), cpkeystore.TronAddress(kp))
+	return nil
+}
+
+func tronAddress(ctx *cli.Context) error {
+	path := ctx.String(config.KeyPathFlag.Name)
+	if path == "" {
+		return fmt.Errorf("--%s is required", config.KeyPathFlag.Name)
+	}
+	kp, err := cpkeystore.KeypairFromEth(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Ethereum address: %s\nTron address:     %s\n", kp.Address.Hex(), cpkeystore.TronAddress(kp))
+	return nil
+}
@@ -35,20 +35,27 @@ type RawChainConfig struct {
 }
 
 type Construction struct {
-	MonitorUrl             string `json:"monitor_url,omitempty"`
-	Env                    string `json:"env,omitempty"`
-	BlackListUrl           string `json:"black_list_url"`
-	Filter                 string `json:"filter"`
-	FilterAPIKey           string `json:"filter_api_key,omitempty"`
-	BtcUrl                 string `json:"btc_url"`
-	Butter                 string `json:"butter"`
-	ButterAPIKey           string `json:"butter_api_key,omitempty"`
-	Price                  string `json:"price"`
-	ReportUrl              string `json:"report_url,omitempty"`
-	ObservabilityAddr      string `json:"observability_addr,omitempty"`
-	SwapFailedKeystore     string `json:"swap_failed_keystore,omitempty"`
-	SwapFailedTronAddress  string `json:"swap_failed_tron_address,omitempty"`
-	SwapFailedTronPassword string `json:"swap_failed_tron_password,omitempty"`
+	MonitorUrl          string `json:"monitor_url,omitempty"`
+	Env                 string `json:"env,omitempty"`
+	BlackListUrl        string `json:"black_list_url"`
+	Filter              string `json:"filter"`
+	FilterAPIKey        string `json:"filter_api_key,omitempty"`
+	BtcUrl              string `json:"btc_url"`
+	Butter              string `json:"butter"`
+	ButterAPIKey        string `json:"butter_api_key,omitempty"`
+	Price               string `json:"price"`
+	ReportUrl           string `json:"report_url,omitempty"`
+	ObservabilityAddr   string `json:"observability_addr,omitempty"`
+	SwapFailedKeystore  string `json:"swap_failed_keystore,omitempty"`
+	ReceiptCacheSize    int    `json:"receipt_cache_size,omitempty"`   // blocks kept in memory
+	ReceiptCacheTTL     int64  `json:"receipt_cache_ttl,omitempty"`    // seconds
+	ReceiptCacheDir     string `json:"receipt_cache_dir,omitempty"`    // enables the on-disk tier
+	ZkUrl               string `json:"zk_url,omitempty"`               // zk prover endpoint, "mock" for the local stand-in
+	ZkPrefetch          int    `json:"zk_prefetch,omitempty"`          // heights of the map chain fetched ahead
+	ZkCacheSize         int    `json:"zk_cache_size,omitempty"`        // proofs kept in memory
+	ZkWait              int64  `json:"zk_wait,omitempty"`              // seconds to wait for an unfinished proof, 0 waits until done
+	LightClientInterval int64  `json:"lightclient_interval,omitempty"` // seconds between light client height checks
+	LightClientStall    int64  `json:"lightclient_stall,omitempty"`    // seconds a light client may stay put while its source advances
 }
 
 func (c *Config) ToJSON(file string) *os.File {
@@ -115,3 +115,153 @@ var (
 		Usage: "Applies a predetermined test keystore to the chains.",
 	}
 )
+
+// flags of the proof command
+var (
+	ChainTypeFlag = &cli.StringFlag{
+		Name:     "type",
+		Usage:    "Chain type of the source chain (ethereum, bsc, matic, eth2, tron, evm)",
+		Required: true,
+	}
+	EndpointFlag = &cli.StringFlag{
+		Name:     "endpoint",
+		Usage:    "RPC endpoint of the source chain",
+		Required: true,
+	}
+	FromChainFlag = &cli.Uint64Flag{
+		Name:  "from",
+		Usage: "Source chain id, read from the endpoint when not set",
+	}
+	TxHashFlag = &cli.StringFlag{
+		Name:  "tx",
+		Usage: "Hash of the source transaction",
+	}
+	LogIndexFlag = &cli.UintFlag{
+		Name:  "log-index",
+		Usage: "Block-level index of the event log to prove",
+	}
+	ToChainFlag = &cli.Uint64Flag{
+		Name:     "to",
+		Usage:    "Destination chain id",
+		Required: true,
+	}
+	ProofTypeFlag = &cli.Int64Flag{
+		Name:  "proof-type",
+		Usage: "Proof type (1 origin, 2 zk, 3 oracle, 4 mpt oracle, 5 log oracle). Asked from --to-mcs when not set, else 1",
+	}
+	McsFlag = &cli.StringFlag{
+		Name:  "mcs",
+		Usage: "MOS contract of the source chain",
+	}
+	ToMcsFlag = &cli.StringFlag{
+		Name:  "to-mcs",
+		Usage: "MOS contract of the destination chain, asked for the proof type",
+	}
+	ToEndpointFlag = &cli.StringFlag{
+		Name:  "to-endpoint",
+		Usage: "RPC endpoint of the destination chain, needed by --to-mcs",
+	}
+	LightNodeFlag = &cli.StringFlag{
+		Name:  "light-node",
+		Usage: "Light client contract, needed by --maintainer",
+	}
+	OracleNodeFlag = &cli.StringFlag{
+		Name:  "oracle-node",
+		Usage: "Oracle contract of the source chain",
+	}
+	SignaturesFlag = &cli.StringSliceFlag{
+		Name:  "sign",
+		Usage: "Hex oracle signatures to embed in oracle proofs",
+	}
+	MaintainerFlag = &cli.BoolFlag{
+		Name:  "maintainer",
+		Usage: "Assemble the light client header update instead of a proof",
+	}
+	FormatFlag = &cli.StringFlag{
+		Name:  "format",
+		Usage: "Output format: hex, json or calldata",
+		Value: "hex",
+	}
+	OutFlag = &cli.StringFlag{
+		Name:  "out",
+		Usage: "File written by --format calldata, defaults to <tx>.calldata",
+	}
+	ReceiptEncodingFlag = &cli.StringFlag{
+		Name:  "receipt-encoding",
+		Usage: "Receipt family of the source chain (ethereum, optimism, arbitrum, matic), defaults to the built-in one",
+	}
+	ZkUrlFlag = &cli.StringFlag{
+		Name:  "zk-url",
+		Usage: "ZK prover endpoint for zk proofs (--proof-type 2), or mock for the deterministic local prover",
+	}
+	ChainOptFlag = &cli.StringSliceFlag{
+		Name:  "opt",
+		Usage: "Chain option of the source chain as key=value, e.g. --opt headerFormat=raw for the evm type",
+	}
+)
+
+// flags of the eth2 command
+var (
+	BeaconFlag = &cli.StringFlag{
+		Name:     "beacon",
+		Usage:    "Beacon node endpoints, comma separated",
+		Required: true,
+	}
+	CheckpointFlag = &cli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "Trusted finalized block root to bootstrap from",
+	}
+	FinalizedFlag = &cli.BoolFlag{
+		Name:  "finalized",
+		Usage: "Bootstrap from the latest finalized block root of the beacon",
+	}
+	Eth2ChainIdFlag = &cli.Uint64Flag{
+		Name:  "chain-id",
+		Usage: "Chain id of the eth2 chain the light client follows",
+		Value: 1,
+	}
+	ControllerFlag = &cli.StringFlag{
+		Name:     "controller",
+		Usage:    "Controller of the light client",
+		Required: true,
+	}
+	MptVerifyFlag = &cli.StringFlag{
+		Name:     "mpt-verify",
+		Usage:    "MPT verifier contract the light client uses",
+		Required: true,
+	}
+	MapEndpointFlag = &cli.StringFlag{
+		Name:  "map-endpoint",
+		Usage: "RPC endpoint of MAP, needed by --submit and to compare with the light client",
+	}
+	SubmitFlag = &cli.BoolFlag{
+		Name:  "submit",
+		Usage: "Send the initialization to --light-node with the key of --keystorePath",
+	}
+)
+
+// flags of the tron command
+var (
+	TronAddressFlag = &cli.StringFlag{
+		Name:     "address",
+		Usage:    "Base58 address of the key in the gotron keystore (~/.tronctl)",
+		Required: true,
+	}
+	KeyOutFlag = &cli.StringFlag{
+		Name:     "out",
+		Usage:    "Ethereum keystore file written, used as the keystorePath of the tron chain",
+		Required: true,
+	}
+)
+
+// flags of the held command
+var (
+	HeldChainFlag = &cli.StringFlag{
+		Name:  "chain",
+		Usage: "Name of the chain in the config",
+	}
+	HeldIndexFlag = &cli.IntFlag{
+		Name:  "index",
+		Usage: "Index of the held delivery, as compass held list prints it",
+	}
+)
@@ -29,6 +29,21 @@ func NewConnection(endpoint, eth2Endpoint string, http bool, kp *keystore.Key, l
 	}
 }
 
+// SetGasStrategy forwards to the embedded execution-layer connection.
+func (c *Connection) SetGasStrategy(chain string, s ethereum.GasStrategy) {
+	if gc, ok := c.Connection.(*ethereum.Connection); ok {
+		gc.SetGasStrategy(chain, s)
+	}
+}
+
+// GasMultiplier forwards to the embedded execution-layer connection.
+func (c *Connection) GasMultiplier() *big.Float {
+	if gc, ok := c.Connection.(*ethereum.Connection); ok {
+		return gc.GasMultiplier()
+	}
+	return nil
+}
+
 func (c *Connection) Eth2Client() *eth2.Client {
 	return c.eth2Conn
 }
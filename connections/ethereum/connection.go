@@ -23,6 +23,7 @@ import (
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/observability"
 	"github.com/mapprotocol/compass/pkg/ethclient"
 )
 
@@ -33,6 +34,8 @@ type Connection struct {
 	gasLimit                  *big.Int
 	maxGasPrice               *big.Int
 	gasMultiplier             *big.Float
+	gasStrategy               GasStrategy
+	gasState                  *observability.GasState
 	conn                      *ethclient.Client
 	opts                      *bind.TransactOpts
 	callOpts                  *bind.CallOpts
@@ -54,11 +57,24 @@ func NewConnection(endpoint string, http bool, kp *keystore.Key, log log15.Logge
 		gasLimit:      gasLimit,
 		maxGasPrice:   gasPrice,
 		gasMultiplier: bigFloat,
+		gasStrategy:   &nodeGas{multiplier: bigFloat},
 		log:           log,
 		stop:          make(chan int),
 	}
 }
 
+// SetGasStrategy replaces the default node-suggestion pricing. chain labels
+// the gas price metrics.
+func (c *Connection) SetGasStrategy(chain string, s GasStrategy) {
+	c.gasStrategy = s
+	c.gasState = observability.RegisterGas(chain, s.Name())
+}
+
+// GasMultiplier returns the multiplier strategies built for this connection should use.
+func (c *Connection) GasMultiplier() *big.Float {
+	return c.gasMultiplier
+}
+
 // Connect starts the ethereum WS connection
 func (c *Connection) Connect() error {
 	var rpcClient *rpc.Client
@@ -148,17 +164,12 @@ func (c *Connection) CallOpts() *bind.CallOpts {
 }
 
 func (c *Connection) SafeEstimateGas(ctx context.Context) (*big.Int, error) {
-	var suggestedGasPrice *big.Int
-	c.log.Debug("Fetching gasPrice from node")
-	nodePriceEstimate, err := c.conn.SuggestGasPrice(context.TODO())
+	c.log.Debug("Fetching gasPrice", "strategy", c.gasStrategy.Name())
+	gasPrice, err := c.gasStrategy.GasPrice(ctx, c.conn)
 	if err != nil {
 		return nil, err
-	} else {
-		suggestedGasPrice = nodePriceEstimate
 	}
 
-	gasPrice := multiplyGasPrice(suggestedGasPrice, c.gasMultiplier)
-
 	// Check we aren't exceeding our limit
 	if gasPrice.Cmp(c.maxGasPrice) == 1 {
 		return c.maxGasPrice, nil
@@ -175,23 +186,16 @@ func (c *Connection) EstimateGasLondon(ctx context.Context, baseFee *big.Int) (*
 		return maxPriorityFeePerGas, maxFeePerGas, nil
 	}
 
-	maxPriorityFeePerGas, err := c.conn.SuggestGasTipCap(context.TODO())
+	maxPriorityFeePerGas, maxFeePerGas, err := c.gasStrategy.London(ctx, c.conn, baseFee)
 	if err != nil {
 		return nil, nil, err
 	}
-	c.log.Info("EstimateGasLondon", "maxPriorityFeePerGas", maxPriorityFeePerGas)
-
-	maxFeePerGas = new(big.Int).Add(
-		maxPriorityFeePerGas,
-		baseFee,
-	)
-	// mul
-	maxFeePerGas = multiplyGasPrice(maxFeePerGas, c.gasMultiplier)
+	c.log.Info("EstimateGasLondon", "strategy", c.gasStrategy.Name(), "maxPriorityFeePerGas", maxPriorityFeePerGas)
 
 	// Check we aren't exceeding our limit
 	if maxFeePerGas.Cmp(c.maxGasPrice) == 1 {
 		c.log.Info("EstimateGasLondon maxFeePerGas more than set", "maxFeePerGas", maxFeePerGas, "baseFee", baseFee)
-		maxPriorityFeePerGas.Sub(c.maxGasPrice, baseFee)
+		maxPriorityFeePerGas = new(big.Int).Sub(c.maxGasPrice, baseFee)
 		maxFeePerGas = c.maxGasPrice
 	}
 	return maxPriorityFeePerGas, maxFeePerGas, nil
@@ -231,6 +235,9 @@ func (c *Connection) LockAndUpdateOpts(needNewNonce bool) error {
 		}
 		c.log.Info("LockAndUpdateOpts ", "head.BaseFee", head.BaseFee, "maxGasPrice", c.maxGasPrice,
 			"gasTipCap", c.opts.GasTipCap, "gasFeeCap", c.opts.GasFeeCap)
+		c.gasState.SetPrice("base_fee", head.BaseFee)
+		c.gasState.SetPrice("tip", c.opts.GasTipCap)
+		c.gasState.SetPrice("fee_cap", c.opts.GasFeeCap)
 	} else {
 		var gasPrice *big.Int
 		gasPrice, err = c.SafeEstimateGas(context.TODO())
@@ -240,6 +247,7 @@ func (c *Connection) LockAndUpdateOpts(needNewNonce bool) error {
 		}
 		c.opts.GasPrice = gasPrice
 	}
+	c.gasState.SetPrice("gas_price", c.opts.GasPrice)
 
 	if !needNewNonce {
 		return nil
@@ -0,0 +1,207 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/mapprotocol/compass/pkg/ethclient"
+)
+
+// Supported values of the gasStrategy chain option
+const (
+	GasStrategyNode       = "node"
+	GasStrategyFeeHistory = "feeHistory"
+	GasStrategyFixed      = "fixed"
+	GasStrategyTipBounds  = "tipBounds"
+)
+
+const (
+	DefaultFeeHistoryBlocks     = 10
+	DefaultFeeHistoryPercentile = 50
+)
+
+// GasConfig selects and parameterises the gas pricing strategy of a chain.
+type GasConfig struct {
+	Strategy             string
+	FeeHistoryBlocks     uint64   // feeHistory: how many recent blocks to sample
+	FeeHistoryPercentile float64  // feeHistory: reward percentile used as tip
+	FixedPrice           *big.Int // fixed: legacy gas price / London fee cap
+	FixedTip             *big.Int // fixed: London tip, defaults to FixedPrice - baseFee
+	TipFloor             *big.Int // tipBounds: minimum tip
+	TipCeiling           *big.Int // tipBounds: maximum tip
+}
+
+// GasStrategy prices transactions for one chain. The connection still caps the
+// result at maxGasPrice, so strategies only decide what they would like to pay.
+type GasStrategy interface {
+	Name() string
+	// GasPrice returns the price of a legacy transaction
+	GasPrice(ctx context.Context, cli *ethclient.Client) (*big.Int, error)
+	// London returns the tip and fee cap of a dynamic fee transaction
+	London(ctx context.Context, cli *ethclient.Client, baseFee *big.Int) (*big.Int, *big.Int, error)
+}
+
+// NewGasStrategy builds the strategy described by cfg. An empty strategy name
+// keeps the historic node-suggestion behaviour.
+func NewGasStrategy(cfg GasConfig, multiplier *big.Float) (GasStrategy, error) {
+	if multiplier == nil {
+		multiplier = big.NewFloat(1)
+	}
+	node := &nodeGas{multiplier: multiplier}
+	switch cfg.Strategy {
+	case "", GasStrategyNode:
+		return node, nil
+	case GasStrategyFeeHistory:
+		blocks, percentile := cfg.FeeHistoryBlocks, cfg.FeeHistoryPercentile
+		if blocks == 0 {
+			blocks = DefaultFeeHistoryBlocks
+		}
+		if percentile <= 0 {
+			percentile = DefaultFeeHistoryPercentile
+		}
+		if percentile > 100 {
+			return nil, fmt.Errorf("feeHistory percentile %v out of range", percentile)
+		}
+		return &feeHistoryGas{node: node, blocks: blocks, percentile: percentile}, nil
+	case GasStrategyFixed:
+		if cfg.FixedPrice == nil || cfg.FixedPrice.Sign() <= 0 {
+			return nil, fmt.Errorf("gas strategy %s requires a fixed price", GasStrategyFixed)
+		}
+		return &fixedGas{price: cfg.FixedPrice, tip: cfg.FixedTip}, nil
+	case GasStrategyTipBounds:
+		if cfg.TipFloor == nil && cfg.TipCeiling == nil {
+			return nil, fmt.Errorf("gas strategy %s requires a tip floor or ceiling", GasStrategyTipBounds)
+		}
+		if cfg.TipFloor != nil && cfg.TipCeiling != nil && cfg.TipFloor.Cmp(cfg.TipCeiling) > 0 {
+			return nil, fmt.Errorf("tip floor %s above ceiling %s", cfg.TipFloor, cfg.TipCeiling)
+		}
+		return &tipBoundsGas{node: node, floor: cfg.TipFloor, ceiling: cfg.TipCeiling}, nil
+	default:
+		return nil, fmt.Errorf("unknown gas strategy %q", cfg.Strategy)
+	}
+}
+
+// nodeGas trusts eth_gasPrice / eth_maxPriorityFeePerGas scaled by gasMultiplier.
+type nodeGas struct {
+	multiplier *big.Float
+}
+
+func (n *nodeGas) Name() string { return GasStrategyNode }
+
+func (n *nodeGas) GasPrice(ctx context.Context, cli *ethclient.Client) (*big.Int, error) {
+	price, err := cli.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return multiplyGasPrice(price, n.multiplier), nil
+}
+
+func (n *nodeGas) London(ctx context.Context, cli *ethclient.Client, baseFee *big.Int) (*big.Int, *big.Int, error) {
+	tip, err := cli.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tip, n.feeCap(tip, baseFee), nil
+}
+
+func (n *nodeGas) feeCap(tip, baseFee *big.Int) *big.Int {
+	return multiplyGasPrice(new(big.Int).Add(tip, baseFee), n.multiplier)
+}
+
+// feeHistoryGas uses a percentile of the tips paid in recent blocks, which is
+// steadier than a single node's eth_maxPriorityFeePerGas.
+type feeHistoryGas struct {
+	node       *nodeGas
+	blocks     uint64
+	percentile float64
+}
+
+func (f *feeHistoryGas) Name() string { return GasStrategyFeeHistory }
+
+// GasPrice falls back to the node suggestion: chains without a base fee have
+// no meaningful fee history.
+func (f *feeHistoryGas) GasPrice(ctx context.Context, cli *ethclient.Client) (*big.Int, error) {
+	return f.node.GasPrice(ctx, cli)
+}
+
+func (f *feeHistoryGas) London(ctx context.Context, cli *ethclient.Client, baseFee *big.Int) (*big.Int, *big.Int, error) {
+	fh, err := cli.FeeHistory(ctx, f.blocks, nil, []float64{f.percentile})
+	if err != nil {
+		return nil, nil, err
+	}
+	tip := averageReward(fh.Reward)
+	if tip == nil {
+		return f.node.London(ctx, cli, baseFee)
+	}
+	// the trailing entry is the base fee of the next block
+	if n := len(fh.BaseFee); n > 0 && fh.BaseFee[n-1].Cmp(baseFee) > 0 {
+		baseFee = fh.BaseFee[n-1]
+	}
+	return tip, f.node.feeCap(tip, baseFee), nil
+}
+
+// averageReward averages the first percentile column over blocks that had
+// transactions; nil if none did.
+func averageReward(rewards [][]*big.Int) *big.Int {
+	sum, count := new(big.Int), int64(0)
+	for _, r := range rewards {
+		if len(r) == 0 || r[0] == nil || r[0].Sign() == 0 {
+			continue
+		}
+		sum.Add(sum, r[0])
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	return sum.Div(sum, big.NewInt(count))
+}
+
+// fixedGas always bids the configured price.
+type fixedGas struct {
+	price, tip *big.Int
+}
+
+func (f *fixedGas) Name() string { return GasStrategyFixed }
+
+func (f *fixedGas) GasPrice(context.Context, *ethclient.Client) (*big.Int, error) {
+	return new(big.Int).Set(f.price), nil
+}
+
+func (f *fixedGas) London(_ context.Context, _ *ethclient.Client, baseFee *big.Int) (*big.Int, *big.Int, error) {
+	tip := f.tip
+	if tip == nil {
+		tip = new(big.Int).Sub(f.price, baseFee)
+		if tip.Sign() < 0 {
+			tip = new(big.Int)
+		}
+	}
+	return new(big.Int).Set(tip), new(big.Int).Set(f.price), nil
+}
+
+// tipBoundsGas takes the node suggestion but keeps the tip inside [floor, ceiling].
+type tipBoundsGas struct {
+	node           *nodeGas
+	floor, ceiling *big.Int
+}
+
+func (t *tipBoundsGas) Name() string { return GasStrategyTipBounds }
+
+func (t *tipBoundsGas) GasPrice(ctx context.Context, cli *ethclient.Client) (*big.Int, error) {
+	return t.node.GasPrice(ctx, cli)
+}
+
+func (t *tipBoundsGas) London(ctx context.Context, cli *ethclient.Client, baseFee *big.Int) (*big.Int, *big.Int, error) {
+	tip, err := cli.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if t.floor != nil && tip.Cmp(t.floor) < 0 {
+		tip = new(big.Int).Set(t.floor)
+	}
+	if t.ceiling != nil && tip.Cmp(t.ceiling) > 0 {
+		tip = new(big.Int).Set(t.ceiling)
+	}
+	return tip, t.node.feeCap(tip, baseFee), nil
+}
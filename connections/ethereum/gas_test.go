@@ -0,0 +1,95 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+)
+
+// newStubClient answers the JSON-RPC methods in results with canned values.
+func newStubClient(t *testing.T, results map[string]interface{}) *ethclient.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if res, ok := results[req.Method]; ok {
+			resp["result"] = res
+		} else {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := rpc.DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatalf("dial stub: %v", err)
+	}
+	return ethclient.NewClient(c, srv.URL, srv.Client())
+}
+
+func TestFeeHistoryGas_UsesPercentileAndNextBaseFee(t *testing.T) {
+	cli := newStubClient(t, map[string]interface{}{
+		"eth_feeHistory": map[string]interface{}{
+			"oldestBlock":   "0x10",
+			"reward":        [][]string{{"0x64"}, {"0x0"}, {"0xc8"}}, // 100, empty block, 200
+			"baseFeePerGas": []string{"0x3e8", "0x3e8", "0x3e8", "0x7d0"},
+			"gasUsedRatio":  []float64{0.5, 0, 0.9},
+		},
+	})
+	s, err := NewGasStrategy(GasConfig{Strategy: GasStrategyFeeHistory}, big.NewFloat(1))
+	if err != nil {
+		t.Fatalf("NewGasStrategy: %v", err)
+	}
+	tip, feeCap, err := s.London(context.Background(), cli, big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("London: %v", err)
+	}
+	if tip.Int64() != 150 {
+		t.Fatalf("tip = %v, want 150", tip)
+	}
+	if feeCap.Int64() != 2150 {
+		t.Fatalf("feeCap = %v, want 2150", feeCap)
+	}
+}
+
+func TestTipBoundsGas_ClampsTip(t *testing.T) {
+	cli := newStubClient(t, map[string]interface{}{"eth_maxPriorityFeePerGas": "0x1"})
+	s, err := NewGasStrategy(GasConfig{Strategy: GasStrategyTipBounds, TipFloor: big.NewInt(10), TipCeiling: big.NewInt(20)}, big.NewFloat(2))
+	if err != nil {
+		t.Fatalf("NewGasStrategy: %v", err)
+	}
+	tip, feeCap, err := s.London(context.Background(), cli, big.NewInt(100))
+	if err != nil {
+		t.Fatalf("London: %v", err)
+	}
+	if tip.Int64() != 10 || feeCap.Int64() != 220 {
+		t.Fatalf("tip, feeCap = %v, %v, want 10, 220", tip, feeCap)
+	}
+}
+
+func TestFixedGas(t *testing.T) {
+	s, err := NewGasStrategy(GasConfig{Strategy: GasStrategyFixed, FixedPrice: big.NewInt(500)}, nil)
+	if err != nil {
+		t.Fatalf("NewGasStrategy: %v", err)
+	}
+	tip, feeCap, _ := s.London(context.Background(), nil, big.NewInt(300))
+	if tip.Int64() != 200 || feeCap.Int64() != 500 {
+		t.Fatalf("tip, feeCap = %v, %v, want 200, 500", tip, feeCap)
+	}
+	if _, err := NewGasStrategy(GasConfig{Strategy: GasStrategyFixed}, nil); err == nil {
+		t.Fatal("fixed strategy without a price should be rejected")
+	}
+	if _, err := NewGasStrategy(GasConfig{Strategy: "bogus"}, nil); err == nil {
+		t.Fatal("unknown strategy should be rejected")
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"math/big"
-	"sync"
 	"time"
 
 	"github.com/ChainSafe/log15"
-	"github.com/mapprotocol/atlas/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/compass/pkg/ethclient"
 	nearclient "github.com/mapprotocol/near-api-go/pkg/client"
 	"github.com/mapprotocol/near-api-go/pkg/client/block"
 	"github.com/mapprotocol/near-api-go/pkg/types/key"
@@ -24,10 +26,6 @@ type Connection struct {
 	maxGasPrice   *big.Int
 	gasMultiplier *big.Float
 	conn          *nearclient.Client
-	opts          *bind.TransactOpts
-	callOpts      *bind.CallOpts
-	nonce         uint64
-	optsLock      sync.Mutex
 	log           log15.Logger
 	stop          chan int // All routines should exit when this channel is closed
 }
@@ -65,20 +63,30 @@ func (c *Connection) Connect() error {
 	return nil
 }
 
-func (c *Connection) Keypair() *key.KeyPair {
+// Keypair is nil, near accounts sign with NearKeypair.
+func (c *Connection) Keypair() *keystore.Key {
+	return nil
+}
+
+func (c *Connection) NearKeypair() *key.KeyPair {
 	return c.kp
 }
 
-func (c *Connection) Client() *nearclient.Client {
+// Client is nil, near is reached through NearClient.
+func (c *Connection) Client() *ethclient.Client {
+	return nil
+}
+
+func (c *Connection) NearClient() *nearclient.Client {
 	return c.conn
 }
 
 func (c *Connection) Opts() *bind.TransactOpts {
-	return c.opts
+	return nil
 }
 
 func (c *Connection) CallOpts() *bind.CallOpts {
-	return c.callOpts
+	return nil
 }
 
 func (c *Connection) SafeEstimateGas(ctx context.Context) (*big.Int, error) {
@@ -125,7 +133,7 @@ func (c *Connection) LatestBlock() (*big.Int, error) {
 }
 
 // EnsureHasBytecode asserts if contract code exists at the specified address
-func (c *Connection) EnsureHasBytecode(addr string) error {
+func (c *Connection) EnsureHasBytecode(addr common.Address) error {
 	return nil
 }
 
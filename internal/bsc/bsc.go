@@ -7,14 +7,13 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/rlp"
 	maptypes "github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/mapo"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
-	"github.com/mapprotocol/compass/internal/op"
 	"github.com/mapprotocol/compass/internal/proof"
 	iproof "github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/pkg/ethclient"
 	"github.com/mapprotocol/compass/pkg/msg"
 )
@@ -112,22 +111,25 @@ type ReceiptProof struct {
 	ReceiptProof proof.NewReceiptProof
 }
 
-func AssembleProof(header []Header, log *types.Log, receipts []*types.Receipt, method string,
+func AssembleProof(src ireceipt.Source, header []Header, log *types.Log, receipts []*types.Receipt, method string,
 	fId msg.ChainId, proofType int64, sign [][]byte) ([]byte, error) {
 	var ret []byte
 	txIndex := log.TxIndex
 
-	pr := op.Receipts{}
-	for _, r := range receipts {
-		pr = append(pr, &op.Receipt{Receipt: r})
+	pr, err := ireceipt.Encode(src, fId, ireceipt.Ethereum, receipts)
+	if err != nil {
+		return nil, err
+	}
+	var prf [][]byte
+	if proofType == constant.ProofTypeOfLogOracle {
+		prf, err = iproof.Get(pr, txIndex)
+	} else {
+		prf, err = iproof.GetVerified(fId, common.BytesToHash(header[0].ReceiptsRoot), pr, txIndex)
 	}
-
-	prf, err := iproof.Get(pr, txIndex)
 	if err != nil {
 		return nil, err
 	}
-	var key []byte
-	key = rlp.AppendUint64(key[:0], uint64(txIndex))
+	key := iproof.Key(pr, txIndex)
 	switch proofType {
 	case constant.ProofTypeOfOrigin:
 		receipt, err := mapprotocol.GetTxReceipt(receipts[txIndex])
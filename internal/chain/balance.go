@@ -0,0 +1,277 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/util"
+)
+
+const (
+	EvmDecimals  = 18
+	TronDecimals = 6
+	SolDecimals  = 9
+
+	// balanceCostWindow is how many recent relay costs feed the relays-left estimate
+	balanceCostWindow = 20
+	// balanceRealarm is how often an unchanged low-balance condition is re-reported
+	balanceRealarm = 30 * time.Minute
+)
+
+// balanceAlarm is a seam for tests.
+var balanceAlarm = util.Alarm
+
+// BalanceFetcher returns the native balance of the relayer account in the
+// chain's smallest unit (wei, sun, lamports).
+type BalanceFetcher func(ctx context.Context) (*big.Int, error)
+
+type balanceLevel int
+
+const (
+	balanceOk balanceLevel = iota
+	balanceWarn
+	balanceCritical
+)
+
+func (l balanceLevel) String() string {
+	switch l {
+	case balanceWarn:
+		return "low"
+	case balanceCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+// BalanceWatcher polls the balance of a chain's From account, publishes it as
+// metrics, estimates how many relays it still covers from recent tx costs and
+// alarms when it crosses the configured thresholds. With BalancePause set the
+// writer holds sends via WaitUntilFunded while the balance is critical.
+// All methods are nil-safe; a nil watcher never pauses.
+type BalanceWatcher struct {
+	name, address string
+	decimals      int
+	warn          *big.Int
+	critical      *big.Int
+	minRelays     int64
+	pause         bool
+	interval      time.Duration
+	fetch         BalanceFetcher
+	state         *observability.AccountState
+	log           log15.Logger
+
+	mu          sync.RWMutex
+	balance     *big.Int
+	costs       []*big.Int
+	level       balanceLevel
+	paused      bool
+	lastAlarmed time.Time
+	startOnce   sync.Once
+}
+
+// NewBalanceWatcher builds a watcher for address on the chain described by cfg.
+func NewBalanceWatcher(cfg *Config, address string, decimals int, fetch BalanceFetcher, log log15.Logger) *BalanceWatcher {
+	interval := cfg.BalanceInterval
+	if interval <= 0 {
+		interval = DefaultBalanceInterval
+	}
+	return &BalanceWatcher{
+		name:      cfg.Name,
+		address:   address,
+		decimals:  decimals,
+		warn:      toBaseUnits(cfg.BalanceWarn, decimals),
+		critical:  toBaseUnits(cfg.BalanceCritical, decimals),
+		minRelays: cfg.BalanceMinRelays,
+		pause:     cfg.BalancePause,
+		interval:  interval,
+		fetch:     fetch,
+		state:     observability.RegisterAccount(cfg.Name, address),
+		log:       log,
+	}
+}
+
+// Start polls the balance every interval until stop is closed. Calling it more
+// than once is a no-op.
+func (b *BalanceWatcher) Start(stop <-chan int) {
+	if b == nil {
+		return
+	}
+	b.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(b.interval)
+			defer ticker.Stop()
+			for {
+				if err := b.Check(context.Background()); err != nil {
+					b.log.Warn("Balance check failed", "address", b.address, "err", err)
+				}
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+// Check fetches the balance once, refreshes the metrics and fires alarms on
+// threshold transitions.
+func (b *BalanceWatcher) Check(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	balance, err := b.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	b.balance = balance
+	left := b.relaysLeftLocked()
+	level := b.levelOf(balance, left)
+	prev, wasPaused := b.level, b.paused
+	b.level = level
+	b.paused = b.pause && level == balanceCritical
+	now := time.Now()
+	notify := level > prev || (level != balanceOk && now.Sub(b.lastAlarmed) >= balanceRealarm)
+	if notify {
+		b.lastAlarmed = now
+	}
+	paused := b.paused
+	b.mu.Unlock()
+
+	b.state.SetBalance(b.toFloat(balance))
+	b.state.SetRelaysLeft(left)
+	b.state.SetPaused(paused)
+
+	if notify {
+		b.log.Warn("Relayer balance is "+level.String(), "address", b.address, "balance", b.toFloat(balance), "relaysLeft", left)
+		balanceAlarm(ctx, fmt.Sprintf("%s relayer %s balance %s: %s left, about %d relays remaining, paused=%v",
+			b.name, b.address, level, b.format(balance), left, paused))
+	}
+	if wasPaused && !paused {
+		b.log.Info("Relayer refunded, resuming sends", "address", b.address, "balance", b.toFloat(balance))
+		balanceAlarm(ctx, fmt.Sprintf("%s relayer %s refunded (%s), sends resumed", b.name, b.address, b.format(balance)))
+	}
+	return nil
+}
+
+// RecordCost adds the native cost of a confirmed relay to the rolling window
+// used for the relays-left estimate.
+func (b *BalanceWatcher) RecordCost(cost *big.Int) {
+	if b == nil || cost == nil || cost.Sign() <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.costs = append(b.costs, new(big.Int).Set(cost))
+	if len(b.costs) > balanceCostWindow {
+		b.costs = b.costs[len(b.costs)-balanceCostWindow:]
+	}
+	if b.balance != nil {
+		b.balance = new(big.Int).Sub(b.balance, cost)
+	}
+	left := b.relaysLeftLocked()
+	b.mu.Unlock()
+	b.state.SetRelaysLeft(left)
+}
+
+// RelaysLeft estimates how many relays the last known balance covers at the
+// recent average cost. -1 means no balance or cost has been observed yet.
+func (b *BalanceWatcher) RelaysLeft() int64 {
+	if b == nil {
+		return -1
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.relaysLeftLocked()
+}
+
+// Paused reports whether sends are currently held for funds.
+func (b *BalanceWatcher) Paused() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.paused
+}
+
+// WaitUntilFunded blocks while sends are paused, re-checking the balance every
+// interval. It returns false if stop is closed first.
+func (b *BalanceWatcher) WaitUntilFunded(stop <-chan int) bool {
+	if b == nil {
+		return true
+	}
+	for b.Paused() {
+		b.log.Warn("Sends paused until relayer is refunded", "address", b.address)
+		select {
+		case <-stop:
+			return false
+		case <-time.After(b.interval):
+		}
+		if err := b.Check(context.Background()); err != nil {
+			b.log.Warn("Balance check failed", "address", b.address, "err", err)
+		}
+	}
+	return true
+}
+
+func (b *BalanceWatcher) relaysLeftLocked() int64 {
+	if b.balance == nil || len(b.costs) == 0 {
+		return -1
+	}
+	sum := new(big.Int)
+	for _, c := range b.costs {
+		sum.Add(sum, c)
+	}
+	avg := sum.Div(sum, big.NewInt(int64(len(b.costs))))
+	if avg.Sign() == 0 {
+		return -1
+	}
+	if b.balance.Sign() <= 0 {
+		return 0
+	}
+	return new(big.Int).Div(b.balance, avg).Int64()
+}
+
+func (b *BalanceWatcher) levelOf(balance *big.Int, relaysLeft int64) balanceLevel {
+	if b.critical != nil && balance.Cmp(b.critical) < 0 {
+		return balanceCritical
+	}
+	if b.warn != nil && balance.Cmp(b.warn) < 0 {
+		return balanceWarn
+	}
+	if b.minRelays > 0 && relaysLeft >= 0 && relaysLeft < b.minRelays {
+		return balanceWarn
+	}
+	return balanceOk
+}
+
+func (b *BalanceWatcher) toFloat(v *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), new(big.Float).SetInt(unit(b.decimals))).Float64()
+	return f
+}
+
+func (b *BalanceWatcher) format(v *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(v), new(big.Float).SetInt(unit(b.decimals))).Text('f', 6)
+}
+
+func unit(decimals int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+}
+
+// toBaseUnits converts a whole-token threshold into the smallest unit; zero
+// disables the threshold.
+func toBaseUnits(v float64, decimals int) *big.Int {
+	if v <= 0 {
+		return nil
+	}
+	ret, _ := new(big.Float).Mul(big.NewFloat(v), new(big.Float).SetInt(unit(decimals))).Int(nil)
+	return ret
+}
@@ -0,0 +1,82 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+)
+
+func newTestBalanceWatcher(t *testing.T, balance *big.Int, cfg Config) (*BalanceWatcher, *[]string) {
+	t.Helper()
+	var alarms []string
+	prev := balanceAlarm
+	balanceAlarm = func(_ context.Context, msg string) { alarms = append(alarms, msg) }
+	t.Cleanup(func() { balanceAlarm = prev })
+
+	cfg.Name = "bsc"
+	cfg.BalanceInterval = time.Millisecond
+	w := NewBalanceWatcher(&cfg, "0xabc", EvmDecimals, func(context.Context) (*big.Int, error) {
+		return new(big.Int).Set(balance), nil
+	}, log15.New())
+	return w, &alarms
+}
+
+func TestBalanceWatcher_RelaysLeftFromRecentCosts(t *testing.T) {
+	w, _ := newTestBalanceWatcher(t, big.NewInt(1000), Config{})
+	if got := w.RelaysLeft(); got != -1 {
+		t.Fatalf("RelaysLeft before any data = %d, want -1", got)
+	}
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	w.RecordCost(big.NewInt(100))
+	w.RecordCost(big.NewInt(300))
+	// balance 1000 - 400 spent, average cost 200
+	if got := w.RelaysLeft(); got != 3 {
+		t.Fatalf("RelaysLeft = %d, want 3", got)
+	}
+}
+
+func TestBalanceWatcher_PausesBelowCriticalAndResumes(t *testing.T) {
+	balance := new(big.Int).Mul(big.NewInt(5), unit(EvmDecimals-1)) // 0.5
+	w, alarms := newTestBalanceWatcher(t, balance, Config{BalanceWarn: 2, BalanceCritical: 1, BalancePause: true})
+
+	if err := w.Check(context.Background()); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !w.Paused() {
+		t.Fatal("expected sends to be paused below the critical threshold")
+	}
+	if len(*alarms) != 1 || !strings.Contains((*alarms)[0], "critical") {
+		t.Fatalf("alarms = %v, want one critical alarm", *alarms)
+	}
+
+	// a second check at the same level must not re-alarm inside the cooldown
+	_ = w.Check(context.Background())
+	if len(*alarms) != 1 {
+		t.Fatalf("alarms = %d, want 1", len(*alarms))
+	}
+
+	balance.Mul(big.NewInt(3), unit(EvmDecimals))
+	if !w.WaitUntilFunded(make(chan int)) {
+		t.Fatal("WaitUntilFunded returned false without stop")
+	}
+	if w.Paused() {
+		t.Fatal("expected sends to resume after refund")
+	}
+	if len(*alarms) != 2 || !strings.Contains((*alarms)[1], "refunded") {
+		t.Fatalf("alarms = %v, want a refunded alarm", *alarms)
+	}
+}
+
+func TestBalanceWatcher_NilIsNoop(t *testing.T) {
+	var w *BalanceWatcher
+	w.RecordCost(big.NewInt(1))
+	if w.Paused() || !w.WaitUntilFunded(nil) || w.RelaysLeft() != -1 {
+		t.Fatal("nil watcher must never pause")
+	}
+}
@@ -6,12 +6,12 @@ import (
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/pkg/msg"
 	"math/big"
-	"strings"
 	"time"
 
 	"github.com/mapprotocol/compass/pkg/util"
 
 	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/txerr"
 )
 
 // execToMapMsg executes sync msg, and send tx to the destination blockchain
@@ -19,6 +19,7 @@ import (
 func (w *Writer) execToMapMsg(m msg.Message) bool {
 	var (
 		errorCount int64
+		alarmed    bool
 		needNonce  = true
 	)
 	for {
@@ -42,11 +43,9 @@ func (w *Writer) execToMapMsg(m msg.Message) bool {
 			if err != nil {
 				needNonce = w.needNonce(err)
 				time.Sleep(constant.TxRetryInterval)
-				errorCount++
-				if errorCount >= 10 {
+				if txerr.Alarm(&errorCount, &alarmed, err) {
 					util.Alarm(context.Background(), fmt.Sprintf("%s2map updateHeader failed, err is %s",
 						mapprotocol.OnlineChaId[m.Source], err.Error()))
-					errorCount = 0
 				}
 				continue
 			}
@@ -75,7 +74,7 @@ func (w *Writer) toMap(m msg.Message, id *big.Int, marshal []byte, method string
 		// message successfully handled
 		w.log.Info("Sync Header to map tx execution", "tx", tx.Hash(), "src", m.Source, "dst", m.Destination,
 			"method", method, "needNonce", needNonce, "nonce", w.conn.Opts().Nonce)
-		err = w.txStatus(tx.Hash())
+		err = w.txStatus(m, tx.Hash())
 		if err != nil {
 			w.log.Warn("TxHash Status is not successful, will retry", "err", err)
 		} else {
@@ -85,11 +84,9 @@ func (w *Writer) toMap(m msg.Message, id *big.Int, marshal []byte, method string
 		w.log.Warn("Execution failed, ignore this error, Continue to the next ", "err", err)
 		return nil
 	} else {
-		for e := range constant.IgnoreError {
-			if strings.Index(err.Error(), e) != -1 {
-				w.log.Info("Ignore This Error, Continue to the next", "id", id, "method", method, "err", err)
-				return nil
-			}
+		if ce := txerr.Classify(err); ce.Category.Skip() {
+			w.log.Info("Ignore This Error, Continue to the next", "id", id, "method", method, "category", ce.Category, "reason", ce.Reason, "err", err)
+			return nil
 		}
 		w.log.Warn("Sync Header to map Execution failed, will retry", "id", id, "method", method, "err", err)
 	}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/pkg/msg"
-	"strings"
 	"time"
 
 	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/txerr"
 	"github.com/mapprotocol/compass/pkg/util"
 )
 
@@ -16,6 +16,7 @@ import (
 func (w *Writer) execMap2OtherMsg(m msg.Message) bool {
 	var (
 		errorCount int64
+		alarmed    bool
 		needNonce  = true
 	)
 	for {
@@ -35,7 +36,7 @@ func (w *Writer) execMap2OtherMsg(m msg.Message) bool {
 			if err == nil {
 				// message successfully handled
 				w.log.Info("Sync Map Header to other chain tx execution", "tx", tx.Hash(), "src", m.Source, "dst", m.Destination, "needNonce", needNonce, "nonce", w.conn.Opts().Nonce)
-				err = w.txStatus(tx.Hash())
+				err = w.txStatus(m, tx.Hash())
 				if err != nil {
 					w.log.Warn("TxHash Status is not successful, will retry", "err", err)
 				} else {
@@ -47,20 +48,16 @@ func (w *Writer) execMap2OtherMsg(m msg.Message) bool {
 				m.DoneCh <- struct{}{}
 				return true
 			} else {
-				for e := range constant.IgnoreError {
-					if strings.Index(err.Error(), e) != -1 {
-						w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "err", err)
-						m.DoneCh <- struct{}{}
-						return true
-					}
+				if ce := txerr.Classify(err); ce.Category.Skip() {
+					w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "category", ce.Category, "reason", ce.Reason, "err", err)
+					m.DoneCh <- struct{}{}
+					return true
 				}
 				w.log.Warn("Sync Map Header to other chain Execution failed, header may already been synced", "id", m.Destination, "err", err)
 			}
 			needNonce = w.needNonce(err)
-			errorCount++
-			if errorCount >= 10 {
+			if txerr.Alarm(&errorCount, &alarmed, err) {
 				util.Alarm(context.Background(), fmt.Sprintf("map2%s updateHeader failed, err is %s", mapprotocol.OnlineChaId[m.Destination], err.Error()))
-				errorCount = 0
 			}
 			time.Sleep(constant.TxRetryInterval)
 		}
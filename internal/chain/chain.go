@@ -45,6 +45,9 @@ func New(chainCfg *core.ChainConfig, logger log15.Logger, sysErr chan<- error, r
 	if err != nil {
 		return nil, err
 	}
+	if err = ApplyGasStrategy(conn, cfg); err != nil {
+		return nil, err
+	}
 
 	if chainCfg.StartLatest || (chainCfg.LatestBlock && !cfg.Filter) || ((cfg.StartBlock == nil || cfg.StartBlock.Int64() == 0) && !cfg.Filter) {
 		if err := StartLatestBlock(cfg, conn, logger); err != nil {
@@ -82,6 +82,10 @@ type CommonSync struct {
 	oracleHandler             OracleHandler
 	assembleProof             AssembleProof
 	filterClient              FilterClient
+	filterCursor              FilterCursor
+	storedCursor              FilterCursor
+	filterPage                *FilterPage
+	filterSkipped             int
 	reqTime, cacheBlockNumber int64
 }
 
@@ -105,7 +109,7 @@ func (c *CommonSync) ListMosLogs(projectID int64, topic string, limit int) (*str
 		return nil, fmt.Errorf("filter client is nil")
 	}
 	return filterClient.ListMosLogs(FilterListRequest{
-		StartID:   c.Cfg.StartBlock,
+		StartID:   big.NewInt(c.filterCursor.ID),
 		ProjectID: projectID,
 		ChainID:   int64(c.Cfg.Id),
 		Topic:     topic,
@@ -130,6 +134,7 @@ func NewCommonSync(conn core.Connection, cfg *Config, log log15.Logger, stop <-c
 	}
 	if cfg.Filter {
 		cs.filterClient = NewRadarFilterClient(cfg.FilterHost, cfg.FilterAPIKey)
+		cs.loadFilterCursor()
 	}
 	for _, op := range opts {
 		op(cs)
@@ -7,11 +7,14 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	gconfig "github.com/mapprotocol/compass/config"
+	connection "github.com/mapprotocol/compass/connections/ethereum"
 	"github.com/mapprotocol/compass/core"
 	"github.com/mapprotocol/compass/internal/constant"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/pkg/msg"
 )
 
@@ -20,6 +23,13 @@ const (
 	DefaultGasPrice           = 50000000
 	DefaultBlockConfirmations = 10
 	DefaultGasMultiplier      = 1
+	DefaultBalanceInterval    = time.Minute
+	DefaultGasBudgetWindow    = 24 * time.Hour
+	DefaultPreflightHoldAfter = 3
+	DefaultFilterPageSize     = 50
+	DefaultXlayerPadding      = 500000
+	PreflightPending          = "pending"
+	PreflightLatest           = "latest"
 )
 
 // Chain specific options
@@ -37,6 +47,7 @@ var (
 	LightNode             = "lightnode"
 	Event                 = "event"
 	Eth2Url               = "eth2Url"
+	Eth2CommitteeUrl      = "eth2CommitteeUrl"
 	RedisOpt              = "redis"
 	ApiUrl                = "apiUrl"
 	OracleNode            = "oracleNode"
@@ -50,8 +61,49 @@ var (
 	Rent                  = "rent"
 	Addr                  = "addr"
 	Validate              = "validate"
+	BalanceWarnOpt        = "balanceWarn"
+	BalanceCriticalOpt    = "balanceCritical"
+	BalanceMinRelaysOpt   = "balanceMinRelays"
+	BalancePauseOpt       = "balancePause"
+	BalanceIntervalOpt    = "balanceInterval"
+	GasStrategyOpt        = "gasStrategy"
+	GasFeeHistoryBlocks   = "gasFeeHistoryBlocks"
+	GasFeeHistoryPercent  = "gasFeeHistoryPercentile"
+	GasFixedPriceOpt      = "gasFixedPrice"
+	GasFixedTipOpt        = "gasFixedTip"
+	GasTipFloorOpt        = "gasTipFloor"
+	GasTipCeilingOpt      = "gasTipCeiling"
+	GasLimitPaddingOpt    = "gasLimitPadding"
+	GasBudgetOpt          = "gasBudget"
+	GasBudgetWindowOpt    = "gasBudgetWindow"
+	GasBudgetCriticalOpt  = "gasBudgetCritical"
+	PreflightOpt          = "preflight"
+	PreflightHoldAfterOpt = "preflightHoldAfter"
+	HoldingPathOpt        = "holdingPath"
+	PreflightBlockOpt     = "preflightBlock"
+	ReceiptEncodingOpt    = "receiptEncoding"
+	IngestOpt             = "ingest"
+	WsEndpointOpt         = "wsEndpoint"
+	PriorityFeeCapOpt     = "priorityFeeCap"
+	PriorityFeePercentOpt = "priorityFeePercentile"
+	ComputeUnitLimitOpt   = "computeUnitLimit"
+	EnergyProvidersOpt    = "energyProviders"
+	EnergyMarginOpt       = "energyMargin"
+	RentDepositOpt        = "rentDeposit"
+	RentAmountOpt         = "rentAmount"
+	RentMinBalanceOpt     = "rentMinBalance"
+	RentRefundOpt         = "rentRefund"
+	FeeeEnergyOpt         = "feeeEnergy"
+	FeeeDaysOpt           = "feeeDays"
+	StakeMaxOpt           = "stakeMax"
+	StakeCostOpt          = "stakeCost"
+	FilterPageSizeOpt     = "filterPageSize"
 )
 
+// DefaultGasBudgetCritical are the message types still sent once the gas
+// budget is spent: header syncs keep the light clients usable.
+var DefaultGasBudgetCritical = []msg.TransferType{msg.SyncToMap, msg.SyncFromMap}
+
 // Config encapsulates all necessary parameters in ethereum compatible forms
 type Config struct {
 	Name               string      // Human-readable chain name
@@ -78,6 +130,7 @@ type Config struct {
 	Events             []constant.EventSig
 	SkipError          bool
 	Eth2Endpoint       string
+	Eth2CommitteeUrl   string // beacon nodes the sync committees are taken from, apart from Eth2Endpoint
 	ApiUrl             string
 	OracleNode         common.Address
 	Filter             bool
@@ -87,6 +140,22 @@ type Config struct {
 	BtcHost            string
 	PriceHost          string
 	ReportHost         string
+	BalanceWarn        float64       // alarm when the From balance drops below this (whole tokens)
+	BalanceCritical    float64       // critical alarm, and pause sends if BalancePause
+	BalanceMinRelays   int64         // alarm when the estimated relays left drop below this
+	BalancePause       bool          // hold sends while the balance is critical
+	BalanceInterval    time.Duration // balance poll interval
+	Gas                connection.GasConfig
+	GasLimitPadding    uint64             // extra gas added to every estimate
+	GasBudget          float64            // fees allowed per GasBudgetWindow (whole tokens), 0 disables
+	GasBudgetWindow    time.Duration      // rolling window of GasBudget
+	GasBudgetCritical  []msg.TransferType // types sent even when the budget is spent
+	Preflight          bool               // simulate every delivery with eth_estimateGas before sending it
+	PreflightHoldAfter int64              // park a delivery after this many reverted simulations, 0 never parks
+	PreflightBlock     string             // block the simulation runs at, pending or latest
+	HoldingPath        string             // directory of the holding area, defaults next to the blockstore
+	ReceiptEncoding    string             // receipt family the proofs of this chain use, empty keeps the built-in one
+	FilterPageSize     int                // logs read from the filter service a request
 }
 
 // ParseConfig uses a core.ChainConfig to construct a corresponding Config
@@ -118,6 +187,13 @@ func ParseConfig(chainCfg *core.ChainConfig) (*Config, error) {
 		BtcHost:            chainCfg.BtcHost,
 		PriceHost:          chainCfg.PriceHost,
 		ReportHost:         chainCfg.ReportHost,
+		BalanceInterval:    DefaultBalanceInterval,
+		GasBudgetWindow:    DefaultGasBudgetWindow,
+		GasBudgetCritical:  DefaultGasBudgetCritical,
+		Preflight:          true,
+		PreflightHoldAfter: DefaultPreflightHoldAfter,
+		PreflightBlock:     PreflightPending,
+		FilterPageSize:     DefaultFilterPageSize,
 	}
 
 	if contract, ok := chainCfg.Opts[McsOpt]; ok && contract != "" {
@@ -222,11 +298,159 @@ func ParseConfig(chainCfg *core.ChainConfig) (*Config, error) {
 	if eth2Url, ok := chainCfg.Opts[Eth2Url]; ok && eth2Url != "" {
 		config.Eth2Endpoint = eth2Url
 	}
+	if v, ok := chainCfg.Opts[Eth2CommitteeUrl]; ok && v != "" {
+		config.Eth2CommitteeUrl = v
+	}
 
 	if v, ok := chainCfg.Opts[ApiUrl]; ok && v != "" {
 		config.ApiUrl = v
 	}
 
+	if v, ok := chainCfg.Opts[BalanceWarnOpt]; ok && v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", BalanceWarnOpt)
+		}
+		config.BalanceWarn = f
+	}
+
+	if v, ok := chainCfg.Opts[BalanceCriticalOpt]; ok && v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", BalanceCriticalOpt)
+		}
+		config.BalanceCritical = f
+	}
+
+	if v, ok := chainCfg.Opts[BalanceMinRelaysOpt]; ok && v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", BalanceMinRelaysOpt)
+		}
+		config.BalanceMinRelays = n
+	}
+
+	if v, ok := chainCfg.Opts[BalancePauseOpt]; ok && v == "true" {
+		config.BalancePause = true
+	}
+
+	if v, ok := chainCfg.Opts[BalanceIntervalOpt]; ok && v != "" {
+		secs, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || secs <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", BalanceIntervalOpt)
+		}
+		config.BalanceInterval = time.Duration(secs) * time.Second
+	}
+
+	if v, ok := chainCfg.Opts[GasStrategyOpt]; ok && v != "" {
+		config.Gas.Strategy = v
+	}
+
+	if v, ok := chainCfg.Opts[GasFeeHistoryBlocks]; ok && v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", GasFeeHistoryBlocks)
+		}
+		config.Gas.FeeHistoryBlocks = n
+	}
+
+	if v, ok := chainCfg.Opts[GasFeeHistoryPercent]; ok && v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", GasFeeHistoryPercent)
+		}
+		config.Gas.FeeHistoryPercentile = f
+	}
+
+	for opt, dst := range map[string]**big.Int{
+		GasFixedPriceOpt: &config.Gas.FixedPrice,
+		GasFixedTipOpt:   &config.Gas.FixedTip,
+		GasTipFloorOpt:   &config.Gas.TipFloor,
+		GasTipCeilingOpt: &config.Gas.TipCeiling,
+	} {
+		if v, ok := chainCfg.Opts[opt]; ok && v != "" {
+			val, pass := big.NewInt(0).SetString(v, 10)
+			if !pass {
+				return nil, fmt.Errorf("unable to parse %s", opt)
+			}
+			*dst = val
+		}
+	}
+
+	if config.Id == constant.XlayerId {
+		config.GasLimitPadding = DefaultXlayerPadding
+	}
+	if v, ok := chainCfg.Opts[GasLimitPaddingOpt]; ok && v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s", GasLimitPaddingOpt)
+		}
+		config.GasLimitPadding = n
+	}
+
+	if v, ok := chainCfg.Opts[GasBudgetOpt]; ok && v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("unable to parse %s", GasBudgetOpt)
+		}
+		config.GasBudget = f
+	}
+
+	if v, ok := chainCfg.Opts[GasBudgetWindowOpt]; ok && v != "" {
+		hours, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || hours <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", GasBudgetWindowOpt)
+		}
+		config.GasBudgetWindow = time.Duration(hours) * time.Hour
+	}
+
+	if v, ok := chainCfg.Opts[GasBudgetCriticalOpt]; ok {
+		config.GasBudgetCritical = make([]msg.TransferType, 0)
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				config.GasBudgetCritical = append(config.GasBudgetCritical, msg.TransferType(t))
+			}
+		}
+	}
+
+	if v, ok := chainCfg.Opts[PreflightOpt]; ok && v == "false" {
+		config.Preflight = false
+	}
+
+	if v, ok := chainCfg.Opts[PreflightHoldAfterOpt]; ok && v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("unable to parse %s", PreflightHoldAfterOpt)
+		}
+		config.PreflightHoldAfter = n
+	}
+
+	if v, ok := chainCfg.Opts[PreflightBlockOpt]; ok && v != "" {
+		if v != PreflightPending && v != PreflightLatest {
+			return nil, fmt.Errorf("unable to parse %s, want %s or %s", PreflightBlockOpt, PreflightPending, PreflightLatest)
+		}
+		config.PreflightBlock = v
+	}
+
+	if v, ok := chainCfg.Opts[HoldingPathOpt]; ok && v != "" {
+		config.HoldingPath = v
+	}
+
+	if v, ok := chainCfg.Opts[ReceiptEncodingOpt]; ok && v != "" {
+		if err := ireceipt.Bind(config.Id, v); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %w", ReceiptEncodingOpt, err)
+		}
+		config.ReceiptEncoding = v
+	}
+
+	if v, ok := chainCfg.Opts[FilterPageSizeOpt]; ok && v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("unable to parse %s", FilterPageSizeOpt)
+		}
+		config.FilterPageSize = n
+	}
+
 	if config.OracleNode == constant.ZeroAddress {
 		config.OracleNode = config.LightNode
 	}
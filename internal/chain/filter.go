@@ -6,7 +6,6 @@ import (
 	"io"
 	"math/big"
 	"net/http"
-	"sort"
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/mapprotocol/compass/internal/constant"
@@ -14,86 +13,61 @@ import (
 	"github.com/pkg/errors"
 )
 
+// filterMosHandler relays the next page of mos logs of the filter service,
+// in id order, up to the first one not confirmed yet.
 func (m *Messenger) filterMosHandler(latestBlock uint64) (int, uint64, error) {
-	count := 0
-	progressBlock := uint64(0)
-	topic := BuildFilterTopic(m.Cfg.Events)
-	back, err := m.ListMosLogs(constant.ProjectOfMsger, topic, 1)
+	page, err := m.NextFilterPage(BuildFilterTopic(m.Cfg.Events), constant.ProjectOfMsger)
 	if err != nil {
-		return 0, progressBlock, err
+		return 0, 0, err
 	}
-	if len(back.List) == 0 {
+	if len(page.Logs) == 0 {
 		return 0, latestBlock, nil
 	}
 
-	for _, ele := range back.List {
-		progressBlock = ele.BlockNumber
+	count := 0
+	for _, ele := range page.Logs {
 		idx := m.Match(ele.ContractAddress)
 		if idx == -1 {
-			m.Log.Info("Filter Log Address Not Match", "id", ele.Id, "address", ele.ContractAddress)
-			m.Cfg.StartBlock = big.NewInt(ele.Id)
+			m.SkipFilter(ele)
 			continue
 		}
-		if latestBlock-ele.BlockNumber < m.BlockConfirmations.Uint64() {
+		if ele.BlockNumber+m.BlockConfirmations.Uint64() > latestBlock {
 			m.Log.Debug("Block not ready, will retry", "currentBlock", ele.BlockNumber, "latest", latestBlock)
-			continue
+			break
 		}
 
-		log := MosRespToEthLog(ele)
-
-		send, err := log2Msg(m, log, idx)
+		send, err := log2Msg(m, MosRespToEthLog(ele), idx)
 		if err != nil {
-			return 0, progressBlock, err
+			_ = m.WaitUntilMsgHandled(count)
+			return 0, m.FilterCursor().Block, err
 		}
 		count += send
-		m.Cfg.StartBlock = big.NewInt(ele.Id)
+		m.ProcessedFilter(ele)
 	}
 
-	return count, progressBlock, nil
+	return count, m.FilterCursor().Block, nil
 }
 
+// filterOracle proposes the receipts of the next page of logs of the oracle
+// and mos projects of the filter service, in id order.
 func (m *Oracle) filterOracle() error {
-	topic := BuildFilterTopic(m.Cfg.Events)
-
-	tmp := []int{}
-	var err error
-	defer func() {
-		if len(tmp) == 0 {
-			return
-		}
-		if err != nil {
-			return
-		}
-		sort.Ints(tmp) // less - big
-		if int64(tmp[0]) > m.Cfg.StartBlock.Int64() {
-			m.Cfg.StartBlock = big.NewInt(int64(tmp[0]))
-		}
-	}()
-	for _, pid := range []int64{constant.ProjectOfOracle, constant.ProjectOfMsger} {
-		var back *stream.MosListResp
-		back, err = m.ListMosLogs(pid, topic, 1)
-		if err != nil {
-			return err
-		}
-		if len(back.List) == 0 {
+	page, err := m.NextFilterPage(BuildFilterTopic(m.Cfg.Events), constant.ProjectOfOracle, constant.ProjectOfMsger)
+	if err != nil {
+		return err
+	}
+	for _, ele := range page.Logs {
+		idx := m.Match(ele.ContractAddress) // 新版 oracle
+		if idx == -1 {
+			m.SkipFilter(ele)
 			continue
 		}
 
-		for _, ele := range back.List {
-			idx := m.Match(ele.ContractAddress) // 新版 oracle
-			if idx == -1 {
-				m.Log.Info("Filter Log Address Not Match", "id", ele.Id, "address", ele.ContractAddress)
-				tmp = append(tmp, int(ele.Id))
-				continue
-			}
-
-			log := MosRespToEthLog(ele)
-			err = log2Oracle(m, []types.Log{*log}, big.NewInt(0).SetUint64(ele.BlockNumber), ele.Id)
-			if err != nil {
-				return err
-			}
-			tmp = append(tmp, int(ele.Id))
+		log := MosRespToEthLog(ele)
+		err = log2Oracle(m, []types.Log{*log}, big.NewInt(0).SetUint64(ele.BlockNumber), ele.Id)
+		if err != nil {
+			return err
 		}
+		m.ProcessedFilter(ele)
 	}
 	return nil
 }
@@ -0,0 +1,174 @@
+package chain
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mapprotocol/compass/internal/stream"
+	"github.com/mapprotocol/compass/pkg/blockstore"
+)
+
+// FilterCursor is how far a chain read the logs of the filter service. The
+// ids of the service are its own, shared among chains, projects and topics,
+// and have nothing to do with block numbers.
+type FilterCursor struct {
+	ID    int64  `json:"id"`    // id of the last log the chain is done with
+	Block uint64 `json:"block"` // block of that log
+}
+
+// FilterPage is the logs of the filter service following the cursor, in id
+// order.
+type FilterPage struct {
+	Logs []*stream.GetMosResp
+	More bool // the service has logs past the page
+}
+
+// loadFilterCursor starts the cursor at the startBlock option, or the latest
+// id with startLatest, unless the one stored is further.
+func (c *CommonSync) loadFilterCursor() {
+	if c.Cfg.StartBlock != nil {
+		c.filterCursor.ID = c.Cfg.StartBlock.Int64()
+	}
+	store, ok := c.BlockStore.(blockstore.Cursorstorer)
+	if !ok || c.Cfg.FreshStart {
+		return
+	}
+	var stored FilterCursor
+	if _, err := store.TryLoadCursor(&stored); err != nil {
+		c.Log.Error("Unable to load the filter cursor, starting from startBlock", "id", c.filterCursor.ID, "err", err)
+		return
+	}
+	c.storedCursor = stored
+	if stored.ID > c.filterCursor.ID {
+		c.filterCursor = stored
+	}
+}
+
+// FilterCursor is the id of the last filter log the chain is done with.
+func (c *CommonSync) FilterCursor() FilterCursor {
+	return c.filterCursor
+}
+
+// NextFilterPage lists the logs of topic of the projects after the cursor, a
+// page of filterPageSize a project. Logs at or behind the cursor, which the
+// service returns out of order or twice, are dropped. When a project fills
+// its page, the logs of the others past the end of it wait for the next page,
+// so none is passed over.
+func (c *CommonSync) NextFilterPage(topic string, projects ...int64) (*FilterPage, error) {
+	limit := c.Cfg.FilterPageSize
+	if limit <= 0 {
+		limit = DefaultFilterPageSize
+	}
+	var (
+		logs  []*stream.GetMosResp
+		bound int64 = math.MaxInt64
+	)
+	for _, pid := range projects {
+		back, err := c.ListMosLogs(pid, topic, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(back.List) >= limit {
+			last := int64(0)
+			for _, ele := range back.List {
+				last = max(last, ele.Id)
+			}
+			bound = min(bound, last)
+		}
+		logs = append(logs, back.List...)
+	}
+	sort.SliceStable(logs, func(i, j int) bool { return logs[i].Id < logs[j].Id })
+
+	ret := &FilterPage{More: bound != math.MaxInt64}
+	prev, stale := c.filterCursor.ID, 0
+	for _, ele := range logs {
+		if ele.Id > bound {
+			break
+		}
+		if ele.Id <= prev {
+			stale++
+			continue
+		}
+		ret.Logs = append(ret.Logs, ele)
+		prev = ele.Id
+	}
+	if stale > 0 {
+		c.Log.Warn("Filter returned logs at or behind the cursor, dropped", "cursor", c.filterCursor.ID, "logs", stale)
+		c.State.IncFilterLogs("stale", stale)
+	}
+	c.filterPage = ret
+	return ret, nil
+}
+
+// AdvanceFilter moves the cursor past log once the chain is done with it.
+// The ids the service skipped to get to it aren't the chain's logs.
+func (c *CommonSync) AdvanceFilter(log *stream.GetMosResp) {
+	if log.Id <= c.filterCursor.ID {
+		return
+	}
+	if gap := log.Id - c.filterCursor.ID - 1; gap > 0 && c.filterCursor.ID > 0 {
+		c.Log.Debug("Filter ids skipped", "from", c.filterCursor.ID, "to", log.Id)
+		c.State.IncFilterGaps(gap)
+	}
+	c.filterCursor = FilterCursor{ID: log.Id, Block: log.BlockNumber}
+	c.State.SetFilterCursor(log.Id)
+}
+
+// ProcessedFilter moves the cursor past log once it was processed.
+func (c *CommonSync) ProcessedFilter(log *stream.GetMosResp) {
+	c.AdvanceFilter(log)
+	c.State.IncFilterLogs("processed", 1)
+}
+
+// SkipFilter moves the cursor past log of an address the chain doesn't
+// watch. The skipped logs are reported together when the cursor is stored.
+func (c *CommonSync) SkipFilter(log *stream.GetMosResp) {
+	c.AdvanceFilter(log)
+	c.filterSkipped++
+}
+
+// StoreFilterCursor persists the cursor, if it moved, next to the blockstore.
+func (c *CommonSync) StoreFilterCursor() error {
+	if c.filterSkipped > 0 {
+		c.Log.Info("Filter logs of addresses not watched skipped", "logs", c.filterSkipped, "cursor", c.filterCursor.ID)
+		c.State.IncFilterLogs("skipped", c.filterSkipped)
+		c.filterSkipped = 0
+	}
+	if c.filterCursor == c.storedCursor {
+		return nil
+	}
+	store, ok := c.BlockStore.(blockstore.Cursorstorer)
+	if !ok {
+		return nil
+	}
+	if err := store.StoreCursor(c.filterCursor); err != nil {
+		return err
+	}
+	c.storedCursor = c.filterCursor
+	return nil
+}
+
+// skipFilterLog moves the cursor past the first log of the page the chain
+// isn't done with, and returns it.
+func (c *CommonSync) skipFilterLog() *stream.GetMosResp {
+	if c.filterPage == nil {
+		return nil
+	}
+	for _, ele := range c.filterPage.Logs {
+		if ele.Id > c.filterCursor.ID {
+			c.AdvanceFilter(ele)
+			return ele
+		}
+	}
+	return nil
+}
+
+// FilterBacklog says whether the last page was full and the chain went
+// through all of it, so the next can be read straight away.
+func (c *CommonSync) FilterBacklog() bool {
+	page := c.filterPage
+	if page == nil || !page.More || len(page.Logs) == 0 {
+		return false
+	}
+	return c.filterCursor.ID >= page.Logs[len(page.Logs)-1].Id
+}
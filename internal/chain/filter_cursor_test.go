@@ -0,0 +1,151 @@
+package chain
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/stream"
+	"github.com/mapprotocol/compass/pkg/blockstore"
+)
+
+// fakeFilter serves the logs of each project after the id asked for, in the
+// order they are listed, which needn't be by id.
+type fakeFilter struct {
+	logs     map[int64][]*stream.GetMosResp
+	requests int
+}
+
+func (f *fakeFilter) LatestBlock(int64) (*big.Int, error) { return big.NewInt(0), nil }
+
+func (f *fakeFilter) MaxID(int64) (*big.Int, error) { return big.NewInt(0), nil }
+
+func (f *fakeFilter) ListMosLogs(req FilterListRequest) (*stream.MosListResp, error) {
+	f.requests++
+	ret := &stream.MosListResp{}
+	for _, ele := range f.logs[req.ProjectID] {
+		if ele.Id > req.StartID.Int64() && len(ret.List) < req.Limit {
+			ret.List = append(ret.List, ele)
+		}
+	}
+	return ret, nil
+}
+
+func filterLogs(addr string, ids ...int64) []*stream.GetMosResp {
+	ret := make([]*stream.GetMosResp, 0, len(ids))
+	for _, id := range ids {
+		ret = append(ret, &stream.GetMosResp{Id: id, ContractAddress: addr, BlockNumber: uint64(id) * 10})
+	}
+	return ret
+}
+
+func newFilterSync(cfg Config, bs blockstore.Blockstorer, client FilterClient) *CommonSync {
+	cfg.Filter = true
+	if cfg.StartBlock == nil {
+		cfg.StartBlock = big.NewInt(0)
+	}
+	return NewCommonSync(nil, &cfg, log15.New(), nil, nil, bs, OptOfFilterClient(client))
+}
+
+func pageIDs(page *FilterPage) []int64 {
+	ret := make([]int64, 0, len(page.Logs))
+	for _, ele := range page.Logs {
+		ret = append(ret, ele.Id)
+	}
+	return ret
+}
+
+func TestFilterPages(t *testing.T) {
+	bs, err := blockstore.NewBlockstore(t.TempDir(), 1, "relayer", mapprotocol.RoleOfMessenger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &fakeFilter{logs: map[int64][]*stream.GetMosResp{
+		1: append(filterLogs("other", 11, 12, 14, 15, 16), filterLogs("mos", 18)...),
+	}}
+	cs := newFilterSync(Config{StartBlock: big.NewInt(10), FilterPageSize: 3}, bs, client)
+
+	var processed []int64
+	for {
+		page, err := cs.NextFilterPage("topic", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, ele := range page.Logs {
+			if ele.ContractAddress != "mos" {
+				cs.SkipFilter(ele)
+				continue
+			}
+			processed = append(processed, ele.Id)
+			cs.ProcessedFilter(ele)
+		}
+		if err = cs.StoreFilterCursor(); err != nil {
+			t.Fatal(err)
+		}
+		if !cs.FilterBacklog() {
+			break
+		}
+	}
+	// the addresses not watched are passed over a page at a time
+	if !reflect.DeepEqual(processed, []int64{18}) || client.requests != 3 {
+		t.Fatalf("processed %v in %d requests", processed, client.requests)
+	}
+	if got := cs.FilterCursor(); got != (FilterCursor{ID: 18, Block: 180}) {
+		t.Fatalf("cursor %+v", got)
+	}
+
+	// restarting goes on from the stored cursor, not the startBlock
+	cs = newFilterSync(Config{StartBlock: big.NewInt(10)}, bs, client)
+	if cs.FilterCursor().ID != 18 {
+		t.Fatalf("loaded cursor %+v", cs.FilterCursor())
+	}
+	if cs = newFilterSync(Config{StartBlock: big.NewInt(10), FreshStart: true}, bs, client); cs.FilterCursor().ID != 10 {
+		t.Fatalf("fresh start at %+v", cs.FilterCursor())
+	}
+	if cs = newFilterSync(Config{StartBlock: big.NewInt(30)}, bs, client); cs.FilterCursor().ID != 30 {
+		t.Fatalf("start latest at %+v", cs.FilterCursor())
+	}
+}
+
+func TestFilterPageOrder(t *testing.T) {
+	client := &fakeFilter{logs: map[int64][]*stream.GetMosResp{
+		// out of order, twice and behind the cursor
+		1: filterLogs("mos", 9, 7, 3, 7, 12),
+	}}
+	cs := newFilterSync(Config{StartBlock: big.NewInt(5), FilterPageSize: 10}, &blockstore.EmptyStore{}, client)
+	page, err := cs.NextFilterPage("topic", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pageIDs(page); !reflect.DeepEqual(got, []int64{7, 9, 12}) || page.More {
+		t.Fatalf("page %v, more %v", got, page.More)
+	}
+
+	// the logs past the end of the full page of a project wait for the next
+	client.logs = map[int64][]*stream.GetMosResp{
+		8: filterLogs("oracle", 6, 8),
+		1: filterLogs("mos", 7, 9, 10),
+	}
+	cs.Cfg.FilterPageSize = 2
+	if page, err = cs.NextFilterPage("topic", 8, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := pageIDs(page); !reflect.DeepEqual(got, []int64{6, 7, 8}) || !page.More {
+		t.Fatalf("page %v, more %v", got, page.More)
+	}
+	cs.ProcessedFilter(page.Logs[0])
+	if cs.FilterBacklog() {
+		t.Fatal("backlog with the page half done")
+	}
+	for _, ele := range page.Logs[1:] {
+		cs.ProcessedFilter(ele)
+	}
+	if page, err = cs.NextFilterPage("topic", 8, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := pageIDs(page); !reflect.DeepEqual(got, []int64{9, 10}) {
+		t.Fatalf("page %v", got)
+	}
+}
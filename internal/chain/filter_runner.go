@@ -88,9 +88,10 @@ func (r *FilterRunner) Run() error {
 			cs.State.SetLatestBlock(latestBlock.Int64())
 			count, progressBlock, err := r.Processor.HandleFilterBlock(latestBlock.Uint64())
 			if cs.Cfg.SkipError && errors.Is(err, NotVerifyAble) {
-				cs.Log.Info("Block not verify, will ignore", "startBlock", cs.Cfg.StartBlock)
-				cs.Cfg.StartBlock = cs.Cfg.StartBlock.Add(cs.Cfg.StartBlock, big.NewInt(1))
-				_ = cs.BlockStore.StoreBlock(cs.Cfg.StartBlock)
+				if log := cs.skipFilterLog(); log != nil {
+					cs.Log.Info("Block not verify, will ignore", "id", log.Id, "block", log.BlockNumber)
+				}
+				_ = cs.StoreFilterCursor()
 				time.Sleep(constant.BlockRetryInterval)
 				continue
 			}
@@ -113,15 +114,17 @@ func (r *FilterRunner) Run() error {
 			}
 
 			_ = cs.WaitUntilMsgHandled(count)
-			if err := cs.BlockStore.StoreBlock(cs.Cfg.StartBlock); err != nil {
-				cs.Log.Error("Filter Failed to write latest block to blockStore", "err", err)
+			if err := cs.StoreFilterCursor(); err != nil {
+				cs.Log.Error("Filter Failed to write the cursor", "cursor", cs.FilterCursor().ID, "err", err)
 			}
 			if count > 0 {
 				cs.State.IncEventsMatched(count)
 			}
 			cs.State.IncBlocksProcessed(1)
 
-			time.Sleep(constant.MessengerInterval)
+			if !cs.FilterBacklog() {
+				time.Sleep(constant.MessengerInterval)
+			}
 		}
 	}
 }
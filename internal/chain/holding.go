@@ -0,0 +1,157 @@
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+const holdingPostfix = ".compass/held"
+
+// HeldMessage is a delivery parked after its simulation kept reverting. It
+// carries everything an operator needs to inspect or replay the call.
+type HeldMessage struct {
+	Time        int64            `json:"time"`
+	Chain       string           `json:"chain"`
+	Type        msg.TransferType `json:"type"`
+	Source      msg.ChainId      `json:"source"`
+	Destination msg.ChainId      `json:"destination"`
+	SrcHash     string           `json:"srcHash,omitempty"`
+	To          string           `json:"to"`
+	Input       string           `json:"input"`
+	Category    string           `json:"category"`
+	Reason      string           `json:"reason,omitempty"`
+	Error       string           `json:"error"`
+}
+
+// HoldingArea appends held messages of one chain to a JSON-lines file so they
+// survive restarts and are out of the writer's retry loop.
+type HoldingArea struct {
+	name  string
+	path  string
+	state *observability.HoldingState
+
+	mu sync.Mutex
+}
+
+// NewHoldingArea returns the holding area of the chain described by cfg. The
+// file lives in cfg.HoldingPath, else in a "held" directory next to the
+// blockstore, else in ~/.compass/held.
+func NewHoldingArea(cfg *Config) (*HoldingArea, error) {
+	dir := cfg.HoldingPath
+	if dir == "" && cfg.BlockstorePath != "" {
+		dir = filepath.Join(cfg.BlockstorePath, "held")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, holdingPostfix)
+	}
+	return &HoldingArea{
+		name:  cfg.Name,
+		path:  filepath.Join(dir, fmt.Sprintf("%s-%d.held", cfg.From, cfg.Id)),
+		state: observability.RegisterHolding(cfg.Name),
+	}, nil
+}
+
+// Path is the file held messages are written to.
+func (h *HoldingArea) Path() string {
+	return h.path
+}
+
+// Put appends hm to the holding area.
+func (h *HoldingArea) Put(hm HeldMessage) error {
+	if hm.Time == 0 {
+		hm.Time = time.Now().Unix()
+	}
+	hm.Chain = h.name
+	line, err := json.Marshal(hm)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err = os.MkdirAll(filepath.Dir(h.path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	h.state.IncHeld(string(hm.Type), hm.Category)
+	return nil
+}
+
+// List returns every message held so far, oldest first.
+func (h *HoldingArea) List() ([]HeldMessage, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, err := os.Open(h.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ret := make([]HeldMessage, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var hm HeldMessage
+		if err = json.Unmarshal(scanner.Bytes(), &hm); err != nil {
+			return nil, fmt.Errorf("corrupt holding area %s: %w", h.path, err)
+		}
+		ret = append(ret, hm)
+	}
+	return ret, scanner.Err()
+}
+
+// Remove takes hm, as List returned it, out of the holding area once it was
+// replayed. Removing a message that isn't held is not an error.
+func (h *HoldingArea) Remove(hm HeldMessage) error {
+	held, err := h.List()
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var buf bytes.Buffer
+	removed := false
+	for _, ele := range held {
+		if !removed && ele == hm {
+			removed = true
+			continue
+		}
+		line, err := json.Marshal(ele)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+	if !removed {
+		return nil
+	}
+	tmp := h.path + ".tmp"
+	if err = os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
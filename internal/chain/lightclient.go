@@ -0,0 +1,215 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/core"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/util"
+)
+
+const (
+	DefaultLightClientInterval = time.Minute
+	DefaultLightClientStall    = 30 * time.Minute
+	// lightClientRealarm is how often a light client that is still stuck is re-reported
+	lightClientRealarm = time.Hour
+)
+
+var (
+	// lightClientAlarm and lightClientNow are seams for tests.
+	lightClientAlarm = util.Alarm
+	lightClientNow   = time.Now
+)
+
+// LightClient is one light client to watch: the one of Src on Dst. Height
+// returns what it has synced to and Head the latest block of Src.
+type LightClient struct {
+	Src, Dst string
+	NodeType int64
+	Height   mapprotocol.GetHeight
+	Head     mapprotocol.GetHeight
+}
+
+// oracleLightClient reports whether a light client of nodeType follows the
+// logs proposed to it rather than every header, so its height only moves
+// when there is something to relay.
+func oracleLightClient(nodeType int64) bool {
+	switch nodeType {
+	case constant.ProofTypeOfOracle, constant.ProofTypeOfNewOracle, constant.ProofTypeOfLogOracle:
+		return true
+	}
+	return false
+}
+
+type lightClientWatch struct {
+	LightClient
+	state *observability.LightClientState
+
+	height    int64
+	head      int64 // head of Src when height last moved
+	movedAt   time.Time
+	stalled   bool
+	alarmedAt time.Time
+}
+
+// LightClientWatchdog polls the height of every light client it is given
+// against the head of its source chain, publishes both as metrics and alarms
+// when a light client stops advancing for stall while the source chain keeps
+// producing blocks.
+type LightClientWatchdog struct {
+	interval time.Duration
+	stall    time.Duration
+	log      log15.Logger
+
+	mu        sync.Mutex
+	clients   []*lightClientWatch
+	startOnce sync.Once
+}
+
+// NewLightClientWatchdog builds a watchdog polling every interval, zero
+// values take the defaults.
+func NewLightClientWatchdog(interval, stall time.Duration, log log15.Logger) *LightClientWatchdog {
+	if interval <= 0 {
+		interval = DefaultLightClientInterval
+	}
+	if stall <= 0 {
+		stall = DefaultLightClientStall
+	}
+	return &LightClientWatchdog{interval: interval, stall: stall, log: log}
+}
+
+// Add watches lc.
+func (w *LightClientWatchdog) Add(lc LightClient) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.clients = append(w.clients, &lightClientWatch{
+		LightClient: lc,
+		state:       observability.RegisterLightClient(lc.Src, lc.Dst),
+	})
+}
+
+// Len returns the number of light clients watched.
+func (w *LightClientWatchdog) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.clients)
+}
+
+// Start polls every interval until stop is closed. Calling it more than once
+// is a no-op.
+func (w *LightClientWatchdog) Start(stop <-chan int) {
+	w.startOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(w.interval)
+			defer ticker.Stop()
+			for {
+				w.Check(context.Background())
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	})
+}
+
+// Check polls every light client once.
+func (w *LightClientWatchdog) Check(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, lc := range w.clients {
+		if err := w.check(ctx, lc); err != nil {
+			w.log.Warn("Light client check failed", "src", lc.Src, "dst", lc.Dst, "err", err)
+		}
+	}
+}
+
+func (w *LightClientWatchdog) check(ctx context.Context, lc *lightClientWatch) error {
+	height, err := lc.Height()
+	if err != nil {
+		return fmt.Errorf("light client height: %w", err)
+	}
+	head, err := lc.Head()
+	if err != nil {
+		return fmt.Errorf("source head: %w", err)
+	}
+	if height == nil || head == nil {
+		return fmt.Errorf("no height or head reported")
+	}
+	lc.state.SetHeight(height.Int64(), head.Int64())
+
+	now := lightClientNow()
+	if lc.movedAt.IsZero() || height.Int64() != lc.height {
+		if lc.stalled {
+			w.log.Info("Light client advancing again", "src", lc.Src, "dst", lc.Dst, "height", height)
+			lightClientAlarm(ctx, fmt.Sprintf("light client of %s on %s advancing again, height %s", lc.Src, lc.Dst, height))
+		}
+		lc.height, lc.head, lc.movedAt, lc.stalled = height.Int64(), head.Int64(), now, false
+		return nil
+	}
+	// an idle source chain, or an oracle light client with nothing to relay,
+	// isn't stuck
+	if oracleLightClient(lc.NodeType) || head.Int64() <= lc.head || now.Sub(lc.movedAt) < w.stall {
+		return nil
+	}
+	if lc.stalled && now.Sub(lc.alarmedAt) < lightClientRealarm {
+		return nil
+	}
+	lc.stalled, lc.alarmedAt = true, now
+	w.log.Warn("Light client stopped advancing", "src", lc.Src, "dst", lc.Dst, "height", height, "head", head,
+		"since", lc.movedAt)
+	lightClientAlarm(ctx, fmt.Sprintf("light client of %s on %s stuck at %s for %s, %s head is %s",
+		lc.Src, lc.Dst, height, now.Sub(lc.movedAt).Truncate(time.Second), lc.Src, head))
+	return nil
+}
+
+// WatchLightClients adds the light clients compass knows of to w: the ones of
+// MAP registered in Map2OtherHeight, and the ones of conns on MAP the light
+// manager has a node type for. conns holds the connections of the chains by
+// id, MAP's among them.
+func WatchLightClients(w *LightClientWatchdog, mapId msg.ChainId, conns map[msg.ChainId]core.Connection) {
+	mapConn, ok := conns[mapId]
+	if !ok {
+		return
+	}
+	name := func(id msg.ChainId) string {
+		if n, ok := mapprotocol.OnlineChaId[id]; ok {
+			return n
+		}
+		return strconv.FormatUint(uint64(id), 10)
+	}
+	for id, fn := range mapprotocol.Map2OtherHeight {
+		nodeType := int64(constant.ProofTypeOfOrigin)
+		if ret, err := GetMap2OtherNodeType(0, uint64(id)); err == nil {
+			nodeType = ret.Int64()
+		}
+		w.Add(LightClient{Src: name(mapId), Dst: name(id), NodeType: nodeType, Height: fn, Head: mapConn.LatestBlock})
+	}
+	for id, conn := range conns {
+		if id == mapId {
+			continue
+		}
+		nodeType, err := mapprotocol.GetNodeTypeByManager(mapprotocol.MethodOfNodeType, big.NewInt(int64(id)))
+		if err != nil || nodeType == nil || nodeType.Sign() == 0 {
+			continue // no light client of it on MAP
+		}
+		id := id
+		w.Add(LightClient{
+			Src:      name(id),
+			Dst:      name(mapId),
+			NodeType: nodeType.Int64(),
+			Height:   func() (*big.Int, error) { return mapprotocol.Get2MapHeight(id) },
+			Head:     conn.LatestBlock,
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type testLightClient struct {
+	height, head int64
+}
+
+func (c *testLightClient) lightClient(src, dst string, nodeType int64) LightClient {
+	return LightClient{
+		Src:      src,
+		Dst:      dst,
+		NodeType: nodeType,
+		Height:   func() (*big.Int, error) { return big.NewInt(c.height), nil },
+		Head:     func() (*big.Int, error) { return big.NewInt(c.head), nil },
+	}
+}
+
+func newTestLightClientWatchdog(t *testing.T) (*LightClientWatchdog, *[]string, *time.Time) {
+	t.Helper()
+	var alarms []string
+	prevAlarm, prevNow := lightClientAlarm, lightClientNow
+	now := time.Unix(1700000000, 0)
+	lightClientAlarm = func(_ context.Context, msg string) { alarms = append(alarms, msg) }
+	lightClientNow = func() time.Time { return now }
+	t.Cleanup(func() { lightClientAlarm, lightClientNow = prevAlarm, prevNow })
+	return NewLightClientWatchdog(time.Millisecond, 10*time.Minute, log15.New()), &alarms, &now
+}
+
+func TestLightClientWatchdog_AlarmsWhenStuckAndSourceAdvances(t *testing.T) {
+	w, alarms, now := newTestLightClientWatchdog(t)
+	lc := &testLightClient{height: 100, head: 110}
+	w.Add(lc.lightClient("bsc", "map", constant.ProofTypeOfOrigin))
+
+	w.Check(context.Background())
+	m := observability.Default().Metrics
+	if got := testutil.ToFloat64(m.LightClientLag.WithLabelValues("bsc", "map")); got != 10 {
+		t.Fatalf("lightclient_lag = %v, want 10", got)
+	}
+
+	// the source keeps producing, the light client stays put
+	lc.head = 200
+	*now = now.Add(5 * time.Minute)
+	w.Check(context.Background())
+	if len(*alarms) != 0 {
+		t.Fatalf("alarmed inside the stall window: %v", *alarms)
+	}
+	*now = now.Add(6 * time.Minute)
+	w.Check(context.Background())
+	if len(*alarms) != 1 || !strings.Contains((*alarms)[0], "bsc on map stuck at 100") {
+		t.Fatalf("alarms = %v, want one stuck alarm", *alarms)
+	}
+	if got := testutil.ToFloat64(m.LightClient.WithLabelValues("bsc", "map")); got != 100 {
+		t.Fatalf("lightclient_height = %v, want 100", got)
+	}
+
+	// no re-alarm inside the cooldown
+	*now = now.Add(time.Minute)
+	w.Check(context.Background())
+	if len(*alarms) != 1 {
+		t.Fatalf("alarms = %d, want 1", len(*alarms))
+	}
+
+	lc.height = 150
+	w.Check(context.Background())
+	if len(*alarms) != 2 || !strings.Contains((*alarms)[1], "advancing again") {
+		t.Fatalf("alarms = %v, want a recovery alarm", *alarms)
+	}
+}
+
+func TestLightClientWatchdog_IdleSourceIsNotStuck(t *testing.T) {
+	w, alarms, now := newTestLightClientWatchdog(t)
+	idle := &testLightClient{height: 100, head: 100}
+	oracle := &testLightClient{height: 100, head: 100}
+	w.Add(idle.lightClient("map", "eth", constant.ProofTypeOfOrigin))
+	w.Add(oracle.lightClient("map", "tron", constant.ProofTypeOfLogOracle))
+
+	w.Check(context.Background())
+	oracle.head = 500
+	*now = now.Add(time.Hour)
+	w.Check(context.Background())
+	if len(*alarms) != 0 {
+		t.Fatalf("alarms = %v, want none", *alarms)
+	}
+}
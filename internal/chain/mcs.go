@@ -9,6 +9,7 @@ import (
 
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/internal/report"
+	"github.com/mapprotocol/compass/internal/txerr"
 	"github.com/mapprotocol/compass/pkg/msg"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -31,8 +32,9 @@ func (w *Writer) exeSwapMsg(m msg.Message) bool {
 // callContractWithMsg contract using address and function signature with message info
 func (w *Writer) callContractWithMsg(addr common.Address, m msg.Message) bool {
 	var (
-		errorCount, checkIdCount int64
-		needNonce                = true
+		errorCount, checkIdCount, reverts int64
+		alarmed                           bool
+		needNonce                         = true
 	)
 	for {
 		select {
@@ -72,7 +74,7 @@ func (w *Writer) callContractWithMsg(addr common.Address, m msg.Message) bool {
 			if err == nil {
 				w.log.Info("Submitted cross tx execution", "src", m.Source, "dst", m.Destination,
 					"srcHash", inputHash, "mcsTx", mcsTx.Hash())
-				err = w.txStatus(mcsTx.Hash())
+				err = w.txStatus(m, mcsTx.Hash())
 				if err != nil {
 					w.log.Warn("TxHash Status is not successful, will retry", "err", err)
 				} else {
@@ -89,20 +91,20 @@ func (w *Writer) callContractWithMsg(addr common.Address, m msg.Message) bool {
 				m.DoneCh <- struct{}{}
 				return true
 			} else {
-				for e := range constant.IgnoreError {
-					if strings.Index(err.Error(), e) != -1 {
-						w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "err", err)
-						m.DoneCh <- struct{}{}
-						return true
-					}
+				if ce := txerr.Classify(err); ce.Category.Skip() {
+					w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "category", ce.Category, "reason", ce.Reason, "err", err)
+					m.DoneCh <- struct{}{}
+					return true
+				}
+				if w.holdOnRevert(m, inputHash, err, &reverts) {
+					m.DoneCh <- struct{}{}
+					return true
 				}
 				w.log.Warn("Execution failed, will retry", "srcHash", inputHash, "err", err)
 			}
 			needNonce = w.needNonce(err)
-			errorCount++
-			if errorCount >= 10 {
+			if txerr.Alarm(&errorCount, &alarmed, err) {
 				w.mosAlarm(m, inputHash, err)
-				errorCount = 0
 			}
 			time.Sleep(constant.TxRetryInterval)
 		}
@@ -111,9 +113,10 @@ func (w *Writer) callContractWithMsg(addr common.Address, m msg.Message) bool {
 
 func (w *Writer) merlinWithMsg(m msg.Message) bool {
 	var (
-		errorCount int64
-		needNonce  = true
-		addr       = w.cfg.McsContract[m.Idx]
+		errorCount, reverts int64
+		alarmed             bool
+		needNonce           = true
+		addr                = w.cfg.McsContract[m.Idx]
 	)
 	for {
 		select {
@@ -131,7 +134,7 @@ func (w *Writer) merlinWithMsg(m msg.Message) bool {
 			mcsTx, err := w.sendTx(&addr, nil, m.Payload[0].([]byte))
 			if err == nil {
 				w.log.Info("Submitted cross tx execution", "src", m.Source, "dst", m.Destination, "srcHash", inputHash, "mcsTx", mcsTx.Hash())
-				err = w.txStatus(mcsTx.Hash())
+				err = w.txStatus(m, mcsTx.Hash())
 				if err != nil {
 					w.log.Warn("Store TxHash Status is not successful, will retry", "err", err)
 				} else {
@@ -143,21 +146,21 @@ func (w *Writer) merlinWithMsg(m msg.Message) bool {
 				m.DoneCh <- struct{}{}
 				return true
 			} else {
-				for e := range constant.IgnoreError {
-					if strings.Index(err.Error(), e) != -1 {
-						w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "err", err)
-						m.DoneCh <- struct{}{}
-						return true
-					}
+				if ce := txerr.Classify(err); ce.Category.Skip() {
+					w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "category", ce.Category, "reason", ce.Reason, "err", err)
+					m.DoneCh <- struct{}{}
+					return true
+				}
+				if w.holdOnRevert(m, inputHash, err, &reverts) {
+					m.DoneCh <- struct{}{}
+					return true
 				}
 				w.log.Warn("Execution SwapInVerify failed, will retry", "srcHash", inputHash, "err", err)
 			}
 
 			needNonce = w.needNonce(err)
-			errorCount++
-			if errorCount >= 10 {
+			if txerr.Alarm(&errorCount, &alarmed, err) {
 				w.mosAlarm(m, inputHash, err)
-				errorCount = 0
 			}
 			time.Sleep(constant.TxRetryInterval)
 		}
@@ -167,6 +170,7 @@ func (w *Writer) merlinWithMsg(m msg.Message) bool {
 func (w *Writer) proposal(m msg.Message) bool {
 	var (
 		errorCount int64
+		alarmed    bool
 		needNonce  = true
 		addr       = w.cfg.OracleNode
 	)
@@ -209,7 +213,7 @@ func (w *Writer) proposal(m msg.Message) bool {
 			mcsTx, err := w.sendTx(&addr, nil, data)
 			if err == nil {
 				w.log.Info("Submitted cross tx execution", "src", m.Source, "dst", m.Destination, "mcsTx", mcsTx.Hash())
-				err = w.txStatus(mcsTx.Hash())
+				err = w.txStatus(m, mcsTx.Hash())
 				if err != nil {
 					w.log.Warn("Store TxHash Status is not successful, will retry", "err", err)
 				} else {
@@ -221,21 +225,17 @@ func (w *Writer) proposal(m msg.Message) bool {
 				m.DoneCh <- struct{}{}
 				return true
 			} else {
-				for e := range constant.IgnoreError {
-					if strings.Index(err.Error(), e) != -1 {
-						w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "err", err)
-						m.DoneCh <- struct{}{}
-						return true
-					}
+				if ce := txerr.Classify(err); ce.Category.Skip() {
+					w.log.Info("Ignore This Error, Continue to the next", "id", m.Destination, "category", ce.Category, "reason", ce.Reason, "err", err)
+					m.DoneCh <- struct{}{}
+					return true
 				}
 				w.log.Warn("Execution SwapInVerify failed, will retry", "err", err)
 			}
 
 			needNonce = w.needNonce(err)
-			errorCount++
-			if errorCount >= 10 {
+			if txerr.Alarm(&errorCount, &alarmed, err) {
 				w.mosAlarm(m, "proposal", err)
-				errorCount = 0
 			}
 			time.Sleep(constant.TxRetryInterval)
 		}
@@ -323,7 +323,9 @@ func (w *Writer) checkOrderId(toAddress *common.Address, input common.Hash, useA
 	return exist, nil
 }
 
-func (w *Writer) txStatus(txHash common.Hash) error {
+// txStatus waits for txHash, sent for m, to be mined. The fee of a mined tx is
+// charged to the spend meter whether or not it succeeded.
+func (w *Writer) txStatus(m msg.Message, txHash common.Hash) error {
 	var count int64
 	for {
 		pending, err := w.conn.Client().IsPendingByTxHash(context.Background(), txHash) // Query whether it is on the chain
@@ -363,6 +365,11 @@ func (w *Writer) txStatus(txHash common.Hash) error {
 			return err
 		}
 
+		if fee, err := w.conn.Client().ReceiptFee(context.Background(), txHash); err == nil {
+			w.spend.Charge(m, fee)
+		} else {
+			w.log.Warn("Failed to read tx fee", "hash", txHash, "err", err)
+		}
 		if receipt.Status == types.ReceiptStatusSuccessful {
 			w.log.Info("Tx receipt status is success", "hash", txHash)
 			return nil
@@ -86,6 +86,9 @@ func (m *Messenger) sync() error {
 				time.Sleep(constant.BalanceRetryInterval)
 				continue
 			}
+			if m.Cfg.Id == m.Cfg.MapChainID {
+				proof.PrefetchZk(m.Cfg.Id, currentBlock.Uint64())
+			}
 			count, err := m.mosHandler(m, currentBlock)
 			if m.Cfg.SkipError && errors.Is(err, NotVerifyAble) {
 				m.Log.Info("Block not verify, will ignore", "startBlock", m.Cfg.StartBlock)
@@ -296,7 +299,7 @@ func Signer(cli *ethclient.Client, selfId, toId uint64, log *types.Log, proofTyp
 	}
 	switch proofType {
 	case constant.ProofTypeOfNewOracle:
-		genRece, err := genMptReceipt(cli, int64(selfId), bn) //  hash修改
+		genRece, err := genMptReceipt(cli, int64(selfId), bn, log.BlockHash) //  hash修改
 		if err != nil {
 			return nil, err
 		}
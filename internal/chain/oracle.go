@@ -19,6 +19,7 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/internal/tx"
 	"github.com/mapprotocol/compass/pkg/ethclient"
 	"github.com/mapprotocol/compass/pkg/util"
@@ -122,12 +123,14 @@ func (m *Oracle) filter() error {
 			}
 			m.State.IncBlocksProcessed(1)
 
-			err = m.BlockStore.StoreBlock(m.Cfg.StartBlock)
+			err = m.StoreFilterCursor()
 			if err != nil {
-				m.Log.Error("Filter Failed to write latest block to blockstore", "err", err)
+				m.Log.Error("Filter Failed to write the cursor", "cursor", m.FilterCursor().ID, "err", err)
 			}
 
-			time.Sleep(constant.MessengerInterval)
+			if !m.FilterBacklog() {
+				time.Sleep(constant.MessengerInterval)
+			}
 		}
 	}
 }
@@ -249,7 +252,7 @@ func log2Oracle(m *Oracle, logs []types.Log, blockNumber *big.Int, filterId int6
 				return fmt.Errorf("oracle get header failed, err: %w", err)
 			}
 			receipt = &header.ReceiptHash
-			genRece, err := genMptReceipt(m.Conn.Client(), int64(m.Cfg.Id), blockNumber)
+			genRece, err := genMptReceipt(m.Conn.Client(), int64(m.Cfg.Id), blockNumber, tmp.BlockHash)
 			if genRece != nil {
 				receipt = genRece
 			}
@@ -321,21 +324,40 @@ func Completion(bytes []byte, number int) []byte {
 	return ret
 }
 
-func genMptReceipt(cli *ethclient.Client, selfId int64, latestBlock *big.Int) (*common.Hash, error) {
+// genMptReceipt is the receipts root of block latestBlock, blockHash the way
+// the chains listed encode it, nil for the others. The receipts and the root
+// go through the shared caches.
+func genMptReceipt(cli *ethclient.Client, selfId int64, latestBlock *big.Int, blockHash common.Hash) (*common.Hash, error) {
 	if !exist(selfId, []int64{constant.CfxChainId}) {
 		return nil, nil
 	}
-	txsHash, err := mapprotocol.GetTxsByBn(cli, latestBlock)
-	if err != nil {
-		return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
-	}
-	receipts, err := tx.GetReceiptsByTxsHash(cli, txsHash)
+	root, err := proof.CacheProof.Proof(uint64(selfId), blockHash, "mptReceipt", func() ([]byte, error) {
+		receipts, err := proof.CacheReceipt.Receipts(uint64(selfId), blockHash, func() ([]*types.Receipt, error) {
+			txsHash, err := mapprotocol.GetTxsByBn(cli, latestBlock)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get tx hashes Logs: %w", err)
+			}
+			receipts, err := tx.GetBlockReceipts(cli, latestBlock, txsHash)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+			}
+			return receipts, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		dls, err := ireceipt.Encode(cli, msg.ChainId(selfId), ireceipt.Ethereum, receipts)
+		if err != nil {
+			return nil, err
+		}
+		tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+		tr = proof.DeriveTire(dls, tr)
+		return tr.Hash().Bytes(), nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to get receipts hashes Logs: %w", err)
+		return nil, err
 	}
-	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
-	tr = proof.DeriveTire(types.Receipts(receipts), tr)
-	ret := tr.Hash()
+	ret := common.BytesToHash(root)
 	return &ret, nil
 }
 
@@ -386,7 +408,7 @@ func ExternalOracleInput(selfId, nodeType int64, log *types.Log, client *ethclie
 			return nil, fmt.Errorf("oracle get header failed, err: %w", err)
 		}
 		receipt = &header.ReceiptHash
-		genRece, err := genMptReceipt(client, selfId, blockNumber)
+		genRece, err := genMptReceipt(client, selfId, blockNumber, log.BlockHash)
 		if genRece != nil {
 			receipt = genRece
 		}
@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/txerr"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/util"
+)
+
+// PreflightError is returned by sendTx when the simulation of a delivery
+// fails. Nothing was broadcast.
+type PreflightError struct {
+	To    common.Address
+	Input []byte
+	Err   *txerr.Error
+}
+
+func (e *PreflightError) Error() string {
+	return "pre-flight simulation failed: " + e.Err.Error()
+}
+
+func (e *PreflightError) Unwrap() error { return e.Err }
+
+// preflight simulates call at PreflightBlock so deliveries that would revert
+// on chain are never broadcast. eth_estimateGas stands in for eth_call: it
+// executes the call against the same block and fails with the same revert
+// data, and its estimate is the gas limit sendTx needs anyway, so a delivery
+// costs one simulation instead of an eth_call and an estimate.
+func (w *Writer) preflight(call ethereum.CallMsg) (uint64, error) {
+	block := big.NewInt(-1)
+	if w.cfg.PreflightBlock == PreflightLatest {
+		block = nil
+	}
+	gas, err := w.conn.Client().EstimateGasAt(context.Background(), call, block)
+	if err == nil {
+		return gas, nil
+	}
+	ce := txerr.Classify(err)
+	w.log.Warn("Pre-flight simulation failed", "to", call.To, "category", ce.Category, "reason", ce.Reason, "err", err)
+	pe := &PreflightError{Input: call.Data, Err: ce}
+	if call.To != nil {
+		pe.To = *call.To
+	}
+	return 0, pe
+}
+
+// holdable reports whether messages of typ may be parked in the holding
+// area. Only deliveries are: header syncs and proposals must go through.
+func holdable(typ msg.TransferType) bool {
+	switch typ {
+	case msg.SwapWithProof, msg.SwapWithMapProof, msg.SwapWithMerlin:
+		return true
+	default:
+		return false
+	}
+}
+
+// holdOnRevert counts the consecutive reverted simulations of m and, once
+// PreflightHoldAfter is reached, parks m in the holding area instead of
+// retrying it. It reports whether m was held; any other error resets the count.
+// Nothing but deliveries is ever held, and nothing is with PreflightHoldAfter 0.
+func (w *Writer) holdOnRevert(m msg.Message, srcHash interface{}, err error, reverts *int64) bool {
+	var pe *PreflightError
+	if !holdable(m.Type) || !errors.As(err, &pe) || pe.Err.Category != txerr.Reverted {
+		*reverts = 0
+		return false
+	}
+	*reverts++
+	if w.holding == nil || w.cfg.PreflightHoldAfter == 0 || *reverts < w.cfg.PreflightHoldAfter {
+		return false
+	}
+
+	hm := HeldMessage{
+		Type:        m.Type,
+		Source:      m.Source,
+		Destination: m.Destination,
+		To:          pe.To.Hex(),
+		Input:       "0x" + common.Bytes2Hex(pe.Input),
+		Category:    pe.Err.Category.String(),
+		Reason:      pe.Err.Reason,
+		Error:       pe.Err.Err.Error(),
+	}
+	if srcHash != nil {
+		hm.SrcHash = fmt.Sprintf("%v", srcHash)
+	}
+	if herr := w.holding.Put(hm); herr != nil {
+		w.log.Error("Failed to write holding area, will keep retrying", "path", w.holding.Path(), "err", herr)
+		return false
+	}
+	w.log.Warn("Delivery keeps reverting in simulation, moved to holding area", "type", m.Type, "srcHash", hm.SrcHash,
+		"reason", hm.Reason, "path", w.holding.Path())
+	util.Alarm(context.Background(), fmt.Sprintf("%s2%s %s held after %d reverted simulations, srcHash=%s reason=%s, see %s",
+		mapprotocol.OnlineChaId[m.Source], mapprotocol.OnlineChaId[m.Destination], m.Type, *reverts, hm.SrcHash,
+		hm.Reason, w.holding.Path()))
+	*reverts = 0
+	return true
+}
@@ -0,0 +1,61 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/compass/internal/txerr"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+func TestHoldOnRevert_ParksAfterConsecutiveReverts(t *testing.T) {
+	cfg := &Config{Name: "bsc", Id: 56, From: "0xabc", HoldingPath: t.TempDir(), PreflightHoldAfter: 2}
+	holding, err := NewHoldingArea(cfg)
+	if err != nil {
+		t.Fatalf("NewHoldingArea: %v", err)
+	}
+	w := &Writer{cfg: *cfg, log: log15.New(), holding: holding}
+	m := msg.Message{Type: msg.SwapWithProof, Source: 1, Destination: 56}
+	reverted := &PreflightError{
+		To:    common.HexToAddress("0x01"),
+		Input: []byte{0x12, 0x34},
+		Err:   txerr.Classify(errors.New("execution reverted:0xdeadbeef")),
+	}
+
+	var reverts int64
+	if w.holdOnRevert(m, "0xsrc", reverted, &reverts) {
+		t.Fatal("held after the first revert")
+	}
+	if w.holdOnRevert(m, "0xsrc", errors.New("connection refused"), &reverts) || reverts != 0 {
+		t.Fatal("a non-revert error must reset the count")
+	}
+	w.holdOnRevert(m, "0xsrc", reverted, &reverts)
+	if !w.holdOnRevert(m, "0xsrc", reverted, &reverts) {
+		t.Fatal("expected the message to be held after two consecutive reverts")
+	}
+
+	// header syncs are never held
+	sync := msg.Message{Type: msg.SyncToMap, Source: 1, Destination: 56}
+	for i := 0; i < 3; i++ {
+		if w.holdOnRevert(sync, "0xsrc", reverted, &reverts) {
+			t.Fatal("held a header sync")
+		}
+	}
+
+	held, err := holding.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(held) != 1 || held[0].SrcHash != "0xsrc" || held[0].Input != "0x1234" || held[0].Category != "reverted" {
+		t.Fatalf("held = %+v", held)
+	}
+
+	if err = holding.Remove(held[0]); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if held, err = holding.List(); err != nil || len(held) != 0 {
+		t.Fatalf("after Remove held = %+v, err %v", held, err)
+	}
+}
@@ -0,0 +1,164 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/internal/spend"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/util"
+)
+
+// spendRecheck is how often a writer held by the gas budget re-evaluates it
+const spendRecheck = time.Minute
+
+// spendAlarm is a seam for tests.
+var spendAlarm = util.Alarm
+
+type spendEntry struct {
+	at  time.Time
+	fee *big.Int
+}
+
+// SpendMeter accounts for the native fees a chain's writer pays. Every charge
+// feeds the balance watcher's relays-left estimate, the spend metrics and the
+// daily spend report. With GasBudget set it also keeps a rolling window of the
+// fees and, once the window is spent, holds every message type that is not in
+// GasBudgetCritical until older fees age out.
+// All methods are nil-safe; a nil meter never holds sends.
+type SpendMeter struct {
+	name     string
+	decimals int
+	balance  *BalanceWatcher
+	budget   *big.Int
+	window   time.Duration
+	critical map[msg.TransferType]bool
+	state    *observability.SpendState
+	log      log15.Logger
+
+	mu       sync.Mutex
+	entries  []spendEntry
+	spent    *big.Int
+	exceeded bool
+}
+
+// NewSpendMeter builds the meter of the chain described by cfg. balance may be nil.
+func NewSpendMeter(cfg *Config, decimals int, balance *BalanceWatcher, log log15.Logger) *SpendMeter {
+	window := cfg.GasBudgetWindow
+	if window <= 0 {
+		window = DefaultGasBudgetWindow
+	}
+	critical := make(map[msg.TransferType]bool, len(cfg.GasBudgetCritical))
+	for _, t := range cfg.GasBudgetCritical {
+		critical[t] = true
+	}
+	return &SpendMeter{
+		name:     cfg.Name,
+		decimals: decimals,
+		balance:  balance,
+		budget:   toBaseUnits(cfg.GasBudget, decimals),
+		window:   window,
+		critical: critical,
+		state:    observability.RegisterSpend(cfg.Name),
+		log:      log,
+		spent:    new(big.Int),
+	}
+}
+
+// Charge records the fee of a confirmed transaction sent for m, in the chain's
+// smallest unit.
+func (s *SpendMeter) Charge(m msg.Message, fee *big.Int) {
+	if s == nil || fee == nil || fee.Sign() <= 0 {
+		return
+	}
+	s.balance.RecordCost(fee)
+	amount := s.toFloat(fee)
+	source := chainName(m.Source)
+	s.state.AddSpent(string(m.Type), source, amount)
+	spend.Add(s.name, string(m.Type), source, amount)
+	if s.budget == nil {
+		return
+	}
+
+	s.mu.Lock()
+	s.entries = append(s.entries, spendEntry{at: time.Now(), fee: new(big.Int).Set(fee)})
+	s.spent.Add(s.spent, fee)
+	s.refreshLocked()
+	s.mu.Unlock()
+}
+
+// Allowed reports whether a message of type t may be sent under the budget.
+func (s *SpendMeter) Allowed(t msg.TransferType) bool {
+	if s == nil || s.budget == nil || s.critical[t] {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refreshLocked()
+	return !s.exceeded
+}
+
+// WaitForBudget blocks while messages of type t are held by the budget. It
+// returns false if stop is closed first.
+func (s *SpendMeter) WaitForBudget(t msg.TransferType, stop <-chan int) bool {
+	for !s.Allowed(t) {
+		s.log.Warn("Gas budget spent, holding non-critical send", "type", t)
+		select {
+		case <-stop:
+			return false
+		case <-time.After(spendRecheck):
+		}
+	}
+	return true
+}
+
+// refreshLocked drops fees that left the window and alarms when the budget is
+// crossed in either direction.
+func (s *SpendMeter) refreshLocked() {
+	cutoff := time.Now().Add(-s.window)
+	drop := 0
+	for drop < len(s.entries) && s.entries[drop].at.Before(cutoff) {
+		s.spent.Sub(s.spent, s.entries[drop].fee)
+		drop++
+	}
+	s.entries = s.entries[drop:]
+
+	exceeded := s.spent.Cmp(s.budget) >= 0
+	if exceeded == s.exceeded {
+		return
+	}
+	s.exceeded = exceeded
+	s.state.SetBudgetExceeded(exceeded)
+	if exceeded {
+		s.log.Warn("Gas budget exceeded, pausing non-critical sends", "spent", s.format(s.spent), "budget", s.format(s.budget))
+		go spendAlarm(context.Background(), fmt.Sprintf("%s gas budget exceeded: %s spent in the last %s (budget %s), non-critical sends paused",
+			s.name, s.format(s.spent), s.window, s.format(s.budget)))
+		return
+	}
+	s.log.Info("Gas budget available again, resuming sends", "spent", s.format(s.spent), "budget", s.format(s.budget))
+	go spendAlarm(context.Background(), fmt.Sprintf("%s gas budget available again (%s of %s spent), sends resumed",
+		s.name, s.format(s.spent), s.format(s.budget)))
+}
+
+func (s *SpendMeter) toFloat(v *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), new(big.Float).SetInt(unit(s.decimals))).Float64()
+	return f
+}
+
+func (s *SpendMeter) format(v *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(v), new(big.Float).SetInt(unit(s.decimals))).Text('f', 6)
+}
+
+func chainName(id msg.ChainId) string {
+	if name, ok := mapprotocol.OnlineChaId[id]; ok {
+		return name
+	}
+	return strconv.FormatUint(uint64(id), 10)
+}
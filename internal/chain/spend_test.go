@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ChainSafe/log15"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+func TestSpendMeter_HoldsNonCriticalOnceBudgetSpent(t *testing.T) {
+	alarms := make(chan string, 4)
+	prev := spendAlarm
+	spendAlarm = func(_ context.Context, msg string) { alarms <- msg }
+	t.Cleanup(func() { spendAlarm = prev })
+
+	cfg := Config{Name: "bsc", GasBudget: 1, GasBudgetWindow: time.Hour, GasBudgetCritical: DefaultGasBudgetCritical}
+	s := NewSpendMeter(&cfg, EvmDecimals, nil, log15.New())
+	half := new(big.Int).Mul(big.NewInt(5), unit(EvmDecimals-1))
+
+	s.Charge(msg.Message{Type: msg.SwapWithProof}, half)
+	if !s.Allowed(msg.SwapWithProof) {
+		t.Fatal("half the budget spent, sends should still be allowed")
+	}
+	s.Charge(msg.Message{Type: msg.SyncToMap}, half)
+	if s.Allowed(msg.SwapWithProof) {
+		t.Fatal("budget spent, non-critical sends should be held")
+	}
+	if !s.Allowed(msg.SyncToMap) {
+		t.Fatal("critical sends must never be held")
+	}
+	select {
+	case <-alarms:
+	case <-time.After(time.Second):
+		t.Fatal("expected a budget alarm")
+	}
+
+	// age the fees out of the window
+	s.mu.Lock()
+	for i := range s.entries {
+		s.entries[i].at = s.entries[i].at.Add(-2 * time.Hour)
+	}
+	s.mu.Unlock()
+	if !s.Allowed(msg.SwapWithProof) {
+		t.Fatal("sends should resume once the fees leave the window")
+	}
+}
+
+func TestSpendMeter_NilIsNoop(t *testing.T) {
+	var s *SpendMeter
+	s.Charge(msg.Message{}, big.NewInt(1))
+	if !s.Allowed(msg.SwapWithProof) || !s.WaitForBudget(msg.SwapWithProof, nil) {
+		t.Fatal("nil meter must never hold sends")
+	}
+}
@@ -3,13 +3,14 @@ package chain
 import (
 	"context"
 	"math/big"
-	"strings"
 
 	"github.com/mapprotocol/compass/pkg/msg"
 
+	connection "github.com/mapprotocol/compass/connections/ethereum"
 	"github.com/mapprotocol/compass/core"
 
 	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/txerr"
 
 	"github.com/ChainSafe/log15"
 	"github.com/ethereum/go-ethereum"
@@ -18,22 +19,38 @@ import (
 )
 
 type Writer struct {
-	cfg    Config
-	conn   core.Connection
-	log    log15.Logger
-	stop   <-chan int
-	sysErr chan<- error // Reports fatal error to core
+	cfg     Config
+	conn    core.Connection
+	log     log15.Logger
+	stop    <-chan int
+	sysErr  chan<- error // Reports fatal error to core
+	balance *BalanceWatcher
+	spend   *SpendMeter
+	holding *HoldingArea
 }
 
 // NewWriter creates and returns Writer
 func NewWriter(conn core.Connection, cfg *Config, log log15.Logger, stop <-chan int, sysErr chan<- error) *Writer {
-	return &Writer{
+	w := &Writer{
 		cfg:    *cfg,
 		conn:   conn,
 		log:    log,
 		stop:   stop,
 		sysErr: sysErr,
 	}
+	if kp := conn.Keypair(); kp != nil {
+		w.balance = NewBalanceWatcher(cfg, kp.Address.Hex(), EvmDecimals, func(ctx context.Context) (*big.Int, error) {
+			return conn.Client().BalanceAt(ctx, kp.Address, nil)
+		}, log)
+		w.balance.Start(stop)
+	}
+	w.spend = NewSpendMeter(cfg, EvmDecimals, w.balance, log)
+	holding, err := NewHoldingArea(cfg)
+	if err != nil {
+		log.Warn("Holding area unavailable, reverting deliveries will be retried", "err", err)
+	}
+	w.holding = holding
+	return w
 }
 
 func (w *Writer) start() error {
@@ -45,6 +62,12 @@ func (w *Writer) start() error {
 // A bool is returned to indicate failure/success, this should be ignored except for within tests.
 func (w *Writer) ResolveMessage(m msg.Message) bool {
 	w.log.Info("Attempting to resolve message", "type", m.Type, "src", m.Source, "dst", m.Destination)
+	if !w.balance.WaitUntilFunded(w.stop) {
+		return false
+	}
+	if !w.spend.WaitForBudget(m.Type, w.stop) {
+		return false
+	}
 
 	switch m.Type {
 	case msg.SyncToMap:
@@ -79,17 +102,8 @@ func (w *Writer) sendTx(toAddress *common.Address, value *big.Int, input []byte)
 		Data:     input,
 	}
 
-	gasLimit, err := w.conn.Client().EstimateGas(context.Background(), msg)
+	gasLimit, err := w.estimateGas(msg)
 	if err != nil {
-		w.log.Error("EstimateGas failed sendTx", "error:", err.Error())
-		if err.Error() == "execution reverted" {
-			_, serr := w.conn.Client().SelfEstimateGas(context.Background(),
-				w.cfg.Endpoint, from.Hex(), toAddress.Hex(), "0x"+common.Bytes2Hex(input))
-			w.log.Error("EstimateGas failed sendTx", "error:", serr)
-			if serr != nil {
-				return nil, serr
-			}
-		}
 		return nil, err
 	}
 
@@ -99,13 +113,8 @@ func (w *Writer) sendTx(toAddress *common.Address, value *big.Int, input []byte)
 	if w.cfg.LimitMultiplier > 1 {
 		gasLimit = uint64(float64(gasLimit) * w.cfg.LimitMultiplier)
 	}
-	if w.cfg.Id == constant.XlayerId {
-		gasLimit = gasLimit + 500000
-	}
-
-	if w.cfg.GasMultiplier > 1 && gasPrice != nil {
-		gasPrice = big.NewInt(0).SetInt64(int64(float64(gasPrice.Int64()) * w.cfg.GasMultiplier))
-	}
+	gasLimit += w.cfg.GasLimitPadding
+	gasPrice = w.legacyGasPrice(gasPrice)
 	if gasLimit < constant.MinGasLimit {
 		gasLimit = constant.MinGasLimit
 	}
@@ -113,7 +122,7 @@ func (w *Writer) sendTx(toAddress *common.Address, value *big.Int, input []byte)
 		gasLimit = constant.MaxGasLimit
 	}
 	w.log.Info("SendTx gasPrice", "gasPrice", gasPrice, "gasTipCap", gasTipCap, "gasFeeCap", gasFeeCap, "gasLimit", gasLimit,
-		"limitMultiplier", w.cfg.LimitMultiplier, "gasMultiplier", w.cfg.GasMultiplier, "nonce", nonce.Uint64())
+		"limitMultiplier", w.cfg.LimitMultiplier, "gasLimitPadding", w.cfg.GasLimitPadding, "gasMultiplier", w.cfg.GasMultiplier, "nonce", nonce.Uint64())
 	// td interface
 	var td types.TxData
 	// EIP-1559
@@ -158,10 +167,62 @@ func (w *Writer) sendTx(toAddress *common.Address, value *big.Int, input []byte)
 	return signedTx, nil
 }
 
-func (w *Writer) needNonce(err error) bool {
-	if err == nil || err.Error() == constant.ErrNonceTooLow.Error() || strings.Index(err.Error(), "nonce too low") != -1 {
-		return true
+// legacyGasPrice is the price a legacy transaction bids. The node strategy
+// keeps the historic bid, which applies gasMultiplier once more on top of the
+// connection's price; the other strategies bid what they priced.
+func (w *Writer) legacyGasPrice(price *big.Int) *big.Int {
+	if price == nil || w.cfg.GasMultiplier <= 1 {
+		return price
+	}
+	if s := w.cfg.Gas.Strategy; s != "" && s != connection.GasStrategyNode {
+		return price
 	}
+	return big.NewInt(0).SetInt64(int64(float64(price.Int64()) * w.cfg.GasMultiplier))
+}
 
-	return false
+// estimateGas estimates the gas of call. With preflight on the estimate is the
+// simulation, so a call that fails returns a *PreflightError.
+func (w *Writer) estimateGas(call ethereum.CallMsg) (uint64, error) {
+	if w.cfg.Preflight {
+		return w.preflight(call)
+	}
+	gasLimit, err := w.conn.Client().EstimateGas(context.Background(), call)
+	if err != nil {
+		w.log.Error("EstimateGas failed sendTx", "error:", err.Error())
+		if err.Error() == "execution reverted" {
+			_, serr := w.conn.Client().SelfEstimateGas(context.Background(),
+				w.cfg.Endpoint, call.From.Hex(), call.To.Hex(), "0x"+common.Bytes2Hex(call.Data))
+			w.log.Error("EstimateGas failed sendTx", "error:", serr)
+			if serr != nil {
+				return 0, serr
+			}
+		}
+		return 0, err
+	}
+	return gasLimit, nil
+}
+
+// gasConfigurable is implemented by connections that accept a GasStrategy.
+type gasConfigurable interface {
+	SetGasStrategy(chain string, s connection.GasStrategy)
+	GasMultiplier() *big.Float
+}
+
+// ApplyGasStrategy installs the configured gas strategy on conn. Connections
+// that don't price EVM transactions are left untouched.
+func ApplyGasStrategy(conn core.Connection, cfg *Config) error {
+	gc, ok := conn.(gasConfigurable)
+	if !ok {
+		return nil
+	}
+	s, err := connection.NewGasStrategy(cfg.Gas, gc.GasMultiplier())
+	if err != nil {
+		return err
+	}
+	gc.SetGasStrategy(cfg.Name, s)
+	return nil
+}
+
+func (w *Writer) needNonce(err error) bool {
+	return err == nil || txerr.Of(err) == txerr.NonceTooLow
 }
@@ -0,0 +1,33 @@
+package chain
+
+import (
+	"math/big"
+	"testing"
+
+	connection "github.com/mapprotocol/compass/connections/ethereum"
+)
+
+func TestLegacyGasPrice(t *testing.T) {
+	price := big.NewInt(10e9)
+	for _, tc := range []struct {
+		name       string
+		strategy   string
+		multiplier float64
+		want       int64
+	}{
+		{"default strategy keeps the historic bid", "", 1.25, 12.5e9},
+		{"node strategy keeps the historic bid", connection.GasStrategyNode, 1.25, 12.5e9},
+		{"no multiplier", "", 1, 10e9},
+		{"other strategies bid their price", connection.GasStrategyFeeHistory, 1.25, 10e9},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &Writer{cfg: Config{GasMultiplier: tc.multiplier, Gas: connection.GasConfig{Strategy: tc.strategy}}}
+			if got := w.legacyGasPrice(price); got.Int64() != tc.want {
+				t.Fatalf("legacy bid = %s, want %d", got, tc.want)
+			}
+		})
+	}
+	if (&Writer{cfg: Config{GasMultiplier: 2}}).legacyGasPrice(nil) != nil {
+		t.Fatal("a London transaction has no legacy price")
+	}
+}
@@ -28,34 +28,6 @@ var (
 	ZeroAddress = common.HexToAddress("0x0000000000000000000000000000000000000000")
 )
 
-var IgnoreError = map[string]struct{}{
-	"order exist":                       {},
-	"Header is have":                    {},
-	"header is have":                    {},
-	"height error":                      {},
-	"invalid start block":               {},
-	"invalid syncing block":             {},
-	"initialized or unknown epoch":      {},
-	"no need to update exe headers":     {},
-	"New block must have higher height": {},
-	"round mismatch":                    {},
-	"epoch mismatch":                    {},
-	"headers size too big":              {},
-	"Height error":                      {},
-	"Update height0 error":              {},
-	"invalid end exe header number":     {},
-	"the update finalized slot should be higher than the finalized slot":      {},
-	"previous exe block headers should be updated before update light client": {},
-	"REVERT opcode executed":    {},
-	"Validators repetition add": {},
-	"oracle: already update":    {},
-	"already verified":          {},
-	"0x6838b56d":                {}, //already_meet()
-	"0x8bc9d07c":                {}, //already_proposal()
-	"0x98087555":                {}, // order exist
-	"already in use":            {}, // solana order exist
-}
-
 type BlockIdOfEth2 string
 
 const (
@@ -133,6 +105,7 @@ const (
 	Conflux  = "conflux"
 	Eth2     = "eth2"
 	Ethereum = "ethereum"
+	Evm      = "evm"
 	Klaytn   = "klaytn"
 	Matic    = "matic"
 	Near     = "near"
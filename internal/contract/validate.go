@@ -27,6 +27,11 @@ func InitDefaultValidator(c *contract.Call) {
 	defaultValidator = &validator{c: c}
 }
 
+// SetDefaultValidator makes Validate ask v, for callers without a MAP chain.
+func SetDefaultValidator(v Validator) {
+	defaultValidator = v
+}
+
 type SwapDataValidator struct {
 	Relay        bool
 	DstChain     *big.Int
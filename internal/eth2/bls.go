@@ -0,0 +1,228 @@
+package eth2
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+// syncCommitteeDST is the ciphersuite of eth2 BLS signatures, proof of
+// possession with signatures in G2.
+var syncCommitteeDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+var (
+	fieldModulus, _ = new(big.Int).SetString("1a0111ea397fe69a4b1ba7b6434bacd764774b84f38512bf6730d2a0f6b0f6241eabfffeb153ffffb9feffffffffaaab", 16)
+	halfModulus     = new(big.Int).Rsh(fieldModulus, 1)                                  // (p-1)/2
+	sqrtExp         = new(big.Int).Rsh(new(big.Int).Add(fieldModulus, big.NewInt(1)), 2) // (p+1)/4
+	sqrtExp2        = new(big.Int).Rsh(new(big.Int).Sub(fieldModulus, big.NewInt(3)), 2) // (p-3)/4
+)
+
+const (
+	flagCompressed = 0x80
+	flagInfinity   = 0x40
+	flagSign       = 0x20
+)
+
+// decompressG1 decodes a compressed G1 point, a BLS public key. Keys at
+// infinity or outside the subgroup are rejected.
+func decompressG1(in []byte) (*bls12381.PointG1, error) {
+	if len(in) != 48 {
+		return nil, fmt.Errorf("g1 point of %d bytes", len(in))
+	}
+	if in[0]&flagCompressed == 0 || in[0]&flagInfinity != 0 {
+		return nil, errors.New("g1 point not compressed or at infinity")
+	}
+	xb := append([]byte{}, in...)
+	xb[0] &= 0x1f
+	x := new(big.Int).SetBytes(xb)
+	if x.Cmp(fieldModulus) >= 0 {
+		return nil, errors.New("g1 x not in field")
+	}
+	// y^2 = x^3 + 4
+	y2 := new(big.Int).Exp(x, big.NewInt(3), fieldModulus)
+	y2.Add(y2, big.NewInt(4)).Mod(y2, fieldModulus)
+	y := new(big.Int).Exp(y2, sqrtExp, fieldModulus)
+	if new(big.Int).Exp(y, big.NewInt(2), fieldModulus).Cmp(y2) != 0 {
+		return nil, errors.New("g1 x not on curve")
+	}
+	if (y.Cmp(halfModulus) > 0) != (in[0]&flagSign != 0) {
+		y.Sub(fieldModulus, y)
+	}
+	raw := make([]byte, 96)
+	x.FillBytes(raw[:48])
+	y.FillBytes(raw[48:])
+	g1 := bls12381.NewG1()
+	p, err := g1.FromBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !g1.InCorrectSubgroup(p) {
+		return nil, errors.New("g1 point not in subgroup")
+	}
+	return p, nil
+}
+
+// fp2 is c0 + c1*i over the base field, for the square root decompression
+// of G2 points needs.
+type fp2 struct{ c0, c1 *big.Int }
+
+func (a fp2) mul(b fp2) fp2 {
+	t0 := new(big.Int).Mul(a.c0, b.c0)
+	t1 := new(big.Int).Mul(a.c1, b.c1)
+	c1 := new(big.Int).Mul(a.c0, b.c1)
+	c1.Add(c1, new(big.Int).Mul(a.c1, b.c0)).Mod(c1, fieldModulus)
+	return fp2{t0.Sub(t0, t1).Mod(t0, fieldModulus), c1}
+}
+
+func (a fp2) exp(e *big.Int) fp2 {
+	ret := fp2{big.NewInt(1), big.NewInt(0)}
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		ret = ret.mul(ret)
+		if e.Bit(i) == 1 {
+			ret = ret.mul(a)
+		}
+	}
+	return ret
+}
+
+func (a fp2) equal(b fp2) bool {
+	return a.c0.Cmp(b.c0) == 0 && a.c1.Cmp(b.c1) == 0
+}
+
+// sqrt is algorithm 9 of eprint 2012/685, for p = 3 mod 4.
+func (a fp2) sqrt() (fp2, bool) {
+	a1 := a.exp(sqrtExp2)
+	x0 := a1.mul(a)
+	alpha := a1.mul(x0)
+	var x fp2
+	if alpha.equal(fp2{new(big.Int).Sub(fieldModulus, big.NewInt(1)), big.NewInt(0)}) {
+		// x = i * x0
+		x = fp2{new(big.Int).Mod(new(big.Int).Sub(fieldModulus, x0.c1), fieldModulus), x0.c0}
+	} else {
+		b := fp2{new(big.Int).Add(alpha.c0, big.NewInt(1)), alpha.c1}.exp(halfModulus)
+		x = b.mul(x0)
+	}
+	return x, x.mul(x).equal(a)
+}
+
+// decompressG2 decodes a compressed G2 point, a BLS signature. Points at
+// infinity or outside the subgroup are rejected.
+func decompressG2(in []byte) (*bls12381.PointG2, error) {
+	if len(in) != 96 {
+		return nil, fmt.Errorf("g2 point of %d bytes", len(in))
+	}
+	if in[0]&flagCompressed == 0 || in[0]&flagInfinity != 0 {
+		return nil, errors.New("g2 point not compressed or at infinity")
+	}
+	xb := append([]byte{}, in...)
+	xb[0] &= 0x1f
+	x := fp2{new(big.Int).SetBytes(xb[48:]), new(big.Int).SetBytes(xb[:48])}
+	if x.c0.Cmp(fieldModulus) >= 0 || x.c1.Cmp(fieldModulus) >= 0 {
+		return nil, errors.New("g2 x not in field")
+	}
+	// y^2 = x^3 + 4(1+i)
+	y2 := x.mul(x).mul(x)
+	y2.c0.Add(y2.c0, big.NewInt(4)).Mod(y2.c0, fieldModulus)
+	y2.c1.Add(y2.c1, big.NewInt(4)).Mod(y2.c1, fieldModulus)
+	y, ok := y2.sqrt()
+	if !ok {
+		return nil, errors.New("g2 x not on curve")
+	}
+	larger := y.c1.Cmp(halfModulus) > 0 || (y.c1.Sign() == 0 && y.c0.Cmp(halfModulus) > 0)
+	if larger != (in[0]&flagSign != 0) {
+		y.c0.Sub(fieldModulus, y.c0).Mod(y.c0, fieldModulus)
+		y.c1.Sub(fieldModulus, y.c1).Mod(y.c1, fieldModulus)
+	}
+	raw := make([]byte, 192)
+	x.c1.FillBytes(raw[:48])
+	x.c0.FillBytes(raw[48:96])
+	y.c1.FillBytes(raw[96:144])
+	y.c0.FillBytes(raw[144:])
+	g2 := bls12381.NewG2()
+	p, err := g2.FromBytes(raw)
+	if err != nil {
+		return nil, err
+	}
+	if !g2.InCorrectSubgroup(p) {
+		return nil, errors.New("g2 point not in subgroup")
+	}
+	return p, nil
+}
+
+// expandMessageXMD is expand_message_xmd of RFC 9380 with sha256.
+func expandMessageXMD(msg, dst []byte, n int) []byte {
+	ell := (n + 31) / 32
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha256.New()
+	h.Write(make([]byte, 64))
+	h.Write(msg)
+	h.Write([]byte{byte(n >> 8), byte(n), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	out := make([]byte, 0, ell*32)
+	prev := make([]byte, 32)
+	for i := 1; i <= ell; i++ {
+		in := make([]byte, 32)
+		for j := range in {
+			in[j] = b0[j] ^ prev[j]
+		}
+		h.Reset()
+		h.Write(in)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		prev = h.Sum(nil)
+		out = append(out, prev...)
+	}
+	return out[:n]
+}
+
+// hashToG2 is hash_to_curve of the BLS12381G2_XMD:SHA-256_SSWU_RO_ suite.
+// MapToCurve clears the cofactor of each point, which commutes with the sum.
+func hashToG2(msg, dst []byte) (*bls12381.PointG2, error) {
+	uniform := expandMessageXMD(msg, dst, 256)
+	g2 := bls12381.NewG2()
+	ret := g2.Zero()
+	for i := 0; i < 2; i++ {
+		in := make([]byte, 96)
+		for j := 0; j < 2; j++ {
+			e := new(big.Int).SetBytes(uniform[64*(2*i+j) : 64*(2*i+j+1)])
+			e.Mod(e, fieldModulus)
+			// the field element is c1 then c0
+			e.FillBytes(in[48*(1-j) : 48*(2-j)])
+		}
+		q, err := g2.MapToCurve(in)
+		if err != nil {
+			return nil, err
+		}
+		g2.Add(ret, ret, q)
+	}
+	return g2.Affine(ret), nil
+}
+
+// fastAggregateVerify checks sig is the aggregate signature of msg by every
+// key of pubkeys.
+func fastAggregateVerify(pubkeys []*bls12381.PointG1, msg, sig []byte) (bool, error) {
+	if len(pubkeys) == 0 {
+		return false, errors.New("no signers")
+	}
+	s, err := decompressG2(sig)
+	if err != nil {
+		return false, err
+	}
+	g1 := bls12381.NewG1()
+	agg := g1.Zero()
+	for _, pk := range pubkeys {
+		g1.Add(agg, agg, pk)
+	}
+	h, err := hashToG2(msg, syncCommitteeDST)
+	if err != nil {
+		return false, err
+	}
+	// e(agg, H(msg)) == e(g1, sig)
+	return bls12381.NewPairingEngine().AddPair(g1.Affine(agg), h).AddPairInv(g1.One(), s).Check(), nil
+}
@@ -0,0 +1,114 @@
+package eth2
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+func compressG1(p *bls12381.PointG1) []byte {
+	raw := bls12381.NewG1().ToBytes(p)
+	out := append([]byte{}, raw[:48]...)
+	out[0] |= flagCompressed
+	if new(big.Int).SetBytes(raw[48:]).Cmp(halfModulus) > 0 {
+		out[0] |= flagSign
+	}
+	return out
+}
+
+func compressG2(p *bls12381.PointG2) []byte {
+	raw := bls12381.NewG2().ToBytes(p)
+	out := append([]byte{}, raw[:96]...)
+	out[0] |= flagCompressed
+	c1, c0 := new(big.Int).SetBytes(raw[96:144]), new(big.Int).SetBytes(raw[144:])
+	if c1.Cmp(halfModulus) > 0 || (c1.Sign() == 0 && c0.Cmp(halfModulus) > 0) {
+		out[0] |= flagSign
+	}
+	return out
+}
+
+func TestDecompressGenerators(t *testing.T) {
+	p1, err := decompressG1(common.FromHex("0x97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g1 := bls12381.NewG1(); !g1.Equal(p1, g1.One()) {
+		t.Fatal("not the g1 generator")
+	}
+	p2, err := decompressG2(common.FromHex("0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g2 := bls12381.NewG2(); !g2.Equal(p2, g2.One()) {
+		t.Fatal("not the g2 generator")
+	}
+
+	// the other y of the same x
+	neg := bls12381.NewG2()
+	n := neg.New()
+	neg.Neg(n, neg.One())
+	p2, err = decompressG2(compressG2(n))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !neg.Equal(p2, n) {
+		t.Fatal("sign flag ignored")
+	}
+	if _, err = decompressG1(make([]byte, 48)); err == nil {
+		t.Fatal("decoded an uncompressed key")
+	}
+}
+
+// vectors of RFC 9380, appendix K.1 and J.10.1
+func TestExpandMessageXMD(t *testing.T) {
+	got := expandMessageXMD([]byte{}, []byte("QUUX-V01-CS02-with-expander-SHA256-128"), 0x20)
+	want := common.FromHex("68a985b87eb6b46952128911f2a4412bbc302a9d759667f87f7a21d803f07235")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %x", got)
+	}
+}
+
+func TestHashToG2(t *testing.T) {
+	p, err := hashToG2([]byte{}, []byte("QUUX-V01-CS02-with-BLS12381G2_XMD:SHA-256_SSWU_RO_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := common.FromHex("05cb8437535e20ecffaef7752baddf98034139c38452458baeefab379ba13dff5bf5dd71b72418717047f5b0f37da03d" +
+		"0141ebfbdca40eb85b87142e130ab689c673cf60f1a3e98d69335266f30d9b8d4ac44c1038e9dcdd5393faf5c41fb78a" +
+		"12424ac32561493f3fe3c260708a12b7c620e7be00099a974e259ddc7d1f6395c3c811cdd19f1e8dbf3e9ecfdcbab8d6" +
+		"0503921d7f6a12805e72940b963c0cf3471c7b2a524950ca195d11062ee75ec076daf2d4bc358c4b190c0c98064fdd92")
+	if got := bls12381.NewG2().ToBytes(p); !bytes.Equal(got, want) {
+		t.Fatalf("got %x", got)
+	}
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	g1, g2 := bls12381.NewG1(), bls12381.NewG2()
+	msg := []byte("attested header root")
+	h, err := hashToG2(msg, syncCommitteeDST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pks := make([]*bls12381.PointG1, 0, 3)
+	sum := int64(0)
+	for _, sk := range []int64{3, 5, 7} {
+		pk := g1.New()
+		g1.MulScalar(pk, g1.One(), big.NewInt(sk))
+		pks = append(pks, pk)
+		sum += sk
+	}
+	sig := g2.New()
+	g2.MulScalar(sig, h, big.NewInt(sum))
+	if ok, err := fastAggregateVerify(pks, msg, compressG2(sig)); err != nil || !ok {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if ok, _ := fastAggregateVerify(pks[:2], msg, compressG2(sig)); ok {
+		t.Fatal("verified without a signer")
+	}
+	if ok, _ := fastAggregateVerify(pks, []byte("other"), compressG2(sig)); ok {
+		t.Fatal("verified another message")
+	}
+}
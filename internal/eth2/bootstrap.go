@@ -0,0 +1,170 @@
+package eth2
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/pkg/errors"
+)
+
+// currentSyncCommitteeIndex is the leaf index of the current sync committee
+// in the beacon state, gindex 54 before electra and 86 from it.
+const currentSyncCommitteeIndex = 22
+
+// Bootstrap is what the eth2 light client on MAP is initialized with: a
+// trusted finalized checkpoint and the sync committees of its period.
+type Bootstrap struct {
+	Root                 common.Hash
+	Header               BeaconBlockHeader
+	ExecutionNumber      *big.Int
+	ExecutionHash        common.Hash
+	CurrentSyncCommittee ContractSyncCommittee
+	NextSyncCommittee    ContractSyncCommittee
+}
+
+// Period returns the sync committee period of the checkpoint.
+func (b *Bootstrap) Period() uint64 {
+	return periodOfSlot(b.Header.Slot)
+}
+
+// VerifyCurrentSyncCommitteeBranch checks branch proves committee is the
+// current sync committee of the state of header.
+func VerifyCurrentSyncCommitteeBranch(header *BeaconBlockHeader, committee *ContractSyncCommittee, branch [][32]byte) error {
+	if n := len(branch); n != 5 && n != 6 {
+		return fmt.Errorf("current sync committee branch of %d nodes", n)
+	}
+	root, err := HashTreeRootSyncCommittee(committee)
+	if err != nil {
+		return err
+	}
+	if !IsValidMerkleBranch(root, branch, currentSyncCommitteeIndex, header.StateRoot) {
+		return errors.New("current sync committee branch doesn't prove the current sync committee")
+	}
+	return nil
+}
+
+// FetchBootstrap gets the bootstrap of checkpoint, a block root or
+// "finalized" for the latest finalized one, and checks it: the header is the
+// one of the root, the current sync committee branch and the update of the
+// period, where the next sync committee comes from, signed by it.
+func FetchBootstrap(ctx context.Context, client *Client, checkpoint string) (*Bootstrap, error) {
+	if checkpoint == string(constant.FinalBlockIdOfEth2) {
+		resp, err := client.BeaconHeaders(ctx, constant.FinalBlockIdOfEth2)
+		if err != nil {
+			return nil, errors.Wrap(err, "get finalized checkpoint")
+		}
+		checkpoint = resp.Data.Root
+	}
+	root := common.HexToHash(checkpoint)
+	resp, err := client.LightClientBootstrap(ctx, root.Hex())
+	if err != nil {
+		return nil, errors.Wrapf(err, "get bootstrap of %s", root)
+	}
+	endpoint := client.Endpoint()
+	header, err := convertBeacon(&resp.Data.Header.Beacon)
+	if err != nil {
+		return nil, err
+	}
+	if got := common.Hash(HashTreeRootHeader(header)); got != root {
+		return nil, fmt.Errorf("%w from beacon %s: bootstrap header root %s, not %s", ErrInvalidUpdate, endpoint, got, root)
+	}
+	current := convertCommittee(&resp.Data.CurrentSyncCommittee)
+	if err = VerifyCurrentSyncCommitteeBranch(header, &current, GenerateByApi(resp.Data.CurrentSyncCommitteeBranch)); err != nil {
+		return nil, fmt.Errorf("%w from beacon %s: %s", ErrInvalidUpdate, endpoint, err)
+	}
+	ret := &Bootstrap{
+		Root:                 root,
+		Header:               *header,
+		ExecutionNumber:      new(big.Int),
+		ExecutionHash:        common.HexToHash(resp.Data.Header.Execution.BlockHash),
+		CurrentSyncCommittee: current,
+	}
+	if n := resp.Data.Header.Execution.BlockNumber; n != "" {
+		if _, ok := ret.ExecutionNumber.SetString(n, 10); !ok {
+			return nil, fmt.Errorf("execution block number %q", n)
+		}
+	}
+
+	period := ret.Period()
+	update, err := client.LightClientUpdate(ctx, period)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get update of period %d", period)
+	}
+	u, err := convertUpdate(&update.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(u.NextSyncCommitteeBranch) == 0 {
+		return nil, fmt.Errorf("update of period %d has no next sync committee yet", period)
+	}
+	verifier := NewVerifier(client, nil)
+	if err = verifier.SetCommittee(periodOfSlot(u.SignatureSlot), &current); err != nil {
+		return nil, err
+	}
+	if err = verifier.Verify(ctx, u); err != nil {
+		return nil, errors.Wrapf(err, "update of period %d", period)
+	}
+	ret.NextSyncCommittee = u.NextSyncCommittee
+	return ret, nil
+}
+
+func convertBeacon(b *Beacon) (*BeaconBlockHeader, error) {
+	slot, err := strconv.ParseUint(b.Slot, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "beacon slot")
+	}
+	proposerIndex, err := strconv.ParseUint(b.ProposerIndex, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "beacon proposer index")
+	}
+	return &BeaconBlockHeader{
+		Slot:          slot,
+		ProposerIndex: proposerIndex,
+		ParentRoot:    common.HexToHash(b.ParentRoot),
+		StateRoot:     common.HexToHash(b.StateRoot),
+		BodyRoot:      common.HexToHash(b.BodyRoot),
+	}, nil
+}
+
+func convertCommittee(c *NextSyncCommittee) ContractSyncCommittee {
+	ret := ContractSyncCommittee{
+		Pubkeys:         make([]byte, 0, len(c.Pubkeys)*48),
+		AggregatePubkey: common.FromHex(c.AggregatePubkey),
+	}
+	for _, pk := range c.Pubkeys {
+		ret.Pubkeys = append(ret.Pubkeys, common.FromHex(pk)...)
+	}
+	return ret
+}
+
+// convertUpdate takes what verifying d needs.
+func convertUpdate(d *LightClientUpdatesData) (*LightClientUpdate, error) {
+	attested, err := convertBeacon(&d.AttestedHeader.Beacon)
+	if err != nil {
+		return nil, err
+	}
+	finalized, err := convertBeacon(&d.FinalizedHeader.Beacon)
+	if err != nil {
+		return nil, err
+	}
+	signatureSlot, err := strconv.ParseUint(d.SignatureSlot, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(err, "signature slot")
+	}
+	return &LightClientUpdate{
+		AttestedHeader: *attested,
+		SignatureSlot:  signatureSlot,
+		SyncAggregate: ContractSyncAggregate{
+			SyncCommitteeBits:      common.FromHex(d.SyncAggregate.SyncCommitteeBits),
+			SyncCommitteeSignature: common.FromHex(d.SyncAggregate.SyncCommitteeSignature),
+		},
+		NextSyncCommittee:       convertCommittee(&d.NextSyncCommittee),
+		NextSyncCommitteeBranch: GenerateByApi(d.NextSyncCommitteeBranch),
+		FinalizedHeader:         *finalized,
+		FinalityBranch:          GenerateByApi(d.FinalityBranch),
+	}, nil
+}
@@ -0,0 +1,137 @@
+package eth2
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func beaconBody(h *BeaconBlockHeader) map[string]string {
+	return map[string]string{
+		"slot":           strconv.FormatUint(h.Slot, 10),
+		"proposer_index": strconv.FormatUint(h.ProposerIndex, 10),
+		"parent_root":    common.Hash(h.ParentRoot).Hex(),
+		"state_root":     common.Hash(h.StateRoot).Hex(),
+		"body_root":      common.Hash(h.BodyRoot).Hex(),
+	}
+}
+
+func committeeBody(c *ContractSyncCommittee) map[string]interface{} {
+	pubkeys := make([]string, 0, syncCommitteeSize)
+	for i := 0; i+48 <= len(c.Pubkeys); i += 48 {
+		pubkeys = append(pubkeys, common.Bytes2Hex(c.Pubkeys[i:i+48]))
+	}
+	return map[string]interface{}{"pubkeys": pubkeys, "aggregate_pubkey": common.Bytes2Hex(c.AggregatePubkey)}
+}
+
+func branchBody(branch [][32]byte) []string {
+	ret := make([]string, 0, len(branch))
+	for _, b := range branch {
+		ret = append(ret, common.Hash(b).Hex())
+	}
+	return ret
+}
+
+// newBootstrapBeacon serves the bootstrap of a checkpoint in the test period
+// whose state holds bootstrapped as the current sync committee, and the
+// update of the period signed by signer.
+func newBootstrapBeacon(t *testing.T, bootstrapped, signer, next *testCommittee) (*testBeacon, common.Hash) {
+	t.Helper()
+	currentRoot, err := HashTreeRootSyncCommittee(&bootstrapped.raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the current sync committee at gindex 54
+	tree := newStateTree([32]byte{}, [32]byte{})
+	tree[54] = currentRoot
+	for g := 27; g >= 1; g /= 2 {
+		tree[g] = hashPair(tree[2*g], tree[2*g+1])
+	}
+	header := BeaconBlockHeader{Slot: testPeriod*8192 + 32, ProposerIndex: 5, StateRoot: tree[1]}
+	root := common.Hash(HashTreeRootHeader(&header))
+
+	u := newTestUpdate(t, signer, next)
+	b := newTestBeacon(t, map[string]interface{}{
+		"eth/v1/beacon/headers/finalized": headerBody(strconv.FormatUint(header.Slot, 10), root.Hex()),
+		"eth/v1/beacon/light_client/bootstrap/" + root.Hex(): map[string]interface{}{"data": map[string]interface{}{
+			"header": map[string]interface{}{
+				"beacon":    beaconBody(&header),
+				"execution": map[string]string{"block_number": "1234", "block_hash": common.Hash{9}.Hex()},
+			},
+			"current_sync_committee":        committeeBody(&bootstrapped.raw),
+			"current_sync_committee_branch": branchBody(tree.branch(54)),
+		}},
+		"eth/v1/beacon/light_client/updates?start_period=3&count=1": []map[string]interface{}{{"version": "deneb", "data": map[string]interface{}{
+			"attested_header":            map[string]interface{}{"beacon": beaconBody(&u.AttestedHeader)},
+			"next_sync_committee":        committeeBody(&u.NextSyncCommittee),
+			"next_sync_committee_branch": branchBody(u.NextSyncCommitteeBranch),
+			"finalized_header":           map[string]interface{}{"beacon": beaconBody(&u.FinalizedHeader)},
+			"finality_branch":            branchBody(u.FinalityBranch),
+			"sync_aggregate": map[string]string{
+				"sync_committee_bits":      common.Bytes2Hex(u.SyncAggregate.SyncCommitteeBits),
+				"sync_committee_signature": common.Bytes2Hex(u.SyncAggregate.SyncCommitteeSignature),
+			},
+			"signature_slot": strconv.FormatUint(u.SignatureSlot, 10),
+		}}},
+		"eth/v1/beacon/genesis": map[string]interface{}{"data": map[string]string{
+			"genesis_validators_root": testGenesisValidatorsRoot.Hex(),
+		}},
+		"eth/v1/config/fork_schedule": map[string]interface{}{"data": []map[string]string{
+			{"previous_version": "0x03000000", "current_version": "0x04000000", "epoch": "768"},
+		}},
+	})
+	return b, root
+}
+
+func TestFetchBootstrap(t *testing.T) {
+	current, next := newTestCommittee(0), newTestCommittee(1000)
+	b, root := newBootstrapBeacon(t, current, current, next)
+	client, err := DialHttp(b.url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, checkpoint := range []string{root.Hex(), "finalized"} {
+		got, err := FetchBootstrap(context.Background(), client, checkpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Root != root || got.Period() != testPeriod || got.ExecutionNumber.Uint64() != 1234 || got.ExecutionHash != (common.Hash{9}) {
+			t.Fatalf("bootstrap %s at %d, execution %s %s", got.Root, got.Header.Slot, got.ExecutionNumber, got.ExecutionHash)
+		}
+		if common.Bytes2Hex(got.NextSyncCommittee.AggregatePubkey) != common.Bytes2Hex(next.raw.AggregatePubkey) {
+			t.Fatal("other next sync committee")
+		}
+	}
+
+	tests := []struct {
+		name                 string
+		bootstrapped, signer *testCommittee
+		otherRoot            bool
+	}{
+		{"bootstrap of another root", current, current, true},
+		{"current sync committee branch", next, current, false},
+		{"update signed by another committee", current, next, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, root := newBootstrapBeacon(t, tt.bootstrapped, tt.signer, next)
+			client, err := DialHttp(b.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkpoint := root.Hex()
+			if tt.otherRoot {
+				checkpoint = common.Hash{1}.Hex()
+				b.mu.Lock()
+				b.paths["eth/v1/beacon/light_client/bootstrap/"+checkpoint] = b.paths["eth/v1/beacon/light_client/bootstrap/"+root.Hex()]
+				b.mu.Unlock()
+			}
+			if _, err = FetchBootstrap(context.Background(), client, checkpoint); !errors.Is(err, ErrInvalidUpdate) {
+				t.Fatalf("got %v", err)
+			}
+		})
+	}
+}
@@ -10,135 +10,441 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 
+	log "github.com/ChainSafe/log15"
+	"github.com/golang/groupcache/lru"
 	"github.com/mapprotocol/compass/internal/constant"
 )
 
 const (
 	contentType = "application/json"
 	vsn         = "2.0"
+
+	// requestTimeout bounds one request to one beacon node, a failover
+	// retries on the next node
+	requestTimeout = time.Second * 10
+	// nodeFailures consecutive failures put a node aside for nodeRetryAfter
+	nodeFailures   = 3
+	nodeRetryAfter = time.Minute
+	// the finalized checkpoints of the nodes are compared every
+	// consistencyInterval, a node more than maxFinalityLag epochs behind the
+	// others or finalizing another root is put aside
+	consistencyInterval = time.Minute
+	maxFinalityLag      = 2
+	responseCacheSize   = 256
 )
 
 var ErrNoResult = errors.New("no result in JSON-RPC response")
 
-type Client struct {
-	client    *http.Client
+type node struct {
 	endpoint  string
-	closeOnce sync.Once
-	closch    chan interface{}
-	mu        sync.Mutex // protcts headers
-	headers   http.Header
-	isHttp    bool
-	idCounter uint32
+	failures  int
+	downUntil time.Time
+}
+
+// Client is a client of the beacon API of one or more beacon nodes. Requests
+// go to the first healthy node in the order given and fail over to the next
+// ones. Responses that can't change any more are cached.
+type Client struct {
+	client  *http.Client
+	mu      sync.Mutex // protects the fields below
+	headers http.Header
+	nodes   []*node
+	served  string // endpoint of the last response
+	cache   *lru.Cache
+
+	finalizedSlot uint64
+	checkedAt     time.Time
 }
 
+// DialHttp returns a client of the beacon nodes of endpoint, comma separated.
 func DialHttp(endpoint string) (*Client, error) {
-	// Sanity chck URL so we don't end up with a client that will fail every request.
-	_, err := url.Parse(endpoint)
-	if err != nil {
-		return nil, err
+	nodes := make([]*node, 0)
+	for _, e := range strings.Split(endpoint, ",") {
+		e = strings.TrimRight(strings.TrimSpace(e), "/")
+		if e == "" {
+			continue
+		}
+		// Sanity chck URL so we don't end up with a client that will fail every request.
+		if _, err := url.Parse(e); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, &node{endpoint: e})
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("no beacon endpoint")
 	}
 
 	headers := make(http.Header, 2)
 	headers.Set("accept", contentType)
 	headers.Set("content-type", contentType)
-	client := &http.Client{
-		Timeout: time.Second * 10,
-	}
 	return &Client{
-		client:    client,
-		endpoint:  endpoint,
-		closeOnce: sync.Once{},
-		closch:    make(chan interface{}),
-		mu:        sync.Mutex{},
-		headers:   headers,
+		client:  &http.Client{Timeout: requestTimeout},
+		headers: headers,
+		nodes:   nodes,
+		cache:   lru.New(responseCacheSize),
 	}, nil
 }
 
+// Endpoint returns the beacon node of the last response, the first one
+// before any.
+func (c *Client) Endpoint() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.served != "" {
+		return c.served
+	}
+	return c.nodes[0].endpoint
+}
+
+// Endpoints returns every beacon node of the client.
+func (c *Client) Endpoints() []string {
+	ret := make([]string, 0, len(c.nodes))
+	for _, n := range c.nodes {
+		ret = append(ret, n.endpoint)
+	}
+	return ret
+}
+
+// Without returns a client of the nodes of c other than endpoint, nil when
+// there are none.
+func (c *Client) Without(endpoint string) *Client {
+	nodes := make([]*node, 0, len(c.nodes))
+	for _, n := range c.nodes {
+		if n.endpoint != endpoint {
+			nodes = append(nodes, &node{endpoint: n.endpoint})
+		}
+	}
+	if len(nodes) == 0 {
+		return nil
+	}
+	return &Client{client: c.client, headers: c.headers, nodes: nodes, cache: lru.New(responseCacheSize)}
+}
+
+// Demote puts the node of endpoint aside for a while, for serving something
+// found wrong.
+func (c *Client) Demote(endpoint, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, n := range c.nodes {
+		if n.endpoint == endpoint {
+			c.demote(n, reason)
+		}
+	}
+}
+
+func (c *Client) demote(n *node, reason string) {
+	if len(c.nodes) == 1 {
+		return
+	}
+	n.failures, n.downUntil = 0, time.Now().Add(nodeRetryAfter)
+	if c.served == n.endpoint {
+		c.served = ""
+	}
+	log.Warn("Beacon endpoint put aside", "endpoint", n.endpoint, "until", n.downUntil, "reason", reason)
+}
+
+// candidates returns the healthy nodes in order, then the ones put aside as
+// a last resort.
+func (c *Client) candidates() []*node {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	healthy, down := make([]*node, 0, len(c.nodes)), make([]*node, 0)
+	for _, n := range c.nodes {
+		if now.Before(n.downUntil) {
+			down = append(down, n)
+		} else {
+			healthy = append(healthy, n)
+		}
+	}
+	return append(healthy, down...)
+}
+
+func (c *Client) succeeded(n *node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n.failures, n.downUntil, c.served = 0, time.Time{}, n.endpoint
+}
+
+func (c *Client) failed(n *node, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n.failures++
+	if n.failures >= nodeFailures {
+		c.demote(n, err.Error())
+	}
+}
+
+func (c *Client) cached(key string, result interface{}) bool {
+	c.mu.Lock()
+	v, ok := c.cache.Get(key)
+	c.mu.Unlock()
+	return ok && json.Unmarshal(v.([]byte), result) == nil
+}
+
+func (c *Client) store(key string, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Add(key, data)
+}
+
+// sawFinalized records slot as finalized, it makes the responses of the
+// periods and slots before immutable.
+func (c *Client) sawFinalized(slot string) {
+	s, err := strconv.ParseUint(slot, 10, 64)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s > c.finalizedSlot {
+		c.finalizedSlot = s
+	}
+}
+
+// immutableBlock reports whether the block of blockId, a root or a slot, is
+// finalized.
+func (c *Client) immutableBlock(blockId string) bool {
+	if strings.HasPrefix(blockId, "0x") {
+		return true
+	}
+	slot, err := strconv.ParseUint(blockId, 10, 64)
+	if err != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slot <= c.finalizedSlot
+}
+
 func (c *Client) BeaconHeaders(ctx context.Context, blockId constant.BlockIdOfEth2) (*BeaconHeadersResp, error) {
-	urlPath := fmt.Sprintf("%s/%s/%s", c.endpoint, "eth/v1/beacon/headers", blockId)
+	urlPath := fmt.Sprintf("%s/%s", "eth/v1/beacon/headers", blockId)
 	var ret BeaconHeadersResp
+	immutable := c.immutableBlock(string(blockId))
+	if immutable && c.cached(urlPath, &ret) {
+		return &ret, nil
+	}
+	if blockId == constant.FinalBlockIdOfEth2 {
+		c.checkConsistency(ctx)
+	}
 	err := c.CallContext(ctx, urlPath, &ret)
 	if err != nil {
 		return nil, err
 	}
+	if blockId == constant.FinalBlockIdOfEth2 {
+		c.sawFinalized(ret.Data.Header.Message.Slot)
+	}
+	if immutable {
+		c.store(urlPath, &ret)
+	}
 	return &ret, nil
 }
 
 func (c *Client) LightClientUpdate(ctx context.Context, startPeriod uint64) (*LightClientUpdatesResp, error) {
-	urlPath := fmt.Sprintf("%s/%s?start_period=%d&count=1", c.endpoint, "eth/v1/beacon/light_client/updates", startPeriod)
-	respBody, err := c.doRequest(ctx, urlPath)
-	if err != nil {
-		return nil, err
+	urlPath := fmt.Sprintf("%s?start_period=%d&count=1", "eth/v1/beacon/light_client/updates", startPeriod)
+	var ret LightClientUpdatesResp
+	if c.cached(urlPath, &ret) {
+		return &ret, nil
 	}
-	defer respBody.Close()
 	var respMsg []CommonData
-	if err = json.NewDecoder(respBody).Decode(&respMsg); err != nil {
+	err := c.do(ctx, urlPath, func(body io.Reader) error {
+		if err := json.NewDecoder(body).Decode(&respMsg); err != nil {
+			return err
+		}
+		if len(respMsg) == 0 {
+			return ErrNoResult
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	var ret LightClientUpdatesResp
 	data, _ := json.Marshal(respMsg[0])
 	err = json.Unmarshal(data, &ret)
 	if err != nil {
 		return nil, err
 	}
+	// the best update of a period is settled once a later one is finalized
+	c.mu.Lock()
+	settled := periodOfSlot(c.finalizedSlot) > startPeriod
+	c.mu.Unlock()
+	if settled {
+		c.store(urlPath, &ret)
+	}
+	return &ret, nil
+}
+
+func (c *Client) LightClientBootstrap(ctx context.Context, blockRoot string) (*BootstrapResp, error) {
+	urlPath := fmt.Sprintf("%s/%s", "eth/v1/beacon/light_client/bootstrap", blockRoot)
+	var ret BootstrapResp
+	if c.cached(urlPath, &ret) {
+		return &ret, nil
+	}
+	err := c.CallContext(ctx, urlPath, &ret)
+	if err != nil {
+		return nil, err
+	}
+	c.store(urlPath, &ret)
 	return &ret, nil
 }
 
 func (c *Client) FinallyUpdate(ctx context.Context) (*FinalityUpdateResp, error) {
-	urlPath := fmt.Sprintf("%s/%s", c.endpoint, "eth/v1/beacon/light_client/finality_update")
+	urlPath := "eth/v1/beacon/light_client/finality_update"
+	c.checkConsistency(ctx)
 	var ret FinalityUpdateResp
 	err := c.CallContext(ctx, urlPath, &ret)
 	if err != nil {
 		return nil, err
 	}
+	c.sawFinalized(ret.Data.FinalizedHeader.Beacon.Slot)
 	return &ret, nil
 }
 
 func (c *Client) GetBlocks(ctx context.Context, blockId string) (*BlocksResp, error) {
-	urlPath := fmt.Sprintf("%s/%s/%s", c.endpoint, "eth/v2/beacon/blocks", blockId)
+	urlPath := fmt.Sprintf("%s/%s", "eth/v2/beacon/blocks", blockId)
 	var ret BlocksResp
+	immutable := c.immutableBlock(blockId)
+	if immutable && c.cached(urlPath, &ret) {
+		return &ret, nil
+	}
 	err := c.CallContext(ctx, urlPath, &ret)
 	if err != nil {
 		return nil, err
 	}
+	if immutable {
+		c.store(urlPath, &ret)
+	}
 	return &ret, nil
 }
 
-type requestOp struct {
-	ids  []json.RawMessage
-	err  error
-	resp chan *CommonData // receives up to len(ids) responses
+func (c *Client) Genesis(ctx context.Context) (*GenesisResp, error) {
+	urlPath := "eth/v1/beacon/genesis"
+	var ret GenesisResp
+	if c.cached(urlPath, &ret) {
+		return &ret, nil
+	}
+	err := c.CallContext(ctx, urlPath, &ret)
+	if err != nil {
+		return nil, err
+	}
+	c.store(urlPath, &ret)
+	return &ret, nil
 }
 
-func (op *requestOp) wait(ctx context.Context) (*CommonData, error) {
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case resp := <-op.resp:
-		return resp, op.err
+func (c *Client) ForkSchedule(ctx context.Context) (*ForkScheduleResp, error) {
+	urlPath := "eth/v1/config/fork_schedule"
+	var ret ForkScheduleResp
+	err := c.CallContext(ctx, urlPath, &ret)
+	if err != nil {
+		return nil, err
 	}
+	return &ret, nil
 }
 
-func (c *Client) CallContext(ctx context.Context, url string, result interface{}) error {
+// checkConsistency compares the finalized checkpoints of the healthy nodes
+// at most every consistencyInterval. The nodes that lag or finalized another
+// root than most of the nodes at the same epoch are put aside.
+func (c *Client) checkConsistency(ctx context.Context) {
+	c.mu.Lock()
+	due := len(c.nodes) > 1 && time.Since(c.checkedAt) >= consistencyInterval
+	if due {
+		c.checkedAt = time.Now()
+	}
+	c.mu.Unlock()
+	if !due {
+		return
+	}
+
+	type checkpoint struct {
+		n     *node
+		epoch uint64
+		root  string
+	}
+	checkpoints := make([]checkpoint, 0, len(c.nodes))
+	maxEpoch := uint64(0)
+	for _, n := range c.candidates() {
+		c.mu.Lock()
+		down := time.Now().Before(n.downUntil)
+		c.mu.Unlock()
+		if down {
+			continue
+		}
+		var ret FinalityCheckpointsResp
+		err := c.callNode(ctx, n, "eth/v1/beacon/states/head/finality_checkpoints", &ret)
+		if err != nil {
+			if !errors.Is(err, ErrNoResult) {
+				c.failed(n, err)
+			}
+			continue
+		}
+		epoch, err := strconv.ParseUint(ret.Data.Finalized.Epoch, 10, 64)
+		if err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, checkpoint{n: n, epoch: epoch, root: ret.Data.Finalized.Root})
+		if epoch > maxEpoch {
+			maxEpoch = epoch
+		}
+	}
+
+	votes := make(map[string]int)
+	for _, cp := range checkpoints {
+		if cp.epoch == maxEpoch {
+			votes[cp.root]++
+		}
+	}
+	root := ""
+	for _, cp := range checkpoints { // the earliest node breaks ties
+		if cp.epoch == maxEpoch && votes[cp.root] > votes[root] {
+			root = cp.root
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cp := range checkpoints {
+		switch {
+		case cp.epoch+maxFinalityLag < maxEpoch:
+			c.demote(cp.n, fmt.Sprintf("finalized epoch %d, others %d", cp.epoch, maxEpoch))
+		case cp.epoch == maxEpoch && cp.root != root:
+			c.demote(cp.n, fmt.Sprintf("finalized %s at epoch %d, others %s", cp.root, cp.epoch, root))
+		}
+	}
+}
+
+// CallContext gets urlPath of the beacon API into result, failing over the
+// nodes.
+func (c *Client) CallContext(ctx context.Context, urlPath string, result interface{}) error {
 	if result != nil && reflect.TypeOf(result).Kind() != reflect.Ptr {
 		return fmt.Errorf("call result parameter must be pointer or nil interface: %v", result)
 	}
+	return c.do(ctx, urlPath, func(body io.Reader) error {
+		return decodeCommonData(body, result)
+	})
+}
 
-	op := &requestOp{ids: []json.RawMessage{c.nextID()}, resp: make(chan *CommonData, 1)}
-
-	err := c.sendHTTP(ctx, url, op)
+func (c *Client) callNode(ctx context.Context, n *node, urlPath string, result interface{}) error {
+	body, err := c.doRequest(ctx, n.endpoint+"/"+urlPath)
 	if err != nil {
 		return err
 	}
+	defer body.Close()
+	return decodeCommonData(body, result)
+}
 
-	// dispatch has accepted the request and will close the channel when it quits.
-	switch resp, err := op.wait(ctx); {
-	case err != nil:
+func decodeCommonData(body io.Reader, result interface{}) error {
+	var resp CommonData
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
 		return err
+	}
+	switch {
 	case resp.StatusCode == 404:
 		return ErrNoResult
 	case resp.Error != "":
@@ -149,24 +455,29 @@ func (c *Client) CallContext(ctx context.Context, url string, result interface{}
 	}
 }
 
-func (c *Client) nextID() json.RawMessage {
-	id := atomic.AddUint32(&c.idCounter, 1)
-	return strconv.AppendUint(nil, uint64(id), 10)
-}
-
-func (c *Client) sendHTTP(ctx context.Context, url string, op *requestOp) error {
-	respBody, err := c.doRequest(ctx, url)
-	if err != nil {
-		return err
+// do gets urlPath of the nodes in turn until one answers something decode
+// takes. A node without the result isn't held against it.
+func (c *Client) do(ctx context.Context, urlPath string, decode func(io.Reader) error) error {
+	var lastErr error
+	for _, n := range c.candidates() {
+		body, err := c.doRequest(ctx, n.endpoint+"/"+urlPath)
+		if err == nil {
+			err = decode(body)
+			body.Close()
+		}
+		if err == nil {
+			c.succeeded(n)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !errors.Is(err, ErrNoResult) {
+			c.failed(n, err)
+		}
+		lastErr = fmt.Errorf("beacon %s: %w", n.endpoint, err)
 	}
-	defer respBody.Close()
-
-	var respMsg CommonData
-	if err = json.NewDecoder(respBody).Decode(&respMsg); err != nil {
-		return err
-	}
-	op.resp <- &respMsg
-	return nil
+	return lastErr
 }
 
 func (c *Client) doRequest(ctx context.Context, url string) (io.ReadCloser, error) {
@@ -185,7 +496,12 @@ func (c *Client) doRequest(ctx context.Context, url string) (io.ReadCloser, erro
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNoResult
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
 		return nil, fmt.Errorf("eth2 doRequest failed, code %v", resp.StatusCode)
 	}
 	return resp.Body, nil
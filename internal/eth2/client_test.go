@@ -0,0 +1,230 @@
+package eth2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mapprotocol/compass/internal/constant"
+)
+
+// testBeacon is a beacon node answering from a path table, or failing all
+// requests when down.
+type testBeacon struct {
+	url string
+
+	mu     sync.Mutex
+	down   bool
+	paths  map[string]interface{}
+	counts map[string]int
+}
+
+func newTestBeacon(t *testing.T, paths map[string]interface{}) *testBeacon {
+	t.Helper()
+	b := &testBeacon{paths: paths, counts: make(map[string]int)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		key := strings.TrimPrefix(r.URL.RequestURI(), "/")
+		b.counts[key]++
+		if b.down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, ok := b.paths[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	b.url = srv.URL
+	return b
+}
+
+func (b *testBeacon) set(down bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.down = down
+}
+
+func (b *testBeacon) count(path string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.counts[path]
+}
+
+func headerBody(slot, root string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]interface{}{
+		"root": root, "header": map[string]interface{}{"message": map[string]string{"slot": slot}},
+	}}
+}
+
+func checkpointBody(epoch, root string) map[string]interface{} {
+	return map[string]interface{}{"data": map[string]interface{}{
+		"finalized": map[string]string{"epoch": epoch, "root": root},
+	}}
+}
+
+const finalizedPath = "eth/v1/beacon/headers/finalized"
+
+func TestClientFailover(t *testing.T) {
+	a := newTestBeacon(t, map[string]interface{}{finalizedPath: headerBody("100", "0xaa")})
+	b := newTestBeacon(t, map[string]interface{}{finalizedPath: headerBody("100", "0xaa")})
+	client, err := DialHttp(a.url + ", " + b.url + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	// a node without the result isn't held against it
+	if _, err = client.BeaconHeaders(ctx, constant.HeadBlockIdOfEth2); err == nil {
+		t.Fatal("no node has the head")
+	}
+
+	a.set(true)
+	for i := 0; i < nodeFailures; i++ {
+		resp, err := client.BeaconHeaders(ctx, constant.BlockIdOfEth2("7"))
+		if err == nil || resp != nil {
+			t.Fatal("no node has slot 7")
+		}
+	}
+	if _, err = client.FinallyUpdate(ctx); err == nil {
+		t.Fatal("no node has a finality update")
+	}
+	// a is put aside after failing nodeFailures times, b answers
+	before := a.count(finalizedPath)
+	resp, err := client.BeaconHeaders(ctx, constant.FinalBlockIdOfEth2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data.Header.Message.Slot != "100" || client.Endpoint() != b.url {
+		t.Fatalf("slot %s from %s", resp.Data.Header.Message.Slot, client.Endpoint())
+	}
+	if a.count(finalizedPath) != before {
+		t.Fatal("asked the node put aside")
+	}
+
+	// the node put aside is still tried when no other answers
+	b.set(true)
+	a.set(false)
+	if _, err = client.BeaconHeaders(ctx, constant.FinalBlockIdOfEth2); err != nil || client.Endpoint() != a.url {
+		t.Fatalf("no last resort: %v", err)
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	updatePath := "eth/v1/beacon/light_client/updates?start_period=2&count=1"
+	update := []map[string]interface{}{{"version": "deneb", "data": map[string]interface{}{"signature_slot": "20000"}}}
+	a := newTestBeacon(t, map[string]interface{}{
+		finalizedPath:                 headerBody("16400", "0xaa"),
+		"eth/v1/beacon/headers/0xbb":  headerBody("16000", "0xbb"),
+		"eth/v1/beacon/headers/16000": headerBody("16000", "0xbb"),
+		"eth/v1/beacon/headers/16800": headerBody("16800", "0xcc"),
+		updatePath:                    update,
+		"eth/v1/beacon/genesis":       map[string]interface{}{"data": map[string]string{"genesis_time": "1606824023"}},
+		"eth/v1/config/fork_schedule": map[string]interface{}{"data": []interface{}{}},
+	})
+	client, err := DialHttp(a.url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	ask := func(n int, f func() error) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if err := f(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	header := func(id string) func() error {
+		return func() error {
+			resp, err := client.BeaconHeaders(ctx, constant.BlockIdOfEth2(id))
+			if err == nil && resp.Data.Header.Message.Slot == "" {
+				t.Fatalf("empty header of %s", id)
+			}
+			return err
+		}
+	}
+	updateOf := func() error {
+		resp, err := client.LightClientUpdate(ctx, 2)
+		if err == nil && resp.Data.SignatureSlot != "20000" {
+			t.Fatalf("update %+v", resp.Data)
+		}
+		return err
+	}
+
+	// period 2 ends at slot 24576, it isn't finalized yet
+	ask(2, updateOf)
+	ask(2, header("16000"))
+	ask(2, header(string(constant.FinalBlockIdOfEth2)))
+	ask(2, header("0xbb"))
+	ask(2, header("16000"))
+	ask(2, header("16800"))
+	ask(2, func() error { _, err := client.Genesis(ctx); return err })
+
+	for path, want := range map[string]int{
+		updatePath:                    2,
+		finalizedPath:                 2,
+		"eth/v1/beacon/headers/0xbb":  1,
+		"eth/v1/beacon/headers/16000": 3, // before and after 16400 is finalized
+		"eth/v1/beacon/headers/16800": 2,
+		"eth/v1/beacon/genesis":       1,
+	} {
+		if got := a.count(path); got != want {
+			t.Errorf("%s asked %d times, want %d", path, got, want)
+		}
+	}
+
+	a.mu.Lock()
+	a.paths[finalizedPath] = headerBody("24600", "0xdd")
+	a.mu.Unlock()
+	ask(1, header(string(constant.FinalBlockIdOfEth2)))
+	ask(3, updateOf)
+	if got := a.count(updatePath); got != 3 {
+		t.Errorf("settled update asked %d times", got)
+	}
+}
+
+func TestClientConsistency(t *testing.T) {
+	checkpointPath := "eth/v1/beacon/states/head/finality_checkpoints"
+	newNode := func(epoch, root string) *testBeacon {
+		return newTestBeacon(t, map[string]interface{}{
+			finalizedPath:  headerBody("3200", root),
+			checkpointPath: checkpointBody(epoch, root),
+		})
+	}
+	forked := newNode("100", "0x01")
+	lagging := newNode("90", "0x02")
+	good1, good2 := newNode("100", "0x02"), newNode("100", "0x02")
+	client, err := DialHttp(strings.Join([]string{forked.url, lagging.url, good1.url, good2.url}, ","))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.BeaconHeaders(context.Background(), constant.FinalBlockIdOfEth2); err != nil {
+		t.Fatal(err)
+	}
+	if client.Endpoint() != good1.url {
+		t.Fatalf("served by %s", client.Endpoint())
+	}
+	if forked.count(finalizedPath) != 0 || lagging.count(finalizedPath) != 0 {
+		t.Fatal("asked an inconsistent node")
+	}
+	// checked once a consistencyInterval
+	if _, err = client.BeaconHeaders(context.Background(), constant.FinalBlockIdOfEth2); err != nil {
+		t.Fatal(err)
+	}
+	if good1.count(checkpointPath) != 1 {
+		t.Fatalf("checked %d times", good1.count(checkpointPath))
+	}
+
+	client.Demote(good1.url, "test")
+	if _, err = client.BeaconHeaders(context.Background(), constant.FinalBlockIdOfEth2); err != nil || client.Endpoint() != good2.url {
+		t.Fatalf("served by %s: %v", client.Endpoint(), err)
+	}
+}
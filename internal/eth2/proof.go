@@ -8,11 +8,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/rlp"
 	maptypes "github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/mapo"
 	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 )
 
 func GenerateByApi(slot []string) [][32]byte {
@@ -31,24 +31,28 @@ type ReceiptProof struct {
 	Proof     [][]byte
 }
 
-func AssembleProof(header BlockHeader, log *types.Log, receipts []*types.Receipt, method string, fId msg.ChainId,
-	proofType int64, sign [][]byte) ([]byte, error) {
+func AssembleProof(src ireceipt.Source, header BlockHeader, log *types.Log, receipts []*types.Receipt, method string,
+	fId msg.ChainId, proofType int64, sign [][]byte) ([]byte, error) {
 	txIndex := log.TxIndex
 	orderId := log.Topics[1]
 	receipt, err := mapprotocol.GetTxReceipt(receipts[txIndex])
 	if err != nil {
 		return nil, err
 	}
-	pr := Receipts{}
-	for _, r := range receipts {
-		pr = append(pr, &Receipt{Receipt: r})
+	pr, err := ireceipt.Encode(src, fId, ireceipt.Ethereum, receipts)
+	if err != nil {
+		return nil, err
+	}
+	var prf [][]byte
+	if proofType == constant.ProofTypeOfLogOracle {
+		prf, err = proof.Get(pr, txIndex)
+	} else {
+		prf, err = proof.GetVerified(fId, header.ReceiptsRoot, pr, txIndex)
 	}
-	prf, err := proof.Get(pr, txIndex)
 	if err != nil {
 		return nil, err
 	}
-	var key []byte
-	key = rlp.AppendUint64(key[:0], uint64(txIndex))
+	key := proof.Key(pr, txIndex)
 
 	idx := 0
 	for i, ele := range receipts[txIndex].Logs {
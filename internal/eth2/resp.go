@@ -185,3 +185,44 @@ type AttestationData struct {
 	Source          Source `json:"source"`
 	Target          Target `json:"target"`
 }
+
+type GenesisResp struct {
+	Data GenesisData `json:"data"`
+}
+
+type GenesisData struct {
+	GenesisTime           string `json:"genesis_time"`
+	GenesisValidatorsRoot string `json:"genesis_validators_root"`
+	GenesisForkVersion    string `json:"genesis_fork_version"`
+}
+
+type ForkScheduleResp struct {
+	Data []Fork `json:"data"`
+}
+
+type Fork struct {
+	PreviousVersion string `json:"previous_version"`
+	CurrentVersion  string `json:"current_version"`
+	Epoch           string `json:"epoch"`
+}
+
+type BootstrapResp struct {
+	Data    BootstrapData `json:"data"`
+	Version string        `json:"version"`
+}
+
+type BootstrapData struct {
+	Header                     NewAttestedHeader `json:"header"`
+	CurrentSyncCommittee       NextSyncCommittee `json:"current_sync_committee"`
+	CurrentSyncCommitteeBranch []string          `json:"current_sync_committee_branch"`
+}
+
+type FinalityCheckpointsResp struct {
+	Data FinalityCheckpoints `json:"data"`
+}
+
+type FinalityCheckpoints struct {
+	PreviousJustified Source `json:"previous_justified"`
+	CurrentJustified  Source `json:"current_justified"`
+	Finalized         Source `json:"finalized"`
+}
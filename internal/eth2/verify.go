@@ -0,0 +1,345 @@
+package eth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidUpdate = errors.New("invalid light client update")
+
+const (
+	syncCommitteeSize = 512
+	// finalizedRootIndex and nextSyncCommitteeIndex are the leaf indexes of
+	// the generalized indexes in the beacon state, 105 and 55 before electra,
+	// 169 and 87 from it, at the depth of the branches.
+	finalizedRootIndex     = 41
+	nextSyncCommitteeIndex = 23
+)
+
+var domainSyncCommittee = [4]byte{0x07, 0x00, 0x00, 0x00}
+
+func hashPair(a, b [32]byte) [32]byte {
+	return sha256.Sum256(append(a[:], b[:]...))
+}
+
+// merkleize returns the root of chunks, their number a power of two.
+func merkleize(chunks [][32]byte) [32]byte {
+	for len(chunks) > 1 {
+		next := make([][32]byte, len(chunks)/2)
+		for i := range next {
+			next[i] = hashPair(chunks[2*i], chunks[2*i+1])
+		}
+		chunks = next
+	}
+	return chunks[0]
+}
+
+func uint64Chunk(v uint64) (ret [32]byte) {
+	binary.LittleEndian.PutUint64(ret[:], v)
+	return ret
+}
+
+// pubkeyRoot is the hash tree root of a Bytes48.
+func pubkeyRoot(pk []byte) [32]byte {
+	var a, b [32]byte
+	copy(a[:], pk[:32])
+	copy(b[:], pk[32:])
+	return hashPair(a, b)
+}
+
+// HashTreeRootHeader is the SSZ hash tree root of a beacon block header.
+func HashTreeRootHeader(h *BeaconBlockHeader) [32]byte {
+	return merkleize([][32]byte{
+		uint64Chunk(h.Slot), uint64Chunk(h.ProposerIndex), h.ParentRoot, h.StateRoot, h.BodyRoot, {}, {}, {},
+	})
+}
+
+// HashTreeRootSyncCommittee is the SSZ hash tree root of a sync committee.
+func HashTreeRootSyncCommittee(c *ContractSyncCommittee) ([32]byte, error) {
+	if len(c.Pubkeys) != 48*syncCommitteeSize || len(c.AggregatePubkey) != 48 {
+		return [32]byte{}, fmt.Errorf("sync committee of %d pubkey bytes", len(c.Pubkeys))
+	}
+	leaves := make([][32]byte, syncCommitteeSize)
+	for i := range leaves {
+		leaves[i] = pubkeyRoot(c.Pubkeys[48*i : 48*(i+1)])
+	}
+	return hashPair(merkleize(leaves), pubkeyRoot(c.AggregatePubkey)), nil
+}
+
+// IsValidMerkleBranch checks leaf is at index of the tree of root.
+func IsValidMerkleBranch(leaf [32]byte, branch [][32]byte, index uint64, root [32]byte) bool {
+	value := leaf
+	for i, b := range branch {
+		if (index>>uint(i))&1 == 1 {
+			value = hashPair(b, value)
+		} else {
+			value = hashPair(value, b)
+		}
+	}
+	return value == root
+}
+
+// ComputeSigningRoot returns the root the sync committee signs for header
+// under the fork of forkVersion.
+func ComputeSigningRoot(header *BeaconBlockHeader, forkVersion [4]byte, genesisValidatorsRoot [32]byte) [32]byte {
+	var version [32]byte
+	copy(version[:], forkVersion[:])
+	forkDataRoot := hashPair(version, genesisValidatorsRoot)
+	var domain [32]byte
+	copy(domain[:4], domainSyncCommittee[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return hashPair(HashTreeRootHeader(header), domain)
+}
+
+// VerifyBranches checks the finality branch of u and, when it carries one,
+// its next sync committee branch against the state root of the attested header.
+func VerifyBranches(u *LightClientUpdate) error {
+	if n := len(u.FinalityBranch); n != 6 && n != 7 {
+		return fmt.Errorf("finality branch of %d nodes", n)
+	}
+	if !IsValidMerkleBranch(HashTreeRootHeader(&u.FinalizedHeader), u.FinalityBranch, finalizedRootIndex,
+		u.AttestedHeader.StateRoot) {
+		return errors.New("finality branch doesn't prove the finalized header")
+	}
+	if len(u.NextSyncCommitteeBranch) == 0 {
+		return nil
+	}
+	if n := len(u.NextSyncCommitteeBranch); n != 5 && n != 6 {
+		return fmt.Errorf("next sync committee branch of %d nodes", n)
+	}
+	root, err := HashTreeRootSyncCommittee(&u.NextSyncCommittee)
+	if err != nil {
+		return err
+	}
+	if !IsValidMerkleBranch(root, u.NextSyncCommitteeBranch, nextSyncCommitteeIndex, u.AttestedHeader.StateRoot) {
+		return errors.New("next sync committee branch doesn't prove the next sync committee")
+	}
+	return nil
+}
+
+// SyncCommittee is a sync committee with its public keys decoded.
+type SyncCommittee struct {
+	pubkeys []*bls12381.PointG1
+}
+
+// NewSyncCommittee decodes the public keys of c and checks they sum up to its
+// aggregate public key.
+func NewSyncCommittee(c *ContractSyncCommittee) (*SyncCommittee, error) {
+	if len(c.Pubkeys) != 48*syncCommitteeSize {
+		return nil, fmt.Errorf("sync committee of %d pubkey bytes", len(c.Pubkeys))
+	}
+	g1 := bls12381.NewG1()
+	sum := g1.Zero()
+	ret := &SyncCommittee{pubkeys: make([]*bls12381.PointG1, syncCommitteeSize)}
+	for i := range ret.pubkeys {
+		pk, err := decompressG1(c.Pubkeys[48*i : 48*(i+1)])
+		if err != nil {
+			return nil, errors.Wrapf(err, "pubkey %d", i)
+		}
+		ret.pubkeys[i] = pk
+		g1.Add(sum, sum, pk)
+	}
+	agg, err := decompressG1(c.AggregatePubkey)
+	if err != nil {
+		return nil, errors.Wrap(err, "aggregate pubkey")
+	}
+	if !g1.Equal(sum, agg) {
+		return nil, errors.New("aggregate pubkey isn't the sum of the pubkeys")
+	}
+	return ret, nil
+}
+
+// VerifySyncAggregate checks the sync aggregate of u is the signature of its
+// attested header by the members of committee its bits select.
+func VerifySyncAggregate(u *LightClientUpdate, committee *SyncCommittee, forkVersion [4]byte,
+	genesisValidatorsRoot [32]byte) error {
+	bits := u.SyncAggregate.SyncCommitteeBits
+	if len(bits) != syncCommitteeSize/8 {
+		return fmt.Errorf("sync committee bits of %d bytes", len(bits))
+	}
+	signers := make([]*bls12381.PointG1, 0, syncCommitteeSize)
+	for i, pk := range committee.pubkeys {
+		if bits[i/8]>>(uint(i)%8)&1 == 1 {
+			signers = append(signers, pk)
+		}
+	}
+	root := ComputeSigningRoot(&u.AttestedHeader, forkVersion, genesisValidatorsRoot)
+	ok, err := fastAggregateVerify(signers, root[:], u.SyncAggregate.SyncCommitteeSignature)
+	if err != nil {
+		return errors.Wrap(err, "sync committee signature")
+	}
+	if !ok {
+		return errors.New("sync committee signature doesn't verify")
+	}
+	return nil
+}
+
+type forkAt struct {
+	epoch   uint64
+	version [4]byte
+}
+
+// Verifier checks light client updates before they are sent to MAP: the
+// sync committee signature and the Merkle branches. The sync committee of a
+// period is one set from the light client on MAP, the next sync committee of
+// a verified update of the period before or, when there is neither, of the
+// update of the period before asked once of committees, or of another beacon
+// node than the one that served the update, never of that one.
+type Verifier struct {
+	client     *Client
+	committees *Client
+
+	mu                    sync.Mutex
+	genesisValidatorsRoot *[32]byte
+	forks                 []forkAt
+	known                 map[uint64]*SyncCommittee
+}
+
+// NewVerifier returns a verifier of the updates of client, committees, if not
+// nil, being the beacon nodes the sync committees are taken from.
+func NewVerifier(client, committees *Client) *Verifier {
+	return &Verifier{client: client, committees: committees, known: make(map[uint64]*SyncCommittee)}
+}
+
+// SetCommittee sets the sync committee of period, for one known some other
+// way than from the update before.
+func (v *Verifier) SetCommittee(period uint64, c *ContractSyncCommittee) error {
+	committee, err := NewSyncCommittee(c)
+	if err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.known[period] = committee
+	return nil
+}
+
+func periodOfSlot(slot uint64) uint64 {
+	return slot / uint64(constant.SlotsPerEpoch*constant.EpochsPerPeriod)
+}
+
+// Verify checks u, fetched last from the client. Updates that fail are
+// reported as ErrInvalidUpdate with the beacon node that served them, which
+// is put aside.
+func (v *Verifier) Verify(ctx context.Context, u *LightClientUpdate) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	endpoint := v.client.Endpoint()
+	invalid := func(err error) error {
+		v.client.Demote(endpoint, err.Error())
+		return fmt.Errorf("%w from beacon %s: %s", ErrInvalidUpdate, endpoint, err)
+	}
+	if err := VerifyBranches(u); err != nil {
+		return invalid(err)
+	}
+	period := periodOfSlot(u.SignatureSlot)
+	committee, err := v.committee(ctx, period, endpoint)
+	if err != nil {
+		return errors.Wrapf(err, "get sync committee of period %d", period)
+	}
+	forkVersion, gvr, err := v.fork(ctx, u.SignatureSlot)
+	if err != nil {
+		return errors.Wrap(err, "get fork")
+	}
+	if err = VerifySyncAggregate(u, committee, forkVersion, gvr); err != nil {
+		return invalid(err)
+	}
+	if len(u.NextSyncCommitteeBranch) != 0 {
+		// proven by the branch, signed by the current committee
+		next, err := NewSyncCommittee(&u.NextSyncCommittee)
+		if err == nil {
+			v.known[period+1] = next
+		}
+	}
+	for p := range v.known {
+		if p+2 < period {
+			delete(v.known, p)
+		}
+	}
+	return nil
+}
+
+// committee returns the sync committee of period, which mustn't come from
+// endpoint, the beacon node of the update it checks.
+func (v *Verifier) committee(ctx context.Context, period uint64, endpoint string) (*SyncCommittee, error) {
+	if c, ok := v.known[period]; ok {
+		return c, nil
+	}
+	if period == 0 {
+		return nil, errors.New("no update before period 0")
+	}
+	source := v.committees
+	if source == nil {
+		source = v.client.Without(endpoint)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("no beacon endpoint besides %s to take the sync committee from", endpoint)
+	}
+	resp, err := source.LightClientUpdate(ctx, period-1)
+	if err != nil {
+		return nil, err
+	}
+	raw := ContractSyncCommittee{AggregatePubkey: common.FromHex(resp.Data.NextSyncCommittee.AggregatePubkey)}
+	for _, pk := range resp.Data.NextSyncCommittee.Pubkeys {
+		raw.Pubkeys = append(raw.Pubkeys, common.FromHex(pk)...)
+	}
+	c, err := NewSyncCommittee(&raw)
+	if err != nil {
+		return nil, err
+	}
+	v.known[period] = c
+	return c, nil
+}
+
+// fork returns the fork version of the signatures made at signatureSlot,
+// the one of the slot before, and the genesis validators root.
+func (v *Verifier) fork(ctx context.Context, signatureSlot uint64) ([4]byte, [32]byte, error) {
+	if v.genesisValidatorsRoot == nil {
+		genesis, err := v.client.Genesis(ctx)
+		if err != nil {
+			return [4]byte{}, [32]byte{}, err
+		}
+		gvr := common.HexToHash(genesis.Data.GenesisValidatorsRoot)
+		v.genesisValidatorsRoot = (*[32]byte)(&gvr)
+	}
+	if len(v.forks) == 0 {
+		schedule, err := v.client.ForkSchedule(ctx)
+		if err != nil {
+			return [4]byte{}, [32]byte{}, err
+		}
+		forks := make([]forkAt, 0, len(schedule.Data))
+		for _, f := range schedule.Data {
+			epoch, err := strconv.ParseUint(f.Epoch, 10, 64)
+			if err != nil {
+				return [4]byte{}, [32]byte{}, errors.Wrapf(err, "fork epoch %q", f.Epoch)
+			}
+			fa := forkAt{epoch: epoch}
+			copy(fa.version[:], common.FromHex(f.CurrentVersion))
+			forks = append(forks, fa)
+		}
+		sort.Slice(forks, func(i, j int) bool { return forks[i].epoch < forks[j].epoch })
+		v.forks = forks
+	}
+	slot := signatureSlot
+	if slot > 0 {
+		slot--
+	}
+	epoch := slot / uint64(constant.SlotsPerEpoch)
+	for i := len(v.forks) - 1; i >= 0; i-- {
+		if v.forks[i].epoch <= epoch {
+			return v.forks[i].version, *v.genesisValidatorsRoot, nil
+		}
+	}
+	return [4]byte{}, [32]byte{}, fmt.Errorf("no fork at epoch %d", epoch)
+}
@@ -0,0 +1,227 @@
+package eth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto/bls12381"
+)
+
+const testPeriod = 3
+
+var (
+	testGenesisValidatorsRoot = common.HexToHash("0x4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95")
+	testForkVersion           = [4]byte{0x04, 0x00, 0x00, 0x00}
+)
+
+// testCommittee is a sync committee of the secret keys 1 to 512.
+type testCommittee struct {
+	raw ContractSyncCommittee
+	sks []*big.Int
+}
+
+func newTestCommittee(offset int64) *testCommittee {
+	g1 := bls12381.NewG1()
+	c := &testCommittee{}
+	sum := g1.Zero()
+	for i := int64(0); i < syncCommitteeSize; i++ {
+		sk := big.NewInt(offset + i + 1)
+		pk := g1.New()
+		g1.MulScalar(pk, g1.One(), sk)
+		g1.Add(sum, sum, pk)
+		c.sks = append(c.sks, sk)
+		c.raw.Pubkeys = append(c.raw.Pubkeys, compressG1(pk)...)
+	}
+	c.raw.AggregatePubkey = compressG1(sum)
+	return c
+}
+
+func (c *testCommittee) sign(t *testing.T, root [32]byte, bits []byte) []byte {
+	t.Helper()
+	h, err := hashToG2(root[:], syncCommitteeDST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := new(big.Int)
+	for i, sk := range c.sks {
+		if bits[i/8]>>(uint(i)%8)&1 == 1 {
+			sum.Add(sum, sk)
+		}
+	}
+	g2 := bls12381.NewG2()
+	sig := g2.New()
+	g2.MulScalar(sig, h, sum)
+	return compressG2(sig)
+}
+
+// stateTree is a beacon state of 64 leaves, with the finalized header at
+// gindex 105 and the next sync committee at gindex 55.
+type stateTree [128][32]byte
+
+func newStateTree(finalized, nextSyncCommittee [32]byte) *stateTree {
+	var tree stateTree
+	for g := 64; g < 128; g++ {
+		tree[g] = sha256.Sum256([]byte{byte(g)})
+	}
+	tree[105] = finalized
+	for g := 63; g >= 1; g-- {
+		tree[g] = hashPair(tree[2*g], tree[2*g+1])
+		if g == 55 {
+			tree[g] = nextSyncCommittee
+		}
+	}
+	return &tree
+}
+
+func (s *stateTree) branch(gindex int) [][32]byte {
+	ret := make([][32]byte, 0)
+	for g := gindex; g > 1; g /= 2 {
+		ret = append(ret, s[g^1])
+	}
+	return ret
+}
+
+func newTestUpdate(t *testing.T, current, next *testCommittee) *LightClientUpdate {
+	t.Helper()
+	finalized := BeaconBlockHeader{Slot: testPeriod*8192 + 64, ProposerIndex: 7, StateRoot: common.Hash{1}}
+	nextRoot, err := HashTreeRootSyncCommittee(&next.raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := newStateTree(HashTreeRootHeader(&finalized), nextRoot)
+	u := &LightClientUpdate{
+		AttestedHeader:          BeaconBlockHeader{Slot: testPeriod*8192 + 128, ProposerIndex: 9, StateRoot: tree[1]},
+		SignatureSlot:           testPeriod*8192 + 129,
+		NextSyncCommittee:       next.raw,
+		NextSyncCommitteeBranch: tree.branch(55),
+		FinalizedHeader:         finalized,
+		FinalityBranch:          tree.branch(105),
+	}
+	bits := make([]byte, 64)
+	for i := range bits {
+		bits[i] = 0xff
+	}
+	bits[3] = 0x7f
+	u.SyncAggregate.SyncCommitteeBits = bits
+	u.SyncAggregate.SyncCommitteeSignature = current.sign(t,
+		ComputeSigningRoot(&u.AttestedHeader, testForkVersion, testGenesisValidatorsRoot), bits)
+	return u
+}
+
+// newBeacon serves the genesis, the fork schedule and the update of the
+// period before the test one, whose next sync committee is current.
+func newBeacon(t *testing.T, current *testCommittee) (*Client, *int32) {
+	t.Helper()
+	var updates int32
+	pubkeys := make([]string, 0, syncCommitteeSize)
+	for i := 0; i < syncCommitteeSize; i++ {
+		pubkeys = append(pubkeys, common.Bytes2Hex(current.raw.Pubkeys[48*i:48*(i+1)]))
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body interface{}
+		switch r.URL.Path {
+		case "/eth/v1/beacon/genesis":
+			body = map[string]interface{}{"data": map[string]string{
+				"genesis_validators_root": testGenesisValidatorsRoot.Hex(),
+			}}
+		case "/eth/v1/config/fork_schedule":
+			body = map[string]interface{}{"data": []map[string]string{
+				{"previous_version": "0x00000000", "current_version": "0x00000000", "epoch": "0"},
+				{"previous_version": "0x03000000", "current_version": "0x04000000", "epoch": "768"},
+				{"previous_version": "0x00000000", "current_version": "0x03000000", "epoch": "512"},
+			}}
+		case "/eth/v1/beacon/light_client/updates":
+			if r.URL.Query().Get("start_period") != "2" {
+				http.NotFound(w, r)
+				return
+			}
+			atomic.AddInt32(&updates, 1)
+			body = []map[string]interface{}{{"version": "deneb", "data": map[string]interface{}{
+				"next_sync_committee": map[string]interface{}{
+					"pubkeys":          pubkeys,
+					"aggregate_pubkey": common.Bytes2Hex(current.raw.AggregatePubkey),
+				},
+			}}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := DialHttp(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, &updates
+}
+
+func TestHashTreeRootHeader(t *testing.T) {
+	h := BeaconBlockHeader{Slot: 1, ProposerIndex: 2, ParentRoot: common.Hash{3}, StateRoot: common.Hash{4}, BodyRoot: common.Hash{5}}
+	l := func(a, b [32]byte) [32]byte { return sha256.Sum256(append(a[:], b[:]...)) }
+	want := l(l(l(uint64Chunk(1), uint64Chunk(2)), l(h.ParentRoot, h.StateRoot)), l(l(h.BodyRoot, [32]byte{}), l([32]byte{}, [32]byte{})))
+	if got := HashTreeRootHeader(&h); got != want {
+		t.Fatalf("got %x", got)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	current, next := newTestCommittee(0), newTestCommittee(1000)
+	client, served := newBeacon(t, current)
+	committees, updates := newBeacon(t, current)
+	v := NewVerifier(client, committees)
+
+	u := newTestUpdate(t, current, next)
+	if err := v.Verify(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+	// the committee is asked once, and not of the beacon node of the update
+	if err := v.Verify(context.Background(), u); err != nil || atomic.LoadInt32(updates) != 1 || atomic.LoadInt32(served) != 0 {
+		t.Fatalf("asked %d updates, %d of the update's node: %v", *updates, *served, err)
+	}
+
+	// without committee nodes another node of the client is asked
+	both, err := DialHttp(client.Endpoint() + "," + committees.Endpoint())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = NewVerifier(both, nil).Verify(context.Background(), u); err != nil || atomic.LoadInt32(updates) != 2 ||
+		atomic.LoadInt32(served) != 0 {
+		t.Fatalf("asked %d updates, %d of the update's node: %v", *updates, *served, err)
+	}
+	// and there is none besides the one of the update
+	if err = NewVerifier(client, nil).Verify(context.Background(), u); err == nil || errors.Is(err, ErrInvalidUpdate) ||
+		atomic.LoadInt32(served) != 0 {
+		t.Fatalf("took the committee of the update's node: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(u *LightClientUpdate)
+	}{
+		{"signer left out", func(u *LightClientUpdate) { u.SyncAggregate.SyncCommitteeBits[0] = 0xfe }},
+		{"other attested header", func(u *LightClientUpdate) { u.AttestedHeader.ProposerIndex++ }},
+		{"finality branch", func(u *LightClientUpdate) { u.FinalizedHeader.Slot++ }},
+		{"next sync committee branch", func(u *LightClientUpdate) { u.NextSyncCommittee = current.raw }},
+		{"short branch", func(u *LightClientUpdate) { u.FinalityBranch = u.FinalityBranch[1:] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newTestUpdate(t, current, next)
+			tt.tamper(u)
+			err := NewVerifier(client, committees).Verify(context.Background(), u)
+			if !errors.Is(err, ErrInvalidUpdate) || !strings.Contains(err.Error(), client.Endpoint()) {
+				t.Fatalf("got %v", err)
+			}
+		})
+	}
+}
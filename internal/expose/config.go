@@ -28,15 +28,25 @@ type RawChainConfig struct {
 	Mcs        string `json:"mcs,omitempty"`
 	OracleNode string `json:"oracleNode,omitempty"`
 	LightNode  string `json:"lightNode,omitempty"`
+	// ReceiptEncoding is the receipt family of the chain, empty keeps the built-in one
+	ReceiptEncoding string `json:"receiptEncoding,omitempty"`
+	// Opts are the chain options of the evm chain type
+	Opts map[string]string `json:"opts,omitempty"`
 }
 
 type Construction struct {
-	MonitorUrl   string `json:"monitor_url,omitempty"`
-	Env          string `json:"env,omitempty"`
-	Port         string `json:"port,omitempty"`
-	Butter       string `json:"butter,omitempty"`
-	ButterAPIKey string `json:"butter_api_key,omitempty"`
-	Key          string `json:"key,omitempty"`
+	MonitorUrl       string `json:"monitor_url,omitempty"`
+	Env              string `json:"env,omitempty"`
+	Port             string `json:"port,omitempty"`
+	Butter           string `json:"butter,omitempty"`
+	ButterAPIKey     string `json:"butter_api_key,omitempty"`
+	Key              string `json:"key,omitempty"`
+	ReceiptCacheSize int    `json:"receipt_cache_size,omitempty"` // blocks kept in memory
+	ReceiptCacheTTL  int64  `json:"receipt_cache_ttl,omitempty"`  // seconds
+	ReceiptCacheDir  string `json:"receipt_cache_dir,omitempty"`  // enables the on-disk tier
+	ZkUrl            string `json:"zk_url,omitempty"`             // zk prover endpoint, "mock" for the local stand-in
+	ZkCacheSize      int    `json:"zk_cache_size,omitempty"`      // proofs kept in memory
+	ZkWait           int64  `json:"zk_wait,omitempty"`            // seconds to wait for an unfinished proof, 0 waits until done
 }
 
 func (c *Config) validate() error {
@@ -6,17 +6,13 @@ package mapo
 import (
 	"context"
 	"math/big"
-	"strings"
-	"sync"
-	"time"
 
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/pkg/msg"
 
 	"github.com/ethereum/go-ethereum/ethdb/memorydb"
-	"github.com/mapprotocol/compass/internal/arb"
 	"github.com/mapprotocol/compass/internal/constant"
-	"github.com/mapprotocol/compass/internal/op"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/pkg/util"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -47,13 +43,29 @@ func AssembleEthProof(conn *ethclient.Client, log *types.Log, receipts []*types.
 	if err != nil {
 		return nil, err
 	}
-	prf, receiptHash, err := ethProof(conn, fId, log.TxIndex, receipts)
+	dls, err := ireceipt.Encode(conn, fId, ireceipt.Ethereum, receipts)
+	if err != nil {
+		return nil, err
+	}
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	receiptHash := proof.DeriveTire(dls, tr).Hash()
+	// the log oracle signs the log itself, every other proof type is checked
+	// against the receipts root; conflux's oracle signs the derived root
+	root := header.ReceiptHash
+	if fId == constant.CfxChainId {
+		root = receiptHash
+	}
+	var prf [][]byte
+	if proofType == constant.ProofTypeOfLogOracle {
+		prf, err = proof.Get(dls, log.TxIndex)
+	} else {
+		prf, err = proof.GetVerified(fId, root, dls, log.TxIndex)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	var key []byte
-	key = rlp.AppendUint64(key[:0], uint64(log.TxIndex))
+	key := proof.Key(dls, log.TxIndex)
 
 	switch proofType {
 	case constant.ProofTypeOfOrigin:
@@ -76,70 +88,8 @@ func AssembleEthProof(conn *ethclient.Client, log *types.Log, receipts []*types.
 	return pack, nil
 }
 
-func ethProof(conn *ethclient.Client, fId msg.ChainId, txIdx uint, receipts []*types.Receipt) ([][]byte, common.Hash, error) {
-	var dls proof.DerivableList
-	switch fId {
-	case constant.ArbChainId, constant.Robinhood:
-		pr := arb.Receipts{}
-		for _, r := range receipts {
-			pr = append(pr, &arb.Receipt{Receipt: r})
-		}
-		dls = pr
-	case constant.BaseChainId, constant.OptimismChainId:
-		pr := op.Receipts{}
-		results := make([]*op.Receipt, len(receipts))
-		var wg sync.WaitGroup
-		for i, r := range receipts {
-			wg.Add(1)
-			go func(i int, r *types.Receipt) {
-				defer wg.Done()
-				tmp, err := conn.OpReceipt(context.Background(), r.TxHash)
-				if err != nil {
-					return
-				}
-				if tmp == nil {
-					return
-				}
-				vptr := uint64(0)
-				nptr := uint64(0)
-				if tmp.DepositReceiptVersion != "" {
-					version, _ := big.NewInt(0).SetString(strings.TrimPrefix(tmp.DepositReceiptVersion, "0x"), 16)
-					vptr = version.Uint64()
-				}
-				if tmp.DepositNonce != "" {
-					nonce, _ := big.NewInt(0).SetString(strings.TrimPrefix(tmp.DepositNonce, "0x"), 16)
-					nptr = nonce.Uint64()
-				}
-				results[i] = &op.Receipt{Receipt: r, DepositReceiptVersion: &vptr, DepositNonce: &nptr}
-			}(i, r)
-			if i%30 == 0 {
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
-		wg.Wait()
-		for _, result := range results {
-			if result == nil {
-				continue
-			}
-			pr = append(pr, result)
-		}
-		dls = pr
-	default:
-		dls = types.Receipts(receipts)
-	}
-	ret, err := proof.Get(dls, txIdx)
-	if err != nil {
-		return nil, constant.ZeroAddress.Hash(), err
-	}
-
-	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
-	tr = proof.DeriveTire(dls, tr)
-
-	return ret, tr.Hash(), nil
-}
-
 func AssembleMapProof(cli *ethclient.Client, log *types.Log, receipts []*types.Receipt,
-	header *maptypes.Header, fId msg.ChainId, method, zkUrl string, proofType int64, sign [][]byte) (uint64, []byte, error) {
+	header *maptypes.Header, fId msg.ChainId, method string, proofType int64, sign [][]byte) (uint64, []byte, error) {
 	uToChainID := big.NewInt(0).SetBytes(log.Topics[2].Bytes()[8:16]).Uint64()
 	txIndex := log.TxIndex
 	orderId := log.Topics[1]
@@ -149,13 +99,24 @@ func AssembleMapProof(cli *ethclient.Client, log *types.Log, receipts []*types.R
 	}
 
 	receipt, err := mapprotocol.GetTxReceipt(receipts[txIndex])
-	prf, err := proof.Get(types.Receipts(receipts), txIndex)
+	if err != nil {
+		return 0, nil, err
+	}
+	dls, err := ireceipt.Encode(cli, fId, ireceipt.Ethereum, receipts)
+	if err != nil {
+		return 0, nil, err
+	}
+	var prf [][]byte
+	if proofType == constant.ProofTypeOfLogOracle {
+		prf, err = proof.Get(dls, txIndex)
+	} else {
+		prf, err = proof.GetVerified(fId, header.ReceiptHash, dls, txIndex)
+	}
 	if err != nil {
 		return 0, nil, err
 	}
 
-	var key []byte
-	key = rlp.AppendUint64(key[:0], uint64(txIndex))
+	key := proof.Key(dls, txIndex)
 	ek := util.Key2Hex(key, len(prf))
 
 	var payloads []byte
@@ -196,7 +157,7 @@ func AssembleMapProof(cli *ethclient.Client, log *types.Log, receipts []*types.R
 
 		switch proofType {
 		case constant.ProofTypeOfZk:
-			zkProof, err := mapprotocol.GetZkProof(zkUrl, fId, header.Number.Uint64())
+			zkProof, err := proof.ZkProver.ZkProof(context.Background(), fId, header.Number.Uint64())
 			if err != nil {
 				return 0, nil, errors.Wrap(err, "GetZkProof failed")
 			}
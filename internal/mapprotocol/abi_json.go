@@ -6,7 +6,7 @@ package mapprotocol
 const (
 	NearAbiJson    = `[{"inputs":[{"internalType":"bytes","name":"head","type":"bytes"},{"internalType":"bytes","name":"proof","type":"bytes"}],"name":"getBytes","outputs":[{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"stateMutability":"view","type":"function"}]`
 	McsAbi         = `[{"inputs":[{"internalType":"address[]","name":"_tokens","type":"address[]"}],"name":"addMintableToken","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"butterRouter","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"_admin","type":"address"}],"name":"changeAdmin","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"_to","type":"address"}],"name":"depositNative","outputs":[],"stateMutability":"payable","type":"function"},{"inputs":[{"internalType":"address","name":"_token","type":"address"},{"internalType":"address","name":"_to","type":"address"},{"internalType":"uint256","name":"_amount","type":"uint256"}],"name":"depositToken","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"getAdmin","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"getImplementation","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"uint256","name":"_blockNum","type":"uint256"},{"internalType":"bytes32","name":"_orderId","type":"bytes32"}],"name":"getOrderStatus","outputs":[{"internalType":"bool","name":"exists","type":"bool"},{"internalType":"bool","name":"verifiable","type":"bool"},{"internalType":"uint256","name":"nodeType","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"_wToken","type":"address"},{"internalType":"address","name":"_lightNode","type":"address"},{"internalType":"address","name":"_owner","type":"address"}],"name":"initialize","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"_token","type":"address"},{"internalType":"uint256","name":"_toChain","type":"uint256"}],"name":"isBridgeable","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"_token","type":"address"}],"name":"isMintable","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"lightNode","outputs":[{"internalType":"contract ILightNode","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"","type":"address"}],"name":"mintableTokens","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"nonce","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"orderList","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"paused","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"proxiableUUID","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"_token","type":"address"},{"internalType":"uint256","name":"_toChain","type":"uint256"},{"internalType":"bool","name":"_enable","type":"bool"}],"name":"registerToken","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"_token","type":"address"},{"internalType":"uint256[]","name":"_toChains","type":"uint256[]"},{"internalType":"bool","name":"_enable","type":"bool"}],"name":"registerTokenChains","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"relayChainId","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"relayContract","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address[]","name":"_tokens","type":"address[]"}],"name":"removeMintableToken","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"selfChainId","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"_lightNode","type":"address"}],"name":"setLightClient","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"setPause","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"address","name":"_relay","type":"address"}],"name":"setRelayContract","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"setUnpause","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"_wToken","type":"address"}],"name":"setWrappedToken","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"storedOrderId","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"uint256","name":"_logIndex","type":"uint256"},{"internalType":"bytes32","name":"_orderId","type":"bytes32"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"swapIn","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"bytes","name":"_logArray","type":"bytes"},{"internalType":"uint256","name":"_logIndex","type":"uint256"},{"internalType":"bytes32","name":"_orderId","type":"bytes32"}],"name":"swapInVerified","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"bytes","name":"_logArray","type":"bytes"},{"internalType":"uint256","name":"_logIndex","type":"uint256"}],"name":"swapInVerifiedWithIndex","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"swapInVerify","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"uint256","name":"_logIndex","type":"uint256"},{"internalType":"bytes32","name":"_orderId","type":"bytes32"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"messageIn","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"uint256","name":"_logIndex","type":"uint256"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"swapInWithIndex","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"_initiatorAddress","type":"address"},{"internalType":"bytes","name":"_to","type":"bytes"},{"internalType":"uint256","name":"_toChain","type":"uint256"},{"internalType":"bytes","name":"_swapData","type":"bytes"}],"name":"swapOutNative","outputs":[{"internalType":"bytes32","name":"orderId","type":"bytes32"}],"stateMutability":"payable","type":"function"},{"inputs":[{"internalType":"address","name":"_initiatorAddress","type":"address"},{"internalType":"address","name":"_token","type":"address"},{"internalType":"bytes","name":"_to","type":"bytes"},{"internalType":"uint256","name":"_amount","type":"uint256"},{"internalType":"uint256","name":"_toChain","type":"uint256"},{"internalType":"bytes","name":"_swapData","type":"bytes"}],"name":"swapOutToken","outputs":[{"internalType":"bytes32","name":"orderId","type":"bytes32"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"address","name":"","type":"address"}],"name":"tokenMappingList","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"newImplementation","type":"address"}],"name":"upgradeTo","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"newImplementation","type":"address"},{"internalType":"bytes","name":"data","type":"bytes"}],"name":"upgradeToAndCall","outputs":[],"stateMutability":"payable","type":"function"},{"inputs":[],"name":"wToken","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"stateMutability":"payable","type":"receive"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"bytes","name":"receiptProof","type":"bytes"}],"name":"transferInWithIndex","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"uint256","name":"","type":"uint256"},{"internalType":"bytes","name":"receiptProof","type":"bytes"}],"name":"swapInWithIndex","outputs":[],"stateMutability":"nonpayable","type":"function"},{"anonymous":false,"inputs":[{"indexed":false,"internalType":"bytes","name":"logs","type":"bytes"}],"name":"mapSwapInVerified","type":"event"}]`
-	LightMangerAbi = `[{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"finalizedState","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"updateLightClient","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"}],"name":"clientState","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_blockHeader","type":"bytes"}],"name":"updateBlockHeader","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"}],"name":"headerHeight","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"verifyProofData","outputs":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"string","name":"message","type":"string"},{"internalType":"bytes","name":"logs","type":"bytes"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"}],"name":"verifiableHeaderRange","outputs":[{"internalType":"uint256","name":"left","type":"uint256"},{"internalType":"uint256","name":"right","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"nodeType","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+	LightMangerAbi = `[{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"lightClientContract","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"finalizedState","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_data","type":"bytes"}],"name":"updateLightClient","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"}],"name":"clientState","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_blockHeader","type":"bytes"}],"name":"updateBlockHeader","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"}],"name":"headerHeight","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"verifyProofData","outputs":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"string","name":"message","type":"string"},{"internalType":"bytes","name":"logs","type":"bytes"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"}],"name":"verifiableHeaderRange","outputs":[{"internalType":"uint256","name":"left","type":"uint256"},{"internalType":"uint256","name":"right","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"nodeType","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
 	BscAbiJson     = `[{"inputs":[{"components":[{"internalType":"bytes","name":"parentHash","type":"bytes"},{"internalType":"bytes","name":"sha3Uncles","type":"bytes"},{"internalType":"address","name":"miner","type":"address"},{"internalType":"bytes","name":"stateRoot","type":"bytes"},{"internalType":"bytes","name":"transactionsRoot","type":"bytes"},{"internalType":"bytes","name":"receiptsRoot","type":"bytes"},{"internalType":"bytes","name":"logsBloom","type":"bytes"},{"internalType":"uint256","name":"difficulty","type":"uint256"},{"internalType":"uint256","name":"number","type":"uint256"},{"internalType":"uint256","name":"gasLimit","type":"uint256"},{"internalType":"uint256","name":"gasUsed","type":"uint256"},{"internalType":"uint256","name":"timestamp","type":"uint256"},{"internalType":"bytes","name":"extraData","type":"bytes"},{"internalType":"bytes","name":"mixHash","type":"bytes"},{"internalType":"bytes","name":"nonce","type":"bytes"},{"internalType":"uint256","name":"baseFeePerGas","type":"uint256"},{"internalType":"bytes","name":"withdrawalsRoot","type":"bytes"},{"internalType":"uint256","name":"blobGasUsed","type":"uint256"},{"internalType":"uint256","name":"excessBlobGas","type":"uint256"},{"internalType":"bytes","name":"parentBeaconBlockRoot","type":"bytes"}],"internalType":"struct Verify.BlockHeader[]","name":"_blockHeaders","type":"tuple[]"}],"name":"getHeadersBytes","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"pure","type":"function"},{"inputs":[{"components":[{"components":[{"internalType":"bytes","name":"parentHash","type":"bytes"},{"internalType":"bytes","name":"sha3Uncles","type":"bytes"},{"internalType":"address","name":"miner","type":"address"},{"internalType":"bytes","name":"stateRoot","type":"bytes"},{"internalType":"bytes","name":"transactionsRoot","type":"bytes"},{"internalType":"bytes","name":"receiptsRoot","type":"bytes"},{"internalType":"bytes","name":"logsBloom","type":"bytes"},{"internalType":"uint256","name":"difficulty","type":"uint256"},{"internalType":"uint256","name":"number","type":"uint256"},{"internalType":"uint256","name":"gasLimit","type":"uint256"},{"internalType":"uint256","name":"gasUsed","type":"uint256"},{"internalType":"uint256","name":"timestamp","type":"uint256"},{"internalType":"bytes","name":"extraData","type":"bytes"},{"internalType":"bytes","name":"mixHash","type":"bytes"},{"internalType":"bytes","name":"nonce","type":"bytes"},{"internalType":"uint256","name":"baseFeePerGas","type":"uint256"},{"internalType":"bytes","name":"withdrawalsRoot","type":"bytes"},{"internalType":"uint256","name":"blobGasUsed","type":"uint256"},{"internalType":"uint256","name":"excessBlobGas","type":"uint256"},{"internalType":"bytes","name":"parentBeaconBlockRoot","type":"bytes"}],"internalType":"struct Verify.BlockHeader[]","name":"headers","type":"tuple[]"},{"components":[{"components":[{"internalType":"uint256","name":"receiptType","type":"uint256"},{"internalType":"bytes","name":"postStateOrStatus","type":"bytes"},{"internalType":"uint256","name":"cumulativeGasUsed","type":"uint256"},{"internalType":"bytes","name":"bloom","type":"bytes"},{"components":[{"internalType":"address","name":"addr","type":"address"},{"internalType":"bytes[]","name":"topics","type":"bytes[]"},{"internalType":"bytes","name":"data","type":"bytes"}],"internalType":"struct Verify.TxLog[]","name":"logs","type":"tuple[]"}],"internalType":"struct Verify.TxReceipt","name":"txReceipt","type":"tuple"},{"internalType":"bytes","name":"keyIndex","type":"bytes"},{"internalType":"bytes[]","name":"proof","type":"bytes[]"}],"internalType":"struct Verify.ReceiptProof","name":"receiptProof","type":"tuple"}],"internalType":"struct LightNode.ProofData","name":"_proof","type":"tuple"}],"name":"getBytes","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"pure","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"uint256","name":"_logIndex","type":"uint256"},{"internalType":"bytes32","name":"_orderId","type":"bytes32"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"messageIn","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
 	KlaytnAbiJson  = `[{"inputs":[{"components":[{"internalType":"bytes","name":"parentHash","type":"bytes"},{"internalType":"address","name":"reward","type":"address"},{"internalType":"bytes","name":"stateRoot","type":"bytes"},{"internalType":"bytes","name":"transactionsRoot","type":"bytes"},{"internalType":"bytes","name":"receiptsRoot","type":"bytes"},{"internalType":"bytes","name":"logsBloom","type":"bytes"},{"internalType":"uint256","name":"blockScore","type":"uint256"},{"internalType":"uint256","name":"number","type":"uint256"},{"internalType":"uint256","name":"gasUsed","type":"uint256"},{"internalType":"uint256","name":"timestamp","type":"uint256"},{"internalType":"uint256","name":"timestampFoS","type":"uint256"},{"internalType":"bytes","name":"extraData","type":"bytes"},{"internalType":"bytes","name":"governanceData","type":"bytes"},{"internalType":"bytes","name":"voteData","type":"bytes"},{"internalType":"uint256","name":"baseFee","type":"uint256"}],"internalType":"struct ILightNodePoint.BlockHeader[]","name":"_blockHeaders","type":"tuple[]"}],"name":"getHeadersBytes","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"pure","type":"function"},{"inputs":[{"components":[{"internalType":"bytes","name":"proof","type":"bytes"},{"internalType":"enum ILightNodePoint.DeriveShaOriginal","name":"deriveSha","type":"uint8"}],"internalType":"struct ILightNodePoint.ReceiptProof","name":"_proof","type":"tuple"}],"name":"getFinalBytes","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"pure","type":"function"},{"inputs":[{"components":[{"components":[{"internalType":"bytes","name":"parentHash","type":"bytes"},{"internalType":"address","name":"reward","type":"address"},{"internalType":"bytes","name":"stateRoot","type":"bytes"},{"internalType":"bytes","name":"transactionsRoot","type":"bytes"},{"internalType":"bytes","name":"receiptsRoot","type":"bytes"},{"internalType":"bytes","name":"logsBloom","type":"bytes"},{"internalType":"uint256","name":"blockScore","type":"uint256"},{"internalType":"uint256","name":"number","type":"uint256"},{"internalType":"uint256","name":"gasUsed","type":"uint256"},{"internalType":"uint256","name":"timestamp","type":"uint256"},{"internalType":"uint256","name":"timestampFoS","type":"uint256"},{"internalType":"bytes","name":"extraData","type":"bytes"},{"internalType":"bytes","name":"governanceData","type":"bytes"},{"internalType":"bytes","name":"voteData","type":"bytes"},{"internalType":"uint256","name":"baseFee","type":"uint256"}],"internalType":"struct IKlaytn.BlockHeader","name":"header","type":"tuple"},{"internalType":"bytes[]","name":"proof","type":"bytes[]"},{"internalType":"bytes","name":"txReceipt","type":"bytes"},{"internalType":"bytes","name":"keyIndex","type":"bytes"}],"internalType":"struct IKlaytn.ReceiptProofOriginal","name":"_proof","type":"tuple"}],"name":"getBytes","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"pure","type":"function"},{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"uint256","name":"_logIndex","type":"uint256"},{"internalType":"bytes32","name":"_orderId","type":"bytes32"},{"internalType":"bytes","name":"_receiptProof","type":"bytes"}],"name":"messageIn","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
 	HeightAbiJson  = `[{"inputs":[],"name":"headerHeight","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
@@ -23,4 +23,12 @@ const (
 	GetJson        = `[{"inputs":[{"components":[{"internalType":"enum LightNodeV2.ProofType","name":"proofType","type":"uint8"},{"internalType":"uint256","name":"blockNum","type":"uint256"},{"internalType":"bytes32","name":"receiptRoot","type":"bytes32"},{"internalType":"bytes[]","name":"signatures","type":"bytes[]"},{"internalType":"bytes","name":"proof","type":"bytes"}],"internalType":"struct LightNodeV2.ProofData","name":"_proof","type":"tuple"}],"name":"getBytes","outputs":[{"internalType":"bytes","name":"","type":"bytes"}],"stateMutability":"pure","type":"function"}]`
 	SolJson        = `[{"inputs":[{"components":[{"internalType":"bool","name":"relay","type":"bool"},{"internalType":"uint8","name":"messageType","type":"uint8"},{"internalType":"uint256","name":"fromChain","type":"uint256"},{"internalType":"uint256","name":"toChain","type":"uint256"},{"internalType":"bytes32","name":"orderId","type":"bytes32"},{"internalType":"bytes","name":"mos","type":"bytes"},{"internalType":"bytes","name":"token","type":"bytes"},{"internalType":"bytes","name":"initiator","type":"bytes"},{"internalType":"bytes","name":"from","type":"bytes"},{"internalType":"bytes","name":"to","type":"bytes"},{"internalType":"uint256","name":"amount","type":"uint256"},{"internalType":"uint256","name":"gasLimit","type":"uint256"},{"internalType":"bytes","name":"swapData","type":"bytes"}],"internalType":"struct MessageOutEvent","name":"log","type":"tuple"}],"name":"solEventEncode","outputs":[{"internalType":"bytes","name":"addr","type":"bytes"}],"stateMutability":"pure","type":"function"},{"inputs":[{"internalType":"bytes","name":"addr","type":"bytes"},{"internalType":"bytes","name":"topic","type":"bytes"},{"internalType":"bytes","name":"event","type":"bytes"}],"name":"solPackReceipt","outputs":[{"internalType":"bytes","name":"addr","type":"bytes"}],"stateMutability":"pure","type":"function"}]`
 	ValidateJson   = `[{"inputs":[{"internalType":"contract ITokenRegister","name":"_register","type":"address"}],"stateMutability":"nonpayable","type":"constructor"},{"inputs":[],"name":"selfChainId","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"components":[{"internalType":"bool","name":"relay","type":"bool"},{"internalType":"uint256","name":"dstChain","type":"uint256"},{"internalType":"bytes","name":"dstToken","type":"bytes"},{"internalType":"bytes","name":"dstReceiver","type":"bytes"},{"internalType":"uint256","name":"dstMinAmount","type":"uint256"},{"internalType":"bytes","name":"swapData","type":"bytes"}],"internalType":"struct SwapDataValidator.Param","name":"param","type":"tuple"}],"name":"validate","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"}]`
+	ErrorsAbiJson  = `[{"inputs":[],"name":"order_exist","type":"error"},{"inputs":[],"name":"already_meet","type":"error"},{"inputs":[],"name":"already_proposal","type":"error"}]`
 )
+
+// Eth2InitAbiJson is the initializer of the eth2 light client on MAP.
+const Eth2InitAbiJson = `[{"inputs":[{"internalType":"uint256","name":"_chainId","type":"uint256"},{"internalType":"address","name":"_controller","type":"address"},{"internalType":"address","name":"_mptVerify","type":"address"},{"components":[{"internalType":"uint64","name":"slot","type":"uint64"},{"internalType":"uint64","name":"proposerIndex","type":"uint64"},{"internalType":"bytes32","name":"parentRoot","type":"bytes32"},{"internalType":"bytes32","name":"stateRoot","type":"bytes32"},{"internalType":"bytes32","name":"bodyRoot","type":"bytes32"}],"internalType":"struct Types.BeaconBlockHeader","name":"_finalizedBeaconHeader","type":"tuple"},{"internalType":"uint256","name":"_finalizedExeHeaderNumber","type":"uint256"},{"internalType":"bytes32","name":"_finalizedExeHeaderHash","type":"bytes32"},{"components":[{"internalType":"bytes","name":"pubkeys","type":"bytes"},{"internalType":"bytes","name":"aggregatePubkey","type":"bytes"}],"internalType":"struct Types.SyncCommittee","name":"_curSyncCommittee","type":"tuple"},{"components":[{"internalType":"bytes","name":"pubkeys","type":"bytes"},{"internalType":"bytes","name":"aggregatePubkey","type":"bytes"}],"internalType":"struct Types.SyncCommittee","name":"_nextSyncCommittee","type":"tuple"}],"name":"initialize","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// Eth2LightNodeAbiJson reads the period and sync committees of the eth2 light
+// client on MAP, which keeps the committee of a period at syncCommittees[period % 2].
+const Eth2LightNodeAbiJson = `[{"inputs":[],"name":"curPeriod","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"uint256","name":"","type":"uint256"}],"name":"syncCommittees","outputs":[{"internalType":"bytes","name":"pubkeys","type":"bytes"},{"internalType":"bytes","name":"aggregatePubkey","type":"bytes"}],"stateMutability":"view","type":"function"}]`
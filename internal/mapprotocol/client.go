@@ -2,23 +2,13 @@ package mapprotocol
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"math/big"
-	"net/http"
-	"time"
 
-	"github.com/mapprotocol/compass/pkg/msg"
-
-	log "github.com/ChainSafe/log15"
 	eth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	maptypes "github.com/mapprotocol/atlas/core/types"
-	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/pkg/ethclient"
-	"github.com/mapprotocol/compass/pkg/util"
 )
 
 func GetTxsByBn(conn *ethclient.Client, number *big.Int) ([]common.Hash, error) {
@@ -105,46 +95,23 @@ type Zk struct {
 	} `json:"data"`
 }
 
-func GetZkProof(endpoint string, cid msg.ChainId, height uint64) ([]*big.Int, error) {
+// ZkStatusReady is the status of a Zk response whose proof is generated.
+const ZkStatusReady = 3
+
+// Ready reports whether the prover has finished the proof.
+func (z *Zk) Ready() bool {
+	return z.Data.Status == ZkStatusReady
+}
+
+// Words flattens the proof into the words the light client takes.
+func (z *Zk) Words() []*big.Int {
 	ret := make([]*big.Int, 0, 8)
-	for {
-		resp, err := http.Get(fmt.Sprintf("%s/proof?chain_id=%d&height=%d", endpoint, cid, height))
-		if err != nil {
-			util.Alarm(context.Background(), fmt.Sprintf("GetZkProof cid(%d) request failed, err is %v", height, err))
-			log.Error("GetZkProof request failed", "err", err, "height", height, "cid", cid)
-			time.Sleep(constant.BlockRetryInterval)
-			continue
-		}
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Error("GetZkProof read body failed", "err", err)
-			time.Sleep(constant.BlockRetryInterval)
-			continue
-		}
-		_ = resp.Body.Close()
-		zk := &Zk{}
-		err = json.Unmarshal(body, zk)
-		if err != nil {
-			util.Alarm(context.Background(), fmt.Sprintf("GetZkProof cid(%d) Unmarshal failed, err is %v", height, err))
-			log.Error("GetZkProof Unmarshal failed", "err", err, "data", string(body))
-			time.Sleep(constant.BlockRetryInterval)
-			continue
-		}
-		// check status
-		if zk.Data.Status != 3 {
-			//util.Alarm(context.Background(), fmt.Sprintf("GetZkProof cid(%d) height(%d) Proof Not Ready", cid, height))
-			log.Info("GetZkProof Proof Not Read", "cid", cid, "height", height)
-			time.Sleep(constant.BalanceRetryInterval)
-			continue
-		}
-		ret = append(ret, getId(zk.Data.Result.Proof.PiA)...)
-		for _, bs := range zk.Data.Result.Proof.PiB {
-			ret = append(ret, getId(bs)...)
-		}
-		ret = append(ret, getId(zk.Data.Result.Proof.PiC)...)
-		break
+	ret = append(ret, getId(z.Data.Result.Proof.PiA)...)
+	for _, bs := range z.Data.Result.Proof.PiB {
+		ret = append(ret, getId(bs)...)
 	}
-	return ret, nil
+	ret = append(ret, getId(z.Data.Result.Proof.PiC)...)
+	return ret
 }
 
 func GetCurValidators(cli *ethclient.Client, number *big.Int) ([]byte, error) {
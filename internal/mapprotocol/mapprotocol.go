@@ -39,8 +39,17 @@ var (
 	GetEth22MapNumber    = func(chainId msg.ChainId) (*big.Int, *big.Int, error) { return nil, nil, nil } // can reform, return data is []byte
 	GetDataByManager     = func(string, ...interface{}) ([]byte, error) { return nil, nil }
 	GetNodeTypeByManager = func(string, ...interface{}) (*big.Int, error) { return nil, nil }
+	// GetEth2SyncCommittees returns the period of the eth2 light client of chainId
+	// on MAP with the sync committees it holds of that period and of the next
+	GetEth2SyncCommittees = func(chainId msg.ChainId) (uint64, []SyncCommittee, error) { return 0, nil, nil }
 )
 
+// SyncCommittee is a sync committee as the eth2 light client on MAP keeps it.
+type SyncCommittee struct {
+	Pubkeys         []byte
+	AggregatePubkey []byte
+}
+
 func InitLightManager(lightNode common.Address) {
 	GetDataByManager = func(method string, params ...interface{}) ([]byte, error) {
 		input, err := PackInput(LightManger, method, params...)
@@ -109,6 +118,49 @@ func Init2GetEth22MapNumber(lightNode common.Address) {
 	}
 }
 
+// InitEth2SyncCommittees reads the sync committees from the eth2 light client
+// lightManager routes chainId to.
+func InitEth2SyncCommittees(lightManager common.Address) {
+	call := func(to common.Address, method string, params ...interface{}) ([]interface{}, error) {
+		target := LightManger
+		if to != lightManager {
+			target = Eth2Node
+		}
+		input, err := PackInput(target, method, params...)
+		if err != nil {
+			return nil, err
+		}
+		output, err := GlobalMapConn.CallContract(context.Background(),
+			goeth.CallMsg{From: constant.ZeroAddress, To: &to, Data: input}, nil)
+		if err != nil {
+			return nil, errors.Wrap(err, method)
+		}
+		return target.Methods[method].Outputs.Unpack(output)
+	}
+	GetEth2SyncCommittees = func(chainId msg.ChainId) (uint64, []SyncCommittee, error) {
+		ret, err := call(lightManager, MethodOfLightClientContract, big.NewInt(int64(chainId)))
+		if err != nil {
+			return 0, nil, err
+		}
+		lightNode := *abi.ConvertType(ret[0], new(common.Address)).(*common.Address)
+		ret, err = call(lightNode, MethodOfCurPeriod)
+		if err != nil {
+			return 0, nil, err
+		}
+		period := ret[0].(*big.Int)
+		committees := make([]SyncCommittee, 0, 2)
+		for i := int64(0); i < 2; i++ {
+			idx := new(big.Int).Mod(new(big.Int).Add(period, big.NewInt(i)), big.NewInt(2))
+			ret, err = call(lightNode, MethodOfSyncCommittees, idx)
+			if err != nil {
+				return 0, nil, err
+			}
+			committees = append(committees, SyncCommittee{Pubkeys: ret[0].([]byte), AggregatePubkey: ret[1].([]byte)})
+		}
+		return period.Uint64(), committees, nil
+	}
+}
+
 func InitOtherChain2MapHeight(lightManager common.Address) {
 	Get2MapHeight = func(chainId msg.ChainId) (*big.Int, error) {
 		input, err := PackInput(LightManger, MethodOfHeaderHeight, big.NewInt(int64(chainId)))
@@ -41,6 +41,10 @@ const (
 	MethodOfSolEventEncode       = "solEventEncode"
 	MethodOfSolPackReceipt       = "solPackReceipt"
 	MethodOfValidate             = "validate"
+	MethodOfInitialize           = "initialize"
+	MethodOfLightClientContract  = "lightClientContract"
+	MethodOfCurPeriod            = "curPeriod"
+	MethodOfSyncCommittees       = "syncCommittees"
 )
 
 const (
@@ -78,6 +82,8 @@ var (
 	Height, _      = abi.JSON(strings.NewReader(HeightAbiJson))
 	Matic, _       = abi.JSON(strings.NewReader(MaticAbiJson))
 	Eth2, _        = abi.JSON(strings.NewReader(Eth2AbiJson))
+	Eth2Init, _    = abi.JSON(strings.NewReader(Eth2InitAbiJson))
+	Eth2Node, _    = abi.JSON(strings.NewReader(Eth2LightNodeAbiJson))
 	Other, _       = abi.JSON(strings.NewReader(OtherAbi))
 	OracleAbi, _   = abi.JSON(strings.NewReader(OracleAbiJson))
 	ProofAbi, _    = abi.JSON(strings.NewReader(ProofAbiJson))
@@ -86,6 +92,7 @@ var (
 	PackAbi, _     = abi.JSON(strings.NewReader(PackJson))
 	GetAbi, _      = abi.JSON(strings.NewReader(GetJson))
 	SolAbi, _      = abi.JSON(strings.NewReader(SolJson))
+	ErrorsAbi, _   = abi.JSON(strings.NewReader(ErrorsAbiJson))
 )
 
 type Role string
@@ -1,19 +1,16 @@
 package matic
 
 import (
-	"bytes"
-	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethdb/memorydb"
 	"github.com/ethereum/go-ethereum/rlp"
-	"github.com/ethereum/go-ethereum/trie"
 	maptypes "github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/compass/internal/constant"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/internal/proof"
+	ireceipt "github.com/mapprotocol/compass/internal/receipt"
 	"github.com/mapprotocol/compass/pkg/msg"
 	"github.com/mapprotocol/compass/pkg/util"
 )
@@ -79,7 +76,7 @@ type ProofData struct {
 	ReceiptProof proof.NewReceiptProof
 }
 
-func AssembleProof(headers []BlockHeader, log *types.Log, fId msg.ChainId, receipts []*types.Receipt,
+func AssembleProof(src ireceipt.Source, headers []BlockHeader, log *types.Log, fId msg.ChainId, receipts []*types.Receipt,
 	method string, proofType int64, sign [][]byte) ([]byte, error) {
 	txIndex := log.TxIndex
 	orderId := log.Topics[1]
@@ -88,20 +85,21 @@ func AssembleProof(headers []BlockHeader, log *types.Log, fId msg.ChainId, recei
 		return nil, err
 	}
 
-	prf, err := proof.Get(Receipts(receipts), txIndex)
+	dls, err := ireceipt.Encode(src, fId, ireceipt.Matic, receipts)
 	if err != nil {
 		return nil, err
 	}
-
-	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
-	tr = proof.DeriveTire(Receipts(receipts), tr)
-	ret := tr.Hash()
-	if ret != common.BytesToHash(headers[0].ReceiptsRoot) {
-		fmt.Println("Matic generate", ret, "oracle", common.BytesToHash(headers[0].ReceiptsRoot), " not same")
+	var prf [][]byte
+	if proofType == constant.ProofTypeOfLogOracle {
+		prf, err = proof.Get(dls, txIndex)
+	} else {
+		prf, err = proof.GetVerified(fId, common.BytesToHash(headers[0].ReceiptsRoot), dls, txIndex)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	var key []byte
-	key = rlp.AppendUint64(key[:0], uint64(txIndex))
+	key := proof.Key(dls, txIndex)
 
 	idx := 0
 	for i, ele := range receipts[txIndex].Logs {
@@ -169,39 +167,3 @@ func AssembleProof(headers []BlockHeader, log *types.Log, fId msg.ChainId, recei
 
 	return pack, nil
 }
-
-type receiptRLP struct {
-	PostStateOrStatus []byte
-	CumulativeGasUsed uint64
-	Bloom             types.Bloom
-	Logs              []*types.Log
-}
-
-type Receipts []*types.Receipt
-
-// Len returns the number of receipts in this list.
-func (rs Receipts) Len() int { return len(rs) }
-
-// EncodeIndex encodes the i'th receipt to w.
-func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
-	r := rs[i]
-	data := &receiptRLP{statusEncoding(r), r.CumulativeGasUsed, r.Bloom, r.Logs}
-	switch r.Type {
-	case constant.LegacyTxType:
-		rlp.Encode(w, data)
-	case constant.AccessListTxType, constant.BlobTxType, constant.SetCodeTxType, constant.DynamicFeeTxType:
-		w.WriteByte(r.Type)
-		rlp.Encode(w, data)
-	default:
-	}
-}
-
-func statusEncoding(r *types.Receipt) []byte {
-	if len(r.PostState) == 0 {
-		if r.Status == constant.ReceiptStatusFailed {
-			return constant.ReceiptStatusFailedRLP
-		}
-		return constant.ReceiptStatusSuccessfulRLP
-	}
-	return r.PostState
-}
@@ -0,0 +1,231 @@
+package observability
+
+import "math/big"
+
+// AccountState publishes the balance watcher's view of one relayer account.
+// Like ChainState every method is nil-safe so chains without a watcher can
+// call through unconditionally.
+type AccountState struct {
+	Chain   string
+	Address string
+
+	m *Metrics
+}
+
+// RegisterAccount returns the AccountState for chain+address. Unlike chains
+// there is no /status entry, so nothing needs to be retained.
+func (o *Observability) RegisterAccount(chain, address string) *AccountState {
+	return &AccountState{m: o.Metrics, Chain: chain, Address: address}
+}
+
+// SetBalance updates the balance gauge (whole native tokens).
+func (a *AccountState) SetBalance(v float64) {
+	if a == nil {
+		return
+	}
+	a.m.AccountBalance.WithLabelValues(a.Chain, a.Address).Set(v)
+}
+
+// SetRelaysLeft updates the estimated number of relays the balance covers.
+func (a *AccountState) SetRelaysLeft(n int64) {
+	if a == nil {
+		return
+	}
+	a.m.RelaysLeft.WithLabelValues(a.Chain, a.Address).Set(float64(n))
+}
+
+// SetPaused flips the sends_paused gauge for the account's chain.
+func (a *AccountState) SetPaused(paused bool) {
+	if a == nil {
+		return
+	}
+	v := 0.0
+	if paused {
+		v = 1
+	}
+	a.m.SendsPaused.WithLabelValues(a.Chain).Set(v)
+}
+
+// GasState publishes the prices chosen by a chain's gas strategy.
+type GasState struct {
+	Chain    string
+	Strategy string
+
+	m *Metrics
+}
+
+// RegisterGas returns the GasState for chain priced by strategy.
+func (o *Observability) RegisterGas(chain, strategy string) *GasState {
+	return &GasState{m: o.Metrics, Chain: chain, Strategy: strategy}
+}
+
+// SetPrice records one price component (gas_price, base_fee, tip, fee_cap)
+// in wei. nil values are skipped.
+func (g *GasState) SetPrice(kind string, wei *big.Int) {
+	if g == nil || wei == nil {
+		return
+	}
+	v, _ := new(big.Float).SetInt(wei).Float64()
+	g.m.GasPrice.WithLabelValues(g.Chain, g.Strategy, kind).Set(v)
+}
+
+// SpendState publishes the fees a chain's writer pays.
+type SpendState struct {
+	Chain string
+
+	m *Metrics
+}
+
+// RegisterSpend returns the SpendState for chain.
+func (o *Observability) RegisterSpend(chain string) *SpendState {
+	return &SpendState{m: o.Metrics, Chain: chain}
+}
+
+// AddSpent adds one confirmed transaction's fee (whole native tokens) for the
+// given message type and source chain.
+func (s *SpendState) AddSpent(transferType, source string, amount float64) {
+	if s == nil {
+		return
+	}
+	s.m.GasSpent.WithLabelValues(s.Chain, transferType, source).Add(amount)
+	s.m.TxsCharged.WithLabelValues(s.Chain, transferType, source).Inc()
+}
+
+// SetBudgetExceeded flips the budget_exceeded gauge for the chain.
+func (s *SpendState) SetBudgetExceeded(exceeded bool) {
+	if s == nil {
+		return
+	}
+	v := 0.0
+	if exceeded {
+		v = 1
+	}
+	s.m.BudgetExceeded.WithLabelValues(s.Chain).Set(v)
+}
+
+// HoldingState publishes the messages a chain's writer parks instead of sending.
+type HoldingState struct {
+	Chain string
+
+	m *Metrics
+}
+
+// RegisterHolding returns the HoldingState for chain.
+func (o *Observability) RegisterHolding(chain string) *HoldingState {
+	return &HoldingState{m: o.Metrics, Chain: chain}
+}
+
+// IncHeld counts one held message of the given type and failure category.
+func (h *HoldingState) IncHeld(transferType, category string) {
+	if h == nil {
+		return
+	}
+	h.m.MessagesHeld.WithLabelValues(h.Chain, transferType, category).Inc()
+}
+
+// ReceiptCacheState publishes the hit and miss counts of the receipt cache.
+type ReceiptCacheState struct {
+	m *Metrics
+}
+
+// RegisterReceiptCache returns the ReceiptCacheState of this Observability.
+func (o *Observability) RegisterReceiptCache() *ReceiptCacheState {
+	return &ReceiptCacheState{m: o.Metrics}
+}
+
+// IncHit counts one lookup on chain served by tier ("memory" or "disk"), or
+// the result of a proof lookup ("proof" or "proof_miss").
+func (r *ReceiptCacheState) IncHit(chain, tier string) {
+	if r == nil {
+		return
+	}
+	r.m.ReceiptCache.WithLabelValues(chain, tier).Inc()
+}
+
+// IncMiss counts one lookup on chain that had to fetch the receipts.
+func (r *ReceiptCacheState) IncMiss(chain string) {
+	if r == nil {
+		return
+	}
+	r.m.ReceiptCache.WithLabelValues(chain, "miss").Inc()
+}
+
+// ProofState publishes the outcome of local receipt proof verification.
+type ProofState struct {
+	m *Metrics
+}
+
+// RegisterProof returns the ProofState of this Observability.
+func (o *Observability) RegisterProof() *ProofState {
+	return &ProofState{m: o.Metrics}
+}
+
+// IncMismatch counts one proof of chain rejected by local verification.
+func (p *ProofState) IncMismatch(chain string) {
+	if p == nil {
+		return
+	}
+	p.m.ProofMismatch.WithLabelValues(chain).Inc()
+}
+
+// ReceiptFetchState publishes which path receipts of one endpoint are fetched by.
+type ReceiptFetchState struct {
+	Endpoint string
+
+	m *Metrics
+}
+
+// RegisterReceiptFetch returns the ReceiptFetchState for endpoint (a host, never a full URL).
+func (o *Observability) RegisterReceiptFetch(endpoint string) *ReceiptFetchState {
+	return &ReceiptFetchState{m: o.Metrics, Endpoint: endpoint}
+}
+
+// IncPath counts one fetch by path.
+func (r *ReceiptFetchState) IncPath(path string) {
+	if r == nil {
+		return
+	}
+	r.m.ReceiptFetches.WithLabelValues(r.Endpoint, path).Inc()
+}
+
+// ZkProofState publishes how zk proofs are obtained.
+type ZkProofState struct {
+	m *Metrics
+}
+
+// RegisterZkProof returns the ZkProofState of this Observability.
+func (o *Observability) RegisterZkProof() *ZkProofState {
+	return &ZkProofState{m: o.Metrics}
+}
+
+// Inc counts one zk proof lookup on chain with result.
+func (z *ZkProofState) Inc(chain, result string) {
+	if z == nil {
+		return
+	}
+	z.m.ZkProofs.WithLabelValues(chain, result).Inc()
+}
+
+// LightClientState publishes the light client watchdog's view of one light
+// client: the one of Src on Dst.
+type LightClientState struct {
+	Src string
+	Dst string
+
+	m *Metrics
+}
+
+// RegisterLightClient returns the LightClientState of the light client of src on dst.
+func (o *Observability) RegisterLightClient(src, dst string) *LightClientState {
+	return &LightClientState{m: o.Metrics, Src: src, Dst: dst}
+}
+
+// SetHeight updates the light client height and its lag behind head, the
+// latest block of the source chain.
+func (l *LightClientState) SetHeight(height, head int64) {
+	if l == nil {
+		return
+	}
+	l.m.LightClient.WithLabelValues(l.Src, l.Dst).Set(float64(height))
+	l.m.LightClientLag.WithLabelValues(l.Src, l.Dst).Set(float64(head - height))
+}
@@ -36,3 +36,58 @@ func noopOnce() *Observability {
 func RegisterChain(chain, role string) *ChainState {
 	return Default().RegisterChain(chain, role)
 }
+
+// RegisterAccount is shorthand for Default().RegisterAccount.
+func RegisterAccount(chain, address string) *AccountState {
+	return Default().RegisterAccount(chain, address)
+}
+
+// RegisterGas is shorthand for Default().RegisterGas.
+func RegisterGas(chain, strategy string) *GasState {
+	return Default().RegisterGas(chain, strategy)
+}
+
+// RegisterSpend is shorthand for Default().RegisterSpend.
+func RegisterSpend(chain string) *SpendState {
+	return Default().RegisterSpend(chain)
+}
+
+// RegisterHolding is shorthand for Default().RegisterHolding.
+func RegisterHolding(chain string) *HoldingState {
+	return Default().RegisterHolding(chain)
+}
+
+// RegisterReceiptCache is shorthand for Default().RegisterReceiptCache.
+func RegisterReceiptCache() *ReceiptCacheState {
+	return Default().RegisterReceiptCache()
+}
+
+// RegisterProof is shorthand for Default().RegisterProof.
+func RegisterProof() *ProofState {
+	return Default().RegisterProof()
+}
+
+// RegisterReceiptFetch is shorthand for Default().RegisterReceiptFetch.
+func RegisterReceiptFetch(endpoint string) *ReceiptFetchState {
+	return Default().RegisterReceiptFetch(endpoint)
+}
+
+// RegisterZkProof is shorthand for Default().RegisterZkProof.
+func RegisterZkProof() *ZkProofState {
+	return Default().RegisterZkProof()
+}
+
+// RegisterLightClient is shorthand for Default().RegisterLightClient.
+func RegisterLightClient(src, dst string) *LightClientState {
+	return Default().RegisterLightClient(src, dst)
+}
+
+// RegisterLanding is shorthand for Default().RegisterLanding.
+func RegisterLanding(chain string) *LandingState {
+	return Default().RegisterLanding(chain)
+}
+
+// RegisterEnergy is shorthand for Default().RegisterEnergy.
+func RegisterEnergy(chain string) *EnergyState {
+	return Default().RegisterEnergy(chain)
+}
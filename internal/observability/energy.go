@@ -0,0 +1,70 @@
+package observability
+
+// EnergyState publishes where the energy of a chain's transactions comes
+// from and what it costs.
+type EnergyState struct {
+	Chain string
+
+	m *Metrics
+}
+
+// RegisterEnergy returns the EnergyState of chain.
+func (o *Observability) RegisterEnergy(chain string) *EnergyState {
+	return &EnergyState{m: o.Metrics, Chain: chain}
+}
+
+// Acquired records that provider supplied energy.
+func (e *EnergyState) Acquired(provider string) {
+	if e == nil {
+		return
+	}
+	e.m.EnergyAcquires.WithLabelValues(e.Chain, provider, "acquired").Inc()
+}
+
+// Failed records that provider failed to supply energy.
+func (e *EnergyState) Failed(provider string) {
+	if e == nil {
+		return
+	}
+	e.m.EnergyAcquires.WithLabelValues(e.Chain, provider, "failed").Inc()
+}
+
+// Spent records tokens paid to provider, whole native tokens.
+func (e *EnergyState) Spent(provider string, tokens float64) {
+	if e == nil || tokens <= 0 {
+		return
+	}
+	e.m.EnergyCost.WithLabelValues(e.Chain, provider).Add(tokens)
+}
+
+// Relayed records what the energy of one relay cost, whole native tokens.
+func (e *EnergyState) Relayed(tokens float64) {
+	if e == nil {
+		return
+	}
+	e.m.RelayEnergyCost.WithLabelValues(e.Chain).Observe(tokens)
+}
+
+// Estimated records the energy a simulation says a call of method takes.
+func (e *EnergyState) Estimated(method string, energy int64) {
+	if e == nil {
+		return
+	}
+	e.m.EnergyEstimated.WithLabelValues(e.Chain, method).Observe(float64(energy))
+}
+
+// Used records the energy a confirmed call of method used.
+func (e *EnergyState) Used(method string, energy int64) {
+	if e == nil {
+		return
+	}
+	e.m.EnergyUsed.WithLabelValues(e.Chain, method).Observe(float64(energy))
+}
+
+// Price records the sun an energy unit burns.
+func (e *EnergyState) Price(sun int64) {
+	if e == nil {
+		return
+	}
+	e.m.EnergyPrice.WithLabelValues(e.Chain).Set(float64(sun))
+}
@@ -0,0 +1,35 @@
+package observability
+
+import "strconv"
+
+// LandingState publishes how long the transactions of a chain take to land
+// and how many broadcasts that takes.
+type LandingState struct {
+	Chain string
+
+	m *Metrics
+}
+
+// RegisterLanding returns the LandingState of chain.
+func (o *Observability) RegisterLanding(chain string) *LandingState {
+	return &LandingState{m: o.Metrics, Chain: chain}
+}
+
+// Landed records that attempt, counted from 1, landed seconds after it was
+// broadcast, and that the attempts before it were replaced.
+func (l *LandingState) Landed(attempt int, seconds float64) {
+	if l == nil {
+		return
+	}
+	l.m.TxLanding.WithLabelValues(l.Chain, strconv.Itoa(attempt)).Observe(seconds)
+	l.m.TxAttempts.WithLabelValues(l.Chain, "landed").Inc()
+	l.m.TxAttempts.WithLabelValues(l.Chain, "replaced").Add(float64(attempt - 1))
+}
+
+// Expired records that none of attempts landed before the blockhash expired.
+func (l *LandingState) Expired(attempts int) {
+	if l == nil {
+		return
+	}
+	l.m.TxAttempts.WithLabelValues(l.Chain, "expired").Add(float64(attempts))
+}
@@ -34,6 +34,31 @@ type Metrics struct {
 	ProcessLatency  *prometheus.HistogramVec // labels: chain, stage (e.g. filter,match,insert)
 	ErrorsTotal     *prometheus.CounterVec   // labels: chain, kind
 	InFlight        *prometheus.GaugeVec     // labels: scope (router, signer, etc.)
+	AccountBalance  *prometheus.GaugeVec     // labels: chain, address (whole native tokens)
+	RelaysLeft      *prometheus.GaugeVec     // labels: chain, address (-1 = unknown)
+	SendsPaused     *prometheus.GaugeVec     // labels: chain (1 = writer paused for funds)
+	GasPrice        *prometheus.GaugeVec     // labels: chain, strategy, kind (wei)
+	GasSpent        *prometheus.CounterVec   // labels: chain, type, source (whole native tokens)
+	TxsCharged      *prometheus.CounterVec   // labels: chain, type, source
+	BudgetExceeded  *prometheus.GaugeVec     // labels: chain (1 = non-critical sends held by the gas budget)
+	MessagesHeld    *prometheus.CounterVec   // labels: chain, type, category
+	ReceiptCache    *prometheus.CounterVec   // labels: chain, result (memory, disk, miss, proof, proof_miss)
+	ProofMismatch   *prometheus.CounterVec   // labels: chain
+	ReceiptFetches  *prometheus.CounterVec   // labels: endpoint (host), path (block, batch, single)
+	ZkProofs        *prometheus.CounterVec   // labels: chain, result (hit, fetch, prefetch, error)
+	LightClient     *prometheus.GaugeVec     // labels: src, dst
+	LightClientLag  *prometheus.GaugeVec     // labels: src, dst (source head - light client height)
+	TxLanding       *prometheus.HistogramVec // labels: chain, attempt
+	TxAttempts      *prometheus.CounterVec   // labels: chain, result (landed, replaced, expired)
+	EnergyAcquires  *prometheus.CounterVec   // labels: chain, provider, result (acquired, failed)
+	EnergyCost      *prometheus.CounterVec   // labels: chain, provider (whole native tokens)
+	RelayEnergyCost *prometheus.HistogramVec // labels: chain (whole native tokens per relay)
+	EnergyEstimated *prometheus.HistogramVec // labels: chain, method
+	EnergyUsed      *prometheus.HistogramVec // labels: chain, method
+	EnergyPrice     *prometheus.GaugeVec     // labels: chain (sun per energy unit)
+	FilterCursor    *prometheus.GaugeVec     // labels: chain, role (id of the last filter log done with)
+	FilterLogs      *prometheus.CounterVec   // labels: chain, role, result (processed, skipped, stale)
+	FilterGaps      *prometheus.CounterVec   // labels: chain, role (filter ids missing between logs)
 
 	reg *prometheus.Registry
 }
@@ -100,10 +125,144 @@ func newMetrics(namespace string) *Metrics {
 		Help: "Currently in-flight units of work (e.g. router messages).",
 	}, []string{"scope"})
 
+	m.AccountBalance = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "account", Name: "balance",
+		Help: "Native token balance of the relayer account, in whole tokens.",
+	}, []string{"chain", "address"})
+
+	m.RelaysLeft = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "account", Name: "relays_left",
+		Help: "Relays the balance can still pay for at the recent average cost. -1 until a cost is known.",
+	}, []string{"chain", "address"})
+
+	m.SendsPaused = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "account", Name: "sends_paused",
+		Help: "1 while the chain writer holds sends until the relayer account is refunded.",
+	}, []string{"chain"})
+
+	m.GasPrice = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "gas", Name: "price_wei",
+		Help: "Last gas price component chosen by the chain's gas strategy (gas_price, base_fee, tip, fee_cap).",
+	}, []string{"chain", "strategy", "kind"})
+
+	m.GasSpent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "gas", Name: "spent_total",
+		Help: "Native fees paid by confirmed transactions, in whole tokens.",
+	}, []string{"chain", "type", "source"})
+
+	m.TxsCharged = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "gas", Name: "txs_total",
+		Help: "Confirmed transactions whose fee was recorded in gas_spent_total.",
+	}, []string{"chain", "type", "source"})
+
+	m.BudgetExceeded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "gas", Name: "budget_exceeded",
+		Help: "1 while the chain's rolling gas budget is spent and non-critical sends are held.",
+	}, []string{"chain"})
+
+	m.MessagesHeld = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "writer", Name: "messages_held_total",
+		Help: "Messages parked in the holding area after their pre-flight simulation kept failing.",
+	}, []string{"chain", "type", "category"})
+
+	m.ReceiptCache = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "proof", Name: "receipt_cache_total",
+		Help: "Block receipt lookups by result: served from memory, from disk, or fetched from the RPC (miss).",
+	}, []string{"chain", "result"})
+
+	m.ProofMismatch = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "proof", Name: "receipt_mismatch_total",
+		Help: "Assembled receipt proofs that failed local verification against the receipts root and were not submitted.",
+	}, []string{"chain"})
+
+	m.ReceiptFetches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "rpc", Name: "receipt_fetches_total",
+		Help: "Receipt fetches by path: eth_getBlockReceipts (block), batched (batch) or one call per receipt (single).",
+	}, []string{"endpoint", "path"})
+
+	m.ZkProofs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "proof", Name: "zk_proofs_total",
+		Help: "ZK proof lookups by result: cached (hit), fetched while assembling (fetch), fetched ahead of the sync height (prefetch), or failed prover requests (error).",
+	}, []string{"chain", "result"})
+
+	m.LightClient = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "lightclient", Name: "height",
+		Help: "Height the light client of the src chain on the dst chain has synced to.",
+	}, []string{"src", "dst"})
+
+	m.LightClientLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "lightclient", Name: "lag",
+		Help: "Head of the src chain minus the height of its light client on the dst chain.",
+	}, []string{"src", "dst"})
+
+	m.TxLanding = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "writer", Name: "landing_seconds",
+		Help:    "Seconds from broadcasting a transaction attempt to it landing, by the attempt that landed (1 = first broadcast).",
+		Buckets: prometheus.ExponentialBuckets(0.25, 2, 10), // 250ms .. ~2m
+	}, []string{"chain", "attempt"})
+
+	m.TxAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "writer", Name: "tx_attempts_total",
+		Help: "Broadcast transaction attempts by outcome: landed, replaced by one with a higher fee, or expired with its blockhash.",
+	}, []string{"chain", "result"})
+
+	m.EnergyAcquires = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "energy", Name: "acquires_total",
+		Help: "Energy acquisitions by provider and outcome, a failed one falls back to the next provider.",
+	}, []string{"chain", "provider", "result"})
+
+	m.EnergyCost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "energy", Name: "cost",
+		Help: "Native tokens paid to energy providers, net of what lent energy refunds.",
+	}, []string{"chain", "provider"})
+
+	m.RelayEnergyCost = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "energy", Name: "relay_cost",
+		Help:    "Native tokens paid for the energy of one relay, over all of its attempts.",
+		Buckets: []float64{0, 1, 2, 5, 10, 20, 50, 100, 200, 500},
+	}, []string{"chain"})
+
+	m.EnergyEstimated = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "energy", Name: "estimated",
+		Help:    "Energy a transaction takes by the simulation run before sending it, by contract method.",
+		Buckets: prometheus.ExponentialBuckets(10_000, 2, 10), // 10k .. ~5M
+	}, []string{"chain", "method"})
+
+	m.EnergyUsed = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "energy", Name: "used",
+		Help:    "Energy a confirmed transaction used, by contract method.",
+		Buckets: prometheus.ExponentialBuckets(10_000, 2, 10),
+	}, []string{"chain", "method"})
+
+	m.EnergyPrice = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "energy", Name: "price",
+		Help: "Sun an energy unit burns, the fee limits of transactions are priced at.",
+	}, []string{"chain"})
+
+	m.FilterCursor = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "filter", Name: "cursor",
+		Help: "Id of the last log of the filter service this chain loop is done with.",
+	}, []string{"chain", "role"})
+
+	m.FilterLogs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "filter", Name: "logs_total",
+		Help: "Logs read from the filter service: processed, skipped for an address not watched, or stale, at or behind the cursor.",
+	}, []string{"chain", "role", "result"})
+
+	m.FilterGaps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "filter", Name: "gaps_total",
+		Help: "Ids the filter service skipped between the logs it returned.",
+	}, []string{"chain", "role"})
+
 	for _, c := range []prometheus.Collector{
 		m.CurrentBlock, m.LatestBlock, m.BlockLag, m.LastProgressTs,
 		m.BlocksProcessed, m.EventsMatched, m.RPCLatency, m.DBInsertLatency,
 		m.ProcessLatency, m.ErrorsTotal, m.InFlight,
+		m.AccountBalance, m.RelaysLeft, m.SendsPaused, m.GasPrice,
+		m.GasSpent, m.TxsCharged, m.BudgetExceeded, m.MessagesHeld, m.ReceiptCache,
+		m.ProofMismatch, m.ReceiptFetches, m.ZkProofs, m.LightClient, m.LightClientLag,
+		m.TxLanding, m.TxAttempts, m.EnergyAcquires, m.EnergyCost, m.RelayEnergyCost,
+		m.EnergyEstimated, m.EnergyUsed, m.EnergyPrice, m.FilterCursor, m.FilterLogs, m.FilterGaps,
 	} {
 		reg.MustRegister(c)
 	}
@@ -219,6 +378,30 @@ func (s *ChainState) RecordError(kind, msg string) {
 	s.m.ErrorsTotal.WithLabelValues(s.Chain, kind).Inc()
 }
 
+// SetFilterCursor records the id of the last filter log done with.
+func (s *ChainState) SetFilterCursor(id int64) {
+	if s == nil {
+		return
+	}
+	s.m.FilterCursor.WithLabelValues(s.Chain, s.Role).Set(float64(id))
+}
+
+// IncFilterLogs counts n filter logs by result.
+func (s *ChainState) IncFilterLogs(result string, n int) {
+	if s == nil || n == 0 {
+		return
+	}
+	s.m.FilterLogs.WithLabelValues(s.Chain, s.Role, result).Add(float64(n))
+}
+
+// IncFilterGaps counts ids missing between filter logs.
+func (s *ChainState) IncFilterGaps(n int64) {
+	if s == nil || n == 0 {
+		return
+	}
+	s.m.FilterGaps.WithLabelValues(s.Chain, s.Role).Add(float64(n))
+}
+
 // snapshot copies the read-side fields atomically for the /status renderer.
 func (s *ChainState) snapshot() chainSnapshot {
 	s.mu.RLock()
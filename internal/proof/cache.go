@@ -0,0 +1,295 @@
+package proof
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/groupcache/lru"
+	"github.com/golang/groupcache/singleflight"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+const (
+	DefaultReceiptCacheSize = 256
+	DefaultReceiptCacheTTL  = time.Hour
+)
+
+// CacheConfig limits a ReceiptCache. Dir enables the on-disk tier; leave it
+// empty to keep receipts in memory only.
+type CacheConfig struct {
+	Size int
+	TTL  time.Duration
+	Dir  string
+}
+
+// CacheReceipt is the process-wide receipt cache shared by the proffers, the
+// messengers and the expose service. Replace it with InitReceiptCache before
+// any chain starts.
+var CacheReceipt = NewReceiptCache(CacheConfig{})
+
+// CacheProof is the process-wide cache of what is built from the receipts of
+// a block, so retries and the other logs of the block don't build it again.
+var CacheProof = NewProofCache(CacheConfig{})
+
+// InitReceiptCache replaces CacheReceipt and CacheProof with caches limited by
+// cfg. Proofs are kept in memory only.
+func InitReceiptCache(cfg CacheConfig) {
+	CacheReceipt = NewReceiptCache(cfg)
+	CacheProof = NewProofCache(cfg)
+	if cfg.Dir != "" {
+		CacheReceipt.Prune()
+	}
+}
+
+type cacheEntry struct {
+	receipts []*types.Receipt
+	expire   time.Time
+}
+
+// ReceiptCache keeps the receipts of whole blocks keyed by chain id and block
+// hash, so every log of a block, and every retry after a restart when the disk
+// tier is on, reuses one fetch. Concurrent misses on one block share the fetch.
+type ReceiptCache struct {
+	ttl   time.Duration
+	dir   string
+	state *observability.ReceiptCacheState
+
+	mu     sync.Mutex
+	memory *lru.Cache
+	group  singleflight.Group
+}
+
+func NewReceiptCache(cfg CacheConfig) *ReceiptCache {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultReceiptCacheSize
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultReceiptCacheTTL
+	}
+	return &ReceiptCache{
+		ttl:    cfg.TTL,
+		dir:    cfg.Dir,
+		state:  observability.RegisterReceiptCache(),
+		memory: lru.New(cfg.Size),
+	}
+}
+
+// Receipts returns the receipts of block blockHash on chain chainId, calling
+// fetch on a miss. The returned slice has no spare capacity, so callers may
+// append to it without touching the cached copy.
+func (c *ReceiptCache) Receipts(chainId uint64, blockHash common.Hash,
+	fetch func() ([]*types.Receipt, error)) ([]*types.Receipt, error) {
+	chain := chainName(chainId)
+	key := cacheKey(chainId, blockHash)
+	if rs, ok := c.fromMemory(key); ok {
+		c.state.IncHit(chain, "memory")
+		return rs[:len(rs):len(rs)], nil
+	}
+
+	v, err := c.group.Do(key, func() (interface{}, error) {
+		if rs, ok := c.fromMemory(key); ok {
+			c.state.IncHit(chain, "memory")
+			return rs, nil
+		}
+		if rs, ok := c.fromDisk(chainId, blockHash); ok {
+			c.state.IncHit(chain, "disk")
+			c.toMemory(key, rs)
+			return rs, nil
+		}
+		c.state.IncMiss(chain)
+		rs, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+		c.toMemory(key, rs)
+		c.toDisk(chainId, blockHash, rs)
+		return rs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	rs := v.([]*types.Receipt)
+	return rs[:len(rs):len(rs)], nil
+}
+
+func (c *ReceiptCache) fromMemory(key string) ([]*types.Receipt, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.memory.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e := v.(*cacheEntry)
+	if time.Now().After(e.expire) {
+		c.memory.Remove(key)
+		return nil, false
+	}
+	return e.receipts, true
+}
+
+func (c *ReceiptCache) toMemory(key string, rs []*types.Receipt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory.Add(key, &cacheEntry{receipts: rs, expire: time.Now().Add(c.ttl)})
+}
+
+func (c *ReceiptCache) path(chainId uint64, blockHash common.Hash) string {
+	return filepath.Join(c.dir, strconv.FormatUint(chainId, 10), blockHash.Hex()+".json")
+}
+
+func (c *ReceiptCache) fromDisk(chainId uint64, blockHash common.Hash) ([]*types.Receipt, bool) {
+	if c.dir == "" {
+		return nil, false
+	}
+	p := c.path(chainId, blockHash)
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, false
+	}
+	if time.Since(fi.ModTime()) > c.ttl {
+		_ = os.Remove(p)
+		return nil, false
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, false
+	}
+	var rs []*types.Receipt
+	if err = json.Unmarshal(data, &rs); err != nil {
+		_ = os.Remove(p)
+		return nil, false
+	}
+	return rs, true
+}
+
+func (c *ReceiptCache) toDisk(chainId uint64, blockHash common.Hash, rs []*types.Receipt) {
+	if c.dir == "" {
+		return
+	}
+	data, err := json.Marshal(rs)
+	if err != nil {
+		return
+	}
+	p := c.path(chainId, blockHash)
+	if err = os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return
+	}
+	// write then rename so a crash never leaves a truncated entry behind
+	tmp := p + ".tmp"
+	if err = os.WriteFile(tmp, data, 0600); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, p)
+}
+
+// Prune removes the on-disk entries older than the cache TTL.
+func (c *ReceiptCache) Prune() {
+	if c.dir == "" {
+		return
+	}
+	_ = filepath.Walk(c.dir, func(p string, fi os.FileInfo, err error) error {
+		if err != nil || fi.IsDir() {
+			return nil
+		}
+		if time.Since(fi.ModTime()) > c.ttl {
+			_ = os.Remove(p)
+		}
+		return nil
+	})
+}
+
+type proofEntry struct {
+	proof  []byte
+	expire time.Time
+}
+
+// ProofCache keeps proofs built from the receipts of a block keyed by chain
+// id, block hash and the kind of proof. Concurrent misses share one build.
+type ProofCache struct {
+	ttl   time.Duration
+	state *observability.ReceiptCacheState
+
+	mu     sync.Mutex
+	memory *lru.Cache
+	group  singleflight.Group
+}
+
+func NewProofCache(cfg CacheConfig) *ProofCache {
+	if cfg.Size <= 0 {
+		cfg.Size = DefaultReceiptCacheSize
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultReceiptCacheTTL
+	}
+	return &ProofCache{
+		ttl:    cfg.TTL,
+		state:  observability.RegisterReceiptCache(),
+		memory: lru.New(cfg.Size),
+	}
+}
+
+// Proof returns the proof kind of block blockHash on chain chainId, calling
+// build on a miss. Callers must not modify the returned slice.
+func (c *ProofCache) Proof(chainId uint64, blockHash common.Hash, kind string,
+	build func() ([]byte, error)) ([]byte, error) {
+	chain := chainName(chainId)
+	key := cacheKey(chainId, blockHash) + "_" + kind
+	if p, ok := c.get(key); ok {
+		c.state.IncHit(chain, "proof")
+		return p, nil
+	}
+	v, err := c.group.Do(key, func() (interface{}, error) {
+		if p, ok := c.get(key); ok {
+			c.state.IncHit(chain, "proof")
+			return p, nil
+		}
+		c.state.IncHit(chain, "proof_miss")
+		p, err := build()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.memory.Add(key, &proofEntry{proof: p, expire: time.Now().Add(c.ttl)})
+		c.mu.Unlock()
+		return p, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+func (c *ProofCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.memory.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e := v.(*proofEntry)
+	if time.Now().After(e.expire) {
+		c.memory.Remove(key)
+		return nil, false
+	}
+	return e.proof, true
+}
+
+func cacheKey(chainId uint64, blockHash common.Hash) string {
+	return fmt.Sprintf("%d_%s", chainId, blockHash.Hex())
+}
+
+func chainName(id uint64) string {
+	if name, ok := mapprotocol.OnlineChaId[msg.ChainId(id)]; ok {
+		return name
+	}
+	return strconv.FormatUint(id, 10)
+}
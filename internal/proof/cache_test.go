@@ -0,0 +1,186 @@
+package proof
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func testReceipts() []*types.Receipt {
+	return []*types.Receipt{{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		Logs: []*types.Log{{
+			Address: common.HexToAddress("0x01"),
+			Topics:  []common.Hash{common.HexToHash("0x02")},
+			Data:    []byte{0x03},
+		}},
+	}}
+}
+
+func TestReceiptCacheMemory(t *testing.T) {
+	c := NewReceiptCache(CacheConfig{Size: 2})
+	var calls int32
+	fetch := func() ([]*types.Receipt, error) {
+		atomic.AddInt32(&calls, 1)
+		return testReceipts(), nil
+	}
+	hash := common.HexToHash("0xaa")
+	for i := 0; i < 3; i++ {
+		rs, err := c.Receipts(1, hash, fetch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rs) != 1 || cap(rs) != 1 {
+			t.Fatalf("len %d cap %d, want 1 1", len(rs), cap(rs))
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("fetched %d times, want 1", calls)
+	}
+
+	// the same block hash on another chain is another entry
+	if _, err := c.Receipts(2, hash, fetch); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("fetched %d times, want 2", calls)
+	}
+
+	// appending to a result must not grow the cached copy
+	rs, _ := c.Receipts(1, hash, fetch)
+	_ = append(rs, &types.Receipt{})
+	if rs, _ = c.Receipts(1, hash, fetch); len(rs) != 1 {
+		t.Fatalf("cached receipts modified, len %d", len(rs))
+	}
+}
+
+func TestReceiptCacheErrorNotCached(t *testing.T) {
+	c := NewReceiptCache(CacheConfig{})
+	hash := common.HexToHash("0xbb")
+	if _, err := c.Receipts(1, hash, func() ([]*types.Receipt, error) {
+		return nil, errors.New("rpc down")
+	}); err == nil {
+		t.Fatal("want error")
+	}
+	rs, err := c.Receipts(1, hash, func() ([]*types.Receipt, error) { return testReceipts(), nil })
+	if err != nil || len(rs) != 1 {
+		t.Fatalf("got %v %v", rs, err)
+	}
+}
+
+func TestReceiptCacheTTL(t *testing.T) {
+	c := NewReceiptCache(CacheConfig{TTL: time.Millisecond})
+	var calls int32
+	fetch := func() ([]*types.Receipt, error) {
+		atomic.AddInt32(&calls, 1)
+		return testReceipts(), nil
+	}
+	hash := common.HexToHash("0xcc")
+	_, _ = c.Receipts(1, hash, fetch)
+	time.Sleep(5 * time.Millisecond)
+	_, _ = c.Receipts(1, hash, fetch)
+	if calls != 2 {
+		t.Fatalf("fetched %d times, want 2", calls)
+	}
+}
+
+func TestReceiptCacheConcurrentMiss(t *testing.T) {
+	c := NewReceiptCache(CacheConfig{})
+	var calls int32
+	release := make(chan struct{})
+	fetch := func() ([]*types.Receipt, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return testReceipts(), nil
+	}
+	hash := common.HexToHash("0xdd")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Receipts(1, hash, fetch)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("fetched %d times, want 1", calls)
+	}
+}
+
+func TestReceiptCacheDisk(t *testing.T) {
+	dir := t.TempDir()
+	hash := common.HexToHash("0xee")
+	c := NewReceiptCache(CacheConfig{Dir: dir})
+	if _, err := c.Receipts(7, hash, func() ([]*types.Receipt, error) { return testReceipts(), nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "7", hash.Hex()+".json")); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new cache, as after a restart, is served from disk
+	restarted := NewReceiptCache(CacheConfig{Dir: dir})
+	rs, err := restarted.Receipts(7, hash, func() ([]*types.Receipt, error) {
+		return nil, errors.New("should not fetch")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := testReceipts()[0]
+	if len(rs) != 1 || rs[0].CumulativeGasUsed != want.CumulativeGasUsed || len(rs[0].Logs) != 1 ||
+		rs[0].Logs[0].Address != want.Logs[0].Address {
+		t.Fatalf("got %+v", rs)
+	}
+
+	// expired entries are pruned
+	old := time.Now().Add(-2 * DefaultReceiptCacheTTL)
+	p := filepath.Join(dir, "7", hash.Hex()+".json")
+	if err = os.Chtimes(p, old, old); err != nil {
+		t.Fatal(err)
+	}
+	restarted.Prune()
+	if _, err = os.Stat(p); !os.IsNotExist(err) {
+		t.Fatalf("expired entry kept: %v", err)
+	}
+}
+
+func TestProofCache(t *testing.T) {
+	c := NewProofCache(CacheConfig{Size: 2})
+	var calls int32
+	build := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		return []byte{0x01}, nil
+	}
+	hash := common.HexToHash("0xaa")
+	for i := 0; i < 3; i++ {
+		p, err := c.Proof(1, hash, "mptReceipt", build)
+		if err != nil || len(p) != 1 {
+			t.Fatalf("proof %x, err %v", p, err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("built %d times, want 1", calls)
+	}
+	// another kind of proof of the block is another entry
+	if _, err := c.Proof(1, hash, "other", build); err != nil || calls != 2 {
+		t.Fatalf("built %d times, err %v", calls, err)
+	}
+	// failed builds aren't cached
+	if _, err := c.Proof(2, hash, "mptReceipt", func() ([]byte, error) { return nil, errors.New("down") }); err == nil {
+		t.Fatal("expected the build error")
+	}
+	if _, err := c.Proof(2, hash, "mptReceipt", build); err != nil || calls != 3 {
+		t.Fatalf("built %d times, err %v", calls, err)
+	}
+}
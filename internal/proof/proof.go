@@ -5,8 +5,6 @@ import (
 	"math/big"
 	"sync"
 
-	"github.com/golang/groupcache/lru"
-
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -23,10 +21,6 @@ import (
 	"github.com/pkg/errors"
 )
 
-var (
-	CacheReceipt = lru.New(30)
-)
-
 type ReceiptRLP struct {
 	PostStateOrStatus []byte
 	CumulativeGasUsed uint64
@@ -84,11 +78,7 @@ func Get(receipts DerivableList, txIndex uint) ([][]byte, error) {
 
 	tr = DeriveTire(receipts, tr)
 	ns := light.NewNodeSet()
-	key, err := rlp.EncodeToBytes(txIndex)
-	if err != nil {
-		return nil, err
-	}
-	if err = tr.Prove(key, 0, ns); err != nil {
+	if err = tr.Prove(Key(receipts, txIndex), 0, ns); err != nil {
 		return nil, err
 	}
 
@@ -104,6 +94,13 @@ func DeriveTire(rs DerivableList, tr *trie.Trie) *trie.Trie {
 	valueBuf := encodeBufferPool.Get().(*bytes.Buffer)
 	defer encodeBufferPool.Put(valueBuf)
 
+	if kl, ok := rs.(KeyedList); ok {
+		for i := 0; i < kl.Len(); i++ {
+			tr.Update(kl.Key(uint(i)), encodeForDerive(kl, i, valueBuf))
+		}
+		return tr
+	}
+
 	var indexBuf []byte
 	for i := 1; i < rs.Len() && i <= 0x7f; i++ {
 		indexBuf = rlp.AppendUint64(indexBuf[:0], uint64(i))
@@ -128,6 +125,41 @@ type DerivableList interface {
 	EncodeIndex(int, *bytes.Buffer)
 }
 
+// KeyFunc returns the trie key the receipt at txIndex is stored under.
+type KeyFunc func(txIndex uint) []byte
+
+// IndexKey is the key Ethereum and its L2s use, rlp(txIndex).
+func IndexKey(txIndex uint) []byte {
+	return rlp.AppendUint64(nil, uint64(txIndex))
+}
+
+// KeyedList is a DerivableList whose receipts are stored under their own keys
+// instead of IndexKey.
+type KeyedList interface {
+	DerivableList
+	Key(txIndex uint) []byte
+}
+
+type keyedList struct {
+	DerivableList
+	key KeyFunc
+}
+
+func (l keyedList) Key(txIndex uint) []byte { return l.key(txIndex) }
+
+// WithKey stores the receipts of rs under key.
+func WithKey(rs DerivableList, key KeyFunc) KeyedList {
+	return keyedList{DerivableList: rs, key: key}
+}
+
+// Key returns the trie key of the receipt at txIndex in rs.
+func Key(rs DerivableList, txIndex uint) []byte {
+	if kl, ok := rs.(KeyedList); ok {
+		return kl.Key(txIndex)
+	}
+	return IndexKey(txIndex)
+}
+
 func encodeForDerive(list DerivableList, i int, buf *bytes.Buffer) []byte {
 	buf.Reset()
 	list.EncodeIndex(i, buf)
@@ -0,0 +1,88 @@
+package proof
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+// ErrReceiptProofMismatch is matched by every MismatchError.
+var ErrReceiptProofMismatch = errors.New("receipt proof does not match receipts root")
+
+// MismatchError reports a receipt proof that the light client would reject.
+// Derived is the root of the trie compass built from the receipts, which
+// differs from Root when the receipt encoding is wrong.
+type MismatchError struct {
+	Chain   msg.ChainId
+	TxIndex uint
+	Root    common.Hash
+	Derived common.Hash
+	Reason  string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("%s: chain %d txIndex %d root %s derived %s: %s", ErrReceiptProofMismatch, e.Chain, e.TxIndex,
+		e.Root.Hex(), e.Derived.Hex(), e.Reason)
+}
+
+func (e *MismatchError) Is(target error) bool {
+	return target == ErrReceiptProofMismatch
+}
+
+// Verify checks prf the way the light client does: walking it from root along
+// the key of txIndex in rs must end at the encoding rs produces for txIndex. It
+// returns a *MismatchError, and counts it, when it doesn't.
+func Verify(fId msg.ChainId, root common.Hash, rs DerivableList, txIndex uint, prf [][]byte) error {
+	reason := ""
+	if int(txIndex) >= rs.Len() {
+		reason = fmt.Sprintf("txIndex out of range of %d receipts", rs.Len())
+	} else {
+		db := memorydb.New()
+		for _, node := range prf {
+			if err := db.Put(crypto.Keccak256(node), node); err != nil {
+				return err
+			}
+		}
+		value, err := trie.VerifyProof(root, Key(rs, txIndex), db)
+		switch {
+		case err != nil:
+			reason = err.Error()
+		case value == nil:
+			reason = "key not in trie"
+		case !bytes.Equal(value, encodeForDerive(rs, int(txIndex), new(bytes.Buffer))):
+			reason = "proven value differs from the encoded receipt"
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	observability.RegisterProof().IncMismatch(chainName(uint64(fId)))
+	return &MismatchError{
+		Chain:   fId,
+		TxIndex: txIndex,
+		Root:    root,
+		Derived: DeriveTire(rs, tr).Hash(),
+		Reason:  reason,
+	}
+}
+
+// GetVerified is Get followed by Verify against root.
+func GetVerified(fId msg.ChainId, root common.Hash, rs DerivableList, txIndex uint) ([][]byte, error) {
+	prf, err := Get(rs, txIndex)
+	if err != nil {
+		return nil, err
+	}
+	if err = Verify(fId, root, rs, txIndex, prf); err != nil {
+		return nil, err
+	}
+	return prf, nil
+}
@@ -0,0 +1,85 @@
+package proof
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+func blockReceipts(n int) types.Receipts {
+	rs := make(types.Receipts, 0, n)
+	for i := 0; i < n; i++ {
+		rs = append(rs, &types.Receipt{
+			Type:              types.DynamicFeeTxType,
+			Status:            types.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs: []*types.Log{{
+				Address: common.BigToAddress(common.Big1),
+				Topics:  []common.Hash{common.BigToHash(common.Big2)},
+				Data:    []byte{byte(i)},
+			}},
+		})
+	}
+	return rs
+}
+
+func rootOf(rs DerivableList) common.Hash {
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	return DeriveTire(rs, tr).Hash()
+}
+
+// legacyOnly drops the type prefix, like an encoder that doesn't know a tx type.
+type legacyOnly struct{ types.Receipts }
+
+func (l legacyOnly) EncodeIndex(i int, w *bytes.Buffer) {
+	r := *l.Receipts[i]
+	r.Type = types.LegacyTxType
+	types.Receipts{&r}.EncodeIndex(0, w)
+}
+
+func TestVerify(t *testing.T) {
+	rs := blockReceipts(130) // crosses the 0x7f key boundary of DeriveTire
+	root := rootOf(rs)
+	if root != types.DeriveSha(rs, trie.NewStackTrie(nil)) {
+		t.Fatal("DeriveTire disagrees with go-ethereum")
+	}
+	for _, idx := range []uint{0, 1, 0x7f, 0x80, 129} {
+		if _, err := GetVerified(1, root, rs, idx); err != nil {
+			t.Fatalf("txIndex %d: %v", idx, err)
+		}
+	}
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	rs := blockReceipts(3)
+	root := rootOf(rs)
+
+	_, err := GetVerified(1, common.HexToHash("0x01"), rs, 1)
+	if !errors.Is(err, ErrReceiptProofMismatch) {
+		t.Fatalf("wrong root: %v", err)
+	}
+	var me *MismatchError
+	if !errors.As(err, &me) || me.Derived != root || me.TxIndex != 1 {
+		t.Fatalf("got %+v", me)
+	}
+
+	// a wrong encoder derives another root than the header's
+	if _, err = GetVerified(1, root, legacyOnly{rs}, 1); !errors.Is(err, ErrReceiptProofMismatch) {
+		t.Fatalf("wrong encoding: %v", err)
+	}
+
+	// a proof of another index
+	prf, _ := Get(rs, 2)
+	if err = Verify(1, root, rs, 1, prf); !errors.Is(err, ErrReceiptProofMismatch) {
+		t.Fatalf("wrong index: %v", err)
+	}
+
+	if err = Verify(1, root, rs, 3, prf); !errors.Is(err, ErrReceiptProofMismatch) {
+		t.Fatalf("out of range: %v", err)
+	}
+}
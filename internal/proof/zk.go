@@ -0,0 +1,272 @@
+package proof
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/groupcache/lru"
+	"github.com/golang/groupcache/singleflight"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/msg"
+	"github.com/mapprotocol/compass/pkg/util"
+)
+
+const (
+	DefaultZkCacheSize = 1024
+	// ZkMock as the prover url selects MockZkProver.
+	ZkMock = "mock"
+
+	zkPrefetchConcurrency = 4
+	zkPrefetchRetry       = 10 * time.Second // before asking again for a proof that wasn't ready
+)
+
+var ErrNoZkProver = errors.New("no zk prover configured")
+
+// ZkProofProvider returns the zk proof of the header at height of chain cid,
+// as the words the light client takes.
+type ZkProofProvider interface {
+	ZkProof(ctx context.Context, cid msg.ChainId, height uint64) ([]*big.Int, error)
+}
+
+// ZkPrefetcher is implemented by providers that can fetch proofs before
+// they're asked for.
+type ZkPrefetcher interface {
+	// Prefetch starts fetching the proofs of the heights from height on that
+	// aren't known yet, without waiting for them.
+	Prefetch(cid msg.ChainId, height uint64)
+}
+
+// ZkConfig configures the zk prover. Ahead and Wait only apply to provers
+// reached over http.
+type ZkConfig struct {
+	Url       string        // prover endpoint, or ZkMock
+	Ahead     int           // heights from the synced one on to prefetch, 0 disables prefetching
+	CacheSize int           // proofs kept in memory
+	Wait      time.Duration // how long to wait for a proof that isn't ready, 0 waits until it is
+}
+
+// ZkProver is the process-wide zk prover. Replace it with InitZkProver before
+// any chain starts.
+var ZkProver ZkProofProvider = noZkProver{}
+
+// InitZkProver replaces ZkProver with the prover cfg selects.
+func InitZkProver(cfg ZkConfig) {
+	switch cfg.Url {
+	case "":
+		ZkProver = noZkProver{}
+	case ZkMock:
+		ZkProver = MockZkProver{}
+	default:
+		ZkProver = NewHttpZkProver(cfg)
+	}
+}
+
+// PrefetchZk lets ZkProver fetch the proofs from height on, when it prefetches.
+func PrefetchZk(cid msg.ChainId, height uint64) {
+	if p, ok := ZkProver.(ZkPrefetcher); ok {
+		p.Prefetch(cid, height)
+	}
+}
+
+type noZkProver struct{}
+
+func (noZkProver) ZkProof(context.Context, msg.ChainId, uint64) ([]*big.Int, error) {
+	return nil, ErrNoZkProver
+}
+
+// HttpZkProver asks the zk prover service for proofs and keeps the finished
+// ones, which never change. Concurrent requests for one height share a fetch.
+type HttpZkProver struct {
+	endpoint string
+	client   *http.Client
+	ahead    int
+	wait     time.Duration
+	notReady time.Duration // between asks for a proof that isn't ready
+	state    *observability.ZkProofState
+	sem      chan struct{}
+
+	mu     sync.Mutex
+	proofs *lru.Cache
+	tried  map[string]time.Time // prefetches in flight or not ready, by key
+	group  singleflight.Group
+}
+
+func NewHttpZkProver(cfg ZkConfig) *HttpZkProver {
+	if cfg.CacheSize <= 0 {
+		cfg.CacheSize = DefaultZkCacheSize
+	}
+	return &HttpZkProver{
+		endpoint: cfg.Url,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		ahead:    cfg.Ahead,
+		wait:     cfg.Wait,
+		notReady: constant.BalanceRetryInterval,
+		state:    observability.RegisterZkProof(),
+		sem:      make(chan struct{}, zkPrefetchConcurrency),
+		proofs:   lru.New(cfg.CacheSize),
+		tried:    make(map[string]time.Time),
+	}
+}
+
+// ZkProof returns the cached proof, or waits for the prover to finish it.
+func (p *HttpZkProver) ZkProof(ctx context.Context, cid msg.ChainId, height uint64) ([]*big.Int, error) {
+	key := zkKey(cid, height)
+	name := chainName(uint64(cid))
+	if prf, ok := p.cached(key); ok {
+		p.state.Inc(name, "hit")
+		return prf, nil
+	}
+	if p.wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.wait)
+		defer cancel()
+	}
+	v, err := p.group.Do(key, func() (interface{}, error) {
+		if prf, ok := p.cached(key); ok {
+			return prf, nil
+		}
+		for {
+			prf, err := p.fetch(cid, height)
+			interval := constant.BlockRetryInterval
+			switch {
+			case err != nil:
+				p.state.Inc(name, "error")
+				util.Alarm(context.Background(), fmt.Sprintf("GetZkProof cid(%d) height(%d) failed, err is %v", cid, height, err))
+				log.Error("GetZkProof failed", "err", err, "height", height, "cid", cid)
+			case prf == nil:
+				log.Info("GetZkProof Proof Not Ready", "cid", cid, "height", height)
+				interval = p.notReady
+			default:
+				p.state.Inc(name, "fetch")
+				p.store(key, prf)
+				return prf, nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("zk proof of chain %d height %d: %w", cid, height, ctx.Err())
+			case <-time.After(interval):
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*big.Int), nil
+}
+
+// Prefetch fetches, in the background, the proofs of the Ahead heights from
+// height on that aren't cached, asking again for unfinished ones after a while.
+func (p *HttpZkProver) Prefetch(cid msg.ChainId, height uint64) {
+	name := chainName(uint64(cid))
+	for h := height; h < height+uint64(p.ahead); h++ {
+		key := zkKey(cid, h)
+		p.mu.Lock()
+		_, done := p.proofs.Get(key)
+		last, tried := p.tried[key]
+		skip := done || (tried && time.Since(last) < zkPrefetchRetry)
+		if !skip {
+			p.tried[key] = time.Now()
+		}
+		p.mu.Unlock()
+		if skip {
+			continue
+		}
+
+		go func(h uint64, key string) {
+			p.sem <- struct{}{}
+			defer func() { <-p.sem }()
+			prf, err := p.fetch(cid, h)
+			if err != nil {
+				p.state.Inc(name, "error")
+				log.Debug("Prefetch zk proof failed", "cid", cid, "height", h, "err", err)
+				return
+			}
+			if prf != nil {
+				p.state.Inc(name, "prefetch")
+				p.store(key, prf)
+			}
+		}(h, key)
+	}
+	// forget unfinished heights the sync has left behind
+	p.mu.Lock()
+	for key, last := range p.tried {
+		if time.Since(last) > p.notReady {
+			delete(p.tried, key)
+		}
+	}
+	p.mu.Unlock()
+}
+
+// fetch asks the prover once. A nil proof without error means it's not ready.
+func (p *HttpZkProver) fetch(cid msg.ChainId, height uint64) ([]*big.Int, error) {
+	resp, err := p.client.Get(fmt.Sprintf("%s/proof?chain_id=%d&height=%d", p.endpoint, cid, height))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	zk := &mapprotocol.Zk{}
+	if err = json.Unmarshal(body, zk); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", body, err)
+	}
+	if !zk.Ready() {
+		return nil, nil
+	}
+	return zk.Words(), nil
+}
+
+func (p *HttpZkProver) cached(key string) ([]*big.Int, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	v, ok := p.proofs.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return v.([]*big.Int), true
+}
+
+func (p *HttpZkProver) store(key string, prf []*big.Int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.proofs.Add(key, prf)
+	delete(p.tried, key)
+}
+
+func zkKey(cid msg.ChainId, height uint64) string {
+	return strconv.FormatUint(uint64(cid), 10) + "/" + strconv.FormatUint(height, 10)
+}
+
+// bn254P is the modulus of the field the proof coordinates live in.
+var bn254P, _ = new(big.Int).SetString("21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+
+// MockZkProver derives a proof from the chain and height alone, so zk proof
+// types can be assembled and compared offline. Light clients reject them.
+type MockZkProver struct{}
+
+func (MockZkProver) ZkProof(_ context.Context, cid msg.ChainId, height uint64) ([]*big.Int, error) {
+	seed := make([]byte, 17)
+	binary.BigEndian.PutUint64(seed, uint64(cid))
+	binary.BigEndian.PutUint64(seed[8:], height)
+	ret := make([]*big.Int, 0, 8)
+	for i := 0; i < 8; i++ {
+		seed[16] = byte(i)
+		ret = append(ret, new(big.Int).Mod(new(big.Int).SetBytes(crypto.Keccak256(seed)), bn254P))
+	}
+	return ret, nil
+}
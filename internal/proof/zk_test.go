@@ -0,0 +1,168 @@
+package proof
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+// zkServer answers like the prover service. A height is ready after notReady
+// asks for it, and every answer is delayed by delay.
+type zkServer struct {
+	notReady int
+	delay    time.Duration
+
+	mu   sync.Mutex
+	asks map[uint64]int
+}
+
+func (s *zkServer) count(height uint64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.asks[height]
+}
+
+func newZkServer(t *testing.T, s *zkServer) string {
+	t.Helper()
+	s.asks = make(map[uint64]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		height, _ := strconv.ParseUint(r.URL.Query().Get("height"), 10, 64)
+		s.mu.Lock()
+		s.asks[height]++
+		ready := s.asks[height] > s.notReady
+		s.mu.Unlock()
+		time.Sleep(s.delay)
+		if !ready {
+			_, _ = fmt.Fprintf(w, `{"code":200,"data":{"height":"%d","status":1}}`, height)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"code":200,"data":{"height":"%d","status":3,"result":{"proof":{`+
+			`"pi_a":["%d1","12","1"],"pi_b":[["13","14"],["15","16"],["1","0"]],"pi_c":["17","18","1"],"protocol":"groth16"}}}}`,
+			height, height)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestHttpZkProverDedup(t *testing.T) {
+	s := &zkServer{delay: 50 * time.Millisecond}
+	p := NewHttpZkProver(ZkConfig{Url: newZkServer(t, s)})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prf, err := p.ZkProof(context.Background(), 22776, 7)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(prf) != 8 || prf[0].Uint64() != 71 || prf[7].Uint64() != 18 {
+				t.Errorf("got %v", prf)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, err := p.ZkProof(context.Background(), 22776, 7); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.count(7); n != 1 {
+		t.Fatalf("prover asked %d times, want 1", n)
+	}
+}
+
+func TestHttpZkProverWaitsUntilReady(t *testing.T) {
+	s := &zkServer{notReady: 2}
+	p := NewHttpZkProver(ZkConfig{Url: newZkServer(t, s)})
+	p.notReady = 10 * time.Millisecond
+	if _, err := p.ZkProof(context.Background(), 22776, 3); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.count(3); n != 3 {
+		t.Fatalf("prover asked %d times, want 3", n)
+	}
+
+	// a bounded wait gives up on a proof that stays unfinished
+	s.notReady = 1000
+	p = NewHttpZkProver(ZkConfig{Url: newZkServer(t, s), Wait: 50 * time.Millisecond})
+	p.notReady = 10 * time.Millisecond
+	if _, err := p.ZkProof(context.Background(), 22776, 4); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want a deadline error", err)
+	}
+}
+
+func TestHttpZkProverPrefetch(t *testing.T) {
+	s := &zkServer{}
+	p := NewHttpZkProver(ZkConfig{Url: newZkServer(t, s), Ahead: 3})
+	p.Prefetch(22776, 10)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := p.cached(zkKey(22776, 12)); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("height 12 not prefetched")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for h := uint64(10); h < 13; h++ {
+		for {
+			if _, ok := p.cached(zkKey(22776, h)); ok {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		if _, err := p.ZkProof(context.Background(), 22776, h); err != nil {
+			t.Fatal(err)
+		}
+		if n := s.count(h); n != 1 {
+			t.Fatalf("height %d asked %d times, want 1", h, n)
+		}
+	}
+	if n := s.count(13); n != 0 {
+		t.Fatalf("height 13 is past the prefetch window but was asked %d times", n)
+	}
+
+	// cached heights aren't asked for again
+	p.Prefetch(22776, 10)
+	time.Sleep(20 * time.Millisecond)
+	if n := s.count(10); n != 1 {
+		t.Fatalf("height 10 asked %d times after a second prefetch", n)
+	}
+}
+
+func TestMockZkProver(t *testing.T) {
+	a, _ := MockZkProver{}.ZkProof(context.Background(), 22776, 100)
+	b, _ := MockZkProver{}.ZkProof(context.Background(), 22776, 100)
+	c, _ := MockZkProver{}.ZkProof(context.Background(), 22776, 101)
+	d, _ := MockZkProver{}.ZkProof(context.Background(), msg.ChainId(1), 100)
+	if len(a) != 8 {
+		t.Fatalf("got %d words, want 8", len(a))
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			t.Fatal("mock proof is not deterministic")
+		}
+		if a[i].Cmp(bn254P) >= 0 {
+			t.Fatalf("word %d outside the field", i)
+		}
+	}
+	if a[0].Cmp(c[0]) == 0 || a[0].Cmp(d[0]) == 0 {
+		t.Fatal("mock proof ignores the chain or height")
+	}
+}
+
+func TestNoZkProver(t *testing.T) {
+	if _, err := (noZkProver{}).ZkProof(context.Background(), 22776, 1); !errors.Is(err, ErrNoZkProver) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package receipt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/compass/internal/arb"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/op"
+	"github.com/mapprotocol/compass/internal/proof"
+)
+
+// Family names.
+const (
+	Ethereum = "ethereum" // typed receipts up to set-code, also used by bsc, map, tron and conflux
+	Optimism = "optimism" // OP stack, deposit receipts carry a nonce and version after canyon
+	Arbitrum = "arbitrum" // arbitrum legacy receipts are encoded untyped
+	Matic    = "matic"    // polygon pos, receipts of unknown types are left out
+)
+
+// Receipts encodes typed receipts the way go-ethereum does, including the
+// blob and set-code types the go-ethereum version compass builds with lacks.
+type Receipts []*types.Receipt
+
+func (rs Receipts) Len() int { return len(rs) }
+
+func (rs Receipts) EncodeIndex(i int, w *bytes.Buffer) {
+	r := rs[i]
+	data := &proof.ReceiptRLP{PostStateOrStatus: statusEncoding(r), CumulativeGasUsed: r.CumulativeGasUsed, Bloom: r.Bloom, Logs: r.Logs}
+	if r.Type == constant.LegacyTxType {
+		rlp.Encode(w, data)
+		return
+	}
+	w.WriteByte(r.Type)
+	switch r.Type {
+	case constant.AccessListTxType, constant.DynamicFeeTxType, constant.BlobTxType, constant.SetCodeTxType:
+		rlp.Encode(w, data)
+	default:
+	}
+}
+
+// maticReceipts leaves receipts of unknown types out entirely, where Receipts
+// still writes their type byte.
+type maticReceipts []*types.Receipt
+
+func (rs maticReceipts) Len() int { return len(rs) }
+
+func (rs maticReceipts) EncodeIndex(i int, w *bytes.Buffer) {
+	r := rs[i]
+	data := &proof.ReceiptRLP{PostStateOrStatus: statusEncoding(r), CumulativeGasUsed: r.CumulativeGasUsed, Bloom: r.Bloom, Logs: r.Logs}
+	switch r.Type {
+	case constant.LegacyTxType:
+		rlp.Encode(w, data)
+	case constant.AccessListTxType, constant.DynamicFeeTxType, constant.BlobTxType, constant.SetCodeTxType:
+		w.WriteByte(r.Type)
+		rlp.Encode(w, data)
+	default:
+	}
+}
+
+func statusEncoding(r *types.Receipt) []byte {
+	if len(r.PostState) == 0 {
+		if r.Status == constant.ReceiptStatusFailed {
+			return constant.ReceiptStatusFailedRLP
+		}
+		return constant.ReceiptStatusSuccessfulRLP
+	}
+	return r.PostState
+}
+
+func encodeEthereum(_ Source, receipts []*types.Receipt) (proof.DerivableList, error) {
+	return Receipts(receipts), nil
+}
+
+func encodeMatic(_ Source, receipts []*types.Receipt) (proof.DerivableList, error) {
+	return maticReceipts(receipts), nil
+}
+
+func encodeArbitrum(_ Source, receipts []*types.Receipt) (proof.DerivableList, error) {
+	ret := make(arb.Receipts, 0, len(receipts))
+	for _, r := range receipts {
+		ret = append(ret, &arb.Receipt{Receipt: r})
+	}
+	return ret, nil
+}
+
+// encodeOptimism asks src for the deposit nonce and receipt version of every
+// deposit receipt, which go-ethereum drops when decoding.
+func encodeOptimism(src Source, receipts []*types.Receipt) (proof.DerivableList, error) {
+	ret := make(op.Receipts, 0, len(receipts))
+	for _, r := range receipts {
+		or := &op.Receipt{Receipt: r}
+		if r.Type == op.DepositTxType {
+			if src == nil {
+				return nil, fmt.Errorf("deposit receipt %s needs a source for its deposit fields", r.TxHash)
+			}
+			fields, err := src.OpReceipt(context.Background(), r.TxHash)
+			if err != nil {
+				return nil, fmt.Errorf("get deposit fields of %s: %w", r.TxHash, err)
+			}
+			if fields != nil {
+				if or.DepositNonce, err = hexUint64(fields.DepositNonce); err != nil {
+					return nil, fmt.Errorf("depositNonce of %s: %w", r.TxHash, err)
+				}
+				if or.DepositReceiptVersion, err = hexUint64(fields.DepositReceiptVersion); err != nil {
+					return nil, fmt.Errorf("depositReceiptVersion of %s: %w", r.TxHash, err)
+				}
+			}
+		}
+		ret = append(ret, or)
+	}
+	return ret, nil
+}
+
+// hexUint64 parses a quantity, leaving absent fields nil so receipts from
+// before canyon keep their shorter encoding.
+func hexUint64(s string) (*uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "0x"), 16, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
@@ -0,0 +1,142 @@
+// Package receipt decides how the receipts of a chain are encoded into its
+// receipts trie. Chains are bound to a family, either by chain id or by the
+// chain type they run under, so an L2 that reuses an existing encoding only
+// needs a receiptEncoding option in its chain config.
+package receipt
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mapprotocol/compass/internal/constant"
+	"github.com/mapprotocol/compass/internal/proof"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+// Source is queried by encoders for receipt fields go-ethereum doesn't decode.
+// *ethclient.Client is one.
+type Source interface {
+	OpReceipt(ctx context.Context, txHash common.Hash) (*ethclient.OpReceipt, error)
+}
+
+// Encoder wraps the receipts of a block, in block order, in the list their
+// trie is derived from.
+type Encoder func(src Source, receipts []*types.Receipt) (proof.DerivableList, error)
+
+// Family is one way of building a receipts trie.
+type Family struct {
+	Name   string
+	Encode Encoder
+	Key    proof.KeyFunc // nil stores receipts under proof.IndexKey
+}
+
+// List encodes receipts with f.
+func (f *Family) List(src Source, receipts []*types.Receipt) (proof.DerivableList, error) {
+	dls, err := f.Encode(src, receipts)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s receipts: %w", f.Name, err)
+	}
+	if f.Key != nil {
+		dls = proof.WithKey(dls, f.Key)
+	}
+	return dls, nil
+}
+
+var registry = struct {
+	mu       sync.RWMutex
+	families map[string]*Family
+	chains   map[msg.ChainId]string
+}{
+	families: make(map[string]*Family),
+	chains:   make(map[msg.ChainId]string),
+}
+
+func init() {
+	for _, f := range []*Family{
+		{Name: Ethereum, Encode: encodeEthereum},
+		{Name: Optimism, Encode: encodeOptimism},
+		{Name: Arbitrum, Encode: encodeArbitrum},
+		{Name: Matic, Encode: encodeMatic},
+	} {
+		if err := Register(f); err != nil {
+			panic(err)
+		}
+	}
+	for id, family := range map[msg.ChainId]string{
+		constant.ArbChainId:      Arbitrum,
+		constant.Robinhood:       Arbitrum,
+		constant.BaseChainId:     Optimism,
+		constant.OptimismChainId: Optimism,
+		constant.MaticChainId:    Matic,
+	} {
+		if err := Bind(id, family); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register adds a family. Names are unique.
+func Register(f *Family) error {
+	if f == nil || f.Name == "" || f.Encode == nil {
+		return fmt.Errorf("receipt family needs a name and an encoder")
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.families[f.Name]; ok {
+		return fmt.Errorf("receipt family %s already registered", f.Name)
+	}
+	registry.families[f.Name] = f
+	return nil
+}
+
+// Bind makes chain id use family, replacing any earlier binding.
+func Bind(id msg.ChainId, family string) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.families[family]; !ok {
+		return fmt.Errorf("unknown receipt encoding %q, want one of %s", family, strings.Join(names(), ", "))
+	}
+	registry.chains[id] = family
+	return nil
+}
+
+// Of returns the family of chain id. Chains that aren't bound use fallback,
+// usually the family of their chain type, and Ethereum when that's unknown too.
+func Of(id msg.ChainId, fallback string) *Family {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	if name, ok := registry.chains[id]; ok {
+		return registry.families[name]
+	}
+	if f, ok := registry.families[fallback]; ok {
+		return f
+	}
+	return registry.families[Ethereum]
+}
+
+// Encode encodes the receipts of chain id with its family.
+func Encode(src Source, id msg.ChainId, fallback string, receipts []*types.Receipt) (proof.DerivableList, error) {
+	return Of(id, fallback).List(src, receipts)
+}
+
+// Families lists the registered family names.
+func Families() []string {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return names()
+}
+
+func names() []string {
+	ret := make([]string, 0, len(registry.families))
+	for name := range registry.families {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
@@ -0,0 +1,239 @@
+package receipt
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/mapprotocol/compass/internal/proof"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+	"github.com/mapprotocol/compass/pkg/msg"
+)
+
+var record = flag.String("record", "", "record a golden block with TestRecordGolden: family=endpoint@number")
+
+// golden is a block under testdata/<family>/: the eth_getBlockByNumber header
+// and the eth_getBlockReceipts result, with the deposit fields OP nodes add,
+// as TestRecordGolden saves them from a node. Its receipts must derive the
+// on-chain receiptsRoot of the header. The blocks under testdata/synthetic/
+// were written by hand and their receiptsRoot derived with these encoders, so
+// they only serve the tests that don't check an encoding.
+type golden struct {
+	ChainId  msg.ChainId       `json:"chainId"`
+	Header   json.RawMessage   `json:"header"`
+	Receipts []json.RawMessage `json:"receipts"`
+
+	ReceiptsRoot common.Hash `json:"-"`
+}
+
+// goldenSource serves the deposit fields of the golden receipts.
+type goldenSource map[common.Hash]*ethclient.OpReceipt
+
+func (s goldenSource) OpReceipt(_ context.Context, txHash common.Hash) (*ethclient.OpReceipt, error) {
+	return s[txHash], nil
+}
+
+func loadGolden(t *testing.T, file string) (*golden, []*types.Receipt, goldenSource) {
+	t.Helper()
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g golden
+	if err = json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	var header struct {
+		ReceiptsRoot common.Hash `json:"receiptsRoot"`
+	}
+	if err = json.Unmarshal(g.Header, &header); err != nil {
+		t.Fatal(err)
+	}
+	g.ReceiptsRoot = header.ReceiptsRoot
+	rs := make([]*types.Receipt, 0, len(g.Receipts))
+	src := make(goldenSource)
+	for _, raw := range g.Receipts {
+		var r types.Receipt
+		if err = json.Unmarshal(raw, &r); err != nil {
+			t.Fatal(err)
+		}
+		var fields ethclient.OpReceipt
+		if err = json.Unmarshal(raw, &fields); err != nil {
+			t.Fatal(err)
+		}
+		rs = append(rs, &r)
+		src[r.TxHash] = &fields
+	}
+	return &g, rs, src
+}
+
+func rootOf(dls proof.DerivableList) common.Hash {
+	tr, _ := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	return proof.DeriveTire(dls, tr).Hash()
+}
+
+func TestGolden(t *testing.T) {
+	for _, family := range []string{Ethereum, Optimism, Arbitrum, Matic} {
+		files, _ := filepath.Glob(filepath.Join("testdata", family, "*.json"))
+		if len(files) == 0 {
+			t.Errorf("family %s has no recorded block, record one with -record %s=<endpoint>@<number>", family, family)
+		}
+		for _, file := range files {
+			t.Run(family+"/"+filepath.Base(file), func(t *testing.T) {
+				g, rs, src := loadGolden(t, file)
+				f := Of(g.ChainId, Ethereum)
+				if f.Name != family {
+					t.Fatalf("chain %d uses %s", g.ChainId, f.Name)
+				}
+				dls, err := f.List(src, rs)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if root := rootOf(dls); root != g.ReceiptsRoot {
+					t.Fatalf("derived %s, want %s", root.Hex(), g.ReceiptsRoot.Hex())
+				}
+				for i := range rs {
+					if _, err = proof.GetVerified(g.ChainId, g.ReceiptsRoot, dls, uint(i)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestRecordGolden saves a block of a node under testdata/<family>/ as the
+// node returns it:
+//
+//	go test ./internal/receipt -run TestRecordGolden -record optimism=https://<rpc>@123456
+func TestRecordGolden(t *testing.T) {
+	if *record == "" {
+		t.Skip("no -record")
+	}
+	family, target, ok := strings.Cut(*record, "=")
+	at := strings.LastIndex(target, "@")
+	if !ok || at < 0 || registry.families[family] == nil {
+		t.Fatalf("-record %q is not family=endpoint@number", *record)
+	}
+	number, err := strconv.ParseUint(target[at+1:], 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cli, err := rpc.Dial(target[:at])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+
+	var (
+		chainId hexutil.Uint64
+		g       golden
+	)
+	if err = cli.Call(&chainId, "eth_chainId"); err != nil {
+		t.Fatal(err)
+	}
+	g.ChainId = msg.ChainId(chainId)
+	if err = cli.Call(&g.Header, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		t.Fatal(err)
+	}
+	if err = cli.Call(&g.Receipts, "eth_getBlockReceipts", hexutil.EncodeUint64(number)); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join("testdata", family, fmt.Sprintf("%d-%d.json", g.ChainId, number))
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("recorded %s", file)
+}
+
+// The L2 blocks only verify with their own family.
+func TestGoldenNeedsFamily(t *testing.T) {
+	for _, file := range []string{
+		"testdata/synthetic/optimism-canyon-deposit.json",
+		"testdata/synthetic/arbitrum-internal-and-legacy.json",
+	} {
+		g, rs, src := loadGolden(t, file)
+		dls, err := Of(0, Ethereum).List(src, rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rootOf(dls) == g.ReceiptsRoot {
+			t.Fatalf("%s verifies with the ethereum encoding", file)
+		}
+	}
+}
+
+func TestOptimismNeedsSource(t *testing.T) {
+	_, rs, _ := loadGolden(t, "testdata/synthetic/optimism-canyon-deposit.json")
+	if _, err := Of(msg.ChainId(10), Ethereum).List(nil, rs); err == nil {
+		t.Fatal("encoded deposit receipts without their deposit fields")
+	}
+}
+
+func TestBind(t *testing.T) {
+	const id = msg.ChainId(990001)
+	if f := Of(id, Matic); f.Name != Matic {
+		t.Fatalf("unbound chain uses %s, want the fallback", f.Name)
+	}
+	if f := Of(id, "unknown"); f.Name != Ethereum {
+		t.Fatalf("unknown fallback uses %s", f.Name)
+	}
+	if err := Bind(id, Optimism); err != nil {
+		t.Fatal(err)
+	}
+	if f := Of(id, Matic); f.Name != Optimism {
+		t.Fatalf("bound chain uses %s", f.Name)
+	}
+	if err := Bind(id, "zksync"); err == nil {
+		t.Fatal("bound an unregistered family")
+	}
+	if err := Register(&Family{Name: Ethereum, Encode: encodeEthereum}); err == nil {
+		t.Fatal("registered ethereum twice")
+	}
+}
+
+// A family may store receipts under other keys, like a secure trie does.
+func TestFamilyKey(t *testing.T) {
+	f := &Family{
+		Name:   "hashed-index",
+		Encode: encodeEthereum,
+		Key:    func(txIndex uint) []byte { return crypto.Keccak256(proof.IndexKey(txIndex)) },
+	}
+	if err := Register(f); err != nil {
+		t.Fatal(err)
+	}
+	_, rs, _ := loadGolden(t, "testdata/synthetic/ethereum-cancun-prague.json")
+	dls, err := f.List(nil, rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := rootOf(dls)
+	if root == rootOf(Receipts(rs)) {
+		t.Fatal("key strategy not applied")
+	}
+	for i := range rs {
+		if _, err = proof.GetVerified(1, root, dls, uint(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package spend keeps a per-day ledger of the native fees compass pays, keyed
+// by destination chain, message type and source chain, and posts a summary of
+// each finished UTC day.
+package spend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/ChainSafe/log15"
+)
+
+var defaultLedger = New()
+
+// Init starts the daily report of the process-wide ledger. alarm receives the
+// summary text; nil only logs it.
+func Init(alarm func(ctx context.Context, msg string)) {
+	defaultLedger.alarm = alarm
+	defaultLedger.Start()
+}
+
+// Add records amount (whole native tokens) on the process-wide ledger.
+func Add(chain, transferType, source string, amount float64) {
+	defaultLedger.Add(chain, transferType, source, amount)
+}
+
+// Key identifies one ledger row.
+type Key struct {
+	Chain  string
+	Type   string
+	Source string
+}
+
+// Row is the accumulated spend of one Key within a day.
+type Row struct {
+	Key
+	Amount float64
+	Txs    int64
+}
+
+type Ledger struct {
+	log   log.Logger
+	alarm func(ctx context.Context, msg string)
+
+	mu   sync.Mutex
+	day  string
+	rows map[Key]*Row
+	once sync.Once
+}
+
+func New() *Ledger {
+	return &Ledger{
+		log:  log.Root().New("func", "spend"),
+		day:  today(),
+		rows: make(map[Key]*Row),
+	}
+}
+
+// Start checks once a minute whether the day has ended and reports it.
+func (l *Ledger) Start() {
+	l.once.Do(func() {
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for range ticker.C {
+				l.rollover()
+			}
+		}()
+	})
+}
+
+func (l *Ledger) Add(chain, transferType, source string, amount float64) {
+	l.rollover()
+	k := Key{Chain: chain, Type: transferType, Source: source}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r, ok := l.rows[k]
+	if !ok {
+		r = &Row{Key: k}
+		l.rows[k] = r
+	}
+	r.Amount += amount
+	r.Txs++
+}
+
+// Today returns the rows of the current day sorted by chain, type and source.
+func (l *Ledger) Today() []Row {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return sortedRows(l.rows)
+}
+
+// rollover emits the report of the previous day once the UTC date changes.
+func (l *Ledger) rollover() {
+	now := today()
+	l.mu.Lock()
+	if now == l.day {
+		l.mu.Unlock()
+		return
+	}
+	day, rows := l.day, sortedRows(l.rows)
+	l.day = now
+	l.rows = make(map[Key]*Row)
+	l.mu.Unlock()
+
+	if len(rows) == 0 {
+		return
+	}
+	report := Format(day, rows)
+	l.log.Info("Daily spend report", "day", day, "report", report)
+	if l.alarm != nil {
+		l.alarm(context.Background(), report)
+	}
+}
+
+// Format renders the rows of day as the text of the daily report.
+func Format(day string, rows []Row) string {
+	totals := make(map[string]float64)
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "compass spend report %s", day)
+	for _, r := range rows {
+		fmt.Fprintf(&b, "\n%s %s from %s: %.6f (%d txs)", r.Chain, r.Type, r.Source, r.Amount, r.Txs)
+		totals[r.Chain] += r.Amount
+	}
+	chains := make([]string, 0, len(totals))
+	for c := range totals {
+		chains = append(chains, c)
+	}
+	sort.Strings(chains)
+	for _, c := range chains {
+		fmt.Fprintf(&b, "\n%s total: %.6f", c, totals[c])
+	}
+	return b.String()
+}
+
+func sortedRows(m map[Key]*Row) []Row {
+	ret := make([]Row, 0, len(m))
+	for _, r := range m {
+		ret = append(ret, *r)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Chain != ret[j].Chain {
+			return ret[i].Chain < ret[j].Chain
+		}
+		if ret[i].Type != ret[j].Type {
+			return ret[i].Type < ret[j].Type
+		}
+		return ret[i].Source < ret[j].Source
+	})
+	return ret
+}
+
+// now is a seam for tests.
+var now = time.Now
+
+func today() string {
+	return now().UTC().Format("2006-01-02")
+}
@@ -0,0 +1,39 @@
+package spend
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLedger_ReportsOnDayRollover(t *testing.T) {
+	day := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	prev := now
+	now = func() time.Time { return day }
+	t.Cleanup(func() { now = prev })
+
+	var reports []string
+	l := New()
+	l.alarm = func(_ context.Context, msg string) { reports = append(reports, msg) }
+	l.Add("bsc", "SwapWithProof", "eth", 0.25)
+	l.Add("bsc", "SwapWithProof", "eth", 0.5)
+	l.Add("bsc", "SyncToMap", "map", 0.1)
+	if rows := l.Today(); len(rows) != 2 || rows[0].Type != "SwapWithProof" || rows[0].Txs != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+
+	day = day.Add(2 * time.Hour)
+	l.Add("bsc", "SyncToMap", "map", 0.1)
+	if len(reports) != 1 {
+		t.Fatalf("reports = %d, want 1", len(reports))
+	}
+	for _, want := range []string{"2024-05-01", "bsc SwapWithProof from eth: 0.750000 (2 txs)", "bsc total: 0.850000"} {
+		if !strings.Contains(reports[0], want) {
+			t.Fatalf("report %q missing %q", reports[0], want)
+		}
+	}
+	if rows := l.Today(); len(rows) != 1 {
+		t.Fatalf("new day rows = %+v, want only the post-rollover entry", rows)
+	}
+}
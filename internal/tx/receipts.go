@@ -0,0 +1,212 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/ChainSafe/log15"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mapprotocol/compass/internal/observability"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+)
+
+const (
+	receiptBatchSize        = 50 // receipts per JSON-RPC batch
+	receiptBatchConcurrency = 4  // batches in flight per call
+	receiptNotFoundRetries  = 50 // rounds to wait for receipts the node doesn't have yet
+	receiptNotFoundInterval = 100 * time.Millisecond
+)
+
+const (
+	PathBlock  = "block"  // one eth_getBlockReceipts call
+	PathBatch  = "batch"  // eth_getTransactionReceipt in JSON-RPC batches
+	PathSingle = "single" // one eth_getTransactionReceipt per call, for endpoints that refuse batches
+)
+
+// blockReceiptsSupport caches, per endpoint, whether eth_getBlockReceipts is
+// available. Endpoints missing from the map haven't been probed yet.
+var blockReceiptsSupport = struct {
+	mu sync.Mutex
+	m  map[string]bool
+}{m: make(map[string]bool)}
+
+// GetBlockReceipts returns the receipts of block number, whose transactions
+// are txsHash, in block order. It uses eth_getBlockReceipts when the endpoint
+// supports it and falls back to batched eth_getTransactionReceipt otherwise.
+func GetBlockReceipts(conn *ethclient.Client, number *big.Int, txsHash []common.Hash) ([]*types.Receipt, error) {
+	if len(txsHash) == 0 {
+		return []*types.Receipt{}, nil
+	}
+	endpoint := conn.Url()
+	blockReceiptsSupport.mu.Lock()
+	supported, probed := blockReceiptsSupport.m[endpoint]
+	blockReceiptsSupport.mu.Unlock()
+
+	if !probed || supported {
+		rs, err := conn.BlockReceipts(context.Background(), number)
+		switch {
+		case err == nil && sameTxs(rs, txsHash):
+			setBlockReceiptsSupport(endpoint, true)
+			countPath(endpoint, PathBlock)
+			return rs, nil
+		case err == nil:
+			log.Debug("eth_getBlockReceipts disagrees with the block's transactions, using batched receipts",
+				"block", number, "receipts", len(rs), "txs", len(txsHash))
+		case isMethodUnsupported(err):
+			log.Info("Endpoint has no eth_getBlockReceipts, using batched receipts", "endpoint", hostOf(endpoint))
+			setBlockReceiptsSupport(endpoint, false)
+		default:
+			log.Debug("eth_getBlockReceipts failed, using batched receipts", "block", number, "err", err)
+		}
+	}
+	return batchReceipts(conn, txsHash, false)
+}
+
+func setBlockReceiptsSupport(endpoint string, supported bool) {
+	blockReceiptsSupport.mu.Lock()
+	blockReceiptsSupport.m[endpoint] = supported
+	blockReceiptsSupport.mu.Unlock()
+}
+
+func sameTxs(rs []*types.Receipt, txsHash []common.Hash) bool {
+	if len(rs) != len(txsHash) {
+		return false
+	}
+	for i, r := range rs {
+		if r == nil || r.TxHash != txsHash[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// batchReceipts fetches the receipts of txsHash in batches of receiptBatchSize,
+// receiptBatchConcurrency at a time. Receipts the node doesn't have are waited
+// for, or left nil when missingOK.
+func batchReceipts(conn *ethclient.Client, txsHash []common.Hash, missingOK bool) ([]*types.Receipt, error) {
+	var (
+		rs   = make([]*types.Receipt, len(txsHash))
+		sem  = make(chan struct{}, receiptBatchConcurrency)
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+	for start := 0; start < len(txsHash); start += receiptBatchSize {
+		end := start + receiptBatchSize
+		if end > len(txsHash) {
+			end = len(txsHash)
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start, end int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := fetchChunk(conn, txsHash[start:end], rs[start:end], missingOK); err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = err
+				}
+				mu.Unlock()
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	if errs != nil {
+		return nil, errs
+	}
+	return rs, nil
+}
+
+// fetchChunk fills rs with the receipts of txsHash.
+func fetchChunk(conn *ethclient.Client, txsHash []common.Hash, rs []*types.Receipt, missingOK bool) error {
+	endpoint := conn.Url()
+	pending := make([]int, len(txsHash))
+	for i := range pending {
+		pending[i] = i
+	}
+	for round := 0; ; round++ {
+		hashes := make([]common.Hash, 0, len(pending))
+		for _, i := range pending {
+			hashes = append(hashes, txsHash[i])
+		}
+		got, err := conn.TransactionReceipts(context.Background(), hashes)
+		if err != nil {
+			// some providers refuse batches, ask for each receipt on its own
+			got, err = singleReceipts(conn, hashes)
+			if err != nil {
+				return err
+			}
+			countPath(endpoint, PathSingle)
+		} else {
+			countPath(endpoint, PathBatch)
+		}
+
+		missing := pending[:0]
+		for j, i := range pending {
+			if got[j] == nil {
+				missing = append(missing, i)
+				continue
+			}
+			rs[i] = got[j]
+		}
+		if len(missing) == 0 || missingOK {
+			return nil
+		}
+		if round >= receiptNotFoundRetries {
+			return fmt.Errorf("receipt of %s not found", txsHash[missing[0]])
+		}
+		pending = missing
+		time.Sleep(receiptNotFoundInterval)
+	}
+}
+
+func singleReceipts(conn *ethclient.Client, txsHash []common.Hash) ([]*types.Receipt, error) {
+	rs := make([]*types.Receipt, len(txsHash))
+	for i, h := range txsHash {
+		r, err := conn.TransactionReceipt(context.Background(), h)
+		if err != nil && err.Error() != "not found" {
+			return nil, err
+		}
+		rs[i] = r
+	}
+	return rs, nil
+}
+
+// isMethodUnsupported reports whether err says the node doesn't serve the
+// method, as opposed to failing this one call.
+func isMethodUnsupported(err error) bool {
+	var rpcErr interface{ ErrorCode() int }
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"method not found", "does not exist", "not supported", "unsupported", "not available",
+		"not allowed", "unknown method"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func countPath(endpoint, path string) {
+	observability.RegisterReceiptFetch(hostOf(endpoint)).IncPath(path)
+}
+
+// hostOf strips the path and query, where providers put API keys, from endpoint.
+func hostOf(endpoint string) string {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
@@ -0,0 +1,184 @@
+package tx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/mapprotocol/compass/pkg/ethclient"
+)
+
+type rpcReq struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// receiptNode serves receipts of txs. It counts calls per method, and answers
+// eth_getBlockReceipts only when blockReceipts is set. The first notFound
+// lookups of each receipt return null.
+type receiptNode struct {
+	txs           []common.Hash
+	blockReceipts bool
+	notFound      int
+
+	mu     sync.Mutex
+	calls  map[string]int
+	misses map[common.Hash]int
+}
+
+func receiptJSON(h common.Hash, i int) map[string]interface{} {
+	return map[string]interface{}{
+		"transactionHash":   h,
+		"transactionIndex":  fmt.Sprintf("0x%x", i),
+		"blockHash":         common.HexToHash("0xb10c"),
+		"blockNumber":       "0x1",
+		"cumulativeGasUsed": fmt.Sprintf("0x%x", 21000*(i+1)),
+		"gasUsed":           "0x5208",
+		"status":            "0x1",
+		"logs":              []interface{}{},
+		"logsBloom":         "0x" + common.Bytes2Hex(make([]byte, 256)),
+		"type":              "0x2",
+	}
+}
+
+func (n *receiptNode) answer(req rpcReq) map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls[req.Method]++
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+	switch req.Method {
+	case "eth_getBlockReceipts":
+		if !n.blockReceipts {
+			resp["error"] = map[string]interface{}{"code": -32601, "message": "the method eth_getBlockReceipts does not exist/is not available"}
+			break
+		}
+		rs := make([]interface{}, 0, len(n.txs))
+		for i, h := range n.txs {
+			rs = append(rs, receiptJSON(h, i))
+		}
+		resp["result"] = rs
+	case "eth_getTransactionReceipt":
+		var h common.Hash
+		_ = json.Unmarshal(req.Params[0], &h)
+		resp["result"] = nil
+		if n.misses[h] < n.notFound {
+			n.misses[h]++
+			break
+		}
+		for i, tx := range n.txs {
+			if tx == h {
+				resp["result"] = receiptJSON(h, i)
+			}
+		}
+	default:
+		resp["error"] = map[string]interface{}{"code": -32601, "message": "method not found"}
+	}
+	return resp
+}
+
+func newReceiptNode(t *testing.T, n *receiptNode) *ethclient.Client {
+	t.Helper()
+	n.calls = make(map[string]int)
+	n.misses = make(map[common.Hash]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		body = bytes.TrimSpace(body)
+		if len(body) > 0 && body[0] == '[' {
+			var reqs []rpcReq
+			_ = json.Unmarshal(body, &reqs)
+			resps := make([]interface{}, 0, len(reqs))
+			for _, req := range reqs {
+				resps = append(resps, n.answer(req))
+			}
+			_ = json.NewEncoder(w).Encode(resps)
+			return
+		}
+		var req rpcReq
+		_ = json.Unmarshal(body, &req)
+		_ = json.NewEncoder(w).Encode(n.answer(req))
+	}))
+	t.Cleanup(srv.Close)
+	c, err := rpc.DialHTTP(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ethclient.NewClient(c, srv.URL, srv.Client())
+}
+
+func hashes(n int) []common.Hash {
+	ret := make([]common.Hash, 0, n)
+	for i := 0; i < n; i++ {
+		ret = append(ret, common.BigToHash(big.NewInt(int64(i+1))))
+	}
+	return ret
+}
+
+func checkOrder(t *testing.T, txs []common.Hash, rs []*types.Receipt) {
+	t.Helper()
+	if len(rs) != len(txs) {
+		t.Fatalf("got %d receipts, want %d", len(rs), len(txs))
+	}
+	for i, h := range txs {
+		if rs[i].TxHash != h {
+			t.Fatalf("receipt %d is of %s, want %s", i, rs[i].TxHash, h)
+		}
+	}
+}
+
+func TestGetBlockReceiptsUsesBlockMethod(t *testing.T) {
+	txs := hashes(120)
+	n := &receiptNode{txs: txs, blockReceipts: true}
+	cli := newReceiptNode(t, n)
+	rs, err := GetBlockReceipts(cli, big.NewInt(1), txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkOrder(t, txs, rs)
+	if n.calls["eth_getBlockReceipts"] != 1 || n.calls["eth_getTransactionReceipt"] != 0 {
+		t.Fatalf("calls %v", n.calls)
+	}
+}
+
+func TestGetBlockReceiptsFallsBackToBatches(t *testing.T) {
+	txs := hashes(120)
+	n := &receiptNode{txs: txs, notFound: 1}
+	cli := newReceiptNode(t, n)
+	for round := 0; round < 2; round++ {
+		rs, err := GetBlockReceipts(cli, big.NewInt(1), txs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkOrder(t, txs, rs)
+	}
+	// the missing method is remembered for the endpoint
+	if n.calls["eth_getBlockReceipts"] != 1 {
+		t.Fatalf("eth_getBlockReceipts called %d times, want 1", n.calls["eth_getBlockReceipts"])
+	}
+	// every receipt was missing once in the first round
+	if want := 2*len(txs) + len(txs); n.calls["eth_getTransactionReceipt"] != want {
+		t.Fatalf("eth_getTransactionReceipt called %d times, want %d", n.calls["eth_getTransactionReceipt"], want)
+	}
+}
+
+func TestMaticReceiptsLeaveMissingNil(t *testing.T) {
+	txs := hashes(3)
+	n := &receiptNode{txs: txs[:2]}
+	cli := newReceiptNode(t, n)
+	rs, err := GetMaticReceiptsByTxsHash(cli, txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 3 || rs[0] == nil || rs[1] == nil || rs[2] != nil {
+		t.Fatalf("got %v", rs)
+	}
+}
@@ -0,0 +1,301 @@
+// Package txerr classifies the errors returned while estimating, simulating
+// and sending relay transactions. Revert data is decoded with the MCS, light
+// node and oracle ABIs so writers can decide whether to skip, retry or alarm
+// from a Category instead of matching on error strings.
+package txerr
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/compass/internal/mapprotocol"
+)
+
+type Category int
+
+const (
+	Unknown           Category = iota
+	AlreadyProcessed           // the order, header or proposal is already on chain
+	Stale                      // the update was superseded or arrived out of order
+	NotYetVerifiable           // the proof can't be verified by the light client yet
+	Underpriced                // the fee is below what the node or pool accepts
+	NonceTooLow                // the nonce was already used, refresh it and resend
+	InsufficientFunds          // the relayer can't pay for the transaction
+	Reverted                   // the contract rejected the call for good
+	Transient                  // rpc or network hiccup
+)
+
+func (c Category) String() string {
+	switch c {
+	case AlreadyProcessed:
+		return "already-processed"
+	case Stale:
+		return "stale"
+	case NotYetVerifiable:
+		return "not-yet-verifiable"
+	case Underpriced:
+		return "underpriced"
+	case NonceTooLow:
+		return "nonce-too-low"
+	case InsufficientFunds:
+		return "insufficient-funds"
+	case Reverted:
+		return "reverted"
+	case Transient:
+		return "transient"
+	default:
+		return "unknown"
+	}
+}
+
+// Skip reports whether the message can be treated as done: there is nothing
+// left for this relayer to send.
+func (c Category) Skip() bool {
+	return c == AlreadyProcessed || c == Stale
+}
+
+// Urgent reports whether an operator should hear about the failure right away
+// instead of after the usual run of retries.
+func (c Category) Urgent() bool {
+	return c == Reverted || c == InsufficientFunds
+}
+
+// Error is a classified error. Reason is the decoded revert reason, if any.
+type Error struct {
+	Category Category
+	Reason   string
+	Data     []byte
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s (%s): %v", e.Category, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Category, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Classify decodes any revert data carried by err and sorts it into a
+// Category. It returns nil for a nil error.
+func Classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var ce *Error
+	if errors.As(err, &ce) {
+		return ce
+	}
+	ret := &Error{Category: Unknown, Err: err}
+	text := err.Error()
+	if data := RevertData(err); len(data) != 0 {
+		ret.Data = data
+		ret.Reason, _ = Decode(data)
+	}
+	if c, ok := matchReason(ret.Reason); ok {
+		ret.Category = c
+		return ret
+	}
+	if c, ok := matchReason(text); ok {
+		ret.Category = c
+		return ret
+	}
+	switch {
+	case ret.Data != nil, containsAny(text, revertedMsgs):
+		ret.Category = Reverted
+	case isTransient(err, text):
+		ret.Category = Transient
+	}
+	return ret
+}
+
+// Of is shorthand for Classify(err).Category; Unknown for a nil error.
+func Of(err error) Category {
+	if ce := Classify(err); ce != nil {
+		return ce.Category
+	}
+	return Unknown
+}
+
+// Alarm counts another failed attempt of a message in count and reports
+// whether the retry loop should alarm: on every tenth failure in a row, and
+// once early on the first urgent one. alarmed remembers that early alarm so a
+// message that keeps reverting isn't reported on every retry; both live for
+// one message only.
+func Alarm(count *int64, alarmed *bool, err error) bool {
+	*count++
+	if *count >= 10 {
+		*count = 0
+		return true
+	}
+	if *alarmed || !Of(err).Urgent() {
+		return false
+	}
+	*alarmed = true
+	return true
+}
+
+var (
+	errorSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+	// customErrors indexes the custom Solidity errors of the contracts compass
+	// sends to by selector.
+	customErrors = indexErrors(mapprotocol.ErrorsAbi, mapprotocol.Mcs, mapprotocol.LightManger,
+		mapprotocol.OracleAbi, mapprotocol.SignerAbi)
+)
+
+func indexErrors(abis ...abi.ABI) map[[4]byte]abi.Error {
+	ret := make(map[[4]byte]abi.Error)
+	for _, a := range abis {
+		for _, e := range a.Errors {
+			var sel [4]byte
+			copy(sel[:], e.ID[:4])
+			ret[sel] = e
+		}
+	}
+	return ret
+}
+
+// Decode turns revert data into a readable reason: the string of Error(string),
+// the code of Panic(uint256), or the name and arguments of a known custom error.
+func Decode(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	switch {
+	case bytes.Equal(data[:4], errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		return reason, err == nil
+	case bytes.Equal(data[:4], panicSelector):
+		if len(data) < 36 {
+			return "", false
+		}
+		return fmt.Sprintf("panic(0x%x)", new(big.Int).SetBytes(data[4:36])), true
+	}
+	var sel [4]byte
+	copy(sel[:], data[:4])
+	e, ok := customErrors[sel]
+	if !ok {
+		return "", false
+	}
+	args, err := e.Inputs.Unpack(data[4:])
+	if err != nil || len(args) == 0 {
+		return e.Name + "()", true
+	}
+	return fmt.Sprintf("%s%v", e.Name, args), true
+}
+
+var hexPattern = regexp.MustCompile(`0x[0-9a-fA-F]{8,}`)
+
+// RevertData extracts the revert payload of err: the JSON-RPC error data when
+// the node returned it, otherwise the first hex blob in the message (which is
+// how SelfEstimateGas and the Tron writer report it) that decodes as a revert.
+func RevertData(err error) []byte {
+	type dataError interface {
+		ErrorData() interface{}
+	}
+	var de dataError
+	if errors.As(err, &de) {
+		if raw, ok := de.ErrorData().(string); ok {
+			if data, derr := hex.DecodeString(strings.TrimPrefix(raw, "0x")); derr == nil && len(data) >= 4 {
+				return data
+			}
+		}
+	}
+	var first []byte
+	for _, m := range hexPattern.FindAllString(err.Error(), -1) {
+		h := m[2:]
+		if len(h)%2 == 1 {
+			h = h[:len(h)-1]
+		}
+		data, derr := hex.DecodeString(h)
+		if derr != nil {
+			continue
+		}
+		if _, ok := Decode(data); ok {
+			return data
+		}
+		if first == nil && len(data) == 4 {
+			first = data
+		}
+	}
+	return first
+}
+
+// reasons maps revert reasons and node messages onto categories. The skip
+// entries carry over the substrings the writers used to ignore.
+var reasons = []struct {
+	category Category
+	matches  []string
+}{
+	{AlreadyProcessed, []string{
+		"order exist", "order_exist", "already_meet", "already_proposal", "already verified",
+		"oracle: already update", "Header is have", "header is have", "Validators repetition add",
+		"already in use",         // solana order account exists
+		"REVERT opcode executed", // tron and klaytn report already-relayed orders without a reason
+		"0x98087555", "0x6838b56d", "0x8bc9d07c",
+	}},
+	{Stale, []string{
+		"height error", "Height error", "Update height0 error", "invalid start block",
+		"invalid syncing block", "initialized or unknown epoch", "no need to update exe headers",
+		"New block must have higher height", "round mismatch", "epoch mismatch",
+		"headers size too big", "invalid end exe header number",
+		"the update finalized slot should be higher than the finalized slot",
+		"previous exe block headers should be updated before update light client",
+	}},
+	{NotYetVerifiable, []string{"not verify able"}},
+	{NonceTooLow, []string{"nonce too low"}},
+	{Underpriced, []string{
+		"underpriced", "fee too low", "gas price too low", "max fee per gas less than block base fee",
+		"feeCap too low", "tip too low",
+	}},
+	{InsufficientFunds, []string{"insufficient funds", "not have enough balance"}},
+}
+
+var revertedMsgs = []string{"execution reverted", "contract result failed"}
+
+var transientMsgs = []string{
+	"timeout", "timed out", "connection refused", "connection reset", "EOF", "no such host",
+	"too many requests", "Too Many Requests", "Bad Gateway", "Service Unavailable", "Gateway Timeout",
+	"header not found", "temporarily unavailable",
+}
+
+func matchReason(s string) (Category, bool) {
+	if s == "" {
+		return Unknown, false
+	}
+	for _, r := range reasons {
+		if containsAny(s, r.matches) {
+			return r.category, true
+		}
+	}
+	return Unknown, false
+}
+
+func isTransient(err error, text string) bool {
+	var ne net.Error
+	if errors.As(err, &ne) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	return containsAny(text, transientMsgs)
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package txerr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+type rpcDataError struct {
+	msg  string
+	data interface{}
+}
+
+func (e *rpcDataError) Error() string          { return e.msg }
+func (e *rpcDataError) ErrorData() interface{} { return e.data }
+
+// revertString is the ABI encoding of Error("order exist").
+const revertString = "0x08c379a0" +
+	"0000000000000000000000000000000000000000000000000000000000000020" +
+	"000000000000000000000000000000000000000000000000000000000000000b" +
+	"6f72646572206578697374000000000000000000000000000000000000000000"
+
+func TestClassify(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		want   Category
+		reason string
+	}{
+		{"revert string in rpc data", &rpcDataError{"execution reverted", revertString}, AlreadyProcessed, "order exist"},
+		{"custom error in rpc data", &rpcDataError{"execution reverted", "0x6838b56d"}, AlreadyProcessed, "already_meet()"},
+		{"custom error in message", errors.New("execution reverted:0x98087555"), AlreadyProcessed, "order_exist()"},
+		{"stale header", errors.New("execution reverted: Height error"), Stale, ""},
+		{"unknown revert", &rpcDataError{"execution reverted", "0xdeadbeef"}, Reverted, ""},
+		{"nonce", errors.New("nonce too low"), NonceTooLow, ""},
+		{"underpriced", errors.New("replacement transaction underpriced"), Underpriced, ""},
+		{"funds", fmt.Errorf("send: %w", errors.New("insufficient funds for gas * price + value")), InsufficientFunds, ""},
+		{"rpc", errors.New("429 Too Many Requests: rate limited"), Transient, ""},
+		{"other", errors.New("something odd"), Unknown, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ce := Classify(tc.err)
+			if ce.Category != tc.want {
+				t.Fatalf("category = %s, want %s (%v)", ce.Category, tc.want, ce)
+			}
+			if ce.Reason != tc.reason {
+				t.Fatalf("reason = %q, want %q", ce.Reason, tc.reason)
+			}
+			if !errors.Is(ce, tc.err) {
+				t.Fatal("classified error must wrap the original")
+			}
+		})
+	}
+	if Classify(nil) != nil || Of(nil) != Unknown {
+		t.Fatal("nil error must classify as nil / Unknown")
+	}
+}
+
+func TestDecodePanic(t *testing.T) {
+	data := hexutil.MustDecode("0x4e487b71" + "0000000000000000000000000000000000000000000000000000000000000011")
+	if reason, ok := Decode(data); !ok || reason != "panic(0x11)" {
+		t.Fatalf("Decode = %q, %v", reason, ok)
+	}
+}
+
+func TestAlarmOnceOnRevert(t *testing.T) {
+	var (
+		count   int64
+		alarmed bool
+		alarms  int
+		err     = &rpcDataError{"execution reverted", "0xdeadbeef"}
+	)
+	for i := 0; i < 9; i++ {
+		if Alarm(&count, &alarmed, err) {
+			alarms++
+		}
+	}
+	if alarms != 1 {
+		t.Fatalf("9 reverted retries alarmed %d times, want 1", alarms)
+	}
+	if !Alarm(&count, &alarmed, err) {
+		t.Fatal("the tenth failure must still alarm")
+	}
+	if count != 0 || Alarm(&count, &alarmed, err) {
+		t.Fatal("the early alarm must not repeat after the counted one")
+	}
+}
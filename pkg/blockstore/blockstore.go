@@ -4,12 +4,14 @@
 package blockstore
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/mapprotocol/compass/internal/mapprotocol"
 	"github.com/mapprotocol/compass/pkg/msg"
 	"math/big"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const PathPostfix = ".compass/blockstore"
@@ -18,14 +20,26 @@ type Blockstorer interface {
 	StoreBlock(*big.Int) error
 }
 
+// Cursorstorer keeps where a chain read up to when a block number can't tell.
+type Cursorstorer interface {
+	StoreCursor(cursor interface{}) error
+	TryLoadCursor(cursor interface{}) (bool, error)
+}
+
 var _ Blockstorer = &EmptyStore{}
 var _ Blockstorer = &Blockstore{}
+var _ Cursorstorer = &EmptyStore{}
+var _ Cursorstorer = &Blockstore{}
 
 // Dummy store for testing only
 type EmptyStore struct{}
 
 func (s *EmptyStore) StoreBlock(_ *big.Int) error { return nil }
 
+func (s *EmptyStore) StoreCursor(_ interface{}) error { return nil }
+
+func (s *EmptyStore) TryLoadCursor(_ interface{}) (bool, error) { return false, nil }
+
 // Blockstore implements Blockstorer.
 type Blockstore struct {
 	path     string // Path excluding filename
@@ -90,6 +104,40 @@ func (b *Blockstore) TryLoadLatestBlock() (*big.Int, error) {
 	return big.NewInt(0), nil
 }
 
+// StoreCursor writes the cursor, as json, next to the block number.
+func (b *Blockstore) StoreCursor(cursor interface{}) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	if _, err = os.Stat(b.path); os.IsNotExist(err) {
+		if err = os.MkdirAll(b.path, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(b.cursorPath(), data, 0600)
+}
+
+// TryLoadCursor will attempt to load the stored cursor into cursor, returning false if there is none.
+func (b *Blockstore) TryLoadCursor(cursor interface{}) (bool, error) {
+	exists, err := fileExists(b.cursorPath())
+	if err != nil || !exists {
+		return false, err
+	}
+	data, err := os.ReadFile(b.cursorPath())
+	if err != nil {
+		return false, err
+	}
+	if err = json.Unmarshal(data, cursor); err != nil {
+		return false, fmt.Errorf("cursor %s: %w", b.cursorPath(), err)
+	}
+	return true, nil
+}
+
+func (b *Blockstore) cursorPath() string {
+	return strings.TrimSuffix(b.fullPath, ".block") + ".cursor"
+}
+
 func getFileName(chain msg.ChainId, relayer string, role mapprotocol.Role) string {
 	return fmt.Sprintf("%s-%d-%s.block", relayer, chain, role)
 }
@@ -70,3 +70,28 @@ func TestSaveAndLoad(t *testing.T) {
 		t.Fatalf("Expected: %d got: %d", block.Uint64(), latest.Uint64())
 	}
 }
+
+func TestSaveAndLoadCursor(t *testing.T) {
+	bs, err := NewBlockstore(t.TempDir(), msg.ChainId(10), constant.ZeroAddress.String(), mapprotocol.RoleOfMessenger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cursor := map[string]string{}
+	if ok, err := bs.TryLoadCursor(&cursor); ok || err != nil {
+		t.Fatalf("loaded %v, %v", ok, err)
+	}
+
+	if err = bs.StoreCursor(map[string]string{"until": "5Xy"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = bs.StoreBlock(big.NewInt(999)); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := bs.TryLoadCursor(&cursor); !ok || err != nil || cursor["until"] != "5Xy" {
+		t.Fatalf("loaded %v %v, %v", cursor, ok, err)
+	}
+	block, err := bs.TryLoadLatestBlock()
+	if err != nil || block.Int64() != 999 {
+		t.Fatalf("block %v, %v", block, err)
+	}
+}
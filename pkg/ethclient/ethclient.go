@@ -160,6 +160,22 @@ func (ec *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.H
 	return head, err
 }
 
+// ReceiptsRootByNumber returns only the receipts root of a block, for chains whose
+// headers decode into neither go-ethereum's header nor MAP's.
+func (ec *Client) ReceiptsRootByNumber(ctx context.Context, number *big.Int) (common.Hash, error) {
+	var head *struct {
+		ReceiptsRoot common.Hash `json:"receiptsRoot"`
+	}
+	err := ec.c.CallContext(ctx, &head, "eth_getBlockByNumber", toBlockNumArg(number), false)
+	if err == nil && head == nil {
+		err = ethereum.NotFound
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return head.ReceiptsRoot, nil
+}
+
 type rpcTransaction struct {
 	tx *types.Transaction
 	txExtraInfo
@@ -274,6 +290,66 @@ func (ec *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*
 	return r, err
 }
 
+// BlockReceipts returns the receipts of every transaction in block number, in
+// block order, with eth_getBlockReceipts.
+func (ec *Client) BlockReceipts(ctx context.Context, number *big.Int) ([]*types.Receipt, error) {
+	var rs []*types.Receipt
+	err := ec.c.CallContext(ctx, &rs, "eth_getBlockReceipts", toBlockNumArg(number))
+	if err == nil && rs == nil {
+		return nil, ethereum.NotFound
+	}
+	return rs, err
+}
+
+// TransactionReceipts fetches the receipts of txHashes in one JSON-RPC batch.
+// The receipt of a transaction the node doesn't know is left nil.
+func (ec *Client) TransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	rs := make([]*types.Receipt, len(txHashes))
+	reqs := make([]rpc.BatchElem, len(txHashes))
+	for i, h := range txHashes {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{h},
+			Result: &rs[i],
+		}
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		return nil, err
+	}
+	for i := range reqs {
+		if reqs[i].Error != nil {
+			return nil, reqs[i].Error
+		}
+	}
+	return rs, nil
+}
+
+// Url is the endpoint the client is connected to.
+func (ec *Client) Url() string {
+	return ec.url
+}
+
+// ReceiptFee returns gasUsed * effectiveGasPrice of a mined transaction, i.e.
+// the native amount the sender paid. The vendored receipt type predates the
+// effectiveGasPrice field, so it is decoded here directly.
+func (ec *Client) ReceiptFee(ctx context.Context, txHash common.Hash) (*big.Int, error) {
+	var r *struct {
+		GasUsed           hexutil.Uint64 `json:"gasUsed"`
+		EffectiveGasPrice *hexutil.Big   `json:"effectiveGasPrice"`
+	}
+	err := ec.c.CallContext(ctx, &r, "eth_getTransactionReceipt", txHash)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, ethereum.NotFound
+	}
+	if r.EffectiveGasPrice == nil {
+		return nil, fmt.Errorf("receipt of %s has no effectiveGasPrice", txHash)
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(uint64(r.GasUsed)), r.EffectiveGasPrice.ToInt()), nil
+}
+
 type rpcProgress struct {
 	StartingBlock hexutil.Uint64
 	CurrentBlock  hexutil.Uint64
@@ -489,6 +565,47 @@ func (ec *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return (*big.Int)(&hex), nil
 }
 
+// FeeHistory is the decoded eth_feeHistory result.
+type FeeHistory struct {
+	OldestBlock  *big.Int
+	Reward       [][]*big.Int // per block, one entry per requested percentile
+	BaseFee      []*big.Int   // per block, plus the next block's base fee
+	GasUsedRatio []float64
+}
+
+// FeeHistory returns base fees and the requested tip percentiles of the
+// blockCount blocks ending at lastBlock (nil for latest).
+func (ec *Client) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*FeeHistory, error) {
+	var res struct {
+		OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+		Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+		BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+		GasUsedRatio []float64        `json:"gasUsedRatio"`
+	}
+	if err := ec.c.CallContext(ctx, &res, "eth_feeHistory", hexutil.Uint(blockCount), toBlockNumArg(lastBlock), rewardPercentiles); err != nil {
+		return nil, err
+	}
+	if res.OldestBlock == nil {
+		return nil, errors.New("eth_feeHistory returned no oldestBlock")
+	}
+	ret := &FeeHistory{
+		OldestBlock:  res.OldestBlock.ToInt(),
+		Reward:       make([][]*big.Int, len(res.Reward)),
+		BaseFee:      make([]*big.Int, len(res.BaseFee)),
+		GasUsedRatio: res.GasUsedRatio,
+	}
+	for i, r := range res.Reward {
+		ret.Reward[i] = make([]*big.Int, len(r))
+		for j, v := range r {
+			ret.Reward[i][j] = v.ToInt()
+		}
+	}
+	for i, b := range res.BaseFee {
+		ret.BaseFee[i] = b.ToInt()
+	}
+	return ret, nil
+}
+
 // EstimateGas tries to estimate the gas needed to execute a specific transaction based on
 // the current pending state of the backend blockchain. There is no guarantee that this is
 // the true gas limit requirement as other transactions may be added or removed by miners,
@@ -502,6 +619,17 @@ func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64
 	return uint64(hex), nil
 }
 
+// EstimateGasAt estimates the gas of msg against the state of blockNumber, the
+// latest block when nil and the pending state when -1.
+func (ec *Client) EstimateGasAt(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (uint64, error) {
+	var hex hexutil.Uint64
+	err := ec.c.CallContext(ctx, &hex, "eth_estimateGas", toCallArg(msg), toBlockNumArg(blockNumber))
+	if err != nil {
+		return 0, err
+	}
+	return uint64(hex), nil
+}
+
 // SendTransaction injects a signed transaction into the pending pool for execution.
 //
 // If the transaction was a contract creation use the TransactionReceipt method to get the
@@ -7,6 +7,9 @@ import (
 	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	tronaddress "github.com/lbtsm/gotron-sdk/pkg/address"
+	tronkeystore "github.com/lbtsm/gotron-sdk/pkg/keystore"
 	"github.com/mapprotocol/near-api-go/pkg/types"
 	"github.com/mapprotocol/near-api-go/pkg/types/key"
 )
@@ -41,6 +44,42 @@ func KeypairFromEth(path string) (*keystore.Key, error) {
 	return ret, nil
 }
 
+// TronAddress is the base58 Tron address of the secp256k1 key of kp, the
+// one an Ethereum keystore file signs Tron transactions for.
+func TronAddress(kp *keystore.Key) string {
+	return tronaddress.PubkeyToAddress(kp.PrivateKey.PublicKey).String()
+}
+
+// KeypairFromTron decrypts keyjson, a key file of the gotron keystore, with
+// auth.
+func KeypairFromTron(keyjson []byte, auth string) (*keystore.Key, error) {
+	key, err := tronkeystore.DecryptKey(keyjson, auth)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptKey failed, err:%s", err)
+	}
+	ret := &keystore.Key{
+		Address:    crypto.PubkeyToAddress(key.PrivateKey.PublicKey),
+		PrivateKey: key.PrivateKey,
+	}
+	copy(ret.Id[:], key.ID)
+	return ret, nil
+}
+
+// EthKeyFromTron decrypts keyjson, a key file of the gotron keystore, with
+// auth and encrypts it with newAuth into an Ethereum keystore file, the one
+// the tron chains sign with.
+func EthKeyFromTron(keyjson []byte, auth, newAuth string, scryptN, scryptP int) ([]byte, *keystore.Key, error) {
+	ret, err := KeypairFromTron(keyjson, auth)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := keystore.EncryptKey(ret, newAuth, scryptN, scryptP)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, ret, nil
+}
+
 func NearKeyPairFrom(networkName, path string, id types.AccountID) (kp key.KeyPair, err error) {
 	var creds struct {
 		AccountID  types.AccountID     `json:"account_id"`
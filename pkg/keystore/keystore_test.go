@@ -0,0 +1,41 @@
+package keystore
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	tronaddress "github.com/lbtsm/gotron-sdk/pkg/address"
+	tronkeystore "github.com/lbtsm/gotron-sdk/pkg/keystore"
+	"github.com/pborman/uuid"
+)
+
+func TestEthKeyFromTron(t *testing.T) {
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tronKey := &tronkeystore.Key{ID: uuid.NewRandom(), Address: tronaddress.PubkeyToAddress(priv.PublicKey), PrivateKey: priv}
+	tronJson, err := tronkeystore.EncryptKey(tronKey, "old", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err = EthKeyFromTron(tronJson, "wrong", "new", 2, 1); err == nil {
+		t.Fatal("decrypted with the wrong password")
+	}
+	ethJson, converted, err := EthKeyFromTron(tronJson, "old", "new", 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp, err := keystore.DecryptKey(ethJson, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !kp.PrivateKey.Equal(priv) || kp.Address != converted.Address || kp.Id.String() != tronKey.ID.String() {
+		t.Fatalf("converted %s, id %s", kp.Address, kp.Id)
+	}
+	if got := TronAddress(kp); got != tronKey.Address.String() || got[0] != 'T' {
+		t.Fatalf("tron address %s, want %s", got, tronKey.Address)
+	}
+}